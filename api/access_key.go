@@ -1,6 +1,9 @@
 package api
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/infrahq/infra/internal/validate"
 	"github.com/infrahq/infra/uid"
 )
@@ -44,6 +47,7 @@ type CreateAccessKeyRequest struct {
 	Name              string   `json:"name"`
 	Expiry            Duration `json:"expiry" note:"maximum time valid"`
 	InactivityTimeout Duration `json:"inactivityTimeout" note:"key must be used within this duration to remain valid"`
+	Scopes            []string `json:"scopes" note:"limit the key to these scopes, in the form resource:action or resource:action:id. The key can be used for any route when empty" example:"['grants:read', 'groups:write:4yJ3n3D8E2']"`
 }
 
 func (r CreateAccessKeyRequest) ValidationRules() []validate.ValidationRule {
@@ -54,19 +58,116 @@ func (r CreateAccessKeyRequest) ValidationRules() []validate.ValidationRule {
 		validate.Enum("issuedForKind", r.IssuedForKind, []string{KeyIssuedForKindUser, KeyIssuedForKindProvider, KeyIssuedForKindOrganization}),
 		validate.Required("expiry", r.Expiry),
 		validate.Required("inactivityTimeout", r.InactivityTimeout),
+		validate.ValidatorFunc(func() *validate.Failure {
+			for _, scope := range r.Scopes {
+				if _, err := ParseAccessKeyScope(scope); err != nil {
+					return validate.Fail("scopes", err.Error())
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+// Actions of an access key scope. The write action includes read.
+const (
+	AccessKeyScopeRead  = "read"
+	AccessKeyScopeWrite = "write"
+)
+
+// AccessKeyScopeResources are the resources that can be used in an access key
+// scope.
+var AccessKeyScopeResources = []string{
+	"access-keys",
+	"access-requests",
+	"audit-events",
+	"destinations",
+	"grants",
+	"groups",
+	"organizations",
+	"providers",
+	"users",
+	"workload-identities",
+}
+
+// AccessKeyScope limits the routes that an access key can be used for. A
+// scope has the form resource:action, for example grants:read, or
+// resource:action:id to only allow the routes for a single resource, for
+// example groups:write:4yJ3n3D8E2.
+type AccessKeyScope struct {
+	Resource string
+	Action   string
+	// ID of the resource, or 0 when the scope allows all resources of the kind
+	ID uid.ID
+}
+
+func (s AccessKeyScope) String() string {
+	if s.ID != 0 {
+		return s.Resource + ":" + s.Action + ":" + s.ID.String()
+	}
+	return s.Resource + ":" + s.Action
+}
+
+// Allows returns true if the scope allows the action on the resource. id is
+// the ID of the resource for routes that act on a single resource, or 0 for
+// routes that act on all resources of the kind.
+func (s AccessKeyScope) Allows(resource, action string, id uid.ID) bool {
+	if s.Resource != resource {
+		return false
+	}
+	if action == AccessKeyScopeWrite && s.Action != AccessKeyScopeWrite {
+		return false
 	}
+	return s.ID == 0 || s.ID == id
+}
+
+// ParseAccessKeyScope parses a scope in the form resource:action or
+// resource:action:id.
+func ParseAccessKeyScope(scope string) (AccessKeyScope, error) {
+	parts := strings.Split(scope, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return AccessKeyScope{}, fmt.Errorf("scope %q must be in the form resource:action or resource:action:id", scope)
+	}
+
+	s := AccessKeyScope{Resource: parts[0], Action: parts[1]}
+	if !isAccessKeyScopeResource(s.Resource) {
+		return AccessKeyScope{}, fmt.Errorf("scope %q has an unknown resource, must be one of (%s)",
+			scope, strings.Join(AccessKeyScopeResources, ", "))
+	}
+	if s.Action != AccessKeyScopeRead && s.Action != AccessKeyScopeWrite {
+		return AccessKeyScope{}, fmt.Errorf("scope %q has an unknown action, must be one of (%s, %s)",
+			scope, AccessKeyScopeRead, AccessKeyScopeWrite)
+	}
+	if len(parts) == 3 {
+		id, err := uid.Parse([]byte(parts[2]))
+		if err != nil || id == 0 {
+			return AccessKeyScope{}, fmt.Errorf("scope %q has an invalid id", scope)
+		}
+		s.ID = id
+	}
+	return s, nil
+}
+
+func isAccessKeyScopeResource(resource string) bool {
+	for _, r := range AccessKeyScopeResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
 }
 
 type CreateAccessKeyResponse struct {
-	ID                uid.ID `json:"id"`
-	Created           Time   `json:"created"`
-	Name              string `json:"name"`
-	IssuedForID       uid.ID `json:"issuedForID"`
-	IssuedForKind     string `json:"issuedForKind"`
-	ProviderID        uid.ID `json:"providerID"`
-	Expires           Time   `json:"expires" note:"after this deadline the key is no longer valid"`
-	InactivityTimeout Time   `json:"inactivityTimeout" note:"the key must be used by this time to remain valid"`
-	AccessKey         string `json:"accessKey"`
+	ID                uid.ID   `json:"id"`
+	Created           Time     `json:"created"`
+	Name              string   `json:"name"`
+	IssuedForID       uid.ID   `json:"issuedForID"`
+	IssuedForKind     string   `json:"issuedForKind"`
+	ProviderID        uid.ID   `json:"providerID"`
+	Expires           Time     `json:"expires" note:"after this deadline the key is no longer valid"`
+	InactivityTimeout Time     `json:"inactivityTimeout" note:"the key must be used by this time to remain valid"`
+	Scopes            []string `json:"scopes" note:"the scopes that limit what the key can be used for"`
+	AccessKey         string   `json:"accessKey"`
 }
 
 // ValidateName returns a standard validation rule for all name fields. The
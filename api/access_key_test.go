@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/uid"
+)
+
+func TestParseAccessKeyScope(t *testing.T) {
+	type testCase struct {
+		scope       string
+		expected    AccessKeyScope
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{scope: "grants:read", expected: AccessKeyScope{Resource: "grants", Action: "read"}},
+		{scope: "destinations:write", expected: AccessKeyScope{Resource: "destinations", Action: "write"}},
+		{scope: "groups:write:yqWx92Z", expected: AccessKeyScope{Resource: "groups", Action: "write", ID: uid.ID(1234567890123)}},
+		{scope: "create-key", expectedErr: `scope "create-key" must be in the form resource:action or resource:action:id`},
+		{scope: "groups:write:4yJ3n3D8E2:extra", expectedErr: "must be in the form resource:action or resource:action:id"},
+		{scope: "secrets:read", expectedErr: `scope "secrets:read" has an unknown resource`},
+		{scope: "grants:delete", expectedErr: `scope "grants:delete" has an unknown action, must be one of (read, write)`},
+		{scope: "groups:write:not-an-id", expectedErr: `scope "groups:write:not-an-id" has an invalid id`},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ParseAccessKeyScope(tc.scope)
+		if tc.expectedErr != "" {
+			assert.ErrorContains(t, err, tc.expectedErr, tc.scope)
+			continue
+		}
+		assert.NilError(t, err, tc.scope)
+		assert.Equal(t, actual, tc.expected, tc.scope)
+		assert.Equal(t, actual.String(), tc.scope)
+	}
+}
+
+func TestAccessKeyScope_Allows(t *testing.T) {
+	groupID := uid.ID(1234)
+
+	type testCase struct {
+		scope    string
+		resource string
+		action   string
+		id       uid.ID
+		expected bool
+	}
+
+	testCases := []testCase{
+		{scope: "grants:read", resource: "grants", action: "read", expected: true},
+		{scope: "grants:read", resource: "grants", action: "write", expected: false},
+		{scope: "grants:read", resource: "groups", action: "read", expected: false},
+		{scope: "grants:write", resource: "grants", action: "read", expected: true},
+		{scope: "grants:write", resource: "grants", action: "write", id: 55, expected: true},
+		{scope: "groups:write:" + groupID.String(), resource: "groups", action: "write", id: groupID, expected: true},
+		{scope: "groups:write:" + groupID.String(), resource: "groups", action: "write", id: 55, expected: false},
+		{scope: "groups:write:" + groupID.String(), resource: "groups", action: "read", expected: false},
+	}
+
+	for _, tc := range testCases {
+		scope, err := ParseAccessKeyScope(tc.scope)
+		assert.NilError(t, err)
+		actual := scope.Allows(tc.resource, tc.action, tc.id)
+		assert.Equal(t, actual, tc.expected, "scope=%v %v:%v:%v", tc.scope, tc.resource, tc.action, tc.id)
+	}
+}
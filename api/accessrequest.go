@@ -0,0 +1,75 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+const (
+	AccessRequestStatusPending  = "pending"
+	AccessRequestStatusApproved = "approved"
+	AccessRequestStatusDenied   = "denied"
+)
+
+type AccessRequest struct {
+	ID      uid.ID `json:"id" note:"ID of the access request" example:"4yJ3n3D8E2"`
+	Created Time   `json:"created"`
+	Updated Time   `json:"updated"`
+
+	UserID        uid.ID   `json:"userID" note:"ID of the user who requested access" example:"6hNnjfjVcc"`
+	Privilege     string   `json:"privilege" note:"a role or permission" example:"view"`
+	Resource      string   `json:"resource" note:"a resource name in Infra's Universal Resource Notation" example:"production.namespace"`
+	Justification string   `json:"justification" note:"the reason access is needed" example:"investigating incident 1234"`
+	Duration      Duration `json:"duration" note:"how long access lasts after the request is approved, 0 if access does not expire" example:"8h0m0s"`
+	Status        string   `json:"status" note:"one of pending, approved, or denied" example:"pending"`
+	ReviewedBy    uid.ID   `json:"reviewedBy,omitempty" note:"ID of the user who approved or denied the request"`
+	Reviewed      Time     `json:"reviewed" note:"time the request was approved or denied"`
+	GrantID       uid.ID   `json:"grantID,omitempty" note:"ID of the grant created when the request was approved"`
+}
+
+type CreateAccessRequestRequest struct {
+	Privilege     string   `json:"privilege" note:"a role or permission" example:"view"`
+	Resource      string   `json:"resource" note:"a resource name in Infra's Universal Resource Notation" example:"production.namespace"`
+	Justification string   `json:"justification" note:"the reason access is needed" example:"investigating incident 1234"`
+	Duration      Duration `json:"duration" note:"if set, access expires this long after the request is approved" example:"8h"`
+}
+
+func (r CreateAccessRequestRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("privilege", r.Privilege),
+		validate.Required("resource", r.Resource),
+		validate.Required("justification", r.Justification),
+		validate.StringRule{
+			Name:      "justification",
+			Value:     r.Justification,
+			MaxLength: 1024,
+		},
+		validate.ValidatorFunc(func() *validate.Failure {
+			if r.Duration < 0 {
+				return validate.Fail("duration", "must be a positive duration")
+			}
+			return nil
+		}),
+	}
+}
+
+type ListAccessRequestsRequest struct {
+	UserID uid.ID `form:"userID" note:"ID of the user who requested access"`
+	Status string `form:"status" note:"status of the access requests to list" example:"pending"`
+	PaginationRequest
+}
+
+func (r ListAccessRequestsRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Enum("status", r.Status, []string{
+			AccessRequestStatusPending,
+			AccessRequestStatusApproved,
+			AccessRequestStatusDenied,
+		}),
+	}
+}
+
+func (r ListAccessRequestsRequest) SetPage(page int) Paginatable {
+	r.PaginationRequest.Page = page
+	return r
+}
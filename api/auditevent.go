@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+const (
+	AuditEventOutcomeSuccess = "success"
+	AuditEventOutcomeFailure = "failure"
+)
+
+type AuditEvent struct {
+	ID      uid.ID `json:"id" note:"ID of the audit event" example:"4yJ3n3D8E2"`
+	Created Time   `json:"created" note:"time the API call was made"`
+
+	ActorID    uid.ID `json:"actorID,omitempty" note:"ID of the user who made the API call" example:"6hNnjfjVcc"`
+	ActorName  string `json:"actorName,omitempty" note:"name of the user who made the API call" example:"admin@example.com"`
+	Method     string `json:"method" note:"HTTP method of the API call" example:"DELETE"`
+	Route      string `json:"route" note:"API route that was called" example:"/api/grants/:id"`
+	TargetID   uid.ID `json:"targetID,omitempty" note:"ID of the resource the API call acted on" example:"3w9XyTrkzk"`
+	Request    string `json:"request" note:"summary of the request body, with sensitive fields redacted" example:"{\"privilege\":\"view\",\"resource\":\"production\"}"`
+	Outcome    string `json:"outcome" note:"one of success or failure" example:"success"`
+	StatusCode int    `json:"statusCode" note:"HTTP status code of the response" example:"201"`
+}
+
+type ListAuditEventsRequest struct {
+	ActorID  uid.ID `form:"actorID" note:"ID of the user who made the API call"`
+	TargetID uid.ID `form:"targetID" note:"ID of the resource the API call acted on"`
+	Route    string `form:"route" note:"API route that was called" example:"/api/grants/:id"`
+	Outcome  string `form:"outcome" note:"outcome of the API call" example:"failure"`
+	PaginationRequest
+}
+
+func (r ListAuditEventsRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Enum("outcome", r.Outcome, []string{
+			AuditEventOutcomeSuccess,
+			AuditEventOutcomeFailure,
+		}),
+	}
+}
+
+func (r ListAuditEventsRequest) SetPage(page int) Paginatable {
+	r.PaginationRequest.Page = page
+	return r
+}
@@ -265,6 +265,24 @@ func (c Client) DeleteUser(ctx context.Context, id uid.ID) error {
 	return delete(ctx, c, fmt.Sprintf("/api/users/%s", id), Query{})
 }
 
+func (c Client) CreateMFAEnrollment(ctx context.Context) (*MFAEnrollment, error) {
+	return post[MFAEnrollment](ctx, c, "/api/users/self/mfa", &CreateMFAEnrollmentRequest{})
+}
+
+func (c Client) VerifyMFAEnrollment(ctx context.Context, req *VerifyMFAEnrollmentRequest) (*MFARecoveryCodes, error) {
+	return post[MFARecoveryCodes](ctx, c, "/api/users/self/mfa/verify", req)
+}
+
+func (c Client) DeleteMFA(ctx context.Context, req *DeleteMFARequest) error {
+	return delete(ctx, c, fmt.Sprintf("/api/users/%s/mfa", req.UserID), Query{
+		"code": {req.Code},
+	})
+}
+
+func (c Client) UnlockUser(ctx context.Context, id uid.ID) error {
+	return delete(ctx, c, fmt.Sprintf("/api/users/%s/lockout", id), Query{})
+}
+
 func (c Client) AddUserPublicKey(ctx context.Context, req *AddUserPublicKeyRequest) (*UserPublicKey, error) {
 	return put[UserPublicKey](ctx, c, "/api/users/public-key", req)
 }
@@ -301,6 +319,11 @@ func (c Client) UpdateUsersInGroup(ctx context.Context, req *UpdateUsersInGroupR
 	return err
 }
 
+func (c Client) UpdateGroupsInGroup(ctx context.Context, req *UpdateGroupsInGroupRequest) error {
+	_, err := patch[EmptyResponse](ctx, c, fmt.Sprintf("/api/groups/%s/groups", req.GroupID), req)
+	return err
+}
+
 func (c Client) ListProviders(ctx context.Context, req ListProvidersRequest) (*ListResponse[Provider], error) {
 	return get[ListResponse[Provider]](ctx, c, "/api/providers", Query{
 		"name": {req.Name},
@@ -350,6 +373,18 @@ func (c Client) DeleteProvider(ctx context.Context, id uid.ID) error {
 	return delete(ctx, c, fmt.Sprintf("/api/providers/%s", id), Query{})
 }
 
+func (c Client) GetProviderSync(ctx context.Context, id uid.ID) (*ProviderSync, error) {
+	return get[ProviderSync](ctx, c, fmt.Sprintf("/api/providers/%s/sync", id), Query{})
+}
+
+func (c Client) SyncProvider(ctx context.Context, id uid.ID) (*ProviderSync, error) {
+	return post[ProviderSync](ctx, c, fmt.Sprintf("/api/providers/%s/sync", id), &EmptyRequest{})
+}
+
+func (c Client) PreviewGroupMapping(ctx context.Context, req *PreviewGroupMappingRequest) (*PreviewGroupMappingResponse, error) {
+	return post[PreviewGroupMappingResponse](ctx, c, fmt.Sprintf("/api/providers/%s/group-mapping/preview", req.ID), req)
+}
+
 func (c Client) ListGrants(ctx context.Context, req ListGrantsRequest) (*ListResponse[Grant], error) {
 	return get[ListResponse[Grant]](ctx, c, "/api/grants", Query{
 		"user":            {req.User.String()},
@@ -377,6 +412,87 @@ func (c Client) DeleteGrant(ctx context.Context, id uid.ID) error {
 	return delete(ctx, c, fmt.Sprintf("/api/grants/%s", id), Query{})
 }
 
+func (c Client) UpdateGrants(ctx context.Context, req *UpdateGrantsRequest) error {
+	_, err := patch[EmptyResponse](ctx, c, "/api/grants", req)
+	return err
+}
+
+func (c Client) ListAccessRequests(ctx context.Context, req ListAccessRequestsRequest) (*ListResponse[AccessRequest], error) {
+	return get[ListResponse[AccessRequest]](ctx, c, "/api/access-requests", Query{
+		"userID": {req.UserID.String()},
+		"status": {req.Status},
+		"page":   {strconv.Itoa(req.Page)},
+		"limit":  {strconv.Itoa(req.Limit)},
+	})
+}
+
+func (c Client) GetAccessRequest(ctx context.Context, id uid.ID) (*AccessRequest, error) {
+	return get[AccessRequest](ctx, c, fmt.Sprintf("/api/access-requests/%s", id), Query{})
+}
+
+func (c Client) CreateAccessRequest(ctx context.Context, req *CreateAccessRequestRequest) (*AccessRequest, error) {
+	return post[AccessRequest](ctx, c, "/api/access-requests", req)
+}
+
+func (c Client) ApproveAccessRequest(ctx context.Context, id uid.ID) (*AccessRequest, error) {
+	return post[AccessRequest](ctx, c, fmt.Sprintf("/api/access-requests/%s/approve", id), &EmptyRequest{})
+}
+
+func (c Client) DenyAccessRequest(ctx context.Context, id uid.ID) (*AccessRequest, error) {
+	return post[AccessRequest](ctx, c, fmt.Sprintf("/api/access-requests/%s/deny", id), &EmptyRequest{})
+}
+
+func (c Client) ListAuditEvents(ctx context.Context, req ListAuditEventsRequest) (*ListResponse[AuditEvent], error) {
+	return get[ListResponse[AuditEvent]](ctx, c, "/api/audit-events", Query{
+		"actorID":  {req.ActorID.String()},
+		"targetID": {req.TargetID.String()},
+		"route":    {req.Route},
+		"outcome":  {req.Outcome},
+		"page":     {strconv.Itoa(req.Page)},
+		"limit":    {strconv.Itoa(req.Limit)},
+	})
+}
+
+func (c Client) ListUserAccess(ctx context.Context, req ListUserAccessRequest) (*ListResponse[EffectiveAccess], error) {
+	return get[ListResponse[EffectiveAccess]](ctx, c, fmt.Sprintf("/api/users/%s/access", req.ID), Query{
+		"destination": {req.Destination},
+	})
+}
+
+func (c Client) ListDestinationAccess(ctx context.Context, id uid.ID) (*ListResponse[EffectiveAccess], error) {
+	return get[ListResponse[EffectiveAccess]](ctx, c, fmt.Sprintf("/api/destinations/%s/access", id), Query{})
+}
+
+func (c Client) ListWorkloadIdentities(ctx context.Context, req ListWorkloadIdentitiesRequest) (*ListResponse[WorkloadIdentity], error) {
+	return get[ListResponse[WorkloadIdentity]](ctx, c, "/api/workload-identities", Query{
+		"issuer": {req.Issuer},
+		"page":   {strconv.Itoa(req.Page)},
+		"limit":  {strconv.Itoa(req.Limit)},
+	})
+}
+
+func (c Client) GetWorkloadIdentity(ctx context.Context, id uid.ID) (*WorkloadIdentity, error) {
+	return get[WorkloadIdentity](ctx, c, fmt.Sprintf("/api/workload-identities/%s", id), Query{})
+}
+
+func (c Client) CreateWorkloadIdentity(ctx context.Context, req *CreateWorkloadIdentityRequest) (*WorkloadIdentity, error) {
+	return post[WorkloadIdentity](ctx, c, "/api/workload-identities", req)
+}
+
+func (c Client) DeleteWorkloadIdentity(ctx context.Context, id uid.ID) error {
+	return delete(ctx, c, fmt.Sprintf("/api/workload-identities/%s", id), Query{})
+}
+
+func (c Client) ListSigningKeys(ctx context.Context, req ListSigningKeysRequest) (*ListResponse[SigningKey], error) {
+	return get[ListResponse[SigningKey]](ctx, c, "/api/signing-keys", Query{
+		"page": {strconv.Itoa(req.Page)}, "limit": {strconv.Itoa(req.Limit)},
+	})
+}
+
+func (c Client) RotateSigningKey(ctx context.Context) (*SigningKey, error) {
+	return post[SigningKey](ctx, c, "/api/signing-keys/rotate", &EmptyRequest{})
+}
+
 func (c Client) ListDestinations(ctx context.Context, req ListDestinationsRequest) (*ListResponse[Destination], error) {
 	return get[ListResponse[Destination]](ctx, c, "/api/destinations", Query{
 		"name":      {req.Name},
@@ -419,6 +535,22 @@ func (c Client) DeleteAccessKeyByName(ctx context.Context, name string) error {
 	return delete(ctx, c, "/api/access-keys", Query{"name": []string{name}})
 }
 
+func (c Client) ListSessions(ctx context.Context, req ListSessionsRequest) (*ListResponse[Session], error) {
+	return get[ListResponse[Session]](ctx, c, fmt.Sprintf("/api/users/%s/sessions", req.UserID), Query{
+		"page": {strconv.Itoa(req.Page)}, "limit": {strconv.Itoa(req.Limit)},
+	})
+}
+
+func (c Client) DeleteSession(ctx context.Context, req DeleteSessionRequest) error {
+	return delete(ctx, c, fmt.Sprintf("/api/users/%s/sessions/%s", req.UserID, req.SessionID), Query{})
+}
+
+func (c Client) DeleteSessions(ctx context.Context, req DeleteSessionsRequest) error {
+	return delete(ctx, c, fmt.Sprintf("/api/users/%s/sessions", req.UserID), Query{
+		"exceptCurrent": {fmt.Sprint(req.ExceptCurrent)},
+	})
+}
+
 func (c Client) CreateToken(ctx context.Context) (*CreateTokenResponse, error) {
 	return post[CreateTokenResponse](ctx, c, "/api/tokens", &EmptyRequest{})
 }
@@ -427,6 +559,10 @@ func (c Client) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, e
 	return post[LoginResponse](ctx, c, "/api/login", req)
 }
 
+func (c Client) DiscoverLogin(ctx context.Context, req *DiscoverLoginRequest) (*DiscoverLoginResponse, error) {
+	return post[DiscoverLoginResponse](ctx, c, "/api/login/discover", req)
+}
+
 func (c Client) Logout(ctx context.Context) error {
 	_, err := post[EmptyResponse](ctx, c, "/api/logout", &EmptyRequest{})
 	return err
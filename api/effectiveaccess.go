@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+// EffectiveAccess is a privilege a user has on a resource, either from a grant
+// to the user or from a grant to a group they belong to.
+type EffectiveAccess struct {
+	UserID    uid.ID `json:"userID" note:"ID of the user with access" example:"6hNnjfjVcc"`
+	UserName  string `json:"userName" note:"name of the user with access" example:"janedoe@example.com"`
+	Privilege string `json:"privilege" note:"a role or permission" example:"view"`
+	Resource  string `json:"resource" note:"a resource name in Infra's Universal Resource Notation" example:"production.namespace"`
+	GrantID   uid.ID `json:"grantID" note:"ID of the grant that gives the access" example:"3w9XyTrkzk"`
+	Expires   Time   `json:"expires" note:"time the grant expires, null if it does not expire"`
+	GroupID   uid.ID `json:"groupID,omitempty" note:"ID of the group the access is inherited from, empty when the grant is to the user directly" example:"6Ti2p7r1h7"`
+	GroupName string `json:"groupName,omitempty" note:"name of the group the access is inherited from" example:"dev"`
+}
+
+type ListUserAccessRequest struct {
+	ID          uid.ID `uri:"id" json:"-" note:"ID of the user"`
+	Destination string `form:"destination" note:"only return access to this destination" example:"production"`
+}
+
+func (r ListUserAccessRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.ID),
+	}
+}
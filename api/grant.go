@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/validate"
 	"github.com/infrahq/infra/uid"
 )
@@ -20,6 +21,7 @@ type Grant struct {
 	Group     uid.ID `json:"group,omitempty" note:"GroupID for a group being granted access" example:"3zMaadcd2U"`
 	Privilege string `json:"privilege" note:"a role or permission" example:"admin"`
 	Resource  string `json:"resource" note:"a resource name in Infra's Universal Resource Notation" example:"production.namespace"`
+	Expires   Time   `json:"expires" note:"grant is no longer valid after this time, null if the grant does not expire"`
 }
 
 type CreateGrantResponse struct {
@@ -144,12 +146,13 @@ func (r ListGrantsRequest) SetPage(page int) Paginatable {
 
 // GrantRequest defines a grant request which can be used for creating or deleting grants
 type GrantRequest struct {
-	User      uid.ID `json:"user" note:"ID of the user granted access" example:"6kdoMDd6PA"`
-	Group     uid.ID `json:"group" note:"ID of the group granted access" example:"6Ti2p7r1h7"`
-	UserName  string `json:"userName" note:"Name of the user granted access" example:"admin@example.com"`
-	GroupName string `json:"groupName" note:"Name of the group granted access" example:"dev"`
-	Privilege string `json:"privilege" example:"view" note:"a role or permission"`
-	Resource  string `json:"resource" example:"production" note:"a resource name in Infra's Universal Resource Notation"`
+	User      uid.ID   `json:"user" note:"ID of the user granted access" example:"6kdoMDd6PA"`
+	Group     uid.ID   `json:"group" note:"ID of the group granted access" example:"6Ti2p7r1h7"`
+	UserName  string   `json:"userName" note:"Name of the user granted access" example:"admin@example.com"`
+	GroupName string   `json:"groupName" note:"Name of the group granted access" example:"dev"`
+	Privilege string   `json:"privilege" example:"view" note:"a role or permission"`
+	Resource  string   `json:"resource" example:"production" note:"a resource name in Infra's Universal Resource Notation. A * in the destination name matches any sequence of characters"`
+	Duration  Duration `json:"duration" example:"72h" note:"if set, the grant expires after this duration"`
 }
 
 func (r GrantRequest) ValidationRules() []validate.ValidationRule {
@@ -162,7 +165,44 @@ func (r GrantRequest) ValidationRules() []validate.ValidationRule {
 		),
 		validate.Required("privilege", r.Privilege),
 		validate.Required("resource", r.Resource),
+		validate.ValidatorFunc(func() *validate.Failure {
+			return validateGrantResource(r.Resource)
+		}),
+		validate.ValidatorFunc(func() *validate.Failure {
+			if r.Duration < 0 {
+				return validate.Fail("duration", "must be a positive duration")
+			}
+			return nil
+		}),
+	}
+}
+
+// validateGrantResource checks the destination name of a resource that
+// contains a pattern. Patterns are only supported in the destination name, not
+// in the name of a resource within the destination.
+func validateGrantResource(resource string) *validate.Failure {
+	destination, rest, _ := strings.Cut(resource, ".")
+	if strings.Contains(rest, "*") {
+		return validate.Fail("resource", "wildcards are only allowed in the destination name")
+	}
+	if !strings.Contains(destination, "*") {
+		return nil
 	}
+
+	rule := validateDestinationName(destination)
+	rule.Name = "resource"
+	rule.CharacterRanges = append(rule.CharacterRanges, validate.CharRange{Low: '*', High: '*'})
+	rule.MinLength = 0
+	return rule.Validate()
+}
+
+// GrantMatchesDestination returns true if a grant to resource applies to the
+// destination, or to a resource within the destination. The destination name
+// in resource may be a pattern, where each * matches any sequence of
+// characters.
+func GrantMatchesDestination(resource, destination string) bool {
+	pattern, _, _ := strings.Cut(resource, ".")
+	return internal.MatchGlob(pattern, destination)
 }
 
 type UpdateGrantsRequest struct {
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/validate"
+)
+
+func TestGrantMatchesDestination(t *testing.T) {
+	type testCase struct {
+		resource    string
+		destination string
+		expected    bool
+	}
+
+	testCases := []testCase{
+		{resource: "prod", destination: "prod", expected: true},
+		{resource: "prod.default", destination: "prod", expected: true},
+		{resource: "prod", destination: "prod-us-1", expected: false},
+		{resource: "prod-*", destination: "prod-us-1", expected: true},
+		{resource: "prod-*", destination: "prod-", expected: true},
+		{resource: "prod-*", destination: "staging-us-1", expected: false},
+		{resource: "prod-*.monitoring", destination: "prod-eu", expected: true},
+		{resource: "*-us-*", destination: "prod-us-1", expected: true},
+		{resource: "*-us-*", destination: "prod-eu-1", expected: false},
+		{resource: "*-1", destination: "prod-us-1", expected: true},
+		{resource: "a*a", destination: "a", expected: false},
+		{resource: "*", destination: "anything", expected: true},
+	}
+
+	for _, tc := range testCases {
+		actual := GrantMatchesDestination(tc.resource, tc.destination)
+		assert.Equal(t, actual, tc.expected, "resource=%v destination=%v", tc.resource, tc.destination)
+	}
+}
+
+func TestGrantRequest_ValidateResource(t *testing.T) {
+	req := GrantRequest{UserName: "user@example.com", Privilege: "view"}
+
+	for _, resource := range []string{"prod", "prod.default", "prod-*", "*-us-*.monitoring", "*"} {
+		req.Resource = resource
+		assert.NilError(t, validate.Validate(req), resource)
+	}
+
+	req.Resource = "prod.team-*"
+	err := validate.Validate(req)
+	assert.Error(t, err, "validation failed: resource: wildcards are only allowed in the destination name")
+
+	req.Resource = "prod/*"
+	err = validate.Validate(req)
+	assert.Error(t, err, `validation failed: resource: character '/' at position 4 is not allowed`)
+}
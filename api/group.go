@@ -49,6 +49,18 @@ func (r UpdateUsersInGroupRequest) ValidationRules() []validate.ValidationRule {
 	}
 }
 
+type UpdateGroupsInGroupRequest struct {
+	GroupID          uid.ID   `uri:"id" json:"-"`
+	GroupIDsToAdd    []uid.ID `json:"groupsToAdd" note:"List of group IDs to nest in the group. Members of a nested group inherit the grants of the group" example:"[6k3Eqcqu6B]"`
+	GroupIDsToRemove []uid.ID `json:"groupsToRemove" note:"List of group IDs to remove from the group" example:"[6Ti2p7r1h7]"`
+}
+
+func (r UpdateGroupsInGroupRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.GroupID),
+	}
+}
+
 func (req ListGroupsRequest) SetPage(page int) Paginatable {
 
 	req.PaginationRequest.Page = page
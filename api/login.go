@@ -6,9 +6,10 @@ import (
 )
 
 type LoginRequestOIDC struct {
-	ProviderID  uid.ID `json:"providerID"`
-	RedirectURL string `json:"redirectURL"`
-	Code        string `json:"code"`
+	ProviderID   uid.ID `json:"providerID"`
+	RedirectURL  string `json:"redirectURL"`
+	Code         string `json:"code"`
+	CodeVerifier string `json:"codeVerifier" note:"PKCE code verifier. Required when the authorization request included a code_challenge"`
 }
 
 func (r LoginRequestOIDC) ValidationRules() []validate.ValidationRule {
@@ -21,6 +22,7 @@ func (r LoginRequestOIDC) ValidationRules() []validate.ValidationRule {
 type LoginRequestPasswordCredentials struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
+	MFACode  string `json:"mfaCode" note:"TOTP or recovery code. Required when the user has enrolled in multi-factor authentication" example:"123456"`
 }
 
 func (r LoginRequestPasswordCredentials) ValidationRules() []validate.ValidationRule {
@@ -30,10 +32,36 @@ func (r LoginRequestPasswordCredentials) ValidationRules() []validate.Validation
 	}
 }
 
+type LoginRequestLDAP struct {
+	ProviderID uid.ID `json:"providerID"`
+	Name       string `json:"name" note:"Username in the directory, or the email address of the user"`
+	Password   string `json:"password"`
+}
+
+func (r LoginRequestLDAP) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("providerID", r.ProviderID),
+		validate.Required("name", r.Name),
+		validate.Required("password", r.Password),
+	}
+}
+
+type LoginRequestWorkloadIdentity struct {
+	Token string `json:"token" note:"OIDC token issued to the workload by a trusted issuer, like a GitHub Actions or GitLab CI job"`
+}
+
+func (r LoginRequestWorkloadIdentity) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("token", r.Token),
+	}
+}
+
 type LoginRequest struct {
 	AccessKey           string                           `json:"accessKey"`
 	PasswordCredentials *LoginRequestPasswordCredentials `json:"passwordCredentials"`
 	OIDC                *LoginRequestOIDC                `json:"oidc"`
+	LDAP                *LoginRequestLDAP                `json:"ldap"`
+	WorkloadIdentity    *LoginRequestWorkloadIdentity    `json:"workloadIdentity"`
 }
 
 func (r LoginRequest) ValidationRules() []validate.ValidationRule {
@@ -42,6 +70,8 @@ func (r LoginRequest) ValidationRules() []validate.ValidationRule {
 			validate.Field{Name: "accessKey", Value: r.AccessKey},
 			validate.Field{Name: "passwordCredentials", Value: r.PasswordCredentials},
 			validate.Field{Name: "oidc", Value: r.OIDC},
+			validate.Field{Name: "ldap", Value: r.LDAP},
+			validate.Field{Name: "workloadIdentity", Value: r.WorkloadIdentity},
 		),
 	}
 }
@@ -53,4 +83,6 @@ type LoginResponse struct {
 	PasswordUpdateRequired bool   `json:"passwordUpdateRequired,omitempty"`
 	Expires                Time   `json:"expires"`
 	OrganizationName       string `json:"organizationName,omitempty"`
+	MFARequired            bool   `json:"mfaRequired,omitempty" note:"The password was accepted, but the login must be repeated with an mfaCode. No access key is issued."`
+	MFAEnrollmentRequired  bool   `json:"mfaEnrollmentRequired,omitempty" note:"The organization requires multi-factor authentication. The access key can only be used to enroll."`
 }
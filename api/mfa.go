@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+)
+
+type CreateMFAEnrollmentRequest struct {
+	UserID IDOrSelf `uri:"id" json:"-"`
+}
+
+func (r CreateMFAEnrollmentRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+	}
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret" note:"Base32 encoded TOTP secret, to be entered into an authenticator app" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI    string `json:"uri" note:"otpauth URI for the secret, which may be displayed as a QR code" example:"otpauth://totp/Infra:bob@example.com?issuer=Infra&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+type VerifyMFAEnrollmentRequest struct {
+	UserID IDOrSelf `uri:"id" json:"-"`
+	Code   string   `json:"code" note:"Code from the authenticator app" example:"123456"`
+}
+
+func (r VerifyMFAEnrollmentRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+		validate.Required("code", r.Code),
+	}
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes" note:"Single use codes which may be used to login when the authenticator app is not available. These are only returned once." example:"['x7kq2-mz9pf', 'b3nwt-v8rcd']"`
+}
+
+type DeleteMFARequest struct {
+	UserID IDOrSelf `uri:"id" json:"-"`
+	Code   string   `form:"code" note:"Code from the authenticator app, or a recovery code. Required when users remove their own multi-factor authentication" example:"123456"`
+}
+
+func (r DeleteMFARequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+	}
+}
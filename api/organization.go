@@ -6,12 +6,43 @@ import (
 )
 
 type Organization struct {
-	ID             uid.ID   `json:"id"`
-	Name           string   `json:"name"`
-	Created        Time     `json:"created"`
-	Updated        Time     `json:"updated"`
-	Domain         string   `json:"domain"`
-	AllowedDomains []string `json:"allowedDomains" note:"domains which can be used to login to this organization" example:"['example.com', 'infrahq.com']"`
+	ID             uid.ID         `json:"id"`
+	Name           string         `json:"name"`
+	Created        Time           `json:"created"`
+	Updated        Time           `json:"updated"`
+	Domain         string         `json:"domain"`
+	AllowedDomains []string       `json:"allowedDomains" note:"domains which can be used to login to this organization" example:"['example.com', 'infrahq.com']"`
+	RequireMFA     bool           `json:"requireMFA" note:"users who login with an Infra password must enroll in multi-factor authentication"`
+	PasswordPolicy PasswordPolicy `json:"passwordPolicy"`
+}
+
+// PasswordPolicy is the policy for the passwords of users who login with an
+// Infra password.
+type PasswordPolicy struct {
+	MinLength    int      `json:"minLength" note:"minimum number of characters in a password. Passwords must always have at least 8 characters"`
+	LowercaseMin int      `json:"lowercaseMin" note:"minimum number of lowercase letters in a password"`
+	UppercaseMin int      `json:"uppercaseMin" note:"minimum number of uppercase letters in a password"`
+	NumberMin    int      `json:"numberMin" note:"minimum number of digits in a password"`
+	SymbolMin    int      `json:"symbolMin" note:"minimum number of symbols in a password"`
+	MaxAge       Duration `json:"maxAge" note:"users must change a password that is older than this duration at their next login. 0s means passwords do not expire" example:"2160h"`
+	HistoryCount int      `json:"historyCount" note:"number of previous passwords of a user that can not be used again"`
+}
+
+func (r PasswordPolicy) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.IntRule{Name: "minLength", Value: r.MinLength, Min: validate.Int(8), Max: validate.Int(256)},
+		validate.IntRule{Name: "lowercaseMin", Value: r.LowercaseMin, Min: validate.Int(0), Max: validate.Int(256)},
+		validate.IntRule{Name: "uppercaseMin", Value: r.UppercaseMin, Min: validate.Int(0), Max: validate.Int(256)},
+		validate.IntRule{Name: "numberMin", Value: r.NumberMin, Min: validate.Int(0), Max: validate.Int(256)},
+		validate.IntRule{Name: "symbolMin", Value: r.SymbolMin, Min: validate.Int(0), Max: validate.Int(256)},
+		validate.IntRule{Name: "historyCount", Value: r.HistoryCount, Min: validate.Int(0), Max: validate.Int(24)},
+		validate.ValidatorFunc(func() *validate.Failure {
+			if r.MaxAge < 0 {
+				return validate.Fail("maxAge", "must not be negative")
+			}
+			return nil
+		}),
+	}
 }
 
 type GetOrganizationRequest struct {
@@ -43,34 +74,17 @@ func (r CreateOrganizationRequest) ValidationRules() []validate.ValidationRule {
 }
 
 type UpdateOrganizationRequest struct {
-	ID             uid.ID   `uri:"id" json:"-"`
-	AllowedDomains []string `json:"allowedDomains"`
+	ID             uid.ID          `uri:"id" json:"-"`
+	AllowedDomains []string        `json:"allowedDomains"`
+	RequireMFA     *bool           `json:"requireMFA" note:"require multi-factor authentication for users who login with an Infra password. The current value is kept when omitted"`
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy" note:"the policy for the passwords of users who login with an Infra password. The current policy is kept when omitted"`
 }
 
 func (r UpdateOrganizationRequest) ValidationRules() []validate.ValidationRule {
 	return []validate.ValidationRule{
 		validate.Required("id", r.ID),
 		validate.Required("allowedDomains", r.AllowedDomains),
-		// permissive validation for a domain field (with no protocol)
-		validate.SliceRule{
-			Value: r.AllowedDomains,
-			Name:  "allowedDomains",
-			ItemRule: validate.StringRule{
-				Name:      "allowedDomains.values",
-				MinLength: 2,
-				MaxLength: 254,
-				CharacterRanges: []validate.CharRange{
-					validate.AlphabetLower,
-					validate.AlphabetUpper,
-					validate.Numbers,
-					validate.Dash,
-					validate.Dot,
-				},
-				FirstCharacterRange: validate.AlphaNumeric,
-				RequiredCharacters:  []rune{'.'},
-				DenyList:            []string{"gmail.com", "googlemail.com"},
-			},
-		},
+		ValidateDomains("allowedDomains", r.AllowedDomains),
 	}
 }
 
@@ -78,3 +92,27 @@ func (req ListOrganizationsRequest) SetPage(page int) Paginatable {
 	req.PaginationRequest.Page = page
 	return req
 }
+
+// ValidateDomains is a permissive validation for a list of email domains (with
+// no protocol).
+func ValidateDomains(name string, domains []string) validate.SliceRule {
+	return validate.SliceRule{
+		Value: domains,
+		Name:  name,
+		ItemRule: validate.StringRule{
+			Name:      name + ".values",
+			MinLength: 2,
+			MaxLength: 254,
+			CharacterRanges: []validate.CharRange{
+				validate.AlphabetLower,
+				validate.AlphabetUpper,
+				validate.Numbers,
+				validate.Dash,
+				validate.Dot,
+			},
+			FirstCharacterRange: validate.AlphaNumeric,
+			RequiredCharacters:  []rune{'.'},
+			DenyList:            []string{"gmail.com", "googlemail.com"},
+		},
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
+	"regexp"
+
 	"github.com/infrahq/infra/internal/validate"
 	"github.com/infrahq/infra/uid"
 )
@@ -19,6 +23,93 @@ func (r ProviderAPICredentials) ValidationRules() []validate.ValidationRule {
 	}
 }
 
+// ProviderSAML configures a provider of kind saml
+type ProviderSAML struct {
+	MetadataURL     string `json:"metadataURL" example:"https://example.okta.com/app/exk1234/sso/saml/metadata" note:"URL of the metadata of the SAML identity provider"`
+	Metadata        string `json:"metadata" note:"Metadata XML of the SAML identity provider, used instead of metadataURL"`
+	GroupsAttribute string `json:"groupsAttribute" example:"groups" note:"Name of the assertion attribute that contains the user's groups. Defaults to groups"`
+}
+
+func (r ProviderSAML) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.RequireOneOf(
+			validate.Field{Name: "metadataURL", Value: r.MetadataURL},
+			validate.Field{Name: "metadata", Value: r.Metadata},
+		),
+	}
+}
+
+// ProviderLDAP configures a provider of kind ldap. The url of the provider is
+// the URL of the LDAP server, with a scheme of ldap or ldaps.
+type ProviderLDAP struct {
+	BindDN        string `json:"bindDN" example:"CN=infra,OU=Service Accounts,DC=example,DC=com" note:"DN of the service account used to search for users and groups. The search is anonymous when empty"`
+	BindPassword  string `json:"bindPassword" note:"Password of the service account"`
+	BaseDN        string `json:"baseDN" example:"OU=Users,DC=example,DC=com" note:"DN of the entry to search for users"`
+	UserFilter    string `json:"userFilter" example:"(sAMAccountName={username})" note:"Filter used to find a user, {username} is replaced with the username used to login. Defaults to a filter on sAMAccountName, userPrincipalName, uid, and mail"`
+	GroupBaseDN   string `json:"groupBaseDN" example:"OU=Groups,DC=example,DC=com" note:"DN of the entry to search for groups. Defaults to baseDN"`
+	StartTLS      bool   `json:"startTLS" note:"Upgrade an ldap:// connection to TLS using StartTLS"`
+	CACertificate PEM    `json:"caCertificate" example:"-----BEGIN CERTIFICATE-----\nMIIDNTCCAh2gAwIBAgIRALRetnpcTo9O3V2fAK3ix+c\n-----END CERTIFICATE-----\n" note:"CA certificate used to verify the TLS certificate of the LDAP server. Defaults to the system roots"`
+}
+
+func (r ProviderLDAP) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("baseDN", r.BaseDN),
+		validate.ValidatorFunc(func() *validate.Failure {
+			if r.BindDN != "" && r.BindPassword == "" {
+				return validate.Fail("bindPassword", "is required with bindDN")
+			}
+			return nil
+		}),
+	}
+}
+
+// ProviderGitHub configures a provider of kind github. The url of the provider
+// is github.com, or the URL of a GitHub Enterprise Server.
+type ProviderGitHub struct {
+	Organizations []string `json:"organizations" example:"['infrahq']" note:"Logins of the GitHub organizations that are allowed to login. Users are added to a group for each of these organizations they are a member of, and a group named org/team for each of their teams"`
+}
+
+func (r ProviderGitHub) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("organizations", r.Organizations),
+	}
+}
+
+// ProviderGroupMapping changes the names of the groups from the identity
+// provider before users are added to them.
+type ProviderGroupMapping struct {
+	Include            string            `json:"include" example:"^AAD-" note:"Regular expression. When set, only the groups that match are kept"`
+	Exclude            string            `json:"exclude" example:"-Admins$" note:"Regular expression. Groups that match are removed"`
+	Rename             []GroupRenameRule `json:"rename" note:"Rules to rename groups. The first rule that matches a group renames it"`
+	PrefixProviderName bool              `json:"prefixProviderName" note:"Add the name of the provider and a slash to the start of each group name"`
+}
+
+type GroupRenameRule struct {
+	Match   string `json:"match" example:"^AAD-(.+)-RW$" note:"Regular expression matched against the group name"`
+	Replace string `json:"replace" example:"$1" note:"Replacement for the part of the name that matches. $1 refers to the first submatch"`
+}
+
+func (r ProviderGroupMapping) ValidationRules() []validate.ValidationRule {
+	rules := []validate.ValidationRule{
+		validRegexp("include", r.Include),
+		validRegexp("exclude", r.Exclude),
+	}
+	for i, rule := range r.Rename {
+		name := fmt.Sprintf("rename[%d].match", i)
+		rules = append(rules, validate.Required(name, rule.Match), validRegexp(name, rule.Match))
+	}
+	return rules
+}
+
+func validRegexp(name, expr string) validate.ValidationRule {
+	return validate.ValidatorFunc(func() *validate.Failure {
+		if _, err := regexp.Compile(expr); err != nil {
+			return validate.Fail(name, "invalid regular expression: "+err.Error())
+		}
+		return nil
+	})
+}
+
 type Provider struct {
 	ID       uid.ID   `json:"id" note:"Provider ID"`
 	Name     string   `json:"name" example:"okta" note:"Name of the provider"`
@@ -29,6 +120,9 @@ type Provider struct {
 	Kind     string   `json:"kind" example:"oidc" note:"Kind of provider"`
 	AuthURL  string   `json:"authURL" example:"https://example.com/oauth2/v1/authorize" note:"Authorize endpoint for the OIDC provider"`
 	Scopes   []string `json:"scopes" example:"['openid', 'email']" note:"Scopes set in the OIDC provider configuration"`
+	Domains  []string `json:"domains" example:"['example.com']" note:"Email domains of the users who login with this provider"`
+
+	GroupMapping *ProviderGroupMapping `json:"groupMapping,omitempty" note:"Rules that change the names of the groups from the provider. Only returned to admins"`
 }
 
 type CreateProviderRequest struct {
@@ -38,24 +132,66 @@ type CreateProviderRequest struct {
 	ClientSecret string                  `json:"clientSecret" example:"jmda5eG93ax3jMDxTGrbHd_TBGT6kgNZtrCugLbU"`
 	Kind         string                  `json:"kind" example:"oidc"`
 	API          *ProviderAPICredentials `json:"api"`
+	SAML         *ProviderSAML           `json:"saml"`
+	LDAP         *ProviderLDAP           `json:"ldap"`
+	GitHub       *ProviderGitHub         `json:"github"`
+	GroupMapping *ProviderGroupMapping   `json:"groupMapping"`
+	Domains      []string                `json:"domains" example:"['example.com']" note:"Email domains of the users who login with this provider, used to find the provider from the email of a user"`
 }
 
-var kinds = []string{"oidc", "okta", "azure", "google"}
+var kinds = []string{"oidc", "okta", "azure", "google", "saml", "ldap", "github"}
 
 func (r CreateProviderRequest) ValidationRules() []validate.ValidationRule {
-	return []validate.ValidationRule{
+	rules := []validate.ValidationRule{
 		ValidateName(r.Name),
-		validate.Required("url", r.URL),
-		validate.Required("clientID", r.ClientID),
-		validate.Required("clientSecret", r.ClientSecret),
 		validate.Enum("kind", r.Kind, kinds),
+		ValidateDomains("domains", r.Domains),
+	}
+	return append(rules, providerKindRules(r.Kind, r.URL, r.ClientID, r.ClientSecret, r.SAML, r.LDAP, r.GitHub)...)
+}
+
+// providerKindRules returns the rules for the fields that are required by the
+// kind of provider. SAML providers are configured from the metadata of the
+// identity provider, and LDAP providers from the directory settings, instead
+// of a client ID and secret. GitHub providers default to github.com, so the
+// url is optional.
+func providerKindRules(kind, url, clientID, clientSecret string, saml *ProviderSAML, ldap *ProviderLDAP, github *ProviderGitHub) []validate.ValidationRule {
+	switch kind {
+	case "saml":
+		return []validate.ValidationRule{
+			validate.Required("saml", saml),
+		}
+	case "ldap":
+		return []validate.ValidationRule{
+			validate.Required("url", url),
+			validate.Required("ldap", ldap),
+		}
+	case "github":
+		return []validate.ValidationRule{
+			validate.Required("clientID", clientID),
+			validate.Required("clientSecret", clientSecret),
+			validate.Required("github", github),
+		}
+	}
+	return []validate.ValidationRule{
+		validate.Required("url", url),
+		validate.Required("clientID", clientID),
+		validate.Required("clientSecret", clientSecret),
 	}
 }
 
 type PatchProviderRequest struct {
-	ID           uid.ID `uri:"id" json:"-"`
-	Name         string `json:"name" example:"okta"`
-	ClientSecret string `json:"clientSecret" example:"jmda5eG93ax3jMDxTGrbHd_TBGT6kgNZtrCugLbU"`
+	ID           uid.ID                `uri:"id" json:"-"`
+	Name         string                `json:"name" example:"okta"`
+	ClientSecret string                `json:"clientSecret" example:"jmda5eG93ax3jMDxTGrbHd_TBGT6kgNZtrCugLbU"`
+	GroupMapping *ProviderGroupMapping `json:"groupMapping" note:"Replaces the group mapping of the provider when set"`
+	Domains      []string              `json:"domains" example:"['example.com']" note:"Replaces the email domains of the provider when set"`
+}
+
+func (r PatchProviderRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		ValidateDomains("domains", r.Domains),
+	}
 }
 
 type UpdateProviderRequest struct {
@@ -66,18 +202,22 @@ type UpdateProviderRequest struct {
 	ClientSecret string                  `json:"clientSecret" example:"jmda5eG93ax3jMDxTGrbHd_TBGT6kgNZtrCugLbU"`
 	Kind         string                  `json:"kind" example:"oidc"`
 	API          *ProviderAPICredentials `json:"api"`
+	SAML         *ProviderSAML           `json:"saml"`
+	LDAP         *ProviderLDAP           `json:"ldap"`
+	GitHub       *ProviderGitHub         `json:"github"`
+	GroupMapping *ProviderGroupMapping   `json:"groupMapping"`
+	Domains      []string                `json:"domains" example:"['example.com']" note:"Email domains of the users who login with this provider, used to find the provider from the email of a user"`
 }
 
 func (r UpdateProviderRequest) ValidationRules() []validate.ValidationRule {
-	return []validate.ValidationRule{
+	rules := []validate.ValidationRule{
 		ValidateName(r.Name),
 		validate.Required("id", r.ID),
 		validate.Required("name", r.Name),
-		validate.Required("url", r.URL),
-		validate.Required("clientID", r.ClientID),
-		validate.Required("clientSecret", r.ClientSecret),
 		validate.Enum("kind", r.Kind, kinds),
+		ValidateDomains("domains", r.Domains),
 	}
+	return append(rules, providerKindRules(r.Kind, r.URL, r.ClientID, r.ClientSecret, r.SAML, r.LDAP, r.GitHub)...)
 }
 
 type ListProvidersRequest struct {
@@ -96,3 +236,65 @@ func (req ListProvidersRequest) SetPage(page int) Paginatable {
 
 	return req
 }
+
+// ProviderSync is the last run of the directory sync of a provider.
+type ProviderSync struct {
+	ProviderID       uid.ID `json:"providerID"`
+	Status           string `json:"status" example:"succeeded" note:"Status of the last sync. One of idle, running, succeeded, or failed"`
+	StartedAt        Time   `json:"startedAt" note:"Time the last sync started"`
+	FinishedAt       Time   `json:"finishedAt" note:"Time the last sync finished"`
+	UsersSynced      int    `json:"usersSynced" note:"Number of users that were synced"`
+	UsersDeactivated int    `json:"usersDeactivated" note:"Number of users that were deactivated because the provider revoked their session"`
+	UsersFailed      int    `json:"usersFailed" note:"Number of users that could not be synced"`
+	Error            string `json:"error,omitempty" note:"Last error from a user that could not be synced"`
+}
+
+// PreviewGroupMappingRequest previews the groups that a user is added to with
+// the group mapping of a provider.
+type PreviewGroupMappingRequest struct {
+	ID           uid.ID                `uri:"id" json:"-"`
+	UserID       uid.ID                `json:"userID" note:"User whose groups are read from the identity provider. Only supported by OIDC providers"`
+	Groups       []string              `json:"groups" example:"['AAD-Eng-Platform-RW']" note:"Groups from the identity provider, used instead of the groups of a user"`
+	GroupMapping *ProviderGroupMapping `json:"groupMapping" note:"Group mapping to preview. Defaults to the group mapping of the provider"`
+}
+
+func (r PreviewGroupMappingRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.RequireOneOf(
+			validate.Field{Name: "userID", Value: r.UserID},
+			validate.Field{Name: "groups", Value: r.Groups},
+		),
+	}
+}
+
+type PreviewGroupMappingResponse struct {
+	ProviderGroups []string `json:"providerGroups" example:"['AAD-Eng-Platform-RW']" note:"Groups from the identity provider"`
+	Groups         []string `json:"groups" example:"['okta/Platform']" note:"Groups after the group mapping"`
+}
+
+func (r *PreviewGroupMappingResponse) StatusCode() int {
+	// the preview does not create anything
+	return http.StatusOK
+}
+
+// DiscoverLoginRequest finds the identity providers that a user can login
+// with from their email address.
+type DiscoverLoginRequest struct {
+	Email string `json:"email" example:"dana@example.com"`
+}
+
+func (r DiscoverLoginRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("email", r.Email),
+		validate.Email("email", r.Email),
+	}
+}
+
+type DiscoverLoginResponse struct {
+	Providers []Provider `json:"providers" note:"Providers for the domain of the email. Empty when the user should login with a password"`
+}
+
+func (r *DiscoverLoginResponse) StatusCode() int {
+	// discovery does not create anything
+	return http.StatusOK
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/validate"
+)
+
+func TestCreateProviderRequest_ValidationRules(t *testing.T) {
+	t.Run("oidc requires client credentials", func(t *testing.T) {
+		req := CreateProviderRequest{Name: "okta", Kind: "okta"}
+		err := validate.Validate(req)
+		assert.ErrorContains(t, err, "clientID: is required")
+		assert.ErrorContains(t, err, "clientSecret: is required")
+		assert.ErrorContains(t, err, "url: is required")
+	})
+
+	t.Run("saml requires metadata", func(t *testing.T) {
+		req := CreateProviderRequest{Name: "saml", Kind: "saml"}
+		err := validate.Validate(req)
+		assert.Error(t, err, "validation failed: saml: is required")
+
+		req.SAML = &ProviderSAML{}
+		err = validate.Validate(req)
+		assert.Error(t, err, "validation failed: saml: one of (metadataURL, metadata) is required")
+
+		req.SAML = &ProviderSAML{MetadataURL: "https://idp.example.com/metadata"}
+		assert.NilError(t, validate.Validate(req))
+	})
+	t.Run("ldap requires directory settings", func(t *testing.T) {
+		req := CreateProviderRequest{Name: "corp", Kind: "ldap"}
+		err := validate.Validate(req)
+		assert.ErrorContains(t, err, "url: is required")
+		assert.ErrorContains(t, err, "ldap: is required")
+
+		req.URL = "ldaps://ldap.example.com"
+		req.LDAP = &ProviderLDAP{BindDN: "cn=infra,dc=example,dc=com"}
+		err = validate.Validate(req)
+		assert.ErrorContains(t, err, "ldap.baseDN: is required")
+		assert.ErrorContains(t, err, "ldap.bindPassword: is required with bindDN")
+
+		req.LDAP = &ProviderLDAP{BaseDN: "dc=example,dc=com"}
+		assert.NilError(t, validate.Validate(req))
+	})
+	t.Run("github requires organizations", func(t *testing.T) {
+		req := CreateProviderRequest{Name: "github", Kind: "github", ClientID: "id", ClientSecret: "secret"}
+		err := validate.Validate(req)
+		assert.ErrorContains(t, err, "github: is required")
+		assert.Assert(t, !strings.Contains(err.Error(), "url"))
+
+		req.GitHub = &ProviderGitHub{}
+		assert.ErrorContains(t, validate.Validate(req), "github.organizations: is required")
+
+		req.GitHub = &ProviderGitHub{Organizations: []string{"infrahq"}}
+		assert.NilError(t, validate.Validate(req))
+	})
+	t.Run("group mapping must be valid regular expressions", func(t *testing.T) {
+		req := CreateProviderRequest{
+			Name: "okta", Kind: "okta", URL: "example.okta.com", ClientID: "id", ClientSecret: "secret",
+			GroupMapping: &ProviderGroupMapping{
+				Exclude: "[",
+				Rename:  []GroupRenameRule{{Match: "^AAD-(.+)$", Replace: "$1"}, {Replace: "none"}},
+			},
+		}
+		err := validate.Validate(req)
+		assert.ErrorContains(t, err, "groupMapping.exclude: invalid regular expression")
+		assert.ErrorContains(t, err, "groupMapping.rename[1].match: is required")
+		assert.Assert(t, !strings.Contains(err.Error(), "rename[0]"))
+
+		req.GroupMapping = &ProviderGroupMapping{Include: "^AAD-", PrefixProviderName: true}
+		assert.NilError(t, validate.Validate(req))
+	})
+	t.Run("domains must be valid", func(t *testing.T) {
+		req := CreateProviderRequest{
+			Name: "okta", Kind: "okta", URL: "example.okta.com", ClientID: "id", ClientSecret: "secret",
+			Domains: []string{"example.com", "https://example.org"},
+		}
+		assert.ErrorContains(t, validate.Validate(req), "domains.values.2: character ':' at position 5 is not allowed")
+
+		req.Domains = []string{"example.com", "example.org"}
+		assert.NilError(t, validate.Validate(req))
+	})
+}
@@ -7,9 +7,16 @@ type VerifyAndRedirectRequest struct {
 
 type RedirectResponse struct {
 	RedirectTo string `json:"-"`
+	// Status is the HTTP status code of the redirect. Defaults to 308 Permanent Redirect.
+	Status int `json:"-"`
 }
 
 // satisfies the isRedirect interface
 func (r RedirectResponse) RedirectURL() string {
 	return r.RedirectTo
 }
+
+// satisfies the statusCoder interface
+func (r RedirectResponse) StatusCode() int {
+	return r.Status
+}
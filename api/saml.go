@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type SAMLLoginRequest struct {
+	ID   uid.ID `uri:"id" json:"-"`
+	Next string `form:"next" json:"-"`
+}
+
+// SAMLACSRequest is the form posted to the assertion consumer service by a
+// SAML identity provider.
+type SAMLACSRequest struct {
+	ID           uid.ID `uri:"id" json:"-"`
+	SAMLResponse string `form:"SAMLResponse" json:"-"`
+	RelayState   string `form:"RelayState" json:"-"`
+}
+
+// satisfies the isFormRequest interface
+func (r SAMLACSRequest) IsFormRequest() bool {
+	return true
+}
+
+type SAMLMetadataResponse struct {
+	Metadata []byte `json:"-"`
+}
+
+// satisfies the hasRawBody interface
+func (r SAMLMetadataResponse) ContentType() string {
+	return "application/samlmetadata+xml"
+}
+
+// satisfies the hasRawBody interface
+func (r SAMLMetadataResponse) RawBody() []byte {
+	return r.Metadata
+}
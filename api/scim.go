@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+
 	"github.com/infrahq/infra/internal/validate"
 	"github.com/infrahq/infra/uid"
 )
@@ -24,6 +26,21 @@ func (r SCIMUserEmail) ValidationRules() []validate.ValidationRule {
 
 const UserSchema = "urn:ietf:params:scim:schemas:core:2.0:User"
 
+const EnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+type SCIMManager struct {
+	Value       string `json:"value"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// SCIM enterprise user extension: https://www.rfc-editor.org/rfc/rfc7643.html#section-4.3
+type SCIMEnterpriseUser struct {
+	EmployeeNumber string       `json:"employeeNumber,omitempty"`
+	Department     string       `json:"department,omitempty"`
+	Manager        *SCIMManager `json:"manager,omitempty"`
+}
+
 type SCIMMetadata struct {
 	ResourceType string `json:"resourceType"`
 }
@@ -38,6 +55,8 @@ type SCIMUser struct {
 	Emails   []SCIMUserEmail `json:"emails"`
 	Active   bool            `json:"active"`
 	Meta     SCIMMetadata    `json:"meta"`
+
+	Enterprise *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
 }
 
 type SCIMParametersRequest struct {
@@ -80,6 +99,8 @@ type SCIMUserCreateRequest struct {
 	Name     SCIMUserName    `json:"name"`
 	Emails   []SCIMUserEmail `json:"emails"`
 	Active   bool            `json:"active"`
+
+	Enterprise *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
 }
 
 func (r SCIMUserCreateRequest) ValidationRules() []validate.ValidationRule {
@@ -96,6 +117,8 @@ type SCIMUserUpdateRequest struct {
 	Name     SCIMUserName    `json:"name"`
 	Emails   []SCIMUserEmail `json:"emails"`
 	Active   bool            `json:"active"`
+
+	Enterprise *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
 }
 
 func (r SCIMUserUpdateRequest) ValidationRules() []validate.ValidationRule {
@@ -128,3 +151,185 @@ func (r SCIMUserPatchRequest) ValidationRules() []validate.ValidationRule {
 		validate.Required("schemas", r.Schemas),
 	}
 }
+
+const GroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+
+type SCIMGroupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+func (r SCIMGroupMember) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("value", r.Value),
+	}
+}
+
+// SCIM group schema: https://www.rfc-editor.org/rfc/rfc7643.html#section-4.2
+type SCIMGroup struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members"`
+	Meta        SCIMMetadata      `json:"meta"`
+}
+
+type ListProviderGroupsResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	Resources    []SCIMGroup `json:"Resources"` // intentionally capitalized
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+}
+
+type SCIMGroupCreateRequest struct {
+	Schemas     []string          `json:"schemas"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members"`
+}
+
+func (r SCIMGroupCreateRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("schemas", r.Schemas),
+		validate.Required("displayName", r.DisplayName),
+	}
+}
+
+type SCIMGroupUpdateRequest struct {
+	ID          uid.ID            `uri:"id" json:"-"`
+	Schemas     []string          `json:"schemas"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members"`
+}
+
+func (r SCIMGroupUpdateRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("schemas", r.Schemas),
+		validate.Required("displayName", r.DisplayName),
+	}
+}
+
+// SCIMGroupPatchOperation changes the members or the name of a group. Value is
+// a list of members when Path is "members", a string when Path is
+// "displayName", and an object with the attributes to replace when Path is
+// empty. Path may also select a single member, like `members[value eq "id"]`.
+type SCIMGroupPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (r SCIMGroupPatchOperation) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("op", r.Op),
+	}
+}
+
+type SCIMGroupPatchRequest struct {
+	ID         uid.ID                    `uri:"id" json:"-"`
+	Schemas    []string                  `json:"schemas"`
+	Operations []SCIMGroupPatchOperation `json:"Operations"` // json intentionally capitalized
+}
+
+func (r SCIMGroupPatchRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("schemas", r.Schemas),
+		validate.Required("Operations", r.Operations),
+	}
+}
+
+const (
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	ResourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+type SCIMBulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type SCIMFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type SCIMAuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+// Service provider configuration: https://www.rfc-editor.org/rfc/rfc7643.html#section-5
+type SCIMServiceProviderConfig struct {
+	Schemas               []string                   `json:"schemas"`
+	Patch                 SCIMSupported              `json:"patch"`
+	Bulk                  SCIMBulkSupport            `json:"bulk"`
+	Filter                SCIMFilterSupport          `json:"filter"`
+	ChangePassword        SCIMSupported              `json:"changePassword"`
+	Sort                  SCIMSupported              `json:"sort"`
+	ETag                  SCIMSupported              `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  SCIMMetadata               `json:"meta"`
+}
+
+type SCIMSchemaExtension struct {
+	Schema   string `json:"schema"`
+	Required bool   `json:"required"`
+}
+
+// Resource type: https://www.rfc-editor.org/rfc/rfc7643.html#section-6
+type SCIMResourceType struct {
+	Schemas          []string              `json:"schemas"`
+	ID               string                `json:"id"`
+	Name             string                `json:"name"`
+	Endpoint         string                `json:"endpoint"`
+	Description      string                `json:"description"`
+	Schema           string                `json:"schema"`
+	SchemaExtensions []SCIMSchemaExtension `json:"schemaExtensions"`
+	Meta             SCIMMetadata          `json:"meta"`
+}
+
+type SCIMSchemaAttribute struct {
+	Name          string                `json:"name"`
+	Type          string                `json:"type"`
+	MultiValued   bool                  `json:"multiValued"`
+	Required      bool                  `json:"required"`
+	CaseExact     bool                  `json:"caseExact"`
+	Mutability    string                `json:"mutability"`
+	Returned      string                `json:"returned"`
+	Uniqueness    string                `json:"uniqueness"`
+	SubAttributes []SCIMSchemaAttribute `json:"subAttributes,omitempty"`
+}
+
+// Schema definition: https://www.rfc-editor.org/rfc/rfc7643.html#section-7
+type SCIMSchema struct {
+	Schemas     []string              `json:"schemas"`
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Attributes  []SCIMSchemaAttribute `json:"attributes"`
+	Meta        SCIMMetadata          `json:"meta"`
+}
+
+type ListSCIMResourceTypesResponse struct {
+	Schemas      []string           `json:"schemas"`
+	TotalResults int                `json:"totalResults"`
+	Resources    []SCIMResourceType `json:"Resources"` // intentionally capitalized
+	StartIndex   int                `json:"startIndex"`
+	ItemsPerPage int                `json:"itemsPerPage"`
+}
+
+type ListSCIMSchemasResponse struct {
+	Schemas      []string     `json:"schemas"`
+	TotalResults int          `json:"totalResults"`
+	Resources    []SCIMSchema `json:"Resources"` // intentionally capitalized
+	StartIndex   int          `json:"startIndex"`
+	ItemsPerPage int          `json:"itemsPerPage"`
+}
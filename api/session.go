@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+// Session is an access key issued to a user by a login.
+type Session struct {
+	ID           uid.ID `json:"id" note:"ID of the access key issued for the session" example:"4yJ3n3D8E2"`
+	Created      Time   `json:"created"`
+	LastUsed     Time   `json:"lastUsed"`
+	Expires      Time   `json:"expires" note:"session is no longer valid after this time"`
+	ProviderID   uid.ID `json:"providerID" note:"ID of the provider the user logged in with" example:"7fk3n3D8E2"`
+	ProviderName string `json:"providerName" note:"name of the provider the user logged in with" example:"okta"`
+	LoginMethod  string `json:"loginMethod" note:"how the user logged in" example:"oidc"`
+	ClientIP     string `json:"clientIP" note:"IP address of the client that last used the session" example:"192.0.2.10"`
+	UserAgent    string `json:"userAgent" note:"user agent of the client that last used the session" example:"Infra CLI/0.21.0"`
+	Current      bool   `json:"current" note:"true if the session is the one used for this request"`
+}
+
+type ListSessionsRequest struct {
+	UserID IDOrSelf `uri:"id" json:"-"`
+	PaginationRequest
+}
+
+func (r ListSessionsRequest) ValidationRules() []validate.ValidationRule {
+	// the rules from the embedded PaginationRequest struct are applied when
+	// the embedded struct is validated, so they are not included here.
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+	}
+}
+
+func (r ListSessionsRequest) SetPage(page int) Paginatable {
+	r.PaginationRequest.Page = page
+	return r
+}
+
+type DeleteSessionRequest struct {
+	UserID    IDOrSelf `uri:"id" json:"-"`
+	SessionID uid.ID   `uri:"sessionID" json:"-"`
+}
+
+func (r DeleteSessionRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+		validate.Required("sessionID", r.SessionID),
+	}
+}
+
+type DeleteSessionsRequest struct {
+	UserID        IDOrSelf `uri:"id" json:"-"`
+	ExceptCurrent bool     `form:"exceptCurrent" note:"do not revoke the session used for this request" example:"true"`
+}
+
+func (r DeleteSessionsRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		validate.Required("id", r.UserID),
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+// SigningKey is a key used to sign the tokens issued to users for
+// destinations. Only the public part of the key is published.
+type SigningKey struct {
+	ID      uid.ID `json:"id" note:"ID of the signing key" example:"4yJ3n3D8E2"`
+	Created Time   `json:"created"`
+	Updated Time   `json:"updated" note:"time of the last change of state"`
+	KeyID   string `json:"keyID" note:"kid of the key in the JSON Web Key Set" example:"X6b1HMa0GmJRUcjBXobCRlsMEavGtTQjV8S-TMd5_hA="`
+	State   string `json:"state" note:"active keys sign new tokens, verifying keys are published to verify tokens, retired keys are no longer published" example:"active"`
+}
+
+type ListSigningKeysRequest struct {
+	PaginationRequest
+}
+
+func (r ListSigningKeysRequest) ValidationRules() []validate.ValidationRule {
+	// no-op ValidationRules implementation so that the rules from the
+	// embedded PaginationRequest struct are not applied twice.
+	return nil
+}
+
+func (r ListSigningKeysRequest) SetPage(page int) Paginatable {
+	r.PaginationRequest.Page = page
+	return r
+}
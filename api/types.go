@@ -42,6 +42,13 @@ func (i *IDOrSelf) UnmarshalText(b []byte) error {
 	return err
 }
 
+func (i IDOrSelf) String() string {
+	if i.IsSelf {
+		return "self"
+	}
+	return i.ID.String()
+}
+
 func (i IDOrSelf) DescribeSchema(schema *openapi3.Schema) {
 	schema.Type = "string"
 	schema.Format = "uid|self"
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+// WorkloadIdentity trusts tokens issued to a workload, like a CI job, by an
+// external OIDC issuer. A workload with a token that matches the audience and
+// subject can login as the user.
+type WorkloadIdentity struct {
+	ID      uid.ID `json:"id" note:"ID of the workload identity" example:"4yJ3n3D8E2"`
+	Created Time   `json:"created"`
+	Updated Time   `json:"updated"`
+
+	Name     string   `json:"name" example:"github-deploy"`
+	Issuer   string   `json:"issuer" note:"iss claim of the tokens" example:"https://token.actions.githubusercontent.com"`
+	JWKSURL  string   `json:"jwksURL" note:"URL of the keys used to verify the tokens" example:"https://token.actions.githubusercontent.com/.well-known/jwks"`
+	Audience string   `json:"audience" note:"aud claim the tokens must include" example:"https://infrahq.com"`
+	Subject  string   `json:"subject" note:"sub claim of the tokens, a * matches any sequence of characters" example:"repo:infrahq/infra:ref:refs/heads/main"`
+	UserID   uid.ID   `json:"userID" note:"ID of the user the workload logs in as" example:"6hNnjfjVcc"`
+	Scopes   []string `json:"scopes" note:"access key scopes of the keys issued to the workload" example:"['grants:read']"`
+}
+
+type CreateWorkloadIdentityRequest struct {
+	Name     string   `json:"name" example:"github-deploy"`
+	Issuer   string   `json:"issuer" note:"iss claim of the tokens" example:"https://token.actions.githubusercontent.com"`
+	JWKSURL  string   `json:"jwksURL" note:"URL of the keys used to verify the tokens" example:"https://token.actions.githubusercontent.com/.well-known/jwks"`
+	Audience string   `json:"audience" note:"aud claim the tokens must include" example:"https://infrahq.com"`
+	Subject  string   `json:"subject" note:"sub claim of the tokens, a * matches any sequence of characters" example:"repo:infrahq/infra:ref:refs/heads/main"`
+	UserID   uid.ID   `json:"userID" note:"ID of the user the workload logs in as" example:"6hNnjfjVcc"`
+	Scopes   []string `json:"scopes" note:"limit the keys issued to the workload to these access key scopes" example:"['grants:read']"`
+}
+
+func (r CreateWorkloadIdentityRequest) ValidationRules() []validate.ValidationRule {
+	return []validate.ValidationRule{
+		ValidateName(r.Name),
+		validate.Required("name", r.Name),
+		validate.Required("issuer", r.Issuer),
+		validate.Required("jwksURL", r.JWKSURL),
+		validate.Required("audience", r.Audience),
+		validate.Required("subject", r.Subject),
+		validate.Required("userID", r.UserID),
+		validate.ValidatorFunc(func() *validate.Failure {
+			if r.JWKSURL == "" {
+				return nil
+			}
+			u, err := url.Parse(r.JWKSURL)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return validate.Fail("jwksURL", "must be an https URL")
+			}
+			return nil
+		}),
+		validate.ValidatorFunc(func() *validate.Failure {
+			for _, scope := range r.Scopes {
+				if _, err := ParseAccessKeyScope(scope); err != nil {
+					return validate.Fail("scopes", err.Error())
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+type ListWorkloadIdentitiesRequest struct {
+	Issuer string `form:"issuer" note:"iss claim of the tokens" example:"https://token.actions.githubusercontent.com"`
+	PaginationRequest
+}
+
+func (r ListWorkloadIdentitiesRequest) ValidationRules() []validate.ValidationRule {
+	// no-op ValidationRules implementation so that the rules from the
+	// embedded PaginationRequest struct are not applied twice.
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 )
@@ -60,7 +61,8 @@ func HandleAuthErr(err error, resource, operation string, roles ...string) error
 // IsAuthorized checks if the request has permission to perform the action. The
 // request has permission if the user or one of the groups they belong to
 // has a grant with one of the required roles.
-// The resource is always ResourceInfraAPI.
+// The resource is always ResourceInfraAPI. Use IsAuthorizedForResource to
+// check roles that are scoped to other resources.
 func IsAuthorized(rCtx RequestContext, requiredRole ...string) error {
 	user := rCtx.Authenticated.User
 	if user == nil {
@@ -82,3 +84,92 @@ func IsAuthorized(rCtx RequestContext, requiredRole ...string) error {
 	}
 	return nil
 }
+
+// IsAuthorizedForResource checks if the request has permission to perform an
+// action on each of the resources using a scoped role, like
+// models.GrantAdminRole or models.GroupOwnerRole. The request has permission
+// if the user or one of the groups they belong to has a grant with the scoped
+// role on a resource that contains every one of the resources.
+//
+// Callers should check IsAuthorized first, so that users with an infra role
+// do not require a scoped role.
+func IsAuthorizedForResource(rCtx RequestContext, scopedRole string, resources ...string) error {
+	scopes, err := listScopes(rCtx, scopedRole)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		if !anyScopeContains(scopes, resource) {
+			return ErrNotAuthorized
+		}
+	}
+	return nil
+}
+
+// isDelegatedAdmin checks if the user has any of the scoped admin roles on
+// any resource. Delegated admins can view users and groups, so that they can
+// find the subject of a grant, or the members of a group.
+func isDelegatedAdmin(rCtx RequestContext) error {
+	scopes, err := listScopes(rCtx, models.GrantAdminRole, models.GroupOwnerRole)
+	if err != nil {
+		return err
+	}
+	if len(scopes) == 0 {
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// listScopes returns the resources of all the grants with one of the scoped
+// roles that apply to the authenticated user.
+func listScopes(rCtx RequestContext, scopedRoles ...string) ([]string, error) {
+	user := rCtx.Authenticated.User
+	if user == nil {
+		return nil, fmt.Errorf("no authenticated user")
+	}
+
+	grants, err := data.ListGrants(rCtx.DBTxn, data.ListGrantsOptions{
+		BySubject:                  models.NewSubjectForUser(user.ID),
+		ByPrivileges:               scopedRoles,
+		IncludeInheritedFromGroups: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list scoped grants: %w", err)
+	}
+
+	scopes := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		scopes = append(scopes, grant.Resource)
+	}
+	return scopes, nil
+}
+
+func anyScopeContains(scopes []string, resource string) bool {
+	for _, scope := range scopes {
+		if scopeContains(scope, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeContains returns true if resource is the same as scope, or is within
+// scope. The destination name in a scope may be a pattern. A scope never
+// contains ResourceInfraAPI, and a group resource is only contained by the
+// same group.
+func scopeContains(scope, resource string) bool {
+	switch {
+	case scope == ResourceInfraAPI || resource == ResourceInfraAPI:
+		return false
+	case strings.HasPrefix(scope, models.GroupResourcePrefix), strings.HasPrefix(resource, models.GroupResourcePrefix):
+		return scope == resource
+	}
+
+	destination, namespace, _ := strings.Cut(resource, ".")
+	if !api.GrantMatchesDestination(scope, destination) {
+		return false
+	}
+	_, scopeNamespace, hasNamespace := strings.Cut(scope, ".")
+	return !hasNamespace || scopeNamespace == namespace
+}
@@ -29,6 +29,10 @@ func ListAccessKeys(rCtx RequestContext, identityID uid.ID, name string, showExp
 }
 
 func CreateAccessKey(rCtx RequestContext, accessKey *models.AccessKey) (string, error) {
+	if key := rCtx.Authenticated.AccessKey; key != nil && len(key.ResourceScopes()) > 0 {
+		// the new key would not be limited by the scopes
+		return "", fmt.Errorf("%w: cannot use an access key with scopes to create other access keys", internal.ErrBadRequest)
+	}
 	if rCtx.Authenticated.AccessKey != nil && !rCtx.Authenticated.AccessKey.Scopes.Includes(models.ScopeAllowCreateAccessKey) {
 		if connector := data.InfraConnectorIdentity(rCtx.DBTxn); connector.ID != accessKey.IssuedForID {
 			// non-login access keys can not currently create non-connector access keys.
@@ -159,6 +159,73 @@ func TestIsAuthorized_MoreCases(t *testing.T) {
 	})
 }
 
+func TestIsAuthorizedForResource(t *testing.T) {
+	db := setupDB(t)
+	tx := txnForTestCase(t, db)
+
+	lead := &models.Identity{Name: "lead@example.com"}
+	assert.NilError(t, data.CreateIdentity(tx, lead))
+
+	leads := &models.Group{Name: "leads"}
+	assert.NilError(t, data.CreateGroup(tx, leads))
+	assert.NilError(t, data.AddUsersToGroup(tx, leads.ID, []uid.ID{lead.ID}))
+
+	admin := &models.Identity{Model: models.Model{ID: uid.ID(512)}}
+	grant(t, tx, admin, models.NewSubjectForUser(lead.ID), models.GrantAdminRole, "prod-*")
+	grant(t, tx, admin, models.NewSubjectForGroup(leads.ID), models.GrantAdminRole, "staging.monitoring")
+	grant(t, tx, admin, models.NewSubjectForUser(lead.ID), models.GroupOwnerRole, "group:sre")
+
+	rCtx := RequestContext{
+		DBTxn:         tx,
+		Authenticated: Authenticated{User: lead},
+	}
+
+	err := IsAuthorizedForResource(rCtx, models.GrantAdminRole, "prod-us-1", "prod-eu.default", "staging.monitoring")
+	assert.NilError(t, err)
+
+	err = IsAuthorizedForResource(rCtx, models.GrantAdminRole, "prod-us-1", "staging")
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+
+	err = IsAuthorizedForResource(rCtx, models.GroupOwnerRole, "group:sre")
+	assert.NilError(t, err)
+
+	err = IsAuthorizedForResource(rCtx, models.GroupOwnerRole, "group:platform")
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+
+	// scoped roles do not give any infra role
+	err = IsAuthorized(rCtx, models.InfraAdminRole, models.InfraViewRole)
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+}
+
+func TestScopeContains(t *testing.T) {
+	type testCase struct {
+		scope    string
+		resource string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{scope: "prod", resource: "prod", expected: true},
+		{scope: "prod", resource: "prod.default", expected: true},
+		{scope: "prod.default", resource: "prod", expected: false},
+		{scope: "prod.default", resource: "prod.default", expected: true},
+		{scope: "prod.default", resource: "prod.other", expected: false},
+		{scope: "prod-*", resource: "prod-us-1.default", expected: true},
+		{scope: "prod-*", resource: "prod-us-*", expected: true},
+		{scope: "prod-us-*", resource: "prod-*", expected: false},
+		{scope: "*", resource: "infra", expected: false},
+		{scope: "*", resource: "group:sre", expected: false},
+		{scope: "group:sre", resource: "group:sre", expected: true},
+		{scope: "group:sre", resource: "group:sre-team", expected: false},
+		{scope: "infra", resource: "infra", expected: false},
+	}
+
+	for _, tc := range testCases {
+		actual := scopeContains(tc.scope, tc.resource)
+		assert.Equal(t, actual, tc.expected, "scope=%v resource=%v", tc.scope, tc.resource)
+	}
+}
+
 func grant(t *testing.T, db data.WriteTxn, createdBy *models.Identity, subject models.Subject, privilege, resource string) {
 	err := data.CreateGrant(db, &models.Grant{
 		Subject:   subject,
@@ -0,0 +1,153 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateAccessRequest creates a request for the authenticated user to be
+// granted access. Any authenticated user may request access.
+func CreateAccessRequest(rCtx RequestContext, req *models.AccessRequest) error {
+	user := rCtx.Authenticated.User
+	if user == nil {
+		return fmt.Errorf("no authenticated user")
+	}
+
+	req.UserID = user.ID
+	req.Status = models.AccessRequestStatusPending
+	return data.CreateAccessRequest(rCtx.DBTxn, req)
+}
+
+func GetAccessRequest(rCtx RequestContext, id uid.ID) (*models.AccessRequest, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	err := IsAuthorized(rCtx, roles...)
+	err = HandleAuthErr(err, "access request", "get", roles...)
+	if errors.Is(err, ErrNotAuthorized) {
+		// Allow an authenticated identity to view their own requests
+		req, getErr := data.GetAccessRequest(rCtx.DBTxn, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if rCtx.Authenticated.User == nil || req.UserID != rCtx.Authenticated.User.ID {
+			return nil, err
+		}
+		return req, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data.GetAccessRequest(rCtx.DBTxn, id)
+}
+
+func ListAccessRequests(rCtx RequestContext, opts data.ListAccessRequestsOptions) ([]models.AccessRequest, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	err := IsAuthorized(rCtx, roles...)
+	err = HandleAuthErr(err, "access requests", "list", roles...)
+	if errors.Is(err, ErrNotAuthorized) {
+		// Allow an authenticated identity to view their own requests
+		user := rCtx.Authenticated.User
+		if user == nil || opts.ByUserID != user.ID {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data.ListAccessRequests(rCtx.DBTxn, opts)
+}
+
+// ApproveAccessRequest approves a pending access request, and creates a grant
+// for the privilege and resource in the request. If the user already has a
+// matching grant, the request is linked to the existing grant.
+func ApproveAccessRequest(rCtx RequestContext, id uid.ID) (*models.AccessRequest, error) {
+	req, err := reviewAccessRequest(rCtx, id, "approve")
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &models.Grant{
+		Subject:   models.NewSubjectForUser(req.UserID),
+		Privilege: req.Privilege,
+		Resource:  req.Resource,
+		CreatedBy: rCtx.Authenticated.User.ID,
+	}
+	if req.Duration > 0 {
+		grant.ExpiresAt = time.Now().Add(req.Duration)
+	}
+
+	err = data.CreateGrant(rCtx.DBTxn, grant)
+	var ucErr data.UniqueConstraintError
+	switch {
+	case errors.As(err, &ucErr):
+		grant, err = data.GetGrant(rCtx.DBTxn, data.GetGrantOptions{
+			BySubject:   grant.Subject,
+			ByPrivilege: grant.Privilege,
+			ByResource:  grant.Resource,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get existing grant: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("create grant: %w", err)
+	}
+
+	req.Status = models.AccessRequestStatusApproved
+	req.GrantID = grant.ID
+	if err := data.UpdateAccessRequest(rCtx.DBTxn, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// DenyAccessRequest denies a pending access request.
+func DenyAccessRequest(rCtx RequestContext, id uid.ID) (*models.AccessRequest, error) {
+	req, err := reviewAccessRequest(rCtx, id, "deny")
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status = models.AccessRequestStatusDenied
+	if err := data.UpdateAccessRequest(rCtx.DBTxn, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// reviewAccessRequest checks that the authenticated user is allowed to review
+// the request, and that the request is still pending. It returns the request
+// with the reviewer fields set.
+func reviewAccessRequest(rCtx RequestContext, id uid.ID, operation string) (*models.AccessRequest, error) {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return nil, HandleAuthErr(err, "access request", operation, models.InfraAdminRole)
+	}
+
+	req, err := data.GetAccessRequest(rCtx.DBTxn, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role := requiredInfraRoleForGrantOperation(&models.Grant{
+		Privilege: req.Privilege,
+		Resource:  req.Resource,
+	})
+	if role != models.InfraAdminRole {
+		if err := IsAuthorized(rCtx, role); err != nil {
+			return nil, HandleAuthErr(err, "access request", operation, role)
+		}
+	}
+
+	if req.Status != models.AccessRequestStatusPending {
+		return nil, fmt.Errorf("%w: access request is already %v", internal.ErrBadRequest, req.Status)
+	}
+
+	req.ReviewedBy = rCtx.Authenticated.User.ID
+	req.ReviewedAt = time.Now()
+	return req, nil
+}
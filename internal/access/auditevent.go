@@ -0,0 +1,15 @@
+package access
+
+import (
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func ListAuditEvents(rCtx RequestContext, opts data.ListAuditEventsOptions) ([]models.AuditEvent, error) {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return nil, HandleAuthErr(err, "audit events", "list", models.InfraAdminRole)
+	}
+
+	return data.ListAuditEvents(rCtx.DBTxn, opts)
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -58,12 +61,14 @@ func createCredential(tx *data.Transaction, user *models.Identity, credential *m
 
 // ResetCredential resets a user's password to a specified value. If the input value is empty, a password
 // is randomly generated. No matter the input, the new password is one-time use and must be changed by the user.
+// A specified value must meet the password policy of the organization.
 func ResetCredential(rCtx RequestContext, user *models.Identity, newPassword string) (string, error) {
 	err := IsAuthorized(rCtx, models.InfraAdminRole)
 	if err != nil {
 		return "", HandleAuthErr(err, "user", "update", models.InfraAdminRole)
 	}
 
+	tx := rCtx.DBTxn
 	if newPassword == "" {
 		password, err := generate.CryptoRandom(12, generate.CharsetPassword)
 		if err != nil {
@@ -71,9 +76,16 @@ func ResetCredential(rCtx RequestContext, user *models.Identity, newPassword str
 		}
 
 		newPassword = password
+	} else {
+		policy, err := passwordPolicy(tx)
+		if err != nil {
+			return "", err
+		}
+		if err := checkPasswordPolicy(policy, newPassword); err != nil {
+			return "", err
+		}
 	}
 
-	tx := rCtx.DBTxn
 	credential, err := data.GetCredentialByUserID(tx, user.ID)
 	switch {
 	case errors.Is(err, internal.ErrNotFound):
@@ -91,8 +103,11 @@ func ResetCredential(rCtx RequestContext, user *models.Identity, newPassword str
 		return "", fmt.Errorf("generate from password: %w", err)
 	}
 
+	// one-time passwords are not added to the password history, they are
+	// not chosen by the user
 	credential.OneTimePassword = true
 	credential.PasswordHash = hash
+	credential.PasswordUpdatedAt = time.Now()
 
 	if err := data.UpdateCredential(tx, credential); err != nil {
 		return "", fmt.Errorf("update credential: %w", err)
@@ -122,14 +137,10 @@ func UpdateCredential(rCtx RequestContext, user *models.Identity, oldPassword, n
 		return errs
 	}
 
-	hash, err := GenerateFromPassword(newPassword)
-	if err != nil {
+	if err := setPassword(tx, credential, newPassword); err != nil {
 		return err
 	}
 
-	credential.OneTimePassword = false
-	credential.PasswordHash = hash
-
 	if err := data.UpdateCredential(tx, credential); err != nil {
 		return fmt.Errorf("update credential: %w", err)
 	}
@@ -151,18 +162,130 @@ func UpdateCredential(rCtx RequestContext, user *models.Identity, oldPassword, n
 	return nil
 }
 
-func GenerateFromPassword(password string) ([]byte, error) {
-	if len(password) < 8 {
-		return nil, validate.Error{"password": []string{"8 characters"}}
-	}
-
-	if err := checkBadPasswords(password); err != nil {
+// GenerateFromPassword checks that the password meets the password policy,
+// and returns the hash of the password.
+func GenerateFromPassword(policy models.PasswordPolicy, password string) ([]byte, error) {
+	if err := checkPasswordPolicy(policy, password); err != nil {
 		return nil, err
 	}
 
 	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 }
 
+// setPassword sets a password chosen by the user on the credential. The
+// password must meet the password policy of the organization, and must not
+// be one of the recent passwords of the user.
+func setPassword(tx *data.Transaction, credential *models.Credential, password string) error {
+	policy, err := passwordPolicy(tx)
+	if err != nil {
+		return err
+	}
+
+	hash, err := GenerateFromPassword(policy, password)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPasswordReuse(policy, credential, password); err != nil {
+		return err
+	}
+
+	history := append(models.CommaSeparatedStrings{string(hash)}, credential.PasswordHashHistory...)
+	if len(history) > policy.PasswordHistory {
+		history = history[:policy.PasswordHistory]
+	}
+
+	credential.OneTimePassword = false
+	credential.PasswordHash = hash
+	credential.PasswordHashHistory = history
+	credential.PasswordUpdatedAt = time.Now()
+	return nil
+}
+
+func passwordPolicy(tx data.ReadTxn) (models.PasswordPolicy, error) {
+	org, err := data.GetOrganization(tx, data.GetOrganizationOptions{ByID: tx.OrganizationID()})
+	if err != nil {
+		return models.PasswordPolicy{}, fmt.Errorf("get organization: %w", err)
+	}
+	return org.PasswordPolicy, nil
+}
+
+// checkPasswordPolicy checks the length and the characters of the password.
+// The password is also checked against the list of bad passwords.
+func checkPasswordPolicy(policy models.PasswordPolicy, password string) error {
+	var lower, upper, number, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsDigit(r):
+			number++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol++
+		}
+	}
+
+	minLength := policy.PasswordMinLength
+	if minLength < models.DefaultPasswordMinLength {
+		minLength = models.DefaultPasswordMinLength
+	}
+
+	var requirements []string
+	if utf8.RuneCountInString(password) < minLength {
+		requirements = append(requirements, plural(minLength, "character"))
+	}
+	if lower < policy.PasswordLowercaseMin {
+		requirements = append(requirements, plural(policy.PasswordLowercaseMin, "lowercase letter"))
+	}
+	if upper < policy.PasswordUppercaseMin {
+		requirements = append(requirements, plural(policy.PasswordUppercaseMin, "uppercase letter"))
+	}
+	if number < policy.PasswordNumberMin {
+		requirements = append(requirements, plural(policy.PasswordNumberMin, "number"))
+	}
+	if symbol < policy.PasswordSymbolMin {
+		requirements = append(requirements, plural(policy.PasswordSymbolMin, "symbol"))
+	}
+	if len(requirements) > 0 {
+		return validate.Error{"password": requirements}
+	}
+
+	return checkBadPasswords(password)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// checkPasswordReuse checks that the password is not one of the most recent
+// passwords chosen by the user.
+func checkPasswordReuse(policy models.PasswordPolicy, credential *models.Credential, password string) error {
+	if policy.PasswordHistory == 0 {
+		return nil
+	}
+
+	hashes := credential.PasswordHashHistory
+	if len(hashes) > policy.PasswordHistory {
+		hashes = hashes[:policy.PasswordHistory]
+	}
+	// the history is empty for passwords set before the policy was enabled
+	if !credential.OneTimePassword && len(hashes) == 0 && len(credential.PasswordHash) > 0 {
+		hashes = models.CommaSeparatedStrings{string(credential.PasswordHash)}
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return fmt.Errorf("%w: cannot reuse one of the last %d passwords", internal.ErrBadRequest, policy.PasswordHistory)
+		}
+	}
+	return nil
+}
+
 // checkBadPasswords checks if the password is a known bad password, i.e. a widely reused password.
 func checkBadPasswords(password string) error {
 	badPasswordsFile := os.Getenv("INFRA_SERVER_BAD_PASSWORDS_FILE")
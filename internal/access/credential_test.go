@@ -102,17 +102,124 @@ func TestResetCredentials(t *testing.T) {
 
 func TestGenerateFromPassword(t *testing.T) {
 	t.Run("default password requirements", func(t *testing.T) {
-		hash, err := GenerateFromPassword("password")
+		hash, err := GenerateFromPassword(models.PasswordPolicy{}, "password")
 		assert.NilError(t, err)
 
 		err = bcrypt.CompareHashAndPassword(hash, []byte("password"))
 		assert.NilError(t, err)
 
-		_, err = GenerateFromPassword("passwor")
+		_, err = GenerateFromPassword(models.PasswordPolicy{}, "passwor")
 		assert.DeepEqual(t, err, validate.Error{
 			"password": []string{"8 characters"},
 		})
 	})
+
+	t.Run("minimum length can not be lower than the default", func(t *testing.T) {
+		_, err := GenerateFromPassword(models.PasswordPolicy{PasswordMinLength: 4}, "passwor")
+		assert.DeepEqual(t, err, validate.Error{
+			"password": []string{"8 characters"},
+		})
+	})
+
+	t.Run("organization password policy", func(t *testing.T) {
+		policy := models.PasswordPolicy{
+			PasswordMinLength:    12,
+			PasswordLowercaseMin: 1,
+			PasswordUppercaseMin: 2,
+			PasswordNumberMin:    1,
+			PasswordSymbolMin:    1,
+		}
+
+		_, err := GenerateFromPassword(policy, "PASSWORD")
+		assert.DeepEqual(t, err, validate.Error{
+			"password": []string{"12 characters", "1 lowercase letter", "1 number", "1 symbol"},
+		})
+
+		_, err = GenerateFromPassword(policy, "Password12345!")
+		assert.DeepEqual(t, err, validate.Error{
+			"password": []string{"2 uppercase letters"},
+		})
+
+		_, err = GenerateFromPassword(policy, "PassWord1234!")
+		assert.NilError(t, err)
+	})
+}
+
+func TestCheckPasswordReuse(t *testing.T) {
+	hash := func(password string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		assert.NilError(t, err)
+		return string(h)
+	}
+	credential := &models.Credential{
+		PasswordHash:        []byte(hash("current-password")),
+		PasswordHashHistory: models.CommaSeparatedStrings{hash("current-password"), hash("older-password"), hash("oldest-password")},
+	}
+
+	t.Run("no history", func(t *testing.T) {
+		err := checkPasswordReuse(models.PasswordPolicy{}, credential, "current-password")
+		assert.NilError(t, err)
+	})
+
+	t.Run("recent passwords can not be reused", func(t *testing.T) {
+		policy := models.PasswordPolicy{PasswordHistory: 2}
+		err := checkPasswordReuse(policy, credential, "current-password")
+		assert.ErrorContains(t, err, "cannot reuse one of the last 2 passwords")
+		err = checkPasswordReuse(policy, credential, "older-password")
+		assert.ErrorContains(t, err, "cannot reuse one of the last 2 passwords")
+	})
+
+	t.Run("passwords older than the history can be reused", func(t *testing.T) {
+		policy := models.PasswordPolicy{PasswordHistory: 2}
+		err := checkPasswordReuse(policy, credential, "oldest-password")
+		assert.NilError(t, err)
+	})
+
+	t.Run("password set before the policy was enabled", func(t *testing.T) {
+		policy := models.PasswordPolicy{PasswordHistory: 3}
+		credential := &models.Credential{PasswordHash: []byte(hash("current-password"))}
+		err := checkPasswordReuse(policy, credential, "current-password")
+		assert.ErrorContains(t, err, "cannot reuse one of the last 3 passwords")
+	})
+}
+
+func TestUpdateCredential_PasswordPolicy(t *testing.T) {
+	rCtx := setupAccessTestContext(t)
+	db := rCtx.DBTxn
+
+	org, err := data.GetOrganization(db, data.GetOrganizationOptions{ByID: db.OrganizationID()})
+	assert.NilError(t, err)
+	org.PasswordPolicy = models.PasswordPolicy{PasswordMinLength: 10, PasswordNumberMin: 1, PasswordHistory: 2}
+	assert.NilError(t, data.UpdateOrganization(db, org))
+
+	user := &models.Identity{Name: "bruce@example.com"}
+	assert.NilError(t, data.CreateIdentity(db, user))
+
+	oneTimePassword, err := CreateCredential(rCtx, user)
+	assert.NilError(t, err)
+	rCtx.Authenticated.User = user
+
+	err = UpdateCredential(rCtx, user, oneTimePassword, "supersecret")
+	assert.DeepEqual(t, err, validate.Error{"password": []string{"10 characters", "1 number"}})
+
+	assert.NilError(t, UpdateCredential(rCtx, user, oneTimePassword, "supersecret1"))
+	assert.NilError(t, UpdateCredential(rCtx, user, "supersecret1", "supersecret2"))
+
+	err = UpdateCredential(rCtx, user, "supersecret2", "supersecret1")
+	assert.ErrorContains(t, err, "cannot reuse one of the last 2 passwords")
+
+	assert.NilError(t, UpdateCredential(rCtx, user, "supersecret2", "supersecret3"))
+	// only the last 2 passwords are kept
+	assert.NilError(t, UpdateCredential(rCtx, user, "supersecret3", "supersecret1"))
+
+	credential, err := data.GetCredentialByUserID(db, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(credential.PasswordHashHistory), 2)
+
+	t.Run("reset to a value that does not meet the policy", func(t *testing.T) {
+		_, err := ResetCredential(rCtx, user, "short")
+		assert.DeepEqual(t, err, validate.Error{"password": []string{"10 characters", "1 number"}})
+	})
 }
 
 func TestCheckBadPasswords(t *testing.T) {
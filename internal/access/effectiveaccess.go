@@ -0,0 +1,214 @@
+package access
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// EffectiveAccess is a grant that gives a user access to a resource, either
+// directly or through their membership in a group.
+type EffectiveAccess struct {
+	UserID   uid.ID
+	UserName string
+	Grant    models.Grant
+	// Group is the group the access is inherited from. Group is nil when the
+	// grant is to the user directly.
+	Group *models.Group
+}
+
+// ListUserAccess returns the access a user has from grants to the user and
+// grants to the groups they belong to. When destination is not empty only the
+// access to that destination is returned.
+func ListUserAccess(rCtx RequestContext, userID uid.ID, destination string) ([]EffectiveAccess, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	err := IsAuthorized(rCtx, roles...)
+	err = HandleAuthErr(err, "user access", "list", roles...)
+	if errors.Is(err, ErrNotAuthorized) {
+		// Allow an authenticated identity to view their own access
+		if user := rCtx.Authenticated.User; user == nil || user.ID != userID {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	tx := rCtx.DBTxn
+	user, err := data.GetIdentity(tx, data.GetIdentityOptions{ByID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := data.ListGrants(tx, data.ListGrantsOptions{
+		BySubject:                  models.NewSubjectForUser(user.ID),
+		ByDestination:              destination,
+		IncludeInheritedFromGroups: true,
+		ExcludeConnectorGrant:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupsForGrants(tx, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]EffectiveAccess, 0, len(grants))
+	for _, grant := range grants {
+		result = append(result, EffectiveAccess{
+			UserID:   user.ID,
+			UserName: user.Name,
+			Grant:    grant,
+			Group:    groups[grant.Subject.ID],
+		})
+	}
+	sortEffectiveAccess(result)
+	return result, nil
+}
+
+// ListDestinationAccess returns the access every user has to the destination,
+// from grants to the user and grants to the groups they belong to, either
+// directly or through a nested group.
+func ListDestinationAccess(rCtx RequestContext, destinationID uid.ID) ([]EffectiveAccess, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	if err := IsAuthorized(rCtx, roles...); err != nil {
+		return nil, HandleAuthErr(err, "destination access", "list", roles...)
+	}
+
+	tx := rCtx.DBTxn
+	destination, err := data.GetDestination(tx, data.GetDestinationOptions{ByID: destinationID})
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := data.ListGrants(tx, data.ListGrantsOptions{ByDestination: destination.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupsForGrants(tx, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uid.ID
+	for _, grant := range grants {
+		if grant.Subject.Kind == models.SubjectKindUser {
+			userIDs = append(userIDs, grant.Subject.ID)
+		}
+	}
+	userNames := make(map[uid.ID]string, len(userIDs))
+	if len(userIDs) > 0 {
+		users, err := data.ListIdentities(tx, data.ListIdentityOptions{ByIDs: userIDs})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			userNames[user.ID] = user.Name
+		}
+	}
+
+	members := make(map[uid.ID][]models.Identity, len(groups))
+	for id := range groups {
+		users, err := listGroupMembers(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		members[id] = users
+	}
+
+	var result []EffectiveAccess
+	for _, grant := range grants {
+		switch grant.Subject.Kind {
+		case models.SubjectKindUser:
+			name, ok := userNames[grant.Subject.ID]
+			if !ok {
+				// the user was deleted, but the grant remains
+				continue
+			}
+			result = append(result, EffectiveAccess{
+				UserID:   grant.Subject.ID,
+				UserName: name,
+				Grant:    grant,
+			})
+		case models.SubjectKindGroup:
+			for _, user := range members[grant.Subject.ID] {
+				result = append(result, EffectiveAccess{
+					UserID:   user.ID,
+					UserName: user.Name,
+					Grant:    grant,
+					Group:    groups[grant.Subject.ID],
+				})
+			}
+		}
+	}
+	sortEffectiveAccess(result)
+	return result, nil
+}
+
+// listGroupMembers returns the members of the group, including the members of
+// any groups nested in the group.
+func listGroupMembers(tx data.ReadTxn, groupID uid.ID) ([]models.Identity, error) {
+	groupIDs, err := data.ListDescendantGroupIDs(tx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uid.ID]bool)
+	var result []models.Identity
+	for _, id := range groupIDs {
+		users, err := data.ListIdentities(tx, data.ListIdentityOptions{ByGroupID: id})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if seen[user.ID] {
+				continue
+			}
+			seen[user.ID] = true
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+// groupsForGrants returns the groups that are the subject of any of the grants,
+// keyed by group ID.
+func groupsForGrants(tx data.ReadTxn, grants []models.Grant) (map[uid.ID]*models.Group, error) {
+	var groupIDs []uid.ID
+	for _, grant := range grants {
+		if grant.Subject.Kind == models.SubjectKindGroup {
+			groupIDs = append(groupIDs, grant.Subject.ID)
+		}
+	}
+	result := make(map[uid.ID]*models.Group, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	groups, err := data.ListGroups(tx, data.ListGroupsOptions{ByIDs: groupIDs})
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		result[groups[i].ID] = &groups[i]
+	}
+	return result, nil
+}
+
+func sortEffectiveAccess(items []EffectiveAccess) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.UserName != b.UserName {
+			return a.UserName < b.UserName
+		}
+		if a.Grant.Resource != b.Grant.Resource {
+			return a.Grant.Resource < b.Grant.Resource
+		}
+		return a.Grant.Privilege < b.Grant.Privilege
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/infrahq/infra/internal"
@@ -16,8 +17,11 @@ import (
 
 func GetGrant(rCtx RequestContext, id uid.ID) (*models.Grant, error) {
 	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if errors.Is(err, ErrNotAuthorized) {
+		err = isGrantAdminForGrantID(rCtx, id)
+	}
 	if err != nil {
-		return nil, HandleAuthErr(err, "grant", "get", models.InfraAdminRole)
+		return nil, HandleAuthErr(err, "grant", "get", models.InfraAdminRole, models.GrantAdminRole)
 	}
 
 	return data.GetGrant(rCtx.DBTxn, data.GetGrantOptions{ByID: id})
@@ -31,6 +35,10 @@ type ListGrantsResponse struct {
 func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateIndex int64) (ListGrantsResponse, error) {
 	subject := opts.BySubject
 
+	// scopes are the resources of the grant-admin roles of the user, set when
+	// those roles are the reason the user is authorized to list the grants.
+	var scopes []string
+
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
 	err := IsAuthorized(rCtx, roles...)
 	err = HandleAuthErr(err, "grants", "list", roles...)
@@ -44,7 +52,11 @@ func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateInde
 		case subject.Kind == models.SubjectKindGroup && userInGroup(rCtx.DBTxn, rCtx.Authenticated.User.ID, subject.ID):
 			// authorized because the request is for grants of a group they belong to
 		default:
-			return ListGrantsResponse{}, err
+			// authorized for the grants to resources they administer
+			scopes, err = grantAdminScopesForListOptions(rCtx, opts)
+			if err != nil {
+				return ListGrantsResponse{}, HandleAuthErr(err, "grants", "list", roles...)
+			}
 		}
 	} else if err != nil {
 		return ListGrantsResponse{}, err
@@ -52,7 +64,7 @@ func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateInde
 
 	if lastUpdateIndex == 0 {
 		result, err := data.ListGrants(rCtx.DBTxn, opts)
-		return ListGrantsResponse{Grants: result}, err
+		return ListGrantsResponse{Grants: grantsInScopes(result, scopes)}, err
 	}
 
 	// Close the request scoped txn to avoid long-running transactions.
@@ -78,7 +90,7 @@ func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateInde
 		}
 	}()
 
-	result, err := listGrantsWithMaxUpdateIndex(rCtx, opts)
+	result, err := listGrantsWithMaxUpdateIndex(rCtx, opts, scopes)
 	if err != nil {
 		return result, err
 	}
@@ -96,7 +108,7 @@ func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateInde
 		return result, fmt.Errorf("waiting for notify: %w", err)
 	}
 
-	result, err = listGrantsWithMaxUpdateIndex(rCtx, opts)
+	result, err = listGrantsWithMaxUpdateIndex(rCtx, opts, scopes)
 	if err != nil {
 		return result, err
 	}
@@ -107,7 +119,7 @@ func ListGrants(rCtx RequestContext, opts data.ListGrantsOptions, lastUpdateInde
 	return result, nil
 }
 
-func listGrantsWithMaxUpdateIndex(rCtx RequestContext, opts data.ListGrantsOptions) (ListGrantsResponse, error) {
+func listGrantsWithMaxUpdateIndex(rCtx RequestContext, opts data.ListGrantsOptions, scopes []string) (ListGrantsResponse, error) {
 	tx, err := rCtx.DataDB.Begin(rCtx.Request.Context(), &sql.TxOptions{
 		ReadOnly:  true,
 		Isolation: sql.LevelRepeatableRead,
@@ -126,7 +138,7 @@ func listGrantsWithMaxUpdateIndex(rCtx RequestContext, opts data.ListGrantsOptio
 	maxUpdateIndex, err := data.GrantsMaxUpdateIndex(tx, data.GrantsMaxUpdateIndexOptions{
 		ByDestination: opts.ByDestination,
 	})
-	return ListGrantsResponse{Grants: result, MaxUpdateIndex: maxUpdateIndex}, err
+	return ListGrantsResponse{Grants: grantsInScopes(result, scopes), MaxUpdateIndex: maxUpdateIndex}, err
 }
 
 func logError(fn func() error, msg string) {
@@ -150,10 +162,11 @@ func userInGroup(db data.ReadTxn, authnUserID uid.ID, groupID uid.ID) bool {
 }
 
 func CreateGrant(rCtx RequestContext, grant *models.Grant) error {
-	role := requiredInfraRoleForGrantOperation(grant)
-	err := IsAuthorized(rCtx, role)
-	if err != nil {
-		return HandleAuthErr(err, "grant", "create", role)
+	if err := isAuthorizedToChangeGrants(rCtx, "create", grant); err != nil {
+		return err
+	}
+	if err := validateScopedRoleGrant(grant); err != nil {
+		return err
 	}
 
 	// TODO: CreatedBy should be set automatically
@@ -165,8 +178,11 @@ func CreateGrant(rCtx RequestContext, grant *models.Grant) error {
 func DeleteGrant(rCtx RequestContext, id uid.ID) error {
 	// TODO: should support-admin role be required to delete support-admin grant?
 	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if errors.Is(err, ErrNotAuthorized) {
+		err = isGrantAdminForGrantID(rCtx, id)
+	}
 	if err != nil {
-		return HandleAuthErr(err, "grant", "delete", models.InfraAdminRole)
+		return HandleAuthErr(err, "grant", "delete", models.InfraAdminRole, models.GrantAdminRole)
 	}
 
 	return data.DeleteGrants(rCtx.DBTxn, data.DeleteGrantsOptions{ByID: id})
@@ -176,10 +192,13 @@ func UpdateGrants(rCtx RequestContext, addGrants, rmGrants []*models.Grant) erro
 	all := make([]*models.Grant, 0, len(addGrants)+len(rmGrants))
 	all = append(all, addGrants...)
 	all = append(all, rmGrants...)
-	role := requiredInfraRoleForGrantOperation(all...)
-	err := IsAuthorized(rCtx, role)
-	if err != nil {
-		return HandleAuthErr(err, "grant", "update", role)
+	if err := isAuthorizedToChangeGrants(rCtx, "update", all...); err != nil {
+		return err
+	}
+	for _, grant := range addGrants {
+		if err := validateScopedRoleGrant(grant); err != nil {
+			return err
+		}
 	}
 
 	return data.UpdateGrants(rCtx.DBTxn, addGrants, rmGrants)
@@ -193,3 +212,107 @@ func requiredInfraRoleForGrantOperation(grants ...*models.Grant) string {
 	}
 	return models.InfraAdminRole
 }
+
+// isAuthorizedToChangeGrants checks if the request has permission to create
+// or delete all of the grants. Users with the infra admin role can change any
+// grant. Users with the grant-admin role can change grants to resources within
+// the scope of their role.
+func isAuthorizedToChangeGrants(rCtx RequestContext, operation string, grants ...*models.Grant) error {
+	role := requiredInfraRoleForGrantOperation(grants...)
+	err := IsAuthorized(rCtx, role)
+	if !errors.Is(err, ErrNotAuthorized) || role != models.InfraAdminRole {
+		return HandleAuthErr(err, "grant", operation, role)
+	}
+
+	err = isGrantAdminFor(rCtx, grants...)
+	return HandleAuthErr(err, "grant", operation, role, models.GrantAdminRole)
+}
+
+// isGrantAdminFor checks if the user has the grant-admin role for the
+// resources of all the grants. The grant-admin role can not be used to give
+// a scoped admin role to another user.
+func isGrantAdminFor(rCtx RequestContext, grants ...*models.Grant) error {
+	resources := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		switch grant.Privilege {
+		case models.GrantAdminRole, models.GroupOwnerRole:
+			return ErrNotAuthorized
+		}
+		resources = append(resources, grant.Resource)
+	}
+	return IsAuthorizedForResource(rCtx, models.GrantAdminRole, resources...)
+}
+
+func isGrantAdminForGrantID(rCtx RequestContext, id uid.ID) error {
+	grant, err := data.GetGrant(rCtx.DBTxn, data.GetGrantOptions{ByID: id})
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		// don't reveal if the grant exists
+		return ErrNotAuthorized
+	case err != nil:
+		return err
+	}
+	return isGrantAdminFor(rCtx, grant)
+}
+
+// grantAdminScopesForListOptions returns the resources of the grant-admin
+// roles of the user, if one of them contains the resource or destination in
+// opts. A destination may match grants that are outside of the scopes, like a
+// grant to every destination, so the results must be filtered with
+// grantsInScopes.
+func grantAdminScopesForListOptions(rCtx RequestContext, opts data.ListGrantsOptions) ([]string, error) {
+	var resource string
+	switch {
+	case opts.ByResource != "":
+		resource = opts.ByResource
+	case opts.ByDestination != "":
+		resource = opts.ByDestination
+	default:
+		return nil, ErrNotAuthorized
+	}
+
+	scopes, err := listScopes(rCtx, models.GrantAdminRole)
+	if err != nil {
+		return nil, err
+	}
+	if !anyScopeContains(scopes, resource) {
+		return nil, ErrNotAuthorized
+	}
+	return scopes, nil
+}
+
+// grantsInScopes returns the grants to resources that are contained by one of
+// the scopes. When scopes is nil the user is not limited to scopes, and all of
+// the grants are returned.
+func grantsInScopes(grants []models.Grant, scopes []string) []models.Grant {
+	if scopes == nil {
+		return grants
+	}
+
+	result := make([]models.Grant, 0, len(grants))
+	for _, grant := range grants {
+		if anyScopeContains(scopes, grant.Resource) {
+			result = append(result, grant)
+		}
+	}
+	return result
+}
+
+// validateScopedRoleGrant checks that a grant with a scoped role has a
+// resource that can be used as the scope of that role.
+func validateScopedRoleGrant(grant *models.Grant) error {
+	isGroupResource := strings.HasPrefix(grant.Resource, models.GroupResourcePrefix)
+	switch grant.Privilege {
+	case models.GrantAdminRole:
+		if grant.Resource == ResourceInfraAPI || isGroupResource {
+			return fmt.Errorf("%w: the %v role requires a destination resource",
+				internal.ErrBadRequest, models.GrantAdminRole)
+		}
+	case models.GroupOwnerRole:
+		if !isGroupResource || grant.Resource == models.GroupResourcePrefix {
+			return fmt.Errorf("%w: the %v role requires a group resource, like %vEngineering",
+				internal.ErrBadRequest, models.GroupOwnerRole, models.GroupResourcePrefix)
+		}
+	}
+	return nil
+}
@@ -33,6 +33,8 @@ func ListGroups(rCtx RequestContext, name string, userID uid.ID, p *data.Paginat
 			return nil, err
 		case userID == identity.ID:
 			return data.ListGroups(rCtx.DBTxn, opts)
+		case isDelegatedAdmin(rCtx) == nil:
+			return data.ListGroups(rCtx.DBTxn, opts)
 		}
 	}
 
@@ -58,10 +60,11 @@ func GetGroup(rCtx RequestContext, opts data.GetGroupOptions) (*models.Group, er
 		if err != nil {
 			return nil, err
 		}
-		if !userInGroup(rCtx.DBTxn, rCtx.Authenticated.User.ID, group.ID) {
+		if !userInGroup(rCtx.DBTxn, rCtx.Authenticated.User.ID, group.ID) && isDelegatedAdmin(rCtx) != nil {
 			return nil, err
 		}
-		// authorized by user belonging to the requested group
+		// authorized by user belonging to the requested group, or by a
+		// scoped admin role
 	} else if err != nil {
 		return nil, err
 	}
@@ -73,9 +76,28 @@ func DeleteGroup(rCtx RequestContext, id uid.ID) error {
 	if err != nil {
 		return HandleAuthErr(err, "group", "delete", models.InfraAdminRole)
 	}
+
+	group, err := data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: id})
+	if err != nil {
+		return err
+	}
+	if err := checkGroupNotManagedByProvider(group); err != nil {
+		return err
+	}
 	return data.DeleteGroup(rCtx.DBTxn, id)
 }
 
+// checkGroupNotManagedByProvider returns an error if the group was provisioned
+// by an identity provider with SCIM. The provider sets the members of these
+// groups, so they can not be changed by hand. Groups created when a user logs
+// in with an identity provider are not managed by the provider.
+func checkGroupNotManagedByProvider(group *models.Group) error {
+	if group.ProvisionedBySCIM {
+		return fmt.Errorf("%w: group %q is managed by an identity provider", internal.ErrBadRequest, group.Name)
+	}
+	return nil
+}
+
 func checkIdentitiesInList(db data.ReadTxn, ids []uid.ID) ([]uid.ID, error) {
 	if len(ids) == 0 {
 		return ids, nil
@@ -108,15 +130,17 @@ func checkIdentitiesInList(db data.ReadTxn, ids []uid.ID) ([]uid.ID, error) {
 }
 
 func UpdateUsersInGroup(rCtx RequestContext, groupID uid.ID, uidsToAdd []uid.ID, uidsToRemove []uid.ID) error {
-	err := IsAuthorized(rCtx, models.InfraAdminRole)
-	if err != nil {
+	if err := isAuthorizedToUpdateGroup(rCtx, groupID); err != nil {
 		return err
 	}
 
-	_, err = data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: groupID})
+	group, err := data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: groupID})
 	if err != nil {
 		return err
 	}
+	if err := checkGroupNotManagedByProvider(group); err != nil {
+		return err
+	}
 
 	addIDList, err := checkIdentitiesInList(rCtx.DBTxn, uidsToAdd)
 	if err != nil {
@@ -141,3 +165,124 @@ func UpdateUsersInGroup(rCtx RequestContext, groupID uid.ID, uidsToAdd []uid.ID,
 	}
 	return nil
 }
+
+func checkGroupsInList(db data.ReadTxn, ids []uid.ID) ([]uid.ID, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	groups, err := data.ListGroups(db, data.ListGroupsOptions{ByIDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uid.ID]bool, len(groups))
+	for _, group := range groups {
+		found[group.ID] = true
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id.String())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, "Couldn't find group UIDs: "+strings.Join(missing, ","))
+	}
+	return ids, nil
+}
+
+// UpdateGroupsInGroup adds and removes groups nested in the group with ID
+// groupID. Members of a nested group inherit the grants of the group.
+func UpdateGroupsInGroup(rCtx RequestContext, groupID uid.ID, idsToAdd []uid.ID, idsToRemove []uid.ID) error {
+	if err := isAuthorizedToUpdateGroup(rCtx, groupID); err != nil {
+		return err
+	}
+
+	group, err := data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: groupID})
+	if err != nil {
+		return err
+	}
+	if err := checkGroupNotManagedByProvider(group); err != nil {
+		return err
+	}
+
+	addIDList, err := checkGroupsInList(rCtx.DBTxn, idsToAdd)
+	if err != nil {
+		return err
+	}
+
+	if len(addIDList) > 0 {
+		err := data.AddGroupsToGroup(rCtx.DBTxn, groupID, addIDList)
+		if errors.Is(err, data.ErrGroupCycle) {
+			return fmt.Errorf("%w: %v", internal.ErrBadRequest, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(idsToRemove) > 0 {
+		if err := data.RemoveGroupsFromGroup(rCtx.DBTxn, groupID, idsToRemove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isAuthorizedToUpdateGroup checks if the request has permission to change the
+// members of a group. Users with the infra admin role can update any group.
+// Users with the group-owner role can update the group named by their grant,
+// unless the group has a grant on infra, or a grant with a scoped admin role
+// (grant-admin or group-owner), either directly or from a group that contains
+// it. Otherwise a group owner could give themselves those roles by joining the
+// group.
+func isAuthorizedToUpdateGroup(rCtx RequestContext, groupID uid.ID) error {
+	roles := []string{models.InfraAdminRole, models.GroupOwnerRole}
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if !errors.Is(err, ErrNotAuthorized) {
+		return err
+	}
+
+	group, err := data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: groupID})
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		// don't reveal if the group exists
+		return HandleAuthErr(ErrNotAuthorized, "group", "update", roles...)
+	case err != nil:
+		return err
+	}
+
+	err = IsAuthorizedForResource(rCtx, models.GroupOwnerRole, models.GroupResourcePrefix+group.Name)
+	if err != nil {
+		return HandleAuthErr(err, "group", "update", roles...)
+	}
+
+	infraGrants, err := data.ListGrants(rCtx.DBTxn, data.ListGrantsOptions{
+		Pagination:                 &data.Pagination{Limit: 1},
+		BySubject:                  models.NewSubjectForGroup(group.ID),
+		ByResource:                 ResourceInfraAPI,
+		IncludeInheritedFromGroups: true,
+	})
+	if err != nil {
+		return err
+	}
+	if len(infraGrants) > 0 {
+		return HandleAuthErr(ErrNotAuthorized, "group", "update", models.InfraAdminRole)
+	}
+
+	adminGrants, err := data.ListGrants(rCtx.DBTxn, data.ListGrantsOptions{
+		Pagination:                 &data.Pagination{Limit: 1},
+		BySubject:                  models.NewSubjectForGroup(group.ID),
+		ByPrivileges:               []string{models.GrantAdminRole, models.GroupOwnerRole},
+		IncludeInheritedFromGroups: true,
+	})
+	if err != nil {
+		return err
+	}
+	if len(adminGrants) > 0 {
+		return HandleAuthErr(ErrNotAuthorized, "group", "update", models.InfraAdminRole)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package access
 
 import (
+	"errors"
+
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -29,6 +31,10 @@ func GetIdentity(rCtx RequestContext, opts data.GetIdentityOptions) (*models.Ide
 	if !isIdentitySelf(rCtx, opts) {
 		roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
 		err := IsAuthorized(rCtx, roles...)
+		if errors.Is(err, ErrNotAuthorized) {
+			// delegated admins need to find users to grant them access
+			err = isDelegatedAdmin(rCtx)
+		}
 		if err != nil {
 			return nil, HandleAuthErr(err, "user", "get", roles...)
 		}
@@ -55,9 +61,31 @@ func DeleteIdentity(rCtx RequestContext, id uid.ID) error {
 	return data.DeleteIdentities(rCtx.DBTxn, data.DeleteIdentitiesOptions{ByID: id})
 }
 
+// UnlockIdentity removes the count of failed password logins for the user,
+// which unlocks the user if they were locked out.
+func UnlockIdentity(rCtx RequestContext, id uid.ID) error {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return HandleAuthErr(err, "user", "unlock", models.InfraAdminRole)
+	}
+
+	identity, err := data.GetIdentity(rCtx.DBTxn, data.GetIdentityOptions{ByID: id})
+	if err != nil {
+		return err
+	}
+
+	key := data.LoginFailureKey{Kind: data.LoginFailureUser, Name: identity.Name}
+	return data.DeleteLoginFailures(rCtx.DBTxn, key)
+}
+
 func ListIdentities(rCtx RequestContext, opts data.ListIdentityOptions) ([]models.Identity, error) {
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	if err := IsAuthorized(rCtx, roles...); err != nil {
+	err := IsAuthorized(rCtx, roles...)
+	if errors.Is(err, ErrNotAuthorized) {
+		// delegated admins need to find users to grant them access
+		err = isDelegatedAdmin(rCtx)
+	}
+	if err != nil {
 		return nil, HandleAuthErr(err, "users", "list", roles...)
 	}
 	return data.ListIdentities(rCtx.DBTxn, opts)
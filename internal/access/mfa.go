@@ -0,0 +1,163 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/totp"
+	"github.com/infrahq/infra/internal/validate"
+	"github.com/infrahq/infra/uid"
+)
+
+const (
+	recoveryCodeCount   = 10
+	recoveryCodeCharset = "0123456789bcdfghjklmnpqrstvwxyz"
+)
+
+// CreateMFAEnrollment starts TOTP enrollment for the authenticated user, and
+// returns the new secret. The secret is not required at login until the user
+// has confirmed it with VerifyMFAEnrollment.
+func CreateMFAEnrollment(rCtx RequestContext, userID uid.ID) (string, error) {
+	credential, err := getSelfCredentialForMFA(rCtx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if credential.MFAEnabled {
+		return "", fmt.Errorf("%w: multi-factor authentication is already enabled, remove it before enrolling again", internal.ErrBadRequest)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	credential.MFASecret = models.EncryptedAtRest(secret)
+	credential.MFALastStep = 0
+	credential.MFARecoveryCodes = nil
+
+	if err := data.UpdateCredential(rCtx.DBTxn, credential); err != nil {
+		return "", fmt.Errorf("update credential: %w", err)
+	}
+	return secret, nil
+}
+
+// VerifyMFAEnrollment completes TOTP enrollment for the authenticated user
+// by checking a code generated from the secret returned by CreateMFAEnrollment.
+// It returns the recovery codes for the user. Only the checksums of the
+// recovery codes are stored, so they can not be retrieved again.
+func VerifyMFAEnrollment(rCtx RequestContext, userID uid.ID, code string) ([]string, error) {
+	credential, err := getSelfCredentialForMFA(rCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case credential.MFAEnabled:
+		return nil, fmt.Errorf("%w: multi-factor authentication is already enabled", internal.ErrBadRequest)
+	case credential.MFASecret == "":
+		return nil, fmt.Errorf("%w: multi-factor authentication enrollment has not started", internal.ErrBadRequest)
+	}
+
+	step, ok := totp.Validate(string(credential.MFASecret), code, time.Now())
+	if !ok {
+		return nil, validate.Error{"code": []string{"invalid code"}}
+	}
+
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	checksums := make(models.CommaSeparatedStrings, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generate.CryptoRandom(10, recoveryCodeCharset)
+		if err != nil {
+			return nil, err
+		}
+		code = code[:5] + "-" + code[5:]
+		recoveryCodes = append(recoveryCodes, code)
+		checksums = append(checksums, models.RecoveryCodeChecksum(code))
+	}
+
+	credential.MFAEnabled = true
+	credential.MFALastStep = step
+	credential.MFARecoveryCodes = checksums
+
+	tx := rCtx.DBTxn
+	if err := data.UpdateCredential(tx, credential); err != nil {
+		return nil, fmt.Errorf("update credential: %w", err)
+	}
+
+	// if this session was limited to enrollment, it can now be used normally.
+	if accessKey := rCtx.Authenticated.AccessKey; accessKey != nil {
+		for i, v := range accessKey.Scopes {
+			if v == models.ScopeMFAEnrollment {
+				accessKey.Scopes = append(accessKey.Scopes[:i], accessKey.Scopes[i+1:]...)
+				if err := data.UpdateAccessKey(tx, accessKey); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	return recoveryCodes, nil
+}
+
+// DeleteMFA removes multi-factor authentication from a user. Users may remove
+// their own with a valid code from their authenticator app or a recovery code,
+// so that a stolen session can not be used to remove it. Admins may remove it
+// for any other user without a code, for example when a user has lost both
+// their authenticator and their recovery codes.
+func DeleteMFA(rCtx RequestContext, userID uid.ID, code string) error {
+	user := rCtx.Authenticated.User
+	isSelf := user != nil && user.ID == userID
+	if !isSelf {
+		if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+			return HandleAuthErr(err, "user", "update", models.InfraAdminRole)
+		}
+	}
+
+	credential, err := data.GetCredentialByUserID(rCtx.DBTxn, userID)
+	if err != nil {
+		return fmt.Errorf("get credential: %w", err)
+	}
+
+	// a code is not required to cancel an enrollment that was never verified
+	if isSelf && credential.MFAEnabled {
+		if code == "" {
+			return validate.Error{"code": []string{"is required"}}
+		}
+		if err := credential.UseMFACode(code, time.Now()); err != nil {
+			return validate.Error{"code": []string{"invalid code"}}
+		}
+	}
+
+	credential.MFASecret = ""
+	credential.MFAEnabled = false
+	credential.MFALastStep = 0
+	credential.MFARecoveryCodes = nil
+
+	if err := data.UpdateCredential(rCtx.DBTxn, credential); err != nil {
+		return fmt.Errorf("update credential: %w", err)
+	}
+	return nil
+}
+
+func getSelfCredentialForMFA(rCtx RequestContext, userID uid.ID) (*models.Credential, error) {
+	user := rCtx.Authenticated.User
+	if user == nil || user.ID != userID {
+		return nil, fmt.Errorf("%w: users may only enroll themselves in multi-factor authentication", ErrNotAuthorized)
+	}
+
+	credential, err := data.GetCredentialByUserID(rCtx.DBTxn, user.ID)
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		return nil, fmt.Errorf("%w: multi-factor authentication is only available for users who login with an Infra password", internal.ErrBadRequest)
+	case err != nil:
+		return nil, fmt.Errorf("get credential: %w", err)
+	}
+	return credential, nil
+}
@@ -44,14 +44,10 @@ func VerifiedPasswordReset(rCtx RequestContext, token, password string) (*models
 		return nil, fmt.Errorf("get credential: %w", err)
 	}
 
-	hash, err := GenerateFromPassword(password)
-	if err != nil {
+	if err := setPassword(tx, credential, password); err != nil {
 		return nil, err
 	}
 
-	credential.OneTimePassword = false
-	credential.PasswordHash = hash
-
 	if err := data.UpdateCredential(tx, credential); err != nil {
 		return nil, err
 	}
@@ -41,3 +41,66 @@ func DeleteProvider(rCtx RequestContext, id uid.ID) error {
 
 	return data.DeleteProviders(rCtx.DBTxn, data.DeleteProvidersOptions{ByID: id})
 }
+
+// GetProviderSync returns the last directory sync of the provider.
+func GetProviderSync(rCtx RequestContext, providerID uid.ID) (*models.ProviderSync, error) {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return nil, HandleAuthErr(err, "provider sync", "get", models.InfraAdminRole)
+	}
+	if _, err := getProviderToSync(rCtx, providerID); err != nil {
+		return nil, err
+	}
+	return data.GetProviderSync(rCtx.DBTxn, providerID)
+}
+
+// GetProviderToSync returns the provider to sync when a directory sync is
+// requested with the API.
+func GetProviderToSync(rCtx RequestContext, providerID uid.ID) (*models.Provider, error) {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return nil, HandleAuthErr(err, "provider", "sync", models.InfraAdminRole)
+	}
+	return getProviderToSync(rCtx, providerID)
+}
+
+func getProviderToSync(rCtx RequestContext, providerID uid.ID) (*models.Provider, error) {
+	provider, err := data.GetProvider(rCtx.DBTxn, data.GetProviderOptions{ByID: providerID})
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Kind.SupportsDirectorySync() {
+		return nil, fmt.Errorf("%w: %s providers do not support directory sync", internal.ErrBadRequest, provider.Kind)
+	}
+	return provider, nil
+}
+
+// GetProviderToPreviewGroupMapping returns the provider, and the user of the
+// provider with identity userID, to preview the group mapping of the provider.
+// The user is nil when userID is zero.
+func GetProviderToPreviewGroupMapping(rCtx RequestContext, providerID, userID uid.ID) (*models.Provider, *models.ProviderUser, error) {
+	err := IsAuthorized(rCtx, models.InfraAdminRole)
+	if err != nil {
+		return nil, nil, HandleAuthErr(err, "group mapping", "preview", models.InfraAdminRole)
+	}
+	provider, err := data.GetProvider(rCtx.DBTxn, data.GetProviderOptions{ByID: providerID})
+	if err != nil {
+		return nil, nil, err
+	}
+	if userID == 0 {
+		return provider, nil, nil
+	}
+
+	// the groups of a user can only be read again from providers with an OIDC client
+	if !provider.Kind.SupportsDirectorySync() {
+		return nil, nil, fmt.Errorf("%w: the groups of a user can not be read from %s providers, use groups instead", internal.ErrBadRequest, provider.Kind)
+	}
+	user, err := data.GetProviderUser(rCtx.DBTxn, providerID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.RefreshToken == "" {
+		return nil, nil, fmt.Errorf("%w: the user has not logged in with the provider", internal.ErrBadRequest)
+	}
+	return provider, user, nil
+}
@@ -3,6 +3,7 @@ package access
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
@@ -99,6 +100,212 @@ func DeleteProviderUser(rCtx RequestContext, userID uid.ID) error {
 	return nil
 }
 
+// GetProviderGroup returns a group created by the identity provider of the
+// SCIM access key, and the users in the group.
+func GetProviderGroup(rCtx RequestContext, id uid.ID) (*models.Group, []models.Identity, error) {
+	// restricted to only SCIM access keys
+	if err := checkKeyIdentityProvider(rCtx); err != nil {
+		return nil, nil, err
+	}
+	group, err := getProviderGroup(rCtx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := data.ListIdentities(rCtx.DBTxn, data.ListIdentityOptions{ByGroupID: group.ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list group members: %w", err)
+	}
+	return group, members, nil
+}
+
+// ListProviderGroups returns the groups created by the identity provider of
+// the SCIM access key, and the users in each group, keyed by group ID.
+func ListProviderGroups(rCtx RequestContext, p *data.SCIMParameters) ([]models.Group, map[uid.ID][]models.Identity, error) {
+	// restricted to only SCIM access keys
+	if err := checkKeyIdentityProvider(rCtx); err != nil {
+		return nil, nil, err
+	}
+	opts := data.ListGroupsOptions{
+		ByCreatedByProvider: rCtx.Authenticated.AccessKey.IssuedForID,
+		SCIMParameters:      p,
+	}
+	groups, err := data.ListGroups(rCtx.DBTxn, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list provider groups: %w", err)
+	}
+	members := make(map[uid.ID][]models.Identity, len(groups))
+	for _, group := range groups {
+		identities, err := data.ListIdentities(rCtx.DBTxn, data.ListIdentityOptions{ByGroupID: group.ID})
+		if err != nil {
+			return nil, nil, fmt.Errorf("list group members: %w", err)
+		}
+		members[group.ID] = identities
+	}
+	return groups, members, nil
+}
+
+// CreateProviderGroup creates a group owned by the identity provider of the
+// SCIM access key, with the users in memberIDs.
+func CreateProviderGroup(rCtx RequestContext, group *models.Group, memberIDs []uid.ID) error {
+	// restricted to only SCIM access keys
+	if err := checkKeyIdentityProvider(rCtx); err != nil {
+		return err
+	}
+	if err := checkProviderUsersInList(rCtx, memberIDs); err != nil {
+		return err
+	}
+	if err := mapProviderGroupName(rCtx, group); err != nil {
+		return err
+	}
+	group.CreatedByProvider = rCtx.Authenticated.AccessKey.IssuedForID
+	group.ProvisionedBySCIM = true
+	if err := data.CreateGroup(rCtx.DBTxn, group); err != nil {
+		return fmt.Errorf("create provider group: %w", err)
+	}
+	if len(memberIDs) > 0 {
+		if err := data.AddUsersToGroup(rCtx.DBTxn, group.ID, memberIDs); err != nil {
+			return fmt.Errorf("add users to group: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateProviderGroup updates the name of a group owned by the identity
+// provider of the SCIM access key, and replaces the users in the group with
+// the users in memberIDs.
+func UpdateProviderGroup(rCtx RequestContext, group *models.Group, memberIDs []uid.ID) error {
+	// restricted to only SCIM access keys
+	if err := checkKeyIdentityProvider(rCtx); err != nil {
+		return err
+	}
+	current, err := getProviderGroup(rCtx, group.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkProviderUsersInList(rCtx, memberIDs); err != nil {
+		return err
+	}
+	// the current name was already mapped when the group was created
+	if group.Name != current.Name {
+		if err := mapProviderGroupName(rCtx, group); err != nil {
+			return err
+		}
+	}
+
+	group.CreatedAt = current.CreatedAt
+	group.CreatedBy = current.CreatedBy
+	group.CreatedByProvider = current.CreatedByProvider
+	group.ProvisionedBySCIM = current.ProvisionedBySCIM
+	if err := data.UpdateGroup(rCtx.DBTxn, group); err != nil {
+		return fmt.Errorf("update provider group: %w", err)
+	}
+
+	members, err := data.ListIdentities(rCtx.DBTxn, data.ListIdentityOptions{ByGroupID: group.ID})
+	if err != nil {
+		return fmt.Errorf("list group members: %w", err)
+	}
+	keep := make(map[uid.ID]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		keep[id] = true
+	}
+	var toRemove []uid.ID
+	for _, member := range members {
+		if !keep[member.ID] {
+			toRemove = append(toRemove, member.ID)
+		}
+	}
+	if len(toRemove) > 0 {
+		if err := data.RemoveUsersFromGroup(rCtx.DBTxn, group.ID, toRemove); err != nil {
+			return fmt.Errorf("remove users from group: %w", err)
+		}
+	}
+	if len(memberIDs) > 0 {
+		if err := data.AddUsersToGroup(rCtx.DBTxn, group.ID, memberIDs); err != nil {
+			return fmt.Errorf("add users to group: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteProviderGroup deletes a group owned by the identity provider of the
+// SCIM access key.
+func DeleteProviderGroup(rCtx RequestContext, id uid.ID) error {
+	// restricted to only SCIM access keys
+	if err := checkKeyIdentityProvider(rCtx); err != nil {
+		return err
+	}
+	if _, err := getProviderGroup(rCtx, id); err != nil {
+		return err
+	}
+	if err := data.DeleteGroup(rCtx.DBTxn, id); err != nil {
+		return fmt.Errorf("delete provider group: %w", err)
+	}
+	return nil
+}
+
+// getProviderGroup returns the group with ID id. Groups that were not created
+// by the identity provider of the SCIM access key are not found.
+func getProviderGroup(rCtx RequestContext, id uid.ID) (*models.Group, error) {
+	group, err := data.GetGroup(rCtx.DBTxn, data.GetGroupOptions{ByID: id})
+	if err != nil {
+		return nil, fmt.Errorf("get provider group: %w", err)
+	}
+	if group.CreatedByProvider != rCtx.Authenticated.AccessKey.IssuedForID {
+		return nil, fmt.Errorf("get provider group: %w", internal.ErrNotFound)
+	}
+	return group, nil
+}
+
+// mapProviderGroupName applies the group mapping of the identity provider of
+// the SCIM access key to the name of the group. Groups that are excluded by the
+// mapping can not be created.
+func mapProviderGroupName(rCtx RequestContext, group *models.Group) error {
+	provider, err := data.GetProvider(rCtx.DBTxn,
+		data.GetProviderOptions{ByID: rCtx.Authenticated.AccessKey.IssuedForID})
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	names, err := provider.GroupMapping.Apply(provider.Name, []string{group.Name})
+	if err != nil {
+		return fmt.Errorf("group mapping: %w", err)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: group %q is excluded by the group mapping of the provider", internal.ErrBadRequest, group.Name)
+	}
+	group.Name = names[0]
+	return nil
+}
+
+// checkProviderUsersInList returns an error if any of the ids are not users
+// of the identity provider of the SCIM access key.
+func checkProviderUsersInList(rCtx RequestContext, ids []uid.ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	users, err := data.ListProviderUsers(rCtx.DBTxn, data.ListProviderUsersOptions{
+		ByProviderID:  rCtx.Authenticated.AccessKey.IssuedForID,
+		ByIdentityIDs: ids,
+	})
+	if err != nil {
+		return fmt.Errorf("list provider users: %w", err)
+	}
+
+	found := make(map[uid.ID]bool, len(users))
+	for _, user := range users {
+		found[user.IdentityID] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", internal.ErrBadRequest, "Couldn't find provider users: "+strings.Join(missing, ","))
+	}
+	return nil
+}
+
 func checkKeyIdentityProvider(rCtx RequestContext) error {
 	_, err := data.GetProvider(rCtx.DBTxn,
 		data.GetProviderOptions{ByID: rCtx.Authenticated.AccessKey.IssuedForID})
@@ -0,0 +1,64 @@
+package access
+
+import (
+	"fmt"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// ListSessions returns the access keys issued to the user by a login. Users
+// can list their own sessions.
+func ListSessions(rCtx RequestContext, userID uid.ID, p *data.Pagination) ([]models.AccessKey, error) {
+	if userID != rCtx.Authenticated.User.ID {
+		roles := []string{models.InfraAdminRole, models.InfraViewRole}
+		if err := IsAuthorized(rCtx, roles...); err != nil {
+			return nil, HandleAuthErr(err, "sessions", "list", roles...)
+		}
+	}
+
+	opts := data.ListAccessKeyOptions{
+		ByIssuedForID: userID,
+		OnlySessions:  true,
+		Pagination:    p,
+	}
+	return data.ListAccessKeys(rCtx.DBTxn, opts)
+}
+
+// DeleteSession revokes one session of the user. Users can revoke their own
+// sessions.
+func DeleteSession(rCtx RequestContext, userID, sessionID uid.ID) error {
+	if userID != rCtx.Authenticated.User.ID {
+		if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+			return HandleAuthErr(err, "sessions", "delete", models.InfraAdminRole)
+		}
+	}
+
+	key, err := data.GetAccessKey(rCtx.DBTxn, data.GetAccessKeysOptions{ByID: sessionID})
+	if err != nil {
+		return err
+	}
+	if key.IssuedForID != userID || key.LoginMethod == "" {
+		return fmt.Errorf("%w: session not found", internal.ErrNotFound)
+	}
+	return data.DeleteAccessKeys(rCtx.DBTxn, data.DeleteAccessKeysOptions{ByID: key.ID})
+}
+
+// DeleteSessions revokes all the sessions of the user, except for the session
+// with exceptID when it is set. Users can revoke their own sessions.
+func DeleteSessions(rCtx RequestContext, userID, exceptID uid.ID) error {
+	if userID != rCtx.Authenticated.User.ID {
+		if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+			return HandleAuthErr(err, "sessions", "delete", models.InfraAdminRole)
+		}
+	}
+
+	opts := data.DeleteAccessKeysOptions{
+		ByIssuedForID: userID,
+		OnlySessions:  true,
+		ExcludeID:     exceptID,
+	}
+	return data.DeleteAccessKeys(rCtx.DBTxn, opts)
+}
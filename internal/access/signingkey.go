@@ -0,0 +1,25 @@
+package access
+
+import (
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func ListSigningKeys(rCtx RequestContext, p *data.Pagination) ([]models.SigningKey, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	if err := IsAuthorized(rCtx, roles...); err != nil {
+		return nil, HandleAuthErr(err, "signing keys", "list", roles...)
+	}
+
+	return data.ListSigningKeys(rCtx.DBTxn, data.ListSigningKeysOptions{Pagination: p})
+}
+
+// RotateSigningKey replaces the active signing key of the organization with a
+// new key.
+func RotateSigningKey(rCtx RequestContext) (*models.SigningKey, error) {
+	if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+		return nil, HandleAuthErr(err, "signing key", "rotate", models.InfraAdminRole)
+	}
+
+	return data.RotateSigningKey(rCtx.DBTxn)
+}
@@ -0,0 +1,55 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateWorkloadIdentity creates a workload identity. Only an admin can create
+// a workload identity, because it allows the workload to login as any user.
+func CreateWorkloadIdentity(rCtx RequestContext, w *models.WorkloadIdentity) error {
+	if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+		return HandleAuthErr(err, "workload identity", "create", models.InfraAdminRole)
+	}
+
+	_, err := data.GetIdentity(rCtx.DBTxn, data.GetIdentityOptions{ByID: w.IdentityID})
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		return fmt.Errorf("%w: user %v does not exist", internal.ErrBadRequest, w.IdentityID)
+	case err != nil:
+		return err
+	}
+
+	return data.CreateWorkloadIdentity(rCtx.DBTxn, w)
+}
+
+func GetWorkloadIdentity(rCtx RequestContext, id uid.ID) (*models.WorkloadIdentity, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	if err := IsAuthorized(rCtx, roles...); err != nil {
+		return nil, HandleAuthErr(err, "workload identity", "get", roles...)
+	}
+
+	return data.GetWorkloadIdentity(rCtx.DBTxn, id)
+}
+
+func ListWorkloadIdentities(rCtx RequestContext, opts data.ListWorkloadIdentitiesOptions) ([]models.WorkloadIdentity, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	if err := IsAuthorized(rCtx, roles...); err != nil {
+		return nil, HandleAuthErr(err, "workload identities", "list", roles...)
+	}
+
+	return data.ListWorkloadIdentities(rCtx.DBTxn, opts)
+}
+
+func DeleteWorkloadIdentity(rCtx RequestContext, id uid.ID) error {
+	if err := IsAuthorized(rCtx, models.InfraAdminRole); err != nil {
+		return HandleAuthErr(err, "workload identity", "delete", models.InfraAdminRole)
+	}
+
+	return data.DeleteWorkloadIdentity(rCtx.DBTxn, id)
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/format"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func newAccessCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "access",
+		Short:   "Request and review access to resources",
+		GroupID: groupManagement,
+	}
+
+	cmd.AddCommand(newAccessRequestCmd(cli))
+	cmd.AddCommand(newAccessListCmd(cli))
+	cmd.AddCommand(newAccessApproveCmd(cli))
+	cmd.AddCommand(newAccessDenyCmd(cli))
+	cmd.AddCommand(newAccessCheckCmd(cli))
+
+	return cmd
+}
+
+type accessRequestOptions struct {
+	Role          string
+	Justification string
+	Duration      time.Duration
+}
+
+func newAccessRequestCmd(cli *CLI) *cobra.Command {
+	var options accessRequestOptions
+
+	cmd := &cobra.Command{
+		Use:   "request DESTINATION",
+		Short: "Request access to a destination",
+		Example: `# Request access to a destination
+$ infra access request staging --reason "deploy hotfix"
+
+# Request a specific role for a limited time
+$ infra access request production.web --role view --duration 4h --reason "investigate incident 1234"
+`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			req := &api.CreateAccessRequestRequest{
+				Privilege:     options.Role,
+				Resource:      args[0],
+				Justification: options.Justification,
+				Duration:      api.Duration(options.Duration),
+			}
+			logging.Debugf("call server: create access request %#v", req)
+			resp, err := client.CreateAccessRequest(context.Background(), req)
+			if err != nil {
+				return err
+			}
+
+			cli.Output("Requested %q access to %q (request ID %s)", resp.Privilege, resp.Resource, resp.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Role, "role", models.BasePermissionConnect, "Type of access being requested")
+	cmd.Flags().StringVar(&options.Justification, "reason", "", "Why the access is needed")
+	cmd.Flags().DurationVar(&options.Duration, "duration", 0, "How long the access is needed for once approved")
+	_ = cmd.MarkFlagRequired("reason")
+	return cmd
+}
+
+type accessListOptions struct {
+	AllUsers bool
+	UserName string
+	Status   string
+}
+
+func newAccessListCmd(cli *CLI) *cobra.Command {
+	var options accessListOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List access requests",
+		Example: `# List your access requests
+$ infra access list
+
+# List pending access requests for all users
+$ infra access list --all --status pending
+`,
+		Args: NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			config, err := currentHostConfig()
+			if err != nil {
+				return err
+			}
+
+			userID := config.UserID
+			if options.UserName != "" {
+				user, err := getUserByNameOrID(client, options.UserName)
+				if err != nil {
+					return err
+				}
+				userID = user.ID
+			}
+			if options.AllUsers {
+				userID = 0
+			}
+
+			logging.Debugf("call server: list access requests")
+			requests, err := listAll(ctx, client.ListAccessRequests, api.ListAccessRequestsRequest{
+				UserID: userID,
+				Status: options.Status,
+			})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.Debugf("%s", err.Error())
+					return Error{
+						Message: "Cannot list access requests: missing privileges for ListAccessRequests",
+					}
+				}
+				return err
+			}
+
+			type row struct {
+				ID            string `header:"ID"`
+				User          string `header:"USER"`
+				Role          string `header:"ROLE"`
+				Resource      string `header:"RESOURCE"`
+				Status        string `header:"STATUS"`
+				Justification string `header:"REASON"`
+				Created       string `header:"CREATED"`
+			}
+
+			userNames := map[uid.ID]string{config.UserID: config.Name}
+			var rows []row
+			for _, req := range requests {
+				name, ok := userNames[req.UserID]
+				if !ok {
+					name = req.UserID.String()
+					if user, err := client.GetUser(ctx, req.UserID); err == nil {
+						name = user.Name
+					}
+					userNames[req.UserID] = name
+				}
+
+				rows = append(rows, row{
+					ID:            req.ID.String(),
+					User:          name,
+					Role:          req.Privilege,
+					Resource:      req.Resource,
+					Status:        req.Status,
+					Justification: req.Justification,
+					Created:       format.HumanTime(req.Created.Time(), "never"),
+				})
+			}
+
+			if len(rows) > 0 {
+				printTable(rows, cli.Stdout)
+			} else {
+				cli.Output("No access requests found")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.AllUsers, "all", false, "Show access requests for all users")
+	cmd.Flags().StringVar(&options.UserName, "user", "", "The name of a user to list access requests for")
+	cmd.Flags().StringVar(&options.Status, "status", "", "Filter by status [pending, approved, denied]")
+	return cmd
+}
+
+func newAccessApproveCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve ID",
+		Short: "Approve an access request",
+		Example: `# Approve an access request
+$ infra access approve 4yJ3n3D8E2
+`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewAccessRequest(cli, args[0], true)
+		},
+	}
+}
+
+func newAccessDenyCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "deny ID",
+		Short: "Deny an access request",
+		Example: `# Deny an access request
+$ infra access deny 4yJ3n3D8E2
+`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewAccessRequest(cli, args[0], false)
+		},
+	}
+}
+
+func reviewAccessRequest(cli *CLI, rawID string, approve bool) error {
+	id, err := uid.Parse([]byte(rawID))
+	if err != nil {
+		return Error{Message: fmt.Sprintf("Invalid access request ID %q", rawID)}
+	}
+
+	client, err := cli.apiClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	review, verb := client.DenyAccessRequest, "deny"
+	if approve {
+		review, verb = client.ApproveAccessRequest, "approve"
+	}
+
+	logging.Debugf("call server: %v access request %s", verb, id)
+	req, err := review(ctx, id)
+	if err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.Debugf("%s", err.Error())
+			return Error{
+				Message: fmt.Sprintf("Cannot %v access request: missing privileges", verb),
+			}
+		}
+		return err
+	}
+
+	cli.Output("Access request %s %v: %q access to %q", req.ID, req.Status, req.Privilege, req.Resource)
+	return nil
+}
+
+func newAccessCheckCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check USER DESTINATION",
+		Short: "Show the access a user has to a destination",
+		Long: `Show the access a user has to a destination.
+
+Access is granted either directly to the user, or to a group the user belongs to.
+The command exits with an error if the user has no access to the destination.`,
+		Example: `# Check the access a user has to a cluster
+$ infra access check janedoe@example.com production
+
+# Check the access a user has to a namespace
+$ infra access check janedoe@example.com production.web
+`,
+		Args: ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			user, err := getUserByNameOrID(client, args[0])
+			if err != nil {
+				return err
+			}
+
+			resource := args[1]
+			destination, _, _ := strings.Cut(resource, ".")
+
+			logging.Debugf("call server: list access for user %s", user.ID)
+			access, err := client.ListUserAccess(ctx, api.ListUserAccessRequest{
+				ID:          user.ID,
+				Destination: destination,
+			})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.Debugf("%s", err.Error())
+					return Error{
+						Message: "Cannot check access: missing privileges for ListUserAccess",
+					}
+				}
+				return err
+			}
+
+			type row struct {
+				Resource string `header:"RESOURCE"`
+				Role     string `header:"ROLE"`
+				Via      string `header:"VIA"`
+				Expires  string `header:"EXPIRES"`
+			}
+
+			var rows []row
+			for _, item := range access.Items {
+				if !resourceOverlaps(item.Resource, resource) {
+					continue
+				}
+
+				via := "direct"
+				if item.GroupID != 0 {
+					via = "group " + item.GroupName
+				}
+				rows = append(rows, row{
+					Resource: item.Resource,
+					Role:     item.Privilege,
+					Via:      via,
+					Expires:  format.HumanTime(item.Expires.Time(), "never"),
+				})
+			}
+
+			if len(rows) == 0 {
+				return Error{Message: fmt.Sprintf("%v has no access to %v", user.Name, resource)}
+			}
+			printTable(rows, cli.Stdout)
+			return nil
+		},
+	}
+}
+
+// resourceOverlaps returns true if a grant to resource applies to target, to a
+// resource within target (ex: a namespace within a cluster), or to all of the
+// destination that contains target. The destination name in resource may be
+// a pattern.
+func resourceOverlaps(resource, target string) bool {
+	destination, targetNamespace, _ := strings.Cut(target, ".")
+	if !api.GrantMatchesDestination(resource, destination) {
+		return false
+	}
+	_, namespace, _ := strings.Cut(resource, ".")
+	return namespace == "" || targetNamespace == "" || namespace == targetNamespace
+}
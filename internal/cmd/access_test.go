@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAccessRequestCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	setup := func(t *testing.T) chan api.CreateAccessRequestRequest {
+		requestCh := make(chan api.CreateAccessRequestRequest, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			if !requestMatches(req, http.MethodPost, "/api/access-requests") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			defer close(requestCh)
+			var createRequest api.CreateAccessRequestRequest
+			err := json.NewDecoder(req.Body).Decode(&createRequest)
+			assert.Check(t, err)
+
+			resp.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(resp).Encode(&api.AccessRequest{
+				ID:            uid.ID(4567),
+				Privilege:     createRequest.Privilege,
+				Resource:      createRequest.Resource,
+				Justification: createRequest.Justification,
+				Status:        api.AccessRequestStatusPending,
+			})
+			assert.Check(t, err)
+			requestCh <- createRequest
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("all flags", func(t *testing.T) {
+		ch := setup(t)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "access", "request", "production.web", "--role=view", "--duration=4h", "--reason=incident 1234")
+		assert.NilError(t, err)
+
+		req := <-ch
+		expected := api.CreateAccessRequestRequest{
+			Privilege:     "view",
+			Resource:      "production.web",
+			Justification: "incident 1234",
+			Duration:      api.Duration(4 * time.Hour),
+		}
+		assert.DeepEqual(t, expected, req)
+		assert.Equal(t, bufs.Stdout.String(), "Requested \"view\" access to \"production.web\" (request ID "+uid.ID(4567).String()+")\n")
+	})
+
+	t.Run("default role", func(t *testing.T) {
+		ch := setup(t)
+
+		err := Run(context.Background(), "access", "request", "staging", "--reason=deploy")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Privilege, "connect")
+		assert.Equal(t, req.Duration, api.Duration(0))
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		err := Run(context.Background(), "access", "request", "staging")
+		assert.ErrorContains(t, err, `required flag(s) "reason" not set`)
+	})
+}
+
+func TestAccessReviewCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	reqID := uid.ID(4567)
+
+	setup := func(t *testing.T) *[]string {
+		var paths []string
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			paths = append(paths, req.URL.Path)
+
+			status := api.AccessRequestStatusDenied
+			if req.URL.Path == "/api/access-requests/"+reqID.String()+"/approve" {
+				status = api.AccessRequestStatusApproved
+			}
+
+			resp.WriteHeader(http.StatusOK)
+			err := json.NewEncoder(resp).Encode(&api.AccessRequest{
+				ID:        reqID,
+				Privilege: "view",
+				Resource:  "production",
+				Status:    status,
+			})
+			assert.Check(t, err)
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return &paths
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		paths := setup(t)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "access", "approve", reqID.String())
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, *paths, []string{"/api/access-requests/" + reqID.String() + "/approve"})
+		assert.Equal(t, bufs.Stdout.String(), "Access request "+reqID.String()+" approved: \"view\" access to \"production\"\n")
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		paths := setup(t)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "access", "deny", reqID.String())
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, *paths, []string{"/api/access-requests/" + reqID.String() + "/deny"})
+		assert.Equal(t, bufs.Stdout.String(), "Access request "+reqID.String()+" denied: \"view\" access to \"production\"\n")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		err := Run(context.Background(), "access", "approve", "not-an-id!")
+		assert.ErrorContains(t, err, `Invalid access request ID "not-an-id!"`)
+	})
+}
+
+func TestAccessCheckCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	userID := uid.ID(12345678)
+
+	setup := func(t *testing.T) {
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			query := req.URL.Query()
+
+			// the command does a lookup for user ID
+			if requestMatches(req, http.MethodGet, "/api/users") {
+				if query.Get("name") != "my-user" {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{{ID: userID, Name: "my-user"}},
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			if !requestMatches(req, http.MethodGet, "/api/users/"+userID.String()+"/access") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if query.Get("destination") != "production" {
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.EffectiveAccess]{})
+				assert.Check(t, err)
+				return
+			}
+
+			resp.WriteHeader(http.StatusOK)
+			err := json.NewEncoder(resp).Encode(api.ListResponse[api.EffectiveAccess]{
+				Count: 3,
+				Items: []api.EffectiveAccess{
+					{UserID: userID, UserName: "my-user", Privilege: "view", Resource: "production"},
+					{UserID: userID, UserName: "my-user", Privilege: "admin", Resource: "production.web", GroupID: uid.ID(4444), GroupName: "developers"},
+					{UserID: userID, UserName: "my-user", Privilege: "edit", Resource: "production.db"},
+				},
+			})
+			assert.Check(t, err)
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+	}
+
+	t.Run("destination", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "check", "my-user", "production")
+		assert.NilError(t, err)
+
+		expected := `  RESOURCE        ROLE   VIA               EXPIRES  
+  production      view   direct            never    
+  production.web  admin  group developers  never    
+  production.db   edit   direct            never    
+`
+		assert.Equal(t, bufs.Stdout.String(), expected)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "check", "my-user", "production.web")
+		assert.NilError(t, err)
+
+		expected := `  RESOURCE        ROLE   VIA               EXPIRES  
+  production      view   direct            never    
+  production.web  admin  group developers  never    
+`
+		assert.Equal(t, bufs.Stdout.String(), expected)
+	})
+
+	t.Run("no access", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "access", "check", "my-user", "staging")
+		assert.ErrorContains(t, err, "my-user has no access to staging")
+	})
+}
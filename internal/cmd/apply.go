@@ -0,0 +1,551 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
+)
+
+// accessFile is the declarative description of groups, group membership and
+// grants read by infra plan and infra apply.
+type accessFile struct {
+	Groups []accessFileGroup `json:"groups"`
+	Grants []accessFileGrant `json:"grants"`
+}
+
+type accessFileGroup struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+}
+
+type accessFileGrant struct {
+	User     string `json:"user"`
+	Group    string `json:"group"`
+	Role     string `json:"role"`
+	Resource string `json:"resource"`
+}
+
+func readAccessFile(filename string) (*accessFile, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file accessFile
+	if err := yaml.UnmarshalStrict(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse %v: %w", filename, err)
+	}
+
+	seen := make(map[string]bool, len(file.Groups))
+	for i, group := range file.Groups {
+		switch {
+		case group.Name == "":
+			return nil, fmt.Errorf("%v: groups[%d]: name is required", filename, i)
+		case seen[group.Name]:
+			return nil, fmt.Errorf("%v: group %q is defined more than once", filename, group.Name)
+		}
+		seen[group.Name] = true
+	}
+	for i, grant := range file.Grants {
+		switch {
+		case grant.User == "" && grant.Group == "":
+			return nil, fmt.Errorf("%v: grants[%d]: one of user or group is required", filename, i)
+		case grant.User != "" && grant.Group != "":
+			return nil, fmt.Errorf("%v: grants[%d]: only one of user or group may be set", filename, i)
+		case grant.Resource == "":
+			return nil, fmt.Errorf("%v: grants[%d]: resource is required", filename, i)
+		}
+		if grant.Role == "" {
+			file.Grants[i].Role = "connect"
+		}
+	}
+	return &file, nil
+}
+
+type applyOptions struct {
+	Filename string
+	Prune    bool
+}
+
+func newPlanCmd(cli *CLI) *cobra.Command {
+	var options applyOptions
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the changes required to match a file of groups and grants",
+		Long: `Show the changes required to match a file of groups and grants.
+
+The file lists groups with their members, and grants to users or groups:
+
+  groups:
+    - name: developers
+      users:
+        - janedoe@example.com
+  grants:
+    - group: developers
+      role: view
+      resource: production
+    - user: janedoe@example.com
+      role: admin
+      resource: staging
+
+The members of each group in the file are replaced with the users in the file.
+Groups that are not in the file are left unchanged. Grants that are not in the
+file are removed only when --prune is set.`,
+		Example: `# Show the changes that infra apply would make
+$ infra plan -f access.yaml
+
+# Include grants that are not in the file
+$ infra plan -f access.yaml --prune
+`,
+		Args:    NoArgs,
+		GroupID: groupManagement,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			file, err := readAccessFile(options.Filename)
+			if err != nil {
+				return err
+			}
+
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			plan, err := planAccessChanges(context.Background(), client, file, options.Prune)
+			if err != nil {
+				return err
+			}
+
+			plan.print(cli.Stdout)
+			return nil
+		},
+	}
+
+	addApplyFlags(cmd, &options)
+	return cmd
+}
+
+func newApplyCmd(cli *CLI) *cobra.Command {
+	var options applyOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Update groups and grants to match a file",
+		Long: `Update groups and grants to match a file.
+
+See 'infra plan --help' for the format of the file. Use infra plan to review the
+changes before applying them.
+
+Groups and their members are updated before grants. If a change fails, the
+changes that were already applied are listed, and infra apply can be run again
+to apply the rest. --prune fails if it would remove every grant that gives you
+the admin role on infra.`,
+		Example: `# Update groups and grants to match a file
+$ infra apply -f access.yaml
+
+# Also remove any grant that is not in the file
+$ infra apply -f access.yaml --prune
+`,
+		Args:    NoArgs,
+		GroupID: groupManagement,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			file, err := readAccessFile(options.Filename)
+			if err != nil {
+				return err
+			}
+
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			plan, err := planAccessChanges(ctx, client, file, options.Prune)
+			if err != nil {
+				return err
+			}
+
+			plan.print(cli.Stdout)
+			if plan.empty() {
+				return nil
+			}
+
+			if err := plan.apply(ctx, client); err != nil {
+				return err
+			}
+
+			cli.Output("Applied %d changes", plan.count())
+			return nil
+		},
+	}
+
+	addApplyFlags(cmd, &options)
+	return cmd
+}
+
+func addApplyFlags(cmd *cobra.Command, options *applyOptions) {
+	cmd.Flags().StringVarP(&options.Filename, "file", "f", "", "Path to the file of groups and grants")
+	cmd.Flags().BoolVar(&options.Prune, "prune", false, "Remove any grant that is not in the file")
+	_ = cmd.MarkFlagRequired("file")
+}
+
+// accessPlan is the set of changes required to make the groups and grants in
+// Infra match an accessFile.
+type accessPlan struct {
+	groupsToCreate []string
+	membership     []groupMembershipChange
+	grantsToAdd    []api.GrantRequest
+	grantsToRemove []api.GrantRequest
+}
+
+type groupMembershipChange struct {
+	group         string
+	groupID       uid.ID
+	usersToAdd    []api.User
+	usersToRemove []api.User
+}
+
+func (p accessPlan) count() int {
+	count := len(p.groupsToCreate) + len(p.grantsToAdd) + len(p.grantsToRemove)
+	for _, change := range p.membership {
+		count += len(change.usersToAdd) + len(change.usersToRemove)
+	}
+	return count
+}
+
+func (p accessPlan) empty() bool {
+	return p.count() == 0
+}
+
+func (p accessPlan) print(out io.Writer) {
+	if p.empty() {
+		fmt.Fprintln(out, "No changes. Groups and grants match the file.")
+		return
+	}
+
+	for _, name := range p.groupsToCreate {
+		fmt.Fprintf(out, "+ group %v\n", name)
+	}
+	for _, change := range p.membership {
+		for _, user := range change.usersToAdd {
+			fmt.Fprintf(out, "+ user %v in group %v\n", user.Name, change.group)
+		}
+		for _, user := range change.usersToRemove {
+			fmt.Fprintf(out, "- user %v in group %v\n", user.Name, change.group)
+		}
+	}
+	for _, grant := range p.grantsToAdd {
+		fmt.Fprintf(out, "+ grant %v\n", describeGrantRequest(grant))
+	}
+	for _, grant := range p.grantsToRemove {
+		fmt.Fprintf(out, "- grant %v\n", describeGrantRequest(grant))
+	}
+	fmt.Fprintf(out, "\n%d to add, %d to remove\n", p.countAdded(), p.count()-p.countAdded())
+}
+
+func (p accessPlan) countAdded() int {
+	count := len(p.groupsToCreate) + len(p.grantsToAdd)
+	for _, change := range p.membership {
+		count += len(change.usersToAdd)
+	}
+	return count
+}
+
+func describeGrantRequest(grant api.GrantRequest) string {
+	subject := "user " + grant.UserName
+	if grant.GroupName != "" {
+		subject = "group " + grant.GroupName
+	}
+	return fmt.Sprintf("%v on %v to %v", grant.Privilege, grant.Resource, subject)
+}
+
+// apply makes the changes in the plan. Groups are created and their members
+// updated first, so that grants to new groups can be created. All grant
+// changes are made in a single request.
+//
+// Each group and each change of members is a separate request, so an error
+// may leave some of the changes applied. The returned applyError lists them.
+func (p accessPlan) apply(ctx context.Context, client *api.Client) error {
+	var applied []string
+
+	groupIDs := make(map[string]uid.ID, len(p.groupsToCreate))
+	for _, name := range p.groupsToCreate {
+		logging.Debugf("call server: create group %q", name)
+		group, err := client.CreateGroup(ctx, &api.CreateGroupRequest{Name: name})
+		if err != nil {
+			return applyError{applied: applied, err: fmt.Errorf("create group %v: %w", name, err)}
+		}
+		groupIDs[name] = group.ID
+		applied = append(applied, "+ group "+name)
+	}
+
+	for _, change := range p.membership {
+		req := &api.UpdateUsersInGroupRequest{GroupID: change.groupID}
+		if req.GroupID == 0 {
+			req.GroupID = groupIDs[change.group]
+		}
+		for _, user := range change.usersToAdd {
+			req.UserIDsToAdd = append(req.UserIDsToAdd, user.ID)
+		}
+		for _, user := range change.usersToRemove {
+			req.UserIDsToRemove = append(req.UserIDsToRemove, user.ID)
+		}
+
+		logging.Debugf("call server: update users in group %q", change.group)
+		if err := client.UpdateUsersInGroup(ctx, req); err != nil {
+			return applyError{applied: applied, err: fmt.Errorf("update users in group %v: %w", change.group, err)}
+		}
+		for _, user := range change.usersToAdd {
+			applied = append(applied, fmt.Sprintf("+ user %v in group %v", user.Name, change.group))
+		}
+		for _, user := range change.usersToRemove {
+			applied = append(applied, fmt.Sprintf("- user %v in group %v", user.Name, change.group))
+		}
+	}
+
+	if len(p.grantsToAdd) == 0 && len(p.grantsToRemove) == 0 {
+		return nil
+	}
+
+	logging.Debugf("call server: update grants")
+	err := client.UpdateGrants(ctx, &api.UpdateGrantsRequest{
+		GrantsToAdd:    p.grantsToAdd,
+		GrantsToRemove: p.grantsToRemove,
+	})
+	if err != nil {
+		return applyError{applied: applied, err: fmt.Errorf("update grants: %w", err)}
+	}
+	return nil
+}
+
+// applyError is returned by accessPlan.apply when a request fails. applied is
+// the list of changes that were made before the failed request.
+type applyError struct {
+	applied []string
+	err     error
+}
+
+func (e applyError) Error() string {
+	if len(e.applied) == 0 {
+		return e.err.Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v\n\nThese changes were applied before the error:\n", e.err)
+	for _, change := range e.applied {
+		fmt.Fprintf(&b, "  %v\n", change)
+	}
+	b.WriteString("\nRun infra apply again to apply the remaining changes.")
+	return b.String()
+}
+
+func (e applyError) Unwrap() error {
+	return e.err
+}
+
+// planAccessChanges compares the groups and grants in file to the ones in
+// Infra, and returns the changes required to make them match.
+func planAccessChanges(ctx context.Context, client *api.Client, file *accessFile, prune bool) (*accessPlan, error) {
+	logging.Debugf("call server: list users")
+	users, err := listAll(ctx, client.ListUsers, api.ListUsersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	usersByName := make(map[string]api.User, len(users))
+	userNames := make(map[uid.ID]string, len(users))
+	for _, user := range users {
+		usersByName[user.Name] = user
+		userNames[user.ID] = user.Name
+	}
+
+	logging.Debugf("call server: list groups")
+	groups, err := listAll(ctx, client.ListGroups, api.ListGroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	groupsByName := make(map[string]api.Group, len(groups))
+	groupNames := make(map[uid.ID]string, len(groups))
+	for _, group := range groups {
+		groupsByName[group.Name] = group
+		groupNames[group.ID] = group.Name
+	}
+
+	plan := &accessPlan{}
+	declaredGroups := make(map[string]bool, len(file.Groups))
+	for _, fileGroup := range file.Groups {
+		declaredGroups[fileGroup.Name] = true
+
+		wanted := make(map[uid.ID]api.User, len(fileGroup.Users))
+		for _, name := range fileGroup.Users {
+			user, ok := usersByName[name]
+			if !ok {
+				return nil, Error{Message: fmt.Sprintf("unknown user %q in group %q", name, fileGroup.Name)}
+			}
+			wanted[user.ID] = user
+		}
+
+		change := groupMembershipChange{group: fileGroup.Name}
+		existing := map[uid.ID]api.User{}
+		if group, ok := groupsByName[fileGroup.Name]; ok {
+			change.groupID = group.ID
+			members, err := listAll(ctx, client.ListUsers, api.ListUsersRequest{Group: group.ID})
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				existing[member.ID] = member
+			}
+		} else {
+			plan.groupsToCreate = append(plan.groupsToCreate, fileGroup.Name)
+		}
+
+		for id, user := range wanted {
+			if _, ok := existing[id]; !ok {
+				change.usersToAdd = append(change.usersToAdd, user)
+			}
+		}
+		for id, user := range existing {
+			if _, ok := wanted[id]; !ok {
+				change.usersToRemove = append(change.usersToRemove, user)
+			}
+		}
+		sortUsersByName(change.usersToAdd)
+		sortUsersByName(change.usersToRemove)
+		if len(change.usersToAdd) > 0 || len(change.usersToRemove) > 0 {
+			plan.membership = append(plan.membership, change)
+		}
+	}
+
+	logging.Debugf("call server: list grants")
+	grants, err := listAll(ctx, client.ListGrants, api.ListGrantsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	existingGrants := make(map[api.GrantRequest]bool, len(grants))
+	grantRequests := make(map[uid.ID]api.GrantRequest, len(grants))
+	for _, grant := range grants {
+		req := api.GrantRequest{Privilege: grant.Privilege, Resource: grant.Resource}
+		switch {
+		case grant.User != 0:
+			req.UserName = userNames[grant.User]
+		case grant.Group != 0:
+			req.GroupName = groupNames[grant.Group]
+		}
+		if req.UserName == "" && req.GroupName == "" {
+			continue
+		}
+		existingGrants[req] = true
+		grantRequests[grant.ID] = req
+	}
+
+	wantedGrants := make(map[api.GrantRequest]bool, len(file.Grants))
+	for _, fileGrant := range file.Grants {
+		req := api.GrantRequest{
+			UserName:  fileGrant.User,
+			GroupName: fileGrant.Group,
+			Privilege: fileGrant.Role,
+			Resource:  fileGrant.Resource,
+		}
+		if _, ok := usersByName[req.UserName]; req.UserName != "" && !ok {
+			return nil, Error{Message: fmt.Sprintf("unknown user %q in grant", req.UserName)}
+		}
+		if _, ok := groupsByName[req.GroupName]; req.GroupName != "" && !ok && !declaredGroups[req.GroupName] {
+			return nil, Error{Message: fmt.Sprintf("unknown group %q in grant", req.GroupName)}
+		}
+		if wantedGrants[req] {
+			continue
+		}
+		wantedGrants[req] = true
+		if !existingGrants[req] {
+			plan.grantsToAdd = append(plan.grantsToAdd, req)
+		}
+	}
+
+	if prune {
+		for req := range existingGrants {
+			if !wantedGrants[req] {
+				plan.grantsToRemove = append(plan.grantsToRemove, req)
+			}
+		}
+		sortGrantRequests(plan.grantsToRemove)
+
+		if err := checkPruneKeepsAdmin(ctx, client, plan.grantsToRemove, grantRequests); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// checkPruneKeepsAdmin returns an error if the grants to remove include every
+// grant that gives the current user the admin role on infra. Removing them
+// would leave the user unable to manage Infra, including to undo the change.
+func checkPruneKeepsAdmin(
+	ctx context.Context,
+	client *api.Client,
+	grantsToRemove []api.GrantRequest,
+	grantRequests map[uid.ID]api.GrantRequest,
+) error {
+	if len(grantsToRemove) == 0 {
+		return nil
+	}
+
+	config, err := currentHostConfig()
+	if err != nil {
+		return err
+	}
+
+	logging.Debugf("call server: list access for user %v", config.UserID)
+	access, err := client.ListUserAccess(ctx, api.ListUserAccessRequest{
+		ID:          config.UserID,
+		Destination: "infra",
+	})
+	if err != nil {
+		return err
+	}
+
+	removed := make(map[api.GrantRequest]bool, len(grantsToRemove))
+	for _, req := range grantsToRemove {
+		removed[req] = true
+	}
+
+	var adminGrants, removedAdminGrants []api.GrantRequest
+	for _, item := range access.Items {
+		if item.Privilege != "admin" || item.Resource != "infra" {
+			continue
+		}
+		req := grantRequests[item.GrantID]
+		adminGrants = append(adminGrants, req)
+		if removed[req] {
+			removedAdminGrants = append(removedAdminGrants, req)
+		}
+	}
+
+	if len(adminGrants) > 0 && len(removedAdminGrants) == len(adminGrants) {
+		return Error{Message: fmt.Sprintf(
+			"--prune would remove your admin role on infra (grant %v), add the grant to the file to keep it",
+			describeGrantRequest(removedAdminGrants[0]))}
+	}
+	return nil
+}
+
+func sortUsersByName(users []api.User) {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+}
+
+func sortGrantRequests(grants []api.GrantRequest) {
+	sort.Slice(grants, func(i, j int) bool {
+		return describeGrantRequest(grants[i]) < describeGrantRequest(grants[j])
+	})
+}
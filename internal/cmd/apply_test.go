@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+var accessFileContents = `
+groups:
+  - name: developers
+    users:
+      - alice@example.com
+      - bob@example.com
+  - name: oncall
+    users:
+      - bob@example.com
+grants:
+  - group: developers
+    role: view
+    resource: production
+  - group: oncall
+    role: admin
+    resource: production
+  - user: alice@example.com
+    role: admin
+    resource: staging
+`
+
+func TestPlanApplyCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	filename := filepath.Join(t.TempDir(), "access.yaml")
+	assert.NilError(t, os.WriteFile(filename, []byte(accessFileContents), 0o600))
+
+	var (
+		self       = api.User{ID: uid.ID(100), Name: "self@example.com"}
+		alice      = api.User{ID: uid.ID(101), Name: "alice@example.com"}
+		bob        = api.User{ID: uid.ID(102), Name: "bob@example.com"}
+		carol      = api.User{ID: uid.ID(103), Name: "carol@example.com"}
+		developers = api.Group{ID: uid.ID(201), Name: "developers"}
+		oncallID   = uid.ID(202)
+	)
+
+	type requests struct {
+		CreateGroup   []api.CreateGroupRequest
+		UpdateMembers []api.UpdateUsersInGroupRequest
+		UpdateGrants  []api.UpdateGrantsRequest
+	}
+
+	type fakeServer struct {
+		// grants are returned by the list grants endpoint, in addition to the
+		// grants that every test uses.
+		grants []api.Grant
+		// selfAccess is the access of the current user, returned by the list
+		// user access endpoint.
+		selfAccess []api.EffectiveAccess
+		// failUpdateMembers causes updates to the members of this group to fail.
+		failUpdateMembers uid.ID
+	}
+
+	setupWithServer := func(t *testing.T, fake fakeServer) *requests {
+		reqs := &requests{}
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			query := req.URL.Query()
+			write := func(v any) {
+				resp.WriteHeader(http.StatusOK)
+				assert.Check(t, json.NewEncoder(resp).Encode(v))
+			}
+
+			switch {
+			case requestMatches(req, http.MethodGet, "/api/users/"+self.ID.String()+"/access"):
+				assert.Check(t, query.Get("destination") == "infra")
+				write(api.ListResponse[api.EffectiveAccess]{Count: len(fake.selfAccess), Items: fake.selfAccess})
+			case requestMatches(req, http.MethodGet, "/api/users"):
+				if query.Get("group") == developers.ID.String() {
+					write(api.ListResponse[api.User]{Count: 2, Items: []api.User{alice, carol}})
+					return
+				}
+				write(api.ListResponse[api.User]{Count: 4, Items: []api.User{alice, bob, carol, self}})
+			case requestMatches(req, http.MethodGet, "/api/groups"):
+				write(api.ListResponse[api.Group]{Count: 1, Items: []api.Group{developers}})
+			case requestMatches(req, http.MethodGet, "/api/grants"):
+				grants := []api.Grant{
+					{ID: uid.ID(301), Group: developers.ID, Privilege: "view", Resource: "production"},
+					{ID: uid.ID(302), User: carol.ID, Privilege: "admin", Resource: "production"},
+				}
+				grants = append(grants, fake.grants...)
+				write(api.ListResponse[api.Grant]{Count: len(grants), Items: grants})
+			case requestMatches(req, http.MethodPost, "/api/groups"):
+				var createReq api.CreateGroupRequest
+				assert.Check(t, json.NewDecoder(req.Body).Decode(&createReq))
+				reqs.CreateGroup = append(reqs.CreateGroup, createReq)
+				write(api.Group{ID: oncallID, Name: createReq.Name})
+			case req.Method == http.MethodPatch && req.URL.Path == "/api/groups/"+developers.ID.String()+"/users",
+				req.Method == http.MethodPatch && req.URL.Path == "/api/groups/"+oncallID.String()+"/users":
+				var updateReq api.UpdateUsersInGroupRequest
+				assert.Check(t, json.NewDecoder(req.Body).Decode(&updateReq))
+				groupID := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/api/groups/"), "/users")
+				updateReq.GroupID, _ = uid.Parse([]byte(groupID))
+				if updateReq.GroupID == fake.failUpdateMembers {
+					resp.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				reqs.UpdateMembers = append(reqs.UpdateMembers, updateReq)
+				write(api.EmptyResponse{})
+			case requestMatches(req, http.MethodPatch, "/api/grants"):
+				var updateReq api.UpdateGrantsRequest
+				assert.Check(t, json.NewDecoder(req.Body).Decode(&updateReq))
+				reqs.UpdateGrants = append(reqs.UpdateGrants, updateReq)
+				write(api.EmptyResponse{})
+			default:
+				resp.WriteHeader(http.StatusBadRequest)
+			}
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, self)
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+		return reqs
+	}
+
+	setup := func(t *testing.T) *requests {
+		return setupWithServer(t, fakeServer{})
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		reqs := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "plan", "-f", filename)
+		assert.NilError(t, err)
+
+		expected := `+ group oncall
++ user bob@example.com in group developers
+- user carol@example.com in group developers
++ user bob@example.com in group oncall
++ grant admin on production to group oncall
++ grant admin on staging to user alice@example.com
+
+5 to add, 1 to remove
+`
+		assert.Equal(t, bufs.Stdout.String(), expected)
+		assert.DeepEqual(t, reqs, &requests{})
+	})
+
+	t.Run("plan with prune", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "plan", "-f", filename, "--prune")
+		assert.NilError(t, err)
+
+		assert.Assert(t, containsLine(bufs.Stdout.String(), "- grant admin on production to user carol@example.com"))
+		assert.Assert(t, containsLine(bufs.Stdout.String(), "5 to add, 2 to remove"))
+	})
+
+	t.Run("apply with prune", func(t *testing.T) {
+		reqs := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "apply", "-f", filename, "--prune")
+		assert.NilError(t, err)
+		assert.Assert(t, containsLine(bufs.Stdout.String(), "Applied 7 changes"))
+
+		expected := &requests{
+			CreateGroup: []api.CreateGroupRequest{{Name: "oncall"}},
+			UpdateMembers: []api.UpdateUsersInGroupRequest{
+				{GroupID: developers.ID, UserIDsToAdd: []uid.ID{bob.ID}, UserIDsToRemove: []uid.ID{carol.ID}},
+				{GroupID: oncallID, UserIDsToAdd: []uid.ID{bob.ID}},
+			},
+			UpdateGrants: []api.UpdateGrantsRequest{{
+				GrantsToAdd: []api.GrantRequest{
+					{GroupName: "oncall", Privilege: "admin", Resource: "production"},
+					{UserName: "alice@example.com", Privilege: "admin", Resource: "staging"},
+				},
+				GrantsToRemove: []api.GrantRequest{
+					{UserName: "carol@example.com", Privilege: "admin", Resource: "production"},
+				},
+			}},
+		}
+		assert.DeepEqual(t, reqs, expected)
+	})
+
+	t.Run("apply fails after some changes", func(t *testing.T) {
+		reqs := setupWithServer(t, fakeServer{failUpdateMembers: oncallID})
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "apply", "-f", filename)
+		assert.ErrorContains(t, err, "update users in group oncall")
+		assert.ErrorContains(t, err, `These changes were applied before the error:
+  + group oncall
+  + user bob@example.com in group developers
+  - user carol@example.com in group developers
+`)
+		assert.Equal(t, len(reqs.UpdateGrants), 0)
+	})
+
+	t.Run("prune refuses to remove own admin role", func(t *testing.T) {
+		reqs := setupWithServer(t, fakeServer{
+			grants: []api.Grant{
+				{ID: uid.ID(303), User: self.ID, Privilege: "admin", Resource: "infra"},
+			},
+			selfAccess: []api.EffectiveAccess{
+				{Privilege: "admin", Resource: "infra", GrantID: uid.ID(303)},
+			},
+		})
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "apply", "-f", filename, "--prune")
+		assert.ErrorContains(t, err, "--prune would remove your admin role on infra (grant admin on infra to user self@example.com)")
+		assert.DeepEqual(t, reqs, &requests{})
+	})
+
+	t.Run("prune with admin role from another grant", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "access.yaml")
+		content := accessFileContents + "  - group: developers\n    role: admin\n    resource: infra\n"
+		assert.NilError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+		setupWithServer(t, fakeServer{
+			grants: []api.Grant{
+				{ID: uid.ID(303), User: self.ID, Privilege: "admin", Resource: "infra"},
+				{ID: uid.ID(304), Group: developers.ID, Privilege: "admin", Resource: "infra"},
+			},
+			selfAccess: []api.EffectiveAccess{
+				{Privilege: "admin", Resource: "infra", GrantID: uid.ID(303)},
+				{Privilege: "admin", Resource: "infra", GrantID: uid.ID(304), GroupID: developers.ID},
+			},
+		})
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "plan", "-f", filename, "--prune")
+		assert.NilError(t, err)
+		assert.Assert(t, containsLine(bufs.Stdout.String(), "- grant admin on infra to user self@example.com"))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		setup(t)
+		filename := filepath.Join(t.TempDir(), "access.yaml")
+		content := "grants:\n  - user: nobody@example.com\n    resource: production\n"
+		assert.NilError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+		err := Run(context.Background(), "plan", "-f", filename)
+		assert.ErrorContains(t, err, `unknown user "nobody@example.com" in grant`)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "access.yaml")
+		content := "grants:\n  - user: alice@example.com\n    group: developers\n    resource: production\n"
+		assert.NilError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+		err := Run(context.Background(), "plan", "-f", filename)
+		assert.ErrorContains(t, err, "grants[0]: only one of user or group may be set")
+	})
+}
+
+func containsLine(output, line string) bool {
+	for _, l := range strings.Split(output, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
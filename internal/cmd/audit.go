@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/format"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
+)
+
+func newAuditCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   "View the audit log",
+		GroupID: groupManagement,
+	}
+
+	cmd.AddCommand(newAuditListCmd(cli))
+
+	return cmd
+}
+
+type auditListOptions struct {
+	UserName string
+	TargetID string
+	Route    string
+	Outcome  string
+}
+
+func newAuditListCmd(cli *CLI) *cobra.Command {
+	var options auditListOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List audit events",
+		Long: `List audit events, newest first.
+
+An audit event is recorded for every API call that changes the state of the
+organization, whether it succeeded or failed.`,
+		Example: `# List all audit events
+$ infra audit list
+
+# List the changes made to a grant
+$ infra audit list --target 3w9XyTrkzk
+
+# List failed API calls made by a user
+$ infra audit list --user janedoe@example.com --outcome failure
+`,
+		Args: NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			req := api.ListAuditEventsRequest{
+				Route:   options.Route,
+				Outcome: options.Outcome,
+			}
+			if options.UserName != "" {
+				user, err := getUserByNameOrID(client, options.UserName)
+				if err != nil {
+					return err
+				}
+				req.ActorID = user.ID
+			}
+			if options.TargetID != "" {
+				req.TargetID, err = uid.Parse([]byte(options.TargetID))
+				if err != nil {
+					return Error{Message: fmt.Sprintf("Invalid target ID %q", options.TargetID)}
+				}
+			}
+
+			logging.Debugf("call server: list audit events")
+			events, err := listAll(ctx, client.ListAuditEvents, req)
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.Debugf("%s", err.Error())
+					return Error{
+						Message: "Cannot list audit events: missing privileges for ListAuditEvents",
+					}
+				}
+				return err
+			}
+
+			type row struct {
+				Time    string `header:"TIME"`
+				User    string `header:"USER"`
+				Method  string `header:"METHOD"`
+				Route   string `header:"ROUTE"`
+				Target  string `header:"TARGET"`
+				Outcome string `header:"OUTCOME"`
+			}
+
+			var rows []row
+			for _, event := range events {
+				user := event.ActorName
+				if user == "" && event.ActorID != 0 {
+					user = event.ActorID.String()
+				}
+				var target string
+				if event.TargetID != 0 {
+					target = event.TargetID.String()
+				}
+
+				rows = append(rows, row{
+					Time:    format.HumanTime(event.Created.Time(), "unknown"),
+					User:    user,
+					Method:  event.Method,
+					Route:   event.Route,
+					Target:  target,
+					Outcome: fmt.Sprintf("%v (%d)", event.Outcome, event.StatusCode),
+				})
+			}
+
+			if len(rows) > 0 {
+				printTable(rows, cli.Stdout)
+			} else {
+				cli.Output("No audit events found")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.UserName, "user", "", "List events for API calls made by this user")
+	cmd.Flags().StringVar(&options.TargetID, "target", "", "List events for API calls that acted on the resource with this ID")
+	cmd.Flags().StringVar(&options.Route, "route", "", "List events for an API route, ex: /api/grants/:id")
+	cmd.Flags().StringVar(&options.Outcome, "outcome", "", "Filter by outcome [success, failure]")
+	return cmd
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAuditListCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	base := time.Now().Add(-24 * time.Hour)
+
+	setup := func(t *testing.T) chan api.ListAuditEventsRequest {
+		requestCh := make(chan api.ListAuditEventsRequest, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			query := req.URL.Query()
+
+			// the command does a lookup for user ID
+			if requestMatches(req, http.MethodGet, "/api/users") {
+				if query.Get("name") != "my-user" {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{
+						{ID: uid.ID(12345678)},
+					},
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			if !requestMatches(req, http.MethodGet, "/api/audit-events") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			actorID, err := uid.Parse([]byte(query.Get("actorID")))
+			assert.Check(t, err)
+			targetID, err := uid.Parse([]byte(query.Get("targetID")))
+			assert.Check(t, err)
+			requestCh <- api.ListAuditEventsRequest{
+				ActorID:  actorID,
+				TargetID: targetID,
+				Route:    query.Get("route"),
+				Outcome:  query.Get("outcome"),
+			}
+
+			resp.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(resp).Encode(api.ListResponse[api.AuditEvent]{
+				Count: 2,
+				Items: []api.AuditEvent{
+					{
+						ID:         uid.ID(2),
+						Created:    api.Time(base.Add(2 * time.Hour)),
+						ActorID:    uid.ID(12345678),
+						ActorName:  "my-user",
+						Method:     http.MethodDelete,
+						Route:      "/api/grants/:id",
+						TargetID:   uid.ID(4567),
+						Outcome:    api.AuditEventOutcomeFailure,
+						StatusCode: http.StatusForbidden,
+					},
+					{
+						ID:         uid.ID(1),
+						Created:    api.Time(base.Add(time.Hour)),
+						ActorID:    uid.ID(12345),
+						ActorName:  "admin",
+						Method:     http.MethodPost,
+						Route:      "/api/grants",
+						TargetID:   uid.ID(4567),
+						Outcome:    api.AuditEventOutcomeSuccess,
+						StatusCode: http.StatusCreated,
+					},
+				},
+			})
+			assert.Check(t, err)
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("list all", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "audit", "list")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.DeepEqual(t, req, api.ListAuditEventsRequest{})
+		golden.Assert(t, bufs.Stdout.String(), t.Name())
+	})
+
+	t.Run("with filters", func(t *testing.T) {
+		ch := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "audit", "list", "--user=my-user", "--target", uid.ID(4567).String(), "--outcome=failure", "--route=/api/grants/:id")
+		assert.NilError(t, err)
+
+		req := <-ch
+		expected := api.ListAuditEventsRequest{
+			ActorID:  uid.ID(12345678),
+			TargetID: uid.ID(4567),
+			Route:    "/api/grants/:id",
+			Outcome:  api.AuditEventOutcomeFailure,
+		}
+		assert.DeepEqual(t, req, expected)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "audit", "list", "--target=not-an-id!")
+		assert.ErrorContains(t, err, `Invalid target ID "not-an-id!"`)
+	})
+}
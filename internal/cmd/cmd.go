@@ -217,10 +217,15 @@ func NewRootCmd(cli *CLI) *cobra.Command {
 		// Management commands
 		newDestinationsCmd(cli),
 		newGrantsCmd(cli),
+		newAccessCmd(cli),
 		newUsersCmd(cli),
 		newGroupsCmd(cli),
 		newKeysCmd(cli),
+		newSessionsCmd(cli),
 		newProvidersCmd(cli),
+		newPlanCmd(cli),
+		newApplyCmd(cli),
+		newAuditCmd(cli),
 
 		// Other commands
 		newInfoCmd(cli),
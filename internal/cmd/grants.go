@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -22,6 +23,7 @@ type grantsCmdOptions struct {
 	Role        string
 	Force       bool
 	Inherited   bool
+	Duration    time.Duration
 }
 
 func newGrantsCmd(cli *CLI) *cobra.Command {
@@ -336,6 +338,18 @@ $ infra grants add johndoe@example.com staging --role viewer
 
 # Assign a user a role within Infra
 $ infra grants add johndoe@example.com infra --role admin
+
+# Grant a user temporary access to a destination
+$ infra grants add johndoe@example.com staging --duration 8h
+
+# Grant a user access to every destination with a name that starts with prod-
+$ infra grants add johndoe@example.com 'prod-*'
+
+# Allow a user to manage grants to every destination that starts with prod-
+$ infra grants add johndoe@example.com 'prod-*' --role grant-admin
+
+# Allow a user to manage the members of a group
+$ infra grants add johndoe@example.com group:Engineering --role group-owner
 `,
 		Args: ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -352,6 +366,7 @@ $ infra grants add johndoe@example.com infra --role admin
 	cmd.Flags().BoolVarP(&isGroup, "group", "g", false, "When set, creates a grant for a group instead of a user")
 	cmd.Flags().StringVar(&options.Role, "role", models.BasePermissionConnect, "Type of access that the user or group will be given")
 	cmd.Flags().BoolVar(&options.Force, "force", false, "Create grant even if requested user, destination, or role are unknown")
+	cmd.Flags().DurationVar(&options.Duration, "duration", 0, "Revoke the grant automatically after this duration")
 	return cmd
 }
 
@@ -418,6 +433,7 @@ func addGrant(cli *CLI, cmdOptions grantsCmdOptions) error {
 		Group:     groupID,
 		Privilege: cmdOptions.Role,
 		Resource:  cmdOptions.Resource,
+		Duration:  api.Duration(cmdOptions.Duration),
 	}
 	logging.Debugf("call server: create grant %#v", createGrantReq)
 	response, err := client.CreateGrant(ctx, createGrantReq)
@@ -475,6 +491,16 @@ func checkUserGroup(client *api.Client, user, group string) (userID uid.ID, grou
 // resource (e.g. namespace), and role exist. destination "infra" and role "connect" are
 // reserved values and will always pass checks
 func checkResourcesPrivileges(ctx context.Context, client *api.Client, resource, privilege string) error {
+	if groupName, ok := strings.CutPrefix(resource, models.GroupResourcePrefix); ok {
+		if _, err := getGroupByNameOrID(client, groupName); err != nil {
+			if errors.Is(err, ErrGroupNotFound) {
+				return Error{Message: fmt.Sprintf("Group %q does not exist; to ignore, run with '--force'", groupName)}
+			}
+			return err
+		}
+		return nil
+	}
+
 	parts := strings.SplitN(resource, ".", 2)
 	destination := parts[0]
 	subresource := ""
@@ -487,17 +513,16 @@ func checkResourcesPrivileges(ctx context.Context, client *api.Client, resource,
 	supportedRoles := make(map[string]struct{})
 
 	if destination != "infra" {
-		logging.Debugf("call server: list destinations named %q", destination)
-		destinations, err := client.ListDestinations(ctx, api.ListDestinationsRequest{Name: destination})
+		destinations, err := listDestinationsForResource(ctx, client, destination)
 		if err != nil {
 			return err
 		}
 
-		if destinations.Count == 0 {
+		if len(destinations) == 0 {
 			return Error{Message: fmt.Sprintf("Destination %q not connected; to ignore, run with '--force'", destination)}
 		}
 
-		for _, d := range destinations.Items {
+		for _, d := range destinations {
 			for _, r := range d.Resources {
 				supportedResources[r] = struct{}{}
 			}
@@ -513,7 +538,7 @@ func checkResourcesPrivileges(ctx context.Context, client *api.Client, resource,
 			}
 		}
 
-		if privilege != "connect" {
+		if privilege != "connect" && privilege != models.GrantAdminRole {
 			if _, ok := supportedRoles[privilege]; !ok {
 				return Error{Message: fmt.Sprintf("Role %q is not a known role for destination %q; to ignore, run with '--force'", privilege, destination)}
 			}
@@ -522,3 +547,30 @@ func checkResourcesPrivileges(ctx context.Context, client *api.Client, resource,
 
 	return nil
 }
+
+// listDestinationsForResource returns the destinations that match the
+// destination name of a grant resource, which may be a pattern.
+func listDestinationsForResource(ctx context.Context, client *api.Client, destination string) ([]api.Destination, error) {
+	if !strings.Contains(destination, "*") {
+		logging.Debugf("call server: list destinations named %q", destination)
+		destinations, err := client.ListDestinations(ctx, api.ListDestinationsRequest{Name: destination})
+		if err != nil {
+			return nil, err
+		}
+		return destinations.Items, nil
+	}
+
+	logging.Debugf("call server: list destinations")
+	destinations, err := listAll(ctx, client.ListDestinations, api.ListDestinationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []api.Destination
+	for _, d := range destinations {
+		if api.GrantMatchesDestination(destination, d.Name) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 
@@ -59,8 +60,9 @@ func TestGrantsAddCmd(t *testing.T) {
 
 			if requestMatches(req, http.MethodGet, "/api/destinations") {
 				resp.WriteHeader(http.StatusOK)
-				if query.Get("name") == "the-destination" {
-					writeResponse(t, resp, api.ListResponse[api.Destination]{Count: 1, Items: []api.Destination{{ID: 5000, Roles: []string{"role"}, Resources: []string{"default"}}}})
+				switch query.Get("name") {
+				case "the-destination", "":
+					writeResponse(t, resp, api.ListResponse[api.Destination]{Count: 1, Items: []api.Destination{{ID: 5000, Name: "the-destination", Roles: []string{"role"}, Resources: []string{"default"}}}})
 					return
 				}
 				writeResponse(t, resp, &api.ListResponse[api.Destination]{})
@@ -103,6 +105,75 @@ func TestGrantsAddCmd(t *testing.T) {
 		}
 		assert.DeepEqual(t, createReq, expected)
 	})
+	t.Run("add grant with a pattern", func(t *testing.T) {
+		ch := setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "the-*.default", "--role", "role")
+		assert.NilError(t, err)
+
+		createReq := <-ch
+		expected := api.GrantRequest{
+			User:      3000,
+			Privilege: "role",
+			Resource:  "the-*.default",
+		}
+		assert.DeepEqual(t, createReq, expected)
+	})
+	t.Run("add grant with a pattern that matches no destinations", func(t *testing.T) {
+		setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "other-*")
+		assert.ErrorContains(t, err, `Destination "other-*" not connected`)
+	})
+	t.Run("add group owner grant", func(t *testing.T) {
+		ch := setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "group:existingGroup", "--role", "group-owner")
+		assert.NilError(t, err)
+
+		createReq := <-ch
+		expected := api.GrantRequest{
+			User:      3000,
+			Privilege: "group-owner",
+			Resource:  "group:existingGroup",
+		}
+		assert.DeepEqual(t, createReq, expected)
+	})
+	t.Run("add group owner grant for unknown group", func(t *testing.T) {
+		setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "group:unknown", "--role", "group-owner")
+		assert.ErrorContains(t, err, `Group "unknown" does not exist`)
+	})
+	t.Run("add grant admin grant", func(t *testing.T) {
+		ch := setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "the-*", "--role", "grant-admin")
+		assert.NilError(t, err)
+
+		createReq := <-ch
+		expected := api.GrantRequest{
+			User:      3000,
+			Privilege: "grant-admin",
+			Resource:  "the-*",
+		}
+		assert.DeepEqual(t, createReq, expected)
+	})
+	t.Run("add grant with duration", func(t *testing.T) {
+		ch := setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "the-destination", "--duration", "8h")
+		assert.NilError(t, err)
+
+		createReq := <-ch
+		expected := api.GrantRequest{
+			User:      3000,
+			Privilege: "connect",
+			Resource:  "the-destination",
+			Duration:  api.Duration(8 * time.Hour),
+		}
+		assert.DeepEqual(t, createReq, expected)
+	})
 	t.Run("add default role to existing identity for namespace", func(t *testing.T) {
 		ch := setup(t)
 		ctx := context.Background()
@@ -48,9 +48,11 @@ func newGroupsCmd(cli *CLI) *cobra.Command {
 	}
 
 	cmd.AddCommand(newGroupsAddCmd(cli))
+	cmd.AddCommand(newGroupsAddGroupCmd(cli))
 	cmd.AddCommand(newGroupsAddUserCmd(cli))
 	cmd.AddCommand(newGroupsListCmd(cli))
 	cmd.AddCommand(newGroupsRemoveCmd(cli))
+	cmd.AddCommand(newGroupsRemoveGroupCmd(cli))
 	cmd.AddCommand(newGroupsRemoveUserCmd(cli))
 
 	return cmd
@@ -315,3 +317,120 @@ $ infra groups removeuser johndoe@example.com Engineering
 
 	return cmd
 }
+
+func newGroupsAddGroupCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "addgroup PARENT CHILD",
+		Short: "Add a group to another group",
+		Long: `Add a group to another group.
+
+Members of the child group inherit all the grants of the parent group.`,
+		Args: ExactArgs(2),
+		Example: `# Add the sre group to the platform group
+$ infra groups addgroup platform sre
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parentName := args[0]
+			childName := args[1]
+
+			ctx := context.Background()
+
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			parent, err := getGroupByNameOrID(client, parentName)
+			if err != nil {
+				if errors.Is(err, ErrGroupNotFound) {
+					return Error{Message: fmt.Sprintf("unknown group %q", parentName)}
+				}
+				return err
+			}
+
+			child, err := getGroupByNameOrID(client, childName)
+			if err != nil {
+				if errors.Is(err, ErrGroupNotFound) {
+					return Error{Message: fmt.Sprintf("unknown group %q", childName)}
+				}
+				return err
+			}
+
+			req := &api.UpdateGroupsInGroupRequest{
+				GroupID:       parent.ID,
+				GroupIDsToAdd: []uid.ID{child.ID},
+			}
+			err = client.UpdateGroupsInGroup(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			cli.Output("Added group %q to group %q", child.Name, parent.Name)
+
+			return nil
+		},
+	}
+}
+
+func newGroupsRemoveGroupCmd(cli *CLI) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:     "removegroup PARENT CHILD",
+		Short:   "Remove a group from another group",
+		Aliases: []string{"rmgroup"},
+		Args:    ExactArgs(2),
+		Example: `# Remove the sre group from the platform group
+$ infra groups removegroup platform sre
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parentName := args[0]
+			childName := args[1]
+
+			ctx := context.Background()
+
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			parent, err := getGroupByNameOrID(client, parentName)
+			if err != nil {
+				if !force {
+					if errors.Is(err, ErrGroupNotFound) {
+						return Error{Message: fmt.Sprintf("unknown group %q", parentName)}
+					}
+					return err
+				}
+				return nil
+			}
+
+			child, err := getGroupByNameOrID(client, childName)
+			if err != nil {
+				if !force {
+					if errors.Is(err, ErrGroupNotFound) {
+						return Error{Message: fmt.Sprintf("unknown group %q", childName)}
+					}
+					return err
+				}
+				return nil
+			}
+
+			req := &api.UpdateGroupsInGroupRequest{
+				GroupID:          parent.ID,
+				GroupIDsToRemove: []uid.ID{child.ID},
+			}
+			err = client.UpdateGroupsInGroup(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			cli.Output("Removed group %q from group %q", childName, parentName)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Exit successfully even if either group does not exist")
+
+	return cmd
+}
@@ -12,6 +12,7 @@ import (
 	"gotest.tools/v3/golden"
 
 	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
 )
 
 func TestGroupsAddCmd(t *testing.T) {
@@ -240,6 +241,96 @@ func TestGroupsAddAndRemoveUserCmds(t *testing.T) {
 
 }
 
+func TestGroupsAddAndRemoveGroupCmds(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	setup := func(t *testing.T) *[]api.UpdateGroupsInGroupRequest {
+		var requests []api.UpdateGroupsInGroupRequest
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			if requestMatches(req, http.MethodGet, "/api/groups") {
+				var groups []api.Group
+				switch req.URL.Query().Get("name") {
+				case "platform":
+					groups = []api.Group{{ID: 100, Name: "platform"}}
+				case "sre":
+					groups = []api.Group{{ID: 101, Name: "sre"}}
+				}
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.Group]{Count: len(groups), Items: groups})
+				assert.NilError(t, err)
+				return
+			}
+
+			if !requestMatches(req, http.MethodPatch, "/api/groups/2J/groups") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			var updateRequest api.UpdateGroupsInGroupRequest
+			err := json.NewDecoder(req.Body).Decode(&updateRequest)
+			assert.NilError(t, err)
+			requests = append(requests, updateRequest)
+
+			resp.WriteHeader(http.StatusOK)
+			err = json.NewEncoder(resp).Encode(api.EmptyResponse{})
+			assert.NilError(t, err)
+		}
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+		return &requests
+	}
+
+	t.Run("add group", func(t *testing.T) {
+		requests := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "groups", "addgroup", "platform", "sre")
+		assert.NilError(t, err)
+		assert.Equal(t, bufs.Stdout.String(), `Added group "sre" to group "platform"`+"\n")
+
+		expected := []api.UpdateGroupsInGroupRequest{{GroupIDsToAdd: []uid.ID{101}}}
+		assert.DeepEqual(t, *requests, expected)
+	})
+
+	t.Run("remove group", func(t *testing.T) {
+		requests := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "groups", "removegroup", "platform", "sre")
+		assert.NilError(t, err)
+		assert.Equal(t, bufs.Stdout.String(), `Removed group "sre" from group "platform"`+"\n")
+
+		expected := []api.UpdateGroupsInGroupRequest{{GroupIDsToRemove: []uid.ID{101}}}
+		assert.DeepEqual(t, *requests, expected)
+	})
+
+	t.Run("add group unknown parent", func(t *testing.T) {
+		setup(t)
+		ctx, _ := PatchCLI(context.Background())
+		err := Run(ctx, "groups", "addgroup", "Nonexistent", "sre")
+		assert.ErrorContains(t, err, `unknown group "Nonexistent"`)
+	})
+
+	t.Run("add group unknown child", func(t *testing.T) {
+		setup(t)
+		ctx, _ := PatchCLI(context.Background())
+		err := Run(ctx, "groups", "addgroup", "platform", "Nonexistent")
+		assert.ErrorContains(t, err, `unknown group "Nonexistent"`)
+	})
+
+	t.Run("remove group unknown with force", func(t *testing.T) {
+		requests := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+		err := Run(ctx, "groups", "removegroup", "platform", "Nonexistent", "--force")
+		assert.NilError(t, err)
+		assert.Equal(t, len(*requests), 0)
+	})
+}
+
 var expectedGroupsAddOutput = `Added group "Test"
 `
 
@@ -40,6 +40,7 @@ type keyCreateOptions struct {
 	InactivityTimeout time.Duration
 	Connector         bool
 	Quiet             bool
+	Scopes            []string
 }
 
 func newKeysAddCmd(cli *CLI) *cobra.Command {
@@ -59,6 +60,9 @@ $ infra keys add --connector
 
 # Set an environment variable with the newly created access key
 $ MY_ACCESS_KEY=$(infra keys add -q --name my-key)
+
+# Create an access key that can only list grants and manage the users in one group
+$ infra keys add --name ci --scope grants:read --scope groups:write:4yJ3n3D8E2
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
@@ -109,6 +113,7 @@ $ MY_ACCESS_KEY=$(infra keys add -q --name my-key)
 				Name:              options.Name,
 				Expiry:            api.Duration(options.Expiry),
 				InactivityTimeout: api.Duration(options.InactivityTimeout),
+				Scopes:            options.Scopes,
 			})
 			if err != nil {
 				if api.ErrorStatusCode(err) == 403 {
@@ -160,6 +165,7 @@ $ MY_ACCESS_KEY=$(infra keys add -q --name my-key)
 	cmd.Flags().BoolVarP(&options.Quiet, "quiet", "q", false, "Only display the access key")
 	cmd.Flags().DurationVar(&options.Expiry, "expiry", oneYear, "The total time that the access key will be valid for")
 	cmd.Flags().DurationVar(&options.InactivityTimeout, "inactivity-timeout", thirtyDays, "A specified deadline that the access key must be used within to remain valid")
+	cmd.Flags().StringSliceVar(&options.Scopes, "scope", nil, "Limit the key to a resource:action or resource:action:id scope, such as grants:read")
 
 	return cmd
 }
@@ -101,6 +101,17 @@ func TestKeysAddCmd(t *testing.T) {
 		assert.Equal(t, withNewline(bufs.Stdout.String()), expectedKeysAddOutput)
 	})
 
+	t.Run("scopes", func(t *testing.T) {
+		ch := setup(t)
+
+		ctx, _ := PatchCLI(context.Background())
+		err := Run(ctx, "keys", "add", "--user=my-user", "--scope=grants:read", "--scope", "groups:write:4yJ3n3D8E2,destinations:read")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.DeepEqual(t, req.Scopes, []string{"grants:read", "groups:write:4yJ3n3D8E2", "destinations:read"})
+	})
+
 	t.Run("with unexpected arguments", func(t *testing.T) {
 		err := Run(context.Background(), "keys", "add", "something")
 		assert.ErrorContains(t, err, `"infra keys add" accepts no arguments`)
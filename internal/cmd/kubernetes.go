@@ -83,7 +83,6 @@ func writeKubeconfig(user *api.User, destinations []api.Destination, grants []ap
 
 	for _, g := range grants {
 		parts := strings.Split(g.Resource, ".")
-		cluster := parts[0]
 
 		var namespace string
 		if len(parts) > 1 {
@@ -94,32 +93,24 @@ func writeKubeconfig(user *api.User, destinations []api.Destination, grants []ap
 			namespace = ""
 		}
 
-		contextName := "infra:" + cluster
-		if _, ok := infraContexts[contextName]; ok && namespace != "" {
-			continue
-		}
-
-		var infraContext clusterContext
+		// the destination name in the grant may be a pattern that matches
+		// more than one destination
 		for _, d := range destinations {
-			if !isResourceForDestination(g.Resource, d.Name) {
+			if !isResourceForDestination(g.Resource, d.Name) || !isDestinationAvailable(d) {
 				continue
 			}
 
-			if isDestinationAvailable(d) {
-				infraContext = clusterContext{
-					URL: d.Connection.URL,
-					CA:  []byte(d.Connection.CA),
-				}
-				break
+			contextName := "infra:" + d.Name
+			if _, ok := infraContexts[contextName]; ok && namespace != "" {
+				continue
 			}
-		}
 
-		if infraContext.URL == "" {
-			continue
+			infraContexts[contextName] = clusterContext{
+				URL:       d.Connection.URL,
+				CA:        []byte(d.Connection.CA),
+				Namespace: namespace,
+			}
 		}
-
-		infraContext.Namespace = namespace
-		infraContexts[contextName] = infraContext
 	}
 
 	for contextName, infraContext := range infraContexts {
@@ -324,6 +324,22 @@ func TestWriteKubeconfig(t *testing.T) {
 		assert.DeepEqual(t, actual.Clusters, expectedClusters, cmpKubeconfig)
 		assert.DeepEqual(t, actual.AuthInfos, expectedAuthInfos, cmpKubeconfig)
 	})
+
+	t.Run("Pattern", func(t *testing.T) {
+		expectedContexts := map[string]*clientcmdapi.Context{
+			"infra:connected": {
+				AuthInfo:  "user",
+				Cluster:   "infra:connected",
+				Namespace: "namespace",
+			},
+		}
+
+		actual := run(t, api.Grant{Resource: "*connected.namespace"})
+
+		assert.DeepEqual(t, actual.Contexts, expectedContexts, cmpKubeconfig)
+		assert.DeepEqual(t, actual.Clusters, expectedClusters, cmpKubeconfig)
+		assert.DeepEqual(t, actual.AuthInfos, expectedAuthInfos, cmpKubeconfig)
+	})
 }
 
 func TestWriteKubeconfig_UserNamespaceOverride(t *testing.T) {
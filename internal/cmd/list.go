@@ -105,7 +105,7 @@ func isDestinationAvailable(destination api.Destination) bool {
 }
 
 func isResourceForDestination(resource string, destination string) bool {
-	return resource == destination || strings.HasPrefix(resource, destination+".")
+	return api.GrantMatchesDestination(resource, destination)
 }
 
 func getUserDestinationGrants(client *api.Client, kind string) (*api.User, []api.Destination, []api.Grant, error) {
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -23,6 +27,7 @@ import (
 	"github.com/infrahq/infra/internal/cmd/cliopts"
 	"github.com/infrahq/infra/internal/cmd/types"
 	"github.com/infrahq/infra/internal/format"
+	"github.com/infrahq/infra/internal/generate"
 	"github.com/infrahq/infra/internal/logging"
 )
 
@@ -36,6 +41,8 @@ type loginCmdOptions struct {
 	NoAgent             bool
 	User                string
 	Password            string
+	Provider            string
+	MFACode             string
 	InjectUserSSHConfig bool
 }
 
@@ -50,7 +57,8 @@ func newLoginCmd(cli *CLI) *cobra.Command {
 		Example: `# Login
 infra login example.infrahq.com
 
-# Login with username and password (prompt for password)
+# Login with username and password (prompt for password), or with the
+# identity provider for the domain of the email
 infra login example.infrahq.com --user user@example.com
 
 # Login with access key
@@ -62,7 +70,16 @@ infra login example.infrahq.com
 export INFRA_SERVER=example.infrahq.com
 export INFRA_USER=user@example.com
 export INFRA_PASSWORD=p4ssw0rd
-infra login`,
+infra login
+
+# Login with username, password, and a code from an authenticator app
+infra login example.infrahq.com --user user@example.com --mfa-code 123456
+
+# Login with a username and password from an LDAP directory
+infra login example.infrahq.com --provider active-directory --user dana
+
+# Login with an identity provider in a browser
+infra login example.infrahq.com --provider okta`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cliopts.DefaultsFromEnv("INFRA", cmd.Flags()); err != nil {
 				return err
@@ -90,6 +107,8 @@ infra login`,
 
 	cmd.Flags().StringVar(&options.AccessKey, "key", "", "Login with an access key")
 	cmd.Flags().StringVar(&options.User, "user", "", "User email")
+	cmd.Flags().StringVar(&options.MFACode, "mfa-code", "", "Multi-factor authentication code or recovery code")
+	cmd.Flags().StringVar(&options.Provider, "provider", "", "Name of the provider to login with. Without --user, login with the provider in a browser")
 	cmd.Flags().BoolVar(&options.SkipTLSVerify, "skip-tls-verify", false, "Skip verifying server TLS certificates")
 	cmd.Flags().Var((*types.StringOrFile)(&options.TrustedCertificate), "tls-trusted-cert", "TLS certificate or CA used by the server")
 	cmd.Flags().StringVar(&options.TrustedFingerprint, "tls-trusted-fingerprint", "", "SHA256 fingerprint of the server TLS certificate")
@@ -137,6 +156,20 @@ func login(cli *CLI, options loginCmdOptions) error {
 		return err
 	}
 
+	if options.User != "" && options.Provider == "" && options.Password == "" && !options.NonInteractive {
+		provider, err := discoverProvider(ctx, lc.APIClient, cli, options.User)
+		if err != nil {
+			return err
+		}
+		if provider != nil {
+			options.Provider = provider.Name
+			if provider.Kind != "ldap" {
+				// login with the provider in a browser
+				options.User = ""
+			}
+		}
+	}
+
 	var loginRes *api.LoginResponse
 
 	switch {
@@ -147,30 +180,60 @@ func login(cli *CLI, options loginCmdOptions) error {
 		if err != nil {
 			return err
 		}
+	case options.User != "" && options.Provider != "":
+		fmt.Fprintf(cli.Stderr, "  Logging in as user %s with provider %s\n", termenv.String(options.User).Bold().String(), options.Provider)
+
+		if err := promptLoginPassword(cli, &options); err != nil {
+			return err
+		}
+
+		loginRes, err = ldapLogin(ctx, lc.APIClient, options)
+		if err != nil {
+			return err
+		}
 	case options.User != "":
 		fmt.Fprintf(cli.Stderr, "  Logging in as user %s\n", termenv.String(options.User).Bold().String())
 
-		if options.Password == "" {
-			if options.NonInteractive {
-				return Error{Message: "Non-interactive login requires setting the INFRA_PASSWORD environment variable"}
-			}
-
-			if err := survey.AskOne(&survey.Password{Message: "Password:"}, &options.Password, cli.surveyIO, survey.WithValidator(survey.Required)); err != nil {
-				return err
-			}
+		if err := promptLoginPassword(cli, &options); err != nil {
+			return err
 		}
 
-		loginRes, err = lc.APIClient.Login(ctx, &api.LoginRequest{
+		loginReq := &api.LoginRequest{
 			PasswordCredentials: &api.LoginRequestPasswordCredentials{
 				Name:     options.User,
 				Password: options.Password,
+				MFACode:  options.MFACode,
 			},
-		})
+		}
+		loginRes, err = lc.APIClient.Login(ctx, loginReq)
+		if err == nil && loginRes.MFARequired {
+			if options.NonInteractive {
+				return Error{Message: "Non-interactive login requires setting the INFRA_MFA_CODE environment variable for users enrolled in multi-factor authentication"}
+			}
+
+			if err := survey.AskOne(&survey.Input{Message: "Authentication code:"}, &loginReq.PasswordCredentials.MFACode, cli.surveyIO, survey.WithValidator(survey.Required)); err != nil {
+				return err
+			}
+
+			loginRes, err = lc.APIClient.Login(ctx, loginReq)
+		}
 		if err != nil {
 			if api.ErrorStatusCode(err) == http.StatusUnauthorized {
+				if loginReq.PasswordCredentials.MFACode != "" {
+					return &LoginError{Message: "your username, password, or authentication code may be invalid"}
+				}
 				return &LoginError{Message: "your username or password may be invalid"}
 			}
 
+			return err
+		}
+	case options.Provider != "":
+		if options.NonInteractive {
+			return Error{Message: "Login with a browser can not be non-interactive, use the --user flag with LDAP providers, or an access key"}
+		}
+
+		loginRes, err = browserLogin(ctx, lc.APIClient, cli, options.Provider)
+		if err != nil {
 			return err
 		}
 	default:
@@ -213,6 +276,16 @@ func login(cli *CLI, options loginCmdOptions) error {
 		}
 	}
 
+	if loginRes.MFAEnrollmentRequired {
+		if options.NonInteractive {
+			return Error{Message: "Your organization requires multi-factor authentication. Run 'infra login' interactively to enroll."}
+		}
+
+		if err := enrollMFA(ctx, cli, lc.APIClient); err != nil {
+			return err
+		}
+	}
+
 	if err := updateInfraConfig(lc, loginRes); err != nil {
 		return err
 	}
@@ -244,6 +317,295 @@ func login(cli *CLI, options loginCmdOptions) error {
 	return nil
 }
 
+func promptLoginPassword(cli *CLI, options *loginCmdOptions) error {
+	if options.Password != "" {
+		return nil
+	}
+	if options.NonInteractive {
+		return Error{Message: "Non-interactive login requires setting the INFRA_PASSWORD environment variable"}
+	}
+	return survey.AskOne(&survey.Password{Message: "Password:"}, &options.Password, cli.surveyIO, survey.WithValidator(survey.Required))
+}
+
+// ldapLogin logs in with a username and password that are checked by an LDAP
+// provider.
+func ldapLogin(ctx context.Context, client *api.Client, options loginCmdOptions) (*api.LoginResponse, error) {
+	logging.Debugf("call server: list providers named %q", options.Provider)
+	providers, err := client.ListProviders(ctx, api.ListProvidersRequest{Name: options.Provider})
+	if err != nil {
+		return nil, err
+	}
+
+	var provider *api.Provider
+	for i := range providers.Items {
+		if providers.Items[i].Name == options.Provider {
+			provider = &providers.Items[i]
+			break
+		}
+	}
+	switch {
+	case provider == nil:
+		return nil, Error{Message: fmt.Sprintf("Provider %s does not exist", options.Provider)}
+	case provider.Kind != "ldap":
+		return nil, Error{Message: fmt.Sprintf("Provider %s is not an LDAP provider, only LDAP providers can be used with --user and --provider", options.Provider)}
+	}
+
+	loginRes, err := client.Login(ctx, &api.LoginRequest{
+		LDAP: &api.LoginRequestLDAP{
+			ProviderID: provider.ID,
+			Name:       options.User,
+			Password:   options.Password,
+		},
+	})
+	if err != nil {
+		if api.ErrorStatusCode(err) == http.StatusUnauthorized {
+			return nil, &LoginError{Message: "your username or password may be invalid"}
+		}
+		return nil, err
+	}
+	return loginRes, nil
+}
+
+// loginWithPassword is the option to login with the Infra password of the
+// user, instead of a provider found by discoverProvider.
+const loginWithPassword = "Infra password"
+
+// discoverProvider returns the provider to login with for the domain of the
+// email, or nil if the user should login with a password. A user with an
+// email in the domain of a provider can also have an Infra password, so the
+// user selects one of the providers for the domain, or their password.
+func discoverProvider(ctx context.Context, client *api.Client, cli *CLI, email string) (*api.Provider, error) {
+	logging.Debugf("call server: discover providers for %q", email)
+	resp, err := client.DiscoverLogin(ctx, &api.DiscoverLoginRequest{Email: email})
+	if err != nil {
+		// the server may not support discovery, or the user may be a username
+		// that is not an email, so login with a password
+		logging.Debugf("discover providers: %v", err)
+		return nil, nil
+	}
+	if len(resp.Providers) == 0 {
+		return nil, nil
+	}
+
+	// only LDAP providers, and OIDC providers in a browser, can login with the CLI
+	var usable []api.Provider
+	for _, provider := range resp.Providers {
+		if provider.Kind == "ldap" || (provider.Kind != "saml" && provider.AuthURL != "") {
+			usable = append(usable, provider)
+		}
+	}
+	if len(usable) == 0 {
+		fmt.Fprintf(cli.Stderr, "  Provider %s does not support login with the CLI, logging in with a password\n", termenv.String(resp.Providers[0].Name).Bold().String())
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(usable)+1)
+	for _, provider := range usable {
+		names = append(names, provider.Name)
+	}
+	names = append(names, loginWithPassword)
+	var selected int
+	prompt := &survey.Select{Message: "Select a provider to login with:", Options: names}
+	if err := survey.AskOne(prompt, &selected, cli.surveyIO); err != nil {
+		return nil, err
+	}
+	if selected == len(usable) {
+		return nil, nil
+	}
+
+	fmt.Fprintf(cli.Stderr, "  Logging in with provider %s\n", termenv.String(usable[selected].Name).Bold().String())
+	return &usable[selected], nil
+}
+
+// browserLoginTimeout is how long browserLogin waits for the identity provider
+// to redirect the browser back to the CLI.
+var browserLoginTimeout = 5 * time.Minute
+
+// openBrowser opens url in the default browser. Tests replace it to follow the
+// redirects of a fake identity provider.
+var openBrowser = browser.OpenURL
+
+// browserLogin logs in with an OIDC provider using the authorization code flow
+// with PKCE. The identity provider redirects the browser to a listener on the
+// loopback interface, which receives the authorization code. The server
+// exchanges the code and the code verifier for the identity provider tokens.
+func browserLogin(ctx context.Context, client *api.Client, cli *CLI, providerName string) (*api.LoginResponse, error) {
+	logging.Debugf("call server: list providers named %q", providerName)
+	providers, err := client.ListProviders(ctx, api.ListProvidersRequest{Name: providerName})
+	if err != nil {
+		return nil, err
+	}
+
+	var provider *api.Provider
+	for i := range providers.Items {
+		if providers.Items[i].Name == providerName {
+			provider = &providers.Items[i]
+			break
+		}
+	}
+	switch {
+	case provider == nil:
+		return nil, Error{Message: fmt.Sprintf("Provider %s does not exist", providerName)}
+	case provider.Kind == "ldap":
+		return nil, Error{Message: fmt.Sprintf("Provider %s is an LDAP provider, login with --user", providerName)}
+	case provider.Kind == "saml" || provider.AuthURL == "":
+		return nil, Error{Message: fmt.Sprintf("Provider %s does not support login with the CLI, run 'infra login' without --provider", providerName)}
+	}
+
+	// the code verifier must be between 43 and 128 characters
+	verifier, err := generate.CryptoRandom(64, generate.CharsetAlphaNumeric)
+	if err != nil {
+		return nil, err
+	}
+	state, err := generate.CryptoRandom(16, generate.CharsetAlphaNumeric)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for login redirect: %w", err)
+	}
+	redirectURL := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if query.Get("state") != state {
+			// any web page or local process can send a request to the
+			// listener, so requests without the state are ignored instead of
+			// cancelling the login.
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		}
+
+		var result callbackResult
+		switch {
+		case query.Get("error") != "":
+			result.err = Error{Message: fmt.Sprintf("Login failed: %s %s", query.Get("error"), query.Get("error_description"))}
+		case query.Get("code") == "":
+			result.err = Error{Message: "Login failed, the identity provider did not respond with a code"}
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, "Login failed, return to the terminal for details.", http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login complete, you can close this window and return to the terminal.")
+		}
+
+		select {
+		case results <- result:
+		default:
+			// a result was already received
+		}
+	})
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Debugf("login redirect listener: %v", err)
+		}
+	}()
+	defer srv.Close()
+
+	authURL, err := url.Parse(provider.AuthURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth URL for provider %s: %w", providerName, err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	query := authURL.Query()
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", strings.Join(provider.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if provider.Kind == "google" {
+		// google only sends a refresh token when a user consents
+		query.Set("prompt", "consent")
+		query.Set("access_type", "offline")
+	}
+	authURL.RawQuery = query.Encode()
+
+	fmt.Fprintf(cli.Stderr, "  Opening a browser to login with %s. If it does not open, navigate to:\n\n\t%s\n\n", provider.Name, authURL)
+	// we don't care if this fails, the user can open the URL
+	_ = openBrowser(authURL.String())
+
+	timeout := time.NewTimer(browserLoginTimeout)
+	defer timeout.Stop()
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-timeout.C:
+		return nil, Error{Message: "Login timed out, the identity provider did not redirect back to the CLI"}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	logging.Debugf("call server: login with provider %s", provider.ID)
+	return client.Login(ctx, &api.LoginRequest{
+		OIDC: &api.LoginRequestOIDC{
+			ProviderID:   provider.ID,
+			RedirectURL:  redirectURL,
+			Code:         result.code,
+			CodeVerifier: verifier,
+		},
+	})
+}
+
+// enrollMFA enrolls the logged in user in multi-factor authentication, and
+// prints their recovery codes.
+func enrollMFA(ctx context.Context, cli *CLI, client *api.Client) error {
+	fmt.Fprintf(cli.Stderr, "  Your organization requires multi-factor authentication.\n")
+
+	logging.Debugf("call server: create mfa enrollment")
+	enrollment, err := client.CreateMFAEnrollment(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.Stderr, "  Add this secret to your authenticator app:\n\n    %s\n\n  or add it using this URI:\n\n    %s\n\n",
+		termenv.String(enrollment.Secret).Bold().String(), enrollment.URI)
+
+	for {
+		var code string
+		if err := survey.AskOne(&survey.Input{Message: "Authentication code:"}, &code, cli.surveyIO, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+
+		logging.Debugf("call server: verify mfa enrollment")
+		res, err := client.VerifyMFAEnrollment(ctx, &api.VerifyMFAEnrollmentRequest{Code: code})
+		if err != nil {
+			var apiError api.Error
+			if errors.As(err, &apiError) && len(apiError.FieldErrors) > 0 {
+				cli.Output("  Invalid code. Please try again.")
+				continue
+			}
+			return err
+		}
+
+		fmt.Fprintf(cli.Stderr, "  Enrolled in multi-factor authentication. Store these recovery codes somewhere safe,\n")
+		fmt.Fprintf(cli.Stderr, "  each one can be used once to login without your authenticator app:\n\n")
+		for _, recoveryCode := range res.RecoveryCodes {
+			fmt.Fprintf(cli.Stderr, "    %s\n", recoveryCode)
+		}
+		fmt.Fprintln(cli.Stderr)
+		return nil
+	}
+}
+
 func equalHosts(x, y string) bool {
 	return strings.TrimPrefix(x, "https://") == strings.TrimPrefix(y, "https://")
 }
@@ -449,7 +811,7 @@ func deviceFlowLogin(ctx context.Context, client *api.Client, cli *CLI) (*api.Lo
 	fmt.Fprintf(cli.Stderr, "\t\t%s\n\n", termenv.String(resp.UserCode).Bold().String())
 
 	// we don't care if this fails. some devices won't be able to open the browser
-	_ = browser.OpenURL(url)
+	_ = openBrowser(url)
 
 	// poll for response
 	timeout := time.NewTimer(time.Duration(resp.ExpiresInSeconds) * time.Second)
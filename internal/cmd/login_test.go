@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -338,6 +341,316 @@ func TestLoginCmd_UserPass(t *testing.T) {
 	})
 }
 
+func TestLoginCmd_LDAP(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+	t.Setenv("KUBECONFIG", filepath.Join(home, "kube.config"))
+
+	providerID := uid.New()
+	handler := func(resp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/providers":
+			providers := []api.Provider{}
+			switch req.URL.Query().Get("name") {
+			case "directory":
+				providers = append(providers, api.Provider{ID: providerID, Name: "directory", Kind: "ldap"})
+			case "okta":
+				providers = append(providers, api.Provider{ID: uid.New(), Name: "okta", Kind: "okta"})
+			case "dir":
+				// a provider with a different name is not used
+				providers = append(providers, api.Provider{ID: providerID, Name: "directory", Kind: "ldap"})
+			}
+			err := json.NewEncoder(resp).Encode(api.ListResponse[api.Provider]{Items: providers, Count: len(providers)})
+			assert.Check(t, err)
+		case "/api/login":
+			var loginRequest api.LoginRequest
+			err := json.NewDecoder(req.Body).Decode(&loginRequest)
+			assert.Check(t, err)
+			assert.Assert(t, loginRequest.PasswordCredentials == nil)
+			assert.DeepEqual(t, loginRequest.LDAP, &api.LoginRequestLDAP{
+				ProviderID: providerID,
+				Name:       "dana",
+				Password:   "p4ssw0rd",
+			})
+
+			res := &api.LoginResponse{
+				UserID:           uid.New(),
+				Name:             "dana@example.com",
+				AccessKey:        "abc.xyz",
+				OrganizationName: "Default",
+				Expires:          api.Time(time.Now().UTC().Add(time.Hour * 24)),
+			}
+			err = json.NewEncoder(resp).Encode(res)
+			assert.Check(t, err)
+		}
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("INFRA_USER", "dana")
+	t.Setenv("INFRA_PASSWORD", "p4ssw0rd")
+
+	t.Run("login with ldap provider", func(t *testing.T) {
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--provider", "directory", "--no-agent")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stderr.String(), "dana@example.com"))
+	})
+
+	t.Run("provider is not ldap", func(t *testing.T) {
+		err := Run(context.Background(), "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--provider", "okta", "--no-agent")
+		assert.ErrorContains(t, err, "Provider okta is not an LDAP provider")
+	})
+
+	t.Run("provider does not exist", func(t *testing.T) {
+		err := Run(context.Background(), "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--provider", "missing", "--no-agent")
+		assert.ErrorContains(t, err, "Provider missing does not exist")
+	})
+
+	t.Run("provider name does not match", func(t *testing.T) {
+		err := Run(context.Background(), "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--provider", "dir", "--no-agent")
+		assert.ErrorContains(t, err, "Provider dir does not exist")
+	})
+}
+
+func TestLoginCmd_Browser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+	t.Setenv("KUBECONFIG", filepath.Join(home, "kube.config"))
+	t.Setenv("INFRA_NON_INTERACTIVE", "false")
+
+	providerID := uid.New()
+	// the query of the last auth URL opened in the browser
+	var authQuery url.Values
+	handler := func(resp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/providers":
+			providers := []api.Provider{}
+			switch req.URL.Query().Get("name") {
+			case "okta":
+				providers = append(providers, api.Provider{
+					ID:       providerID,
+					Name:     "okta",
+					Kind:     "okta",
+					ClientID: "the-client-id",
+					AuthURL:  "https://example.okta.com/oauth2/v1/authorize",
+					Scopes:   []string{"openid", "email"},
+				})
+			case "directory":
+				providers = append(providers, api.Provider{ID: uid.New(), Name: "directory", Kind: "ldap"})
+			}
+			err := json.NewEncoder(resp).Encode(api.ListResponse[api.Provider]{Items: providers, Count: len(providers)})
+			assert.Check(t, err)
+		case "/api/login/discover":
+			var discoverRequest api.DiscoverLoginRequest
+			err := json.NewDecoder(req.Body).Decode(&discoverRequest)
+			assert.Check(t, err)
+
+			discovered := &api.DiscoverLoginResponse{Providers: []api.Provider{}}
+			switch discoverRequest.Email {
+			case "dana@example.com":
+				discovered.Providers = append(discovered.Providers, api.Provider{
+					ID:       providerID,
+					Name:     "okta",
+					Kind:     "okta",
+					ClientID: "the-client-id",
+					AuthURL:  "https://example.okta.com/oauth2/v1/authorize",
+					Scopes:   []string{"openid", "email"},
+				})
+			case "dana@saml.example.com":
+				discovered.Providers = append(discovered.Providers, api.Provider{ID: uid.New(), Name: "adfs", Kind: "saml"})
+			}
+			err = json.NewEncoder(resp).Encode(discovered)
+			assert.Check(t, err)
+		case "/api/login":
+			var loginRequest api.LoginRequest
+			err := json.NewDecoder(req.Body).Decode(&loginRequest)
+			assert.Check(t, err)
+			if creds := loginRequest.PasswordCredentials; creds != nil {
+				assert.Check(t, creds.Password == "p4ssw0rd")
+				res := &api.LoginResponse{
+					UserID:           uid.New(),
+					Name:             creds.Name,
+					AccessKey:        "abc.xyz",
+					OrganizationName: "Default",
+					Expires:          api.Time(time.Now().UTC().Add(time.Hour * 24)),
+				}
+				err = json.NewEncoder(resp).Encode(res)
+				assert.Check(t, err)
+				return
+			}
+			assert.Assert(t, loginRequest.OIDC != nil)
+			assert.Equal(t, loginRequest.OIDC.ProviderID, providerID)
+			assert.Equal(t, loginRequest.OIDC.Code, "the-code")
+			assert.Equal(t, loginRequest.OIDC.RedirectURL, authQuery.Get("redirect_uri"))
+
+			challenge := sha256.Sum256([]byte(loginRequest.OIDC.CodeVerifier))
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), authQuery.Get("code_challenge"))
+
+			res := &api.LoginResponse{
+				UserID:           uid.New(),
+				Name:             "dana@example.com",
+				AccessKey:        "abc.xyz",
+				OrganizationName: "Default",
+				Expires:          api.Time(time.Now().UTC().Add(time.Hour * 24)),
+			}
+			err = json.NewEncoder(resp).Encode(res)
+			assert.Check(t, err)
+		}
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	// redirect sets the response of the identity provider to the auth URL
+	redirect := func(t *testing.T, response func(query url.Values) url.Values) {
+		t.Helper()
+		orig := openBrowser
+		t.Cleanup(func() {
+			openBrowser = orig
+		})
+		openBrowser = func(authURL string) error {
+			u, err := url.Parse(authURL)
+			assert.Check(t, err)
+			authQuery = u.Query()
+
+			redirectURL := authQuery.Get("redirect_uri") + "?" + response(authQuery).Encode()
+			// nolint:noctx
+			resp, err := http.Get(redirectURL)
+			assert.Check(t, err)
+			return resp.Body.Close()
+		}
+	}
+
+	run := func(provider string) (BufferedStreams, error) {
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--provider", provider, "--no-agent")
+		return bufs, err
+	}
+
+	t.Run("success", func(t *testing.T) {
+		redirect(t, func(query url.Values) url.Values {
+			return url.Values{"code": {"the-code"}, "state": {query.Get("state")}}
+		})
+
+		bufs, err := run("okta")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stderr.String(), "dana@example.com"))
+
+		assert.Equal(t, authQuery.Get("client_id"), "the-client-id")
+		assert.Equal(t, authQuery.Get("response_type"), "code")
+		assert.Equal(t, authQuery.Get("scope"), "openid email")
+		assert.Equal(t, authQuery.Get("code_challenge_method"), "S256")
+		assert.Assert(t, strings.HasPrefix(authQuery.Get("redirect_uri"), "http://127.0.0.1:"))
+	})
+
+	t.Run("callback with an invalid state is ignored", func(t *testing.T) {
+		redirect(t, func(query url.Values) url.Values {
+			return url.Values{"code": {"the-code"}, "state": {query.Get("state")}}
+		})
+		redirectToCLI := openBrowser
+		openBrowser = func(authURL string) error {
+			u, err := url.Parse(authURL)
+			assert.Check(t, err)
+			invalid := url.Values{"error": {"access_denied"}, "state": {"other"}}
+			// nolint:noctx
+			resp, err := http.Get(u.Query().Get("redirect_uri") + "?" + invalid.Encode())
+			assert.Check(t, err)
+			assert.Check(t, resp.Body.Close())
+			assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+			return redirectToCLI(authURL)
+		}
+
+		bufs, err := run("okta")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stderr.String(), "dana@example.com"))
+	})
+
+	t.Run("identity provider error", func(t *testing.T) {
+		redirect(t, func(query url.Values) url.Values {
+			return url.Values{"error": {"access_denied"}, "state": {query.Get("state")}}
+		})
+
+		_, err := run("okta")
+		assert.ErrorContains(t, err, "Login failed: access_denied")
+	})
+
+	t.Run("ldap provider", func(t *testing.T) {
+		_, err := run("directory")
+		assert.ErrorContains(t, err, "Provider directory is an LDAP provider, login with --user")
+	})
+
+	t.Run("provider does not exist", func(t *testing.T) {
+		_, err := run("missing")
+		assert.ErrorContains(t, err, "Provider missing does not exist")
+	})
+
+	// runWithUser runs login with --user in a terminal, to select how to login
+	runWithUser := func(t *testing.T, user string) (expector, *errgroup.Group) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "login", srv.Listener.Addr().String(), "--tls-trusted-fingerprint", certs.Fingerprint(srv.Certificate().Raw), "--user", user, "--no-agent")
+		})
+		return expector{console: console}, g
+	}
+
+	t.Run("provider discovered from the email of the user", func(t *testing.T) {
+		redirect(t, func(query url.Values) url.Values {
+			return url.Values{"code": {"the-code"}, "state": {query.Get("state")}}
+		})
+
+		exp, g := runWithUser(t, "dana@example.com")
+		exp.ExpectString(t, "Select a provider to login with:")
+		exp.ExpectString(t, loginWithPassword)
+		exp.Send(t, "\r")
+		exp.ExpectString(t, "Logging in with provider")
+		exp.ExpectString(t, fmt.Sprintf("Logged in as %s", termenv.String("dana@example.com").Bold().String()))
+
+		assert.NilError(t, g.Wait())
+		assert.Equal(t, authQuery.Get("client_id"), "the-client-id")
+	})
+
+	t.Run("password selected instead of the discovered provider", func(t *testing.T) {
+		redirect(t, func(query url.Values) url.Values {
+			t.Error("unexpected login with the browser")
+			return url.Values{}
+		})
+
+		exp, g := runWithUser(t, "dana@example.com")
+		exp.ExpectString(t, "Select a provider to login with:")
+		exp.ExpectString(t, loginWithPassword)
+		exp.Send(t, string(terminal.KeyArrowDown))
+		exp.Send(t, "\r")
+		exp.ExpectString(t, "Password:")
+		exp.Send(t, "p4ssw0rd\n")
+		exp.ExpectString(t, fmt.Sprintf("Logged in as %s", termenv.String("dana@example.com").Bold().String()))
+
+		assert.NilError(t, g.Wait())
+	})
+
+	t.Run("provider discovered that does not support the CLI", func(t *testing.T) {
+		exp, g := runWithUser(t, "dana@saml.example.com")
+		exp.ExpectString(t, "does not support login with the CLI, logging in with a password")
+		exp.ExpectString(t, "Password:")
+		exp.Send(t, "p4ssw0rd\n")
+		exp.ExpectString(t, fmt.Sprintf("Logged in as %s", termenv.String("dana@saml.example.com").Bold().String()))
+
+		assert.NilError(t, g.Wait())
+	})
+}
+
 func TestLoginCmd_TLSVerify(t *testing.T) {
 	dir := t.TempDir()
 	t.Setenv("HOME", dir)
@@ -663,3 +976,158 @@ func TestLoginCmd_Unauthorized(t *testing.T) {
 		assert.DeepEqual(t, cfg.Hosts, expected, cmpClientHostConfig)
 	})
 }
+
+func TestLoginCmd_MFA(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	kubeconfig := filepath.Join(home, "kubeconfig")
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	name := "admin@local"
+	var enrollmentRequired bool
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError := func(apiError api.Error) {
+			w.WriteHeader(int(apiError.Code))
+			assert.Check(t, json.NewEncoder(w).Encode(apiError))
+		}
+
+		switch r.URL.Path {
+		case "/api/login":
+			var loginRequest api.LoginRequest
+			err := json.NewDecoder(r.Body).Decode(&loginRequest)
+			assert.Check(t, err)
+			assert.Equal(t, loginRequest.PasswordCredentials.Password, "password")
+
+			var loginResponse *api.LoginResponse
+			switch loginRequest.PasswordCredentials.MFACode {
+			case "":
+				if !enrollmentRequired {
+					loginResponse = &api.LoginResponse{MFARequired: true}
+					break
+				}
+				fallthrough
+			case "123456":
+				loginResponse = &api.LoginResponse{
+					UserID:                uid.New(),
+					Name:                  name,
+					AccessKey:             "aaaaaaaaaa.bbbbbbbbbbbbbbbbbbbbbbbb",
+					OrganizationName:      "Default",
+					MFAEnrollmentRequired: enrollmentRequired,
+					Expires:               api.Time(time.Now().UTC().Add(time.Hour * 24)),
+				}
+			default:
+				writeError(api.Error{Code: http.StatusUnauthorized})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			assert.Check(t, json.NewEncoder(w).Encode(loginResponse))
+		case "/api/users/self/mfa":
+			w.WriteHeader(http.StatusCreated)
+			assert.Check(t, json.NewEncoder(w).Encode(api.MFAEnrollment{
+				Secret: "JBSWY3DPEHPK3PXP",
+				URI:    "otpauth://totp/Infra:admin@local?issuer=Infra&secret=JBSWY3DPEHPK3PXP",
+			}))
+		case "/api/users/self/mfa/verify":
+			var req api.VerifyMFAEnrollmentRequest
+			assert.Check(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.Code != "123456" {
+				writeError(api.Error{
+					Code:        http.StatusBadRequest,
+					FieldErrors: []api.FieldError{{FieldName: "code", Errors: []string{"invalid code"}}},
+				})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			assert.Check(t, json.NewEncoder(w).Encode(api.MFARecoveryCodes{
+				RecoveryCodes: []string{"x7kq2-mz9pf", "b3nwt-v8rcd"},
+			}))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("INFRA_USER", name)
+	t.Setenv("INFRA_PASSWORD", "password")
+	t.Setenv("INFRA_SKIP_TLS_VERIFY", "true")
+
+	t.Run("non-interactive without a code", func(t *testing.T) {
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "login", srv.Listener.Addr().String())
+		assert.ErrorContains(t, err, "INFRA_MFA_CODE")
+	})
+
+	t.Run("login with invalid code", func(t *testing.T) {
+		t.Setenv("INFRA_MFA_CODE", "000000")
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "login", srv.Listener.Addr().String())
+		assert.ErrorContains(t, err, "your username, password, or authentication code may be invalid")
+	})
+
+	t.Run("login with code from env", func(t *testing.T) {
+		t.Setenv("INFRA_MFA_CODE", "123456")
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "login", srv.Listener.Addr().String())
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stderr.String(), "Logged in as"))
+	})
+
+	t.Run("login with code prompt", func(t *testing.T) {
+		t.Setenv("INFRA_NON_INTERACTIVE", "false")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "login", srv.Listener.Addr().String(), "--no-agent")
+		})
+
+		exp := expector{console: console}
+		exp.ExpectString(t, "Authentication code:")
+		exp.Send(t, "123456\n")
+		exp.ExpectString(t, fmt.Sprintf("Logged in as %s", termenv.String(name).Bold().String()))
+
+		assert.NilError(t, g.Wait())
+	})
+
+	t.Run("enrollment required", func(t *testing.T) {
+		enrollmentRequired = true
+		t.Cleanup(func() {
+			enrollmentRequired = false
+		})
+		t.Setenv("INFRA_NON_INTERACTIVE", "false")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "login", srv.Listener.Addr().String(), "--no-agent")
+		})
+
+		exp := expector{console: console}
+		exp.ExpectString(t, "Your organization requires multi-factor authentication")
+		exp.ExpectString(t, "JBSWY3DPEHPK3PXP")
+		exp.ExpectString(t, "Authentication code:")
+		exp.Send(t, "000000\n")
+		exp.ExpectString(t, "Invalid code. Please try again.")
+		exp.ExpectString(t, "Authentication code:")
+		exp.Send(t, "123456\n")
+		exp.ExpectString(t, "x7kq2-mz9pf")
+		exp.ExpectString(t, "b3nwt-v8rcd")
+		exp.ExpectString(t, fmt.Sprintf("Logged in as %s", termenv.String(name).Bold().String()))
+
+		assert.NilError(t, g.Wait())
+	})
+}
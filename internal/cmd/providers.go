@@ -180,9 +180,47 @@ type providerAddOptions struct {
 	Kind               string
 	SCIM               bool
 	ProviderAPIOptions providerAPIOptions
+	SAMLOptions        providerSAMLOptions
+	LDAPOptions        providerLDAPOptions
+	GitHubOrgs         []string
+}
+
+type providerSAMLOptions struct {
+	MetadataURL     string
+	Metadata        string
+	GroupsAttribute string
+}
+
+type providerLDAPOptions struct {
+	BindDN        string
+	BindPassword  string
+	BaseDN        string
+	UserFilter    string
+	GroupBaseDN   string
+	StartTLS      bool
+	CACertificate string
 }
 
 func (o providerAddOptions) Validate() error {
+	switch o.Kind {
+	case "saml":
+		return o.validateSAML()
+	case "ldap":
+		return o.validateLDAP()
+	case "github":
+		return o.validateGitHub()
+	}
+
+	if o.SAMLOptions != (providerSAMLOptions{}) {
+		return fmt.Errorf("saml flags are only applicable to SAML identity providers")
+	}
+	if o.LDAPOptions != (providerLDAPOptions{}) {
+		return fmt.Errorf("ldap flags are only applicable to LDAP identity providers")
+	}
+	if len(o.GitHubOrgs) > 0 {
+		return fmt.Errorf("github flags are only applicable to GitHub identity providers")
+	}
+
 	var missing []string
 	if o.URL == "" {
 		missing = append(missing, "url")
@@ -199,6 +237,52 @@ func (o providerAddOptions) Validate() error {
 	return o.ProviderAPIOptions.Validate(o.Kind)
 }
 
+func (o providerAddOptions) validateSAML() error {
+	switch {
+	case o.SAMLOptions.MetadataURL == "" && o.SAMLOptions.Metadata == "":
+		return fmt.Errorf("one of saml-metadata-url or saml-metadata is required for SAML identity providers")
+	case o.SAMLOptions.MetadataURL != "" && o.SAMLOptions.Metadata != "":
+		return fmt.Errorf("only one of saml-metadata-url or saml-metadata can be specified")
+	case o.URL != "" || o.ClientID != "" || o.ClientSecret != "":
+		return fmt.Errorf("url, client-id, and client-secret are not applicable to SAML identity providers")
+	}
+	return o.ProviderAPIOptions.Validate(o.Kind)
+}
+
+func (o providerAddOptions) validateLDAP() error {
+	var missing []string
+	if o.URL == "" {
+		missing = append(missing, "url")
+	}
+	if o.LDAPOptions.BaseDN == "" {
+		missing = append(missing, "ldap-base-dn")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for required flags: %v", strings.Join(missing, ", "))
+	}
+	if o.ClientID != "" || o.ClientSecret != "" {
+		return fmt.Errorf("client-id and client-secret are not applicable to LDAP identity providers")
+	}
+	return o.ProviderAPIOptions.Validate(o.Kind)
+}
+
+func (o providerAddOptions) validateGitHub() error {
+	var missing []string
+	if o.ClientID == "" {
+		missing = append(missing, "client-id")
+	}
+	if o.ClientSecret == "" {
+		missing = append(missing, "client-secret")
+	}
+	if len(o.GitHubOrgs) == 0 {
+		missing = append(missing, "github-org")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for required flags: %v", strings.Join(missing, ", "))
+	}
+	return o.ProviderAPIOptions.Validate(o.Kind)
+}
+
 func newProvidersAddCmd(cli *CLI) *cobra.Command {
 	var opts providerAddOptions
 
@@ -211,7 +295,16 @@ PROVIDER is a short unique name of the identity provider being added (eg. okta)`
 $ infra providers add okta --url example.okta.com --client-id 0oa3sz06o6do0muoW5d7 --client-secret VT_oXtkEDaT7UFY-C3DSRWYb00qyKZ1K1VCq7YzN --kind okta
 
 # Connect Google to Infra with group sync
-$ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0muoW5d7 --client-secret VT_oXtkEDaT7UFY-C3DSRWYb00qyKZ1K1VCq7YzN --service-account-key ~/client-123.json --workspace-domain-admin admin@example.com --kind google`,
+$ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0muoW5d7 --client-secret VT_oXtkEDaT7UFY-C3DSRWYb00qyKZ1K1VCq7YzN --service-account-key ~/client-123.json --workspace-domain-admin admin@example.com --kind google
+
+# Connect a SAML identity provider to Infra using its metadata URL
+$ infra providers add adfs --kind saml --saml-metadata-url https://adfs.example.com/FederationMetadata/2007-06/FederationMetadata.xml
+
+# Connect Active Directory to Infra using LDAPS
+$ infra providers add ad --kind ldap --url ldaps://dc1.example.com --ldap-base-dn "DC=example,DC=com" --ldap-bind-dn "CN=infra,OU=Service Accounts,DC=example,DC=com" --ldap-bind-password p4ssw0rd
+
+# Connect GitHub to Infra, allowing members of the infrahq organization to login
+$ infra providers add github --kind github --client-id 0oa3sz06o6do0muoW5d7 --client-secret VT_oXtkEDaT7UFY-C3DSRWYb00qyKZ1K1VCq7YzN --github-org infrahq`,
 		Args: ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
@@ -233,8 +326,7 @@ $ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0
 				return err
 			}
 
-			logging.Debugf("call server: create provider named %q", args[0])
-			provider, err := client.CreateProvider(ctx, &api.CreateProviderRequest{
+			req := &api.CreateProviderRequest{
 				Name:         args[0],
 				URL:          opts.URL,
 				ClientID:     opts.ClientID,
@@ -245,7 +337,30 @@ $ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0
 					ClientEmail:      opts.ProviderAPIOptions.ClientEmail,
 					DomainAdminEmail: opts.ProviderAPIOptions.WorkspaceDomainAdminEmail,
 				},
-			})
+			}
+			switch opts.Kind {
+			case "saml":
+				req.SAML = &api.ProviderSAML{
+					MetadataURL:     opts.SAMLOptions.MetadataURL,
+					Metadata:        opts.SAMLOptions.Metadata,
+					GroupsAttribute: opts.SAMLOptions.GroupsAttribute,
+				}
+			case "ldap":
+				req.LDAP = &api.ProviderLDAP{
+					BindDN:        opts.LDAPOptions.BindDN,
+					BindPassword:  opts.LDAPOptions.BindPassword,
+					BaseDN:        opts.LDAPOptions.BaseDN,
+					UserFilter:    opts.LDAPOptions.UserFilter,
+					GroupBaseDN:   opts.LDAPOptions.GroupBaseDN,
+					StartTLS:      opts.LDAPOptions.StartTLS,
+					CACertificate: api.PEM(opts.LDAPOptions.CACertificate),
+				}
+			case "github":
+				req.GitHub = &api.ProviderGitHub{Organizations: opts.GitHubOrgs}
+			}
+
+			logging.Debugf("call server: create provider named %q", args[0])
+			provider, err := client.CreateProvider(ctx, req)
 			if err != nil {
 				if api.ErrorStatusCode(err) == 403 {
 					logging.Debugf("%s", err.Error())
@@ -256,7 +371,15 @@ $ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0
 				return err
 			}
 
-			cli.Output("Connected provider %q (%s) to infra", args[0], opts.URL)
+			if opts.Kind == "saml" {
+				cli.Output("Connected provider %q (%s) to infra", args[0], provider.URL)
+				samlURL := fmt.Sprintf("%s/api/providers/%s/saml", strings.TrimSuffix(client.URL, "/"), provider.ID)
+				cli.Output("Configure the identity provider with these service provider settings:")
+				cli.Output("  Metadata URL (entity ID):        %s/metadata", samlURL)
+				cli.Output("  Assertion consumer service URL:  %s/acs", samlURL)
+			} else {
+				cli.Output("Connected provider %q (%s) to infra", args[0], opts.URL)
+			}
 
 			if opts.SCIM {
 				key, err := client.CreateAccessKey(ctx, &api.CreateAccessKeyRequest{
@@ -275,14 +398,25 @@ $ infra providers add google --url accounts.google.com --client-id 0oa3sz06o6do0
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.URL, "url", "", "Base URL of the domain of the OIDC identity provider (eg. acme.okta.com)")
+	cmd.Flags().StringVar(&opts.URL, "url", "", "Base URL of the domain of the OIDC identity provider (eg. acme.okta.com), the URL of the LDAP server (eg. ldaps://dc1.example.com), or the URL of a GitHub Enterprise Server (default github.com)")
 	cmd.Flags().StringVar(&opts.ClientID, "client-id", "", "OIDC client ID")
 	cmd.Flags().StringVar(&opts.ClientSecret, "client-secret", "", "OIDC client secret")
-	cmd.Flags().StringVar(&opts.Kind, "kind", "oidc", "The identity provider kind. One of 'oidc, okta, azure, or google'")
+	cmd.Flags().StringVar(&opts.Kind, "kind", "oidc", "The identity provider kind. One of 'oidc, okta, azure, google, saml, ldap, or github'")
 	cmd.Flags().BoolVar(&opts.SCIM, "scim", false, "Create an access key for SCIM provisioning")
 	cmd.Flags().Var((*types.StringOrFile)(&opts.ProviderAPIOptions.PrivateKey), "service-account-key", "The private key used to make authenticated requests to Google's API, can be a file or the key string directly")
 	cmd.Flags().StringVar(&opts.ProviderAPIOptions.ClientEmail, "service-account-email", "", "The email assigned to the Infra service client in Google") // this is only needed with the private key is not a file
 	cmd.Flags().StringVar(&opts.ProviderAPIOptions.WorkspaceDomainAdminEmail, "workspace-domain-admin", "", "The email of your Google Workspace domain admin")
+	cmd.Flags().StringVar(&opts.SAMLOptions.MetadataURL, "saml-metadata-url", "", "URL of the SAML identity provider metadata")
+	cmd.Flags().Var((*types.StringOrFile)(&opts.SAMLOptions.Metadata), "saml-metadata", "The SAML identity provider metadata, can be a file or the XML string directly")
+	cmd.Flags().StringVar(&opts.SAMLOptions.GroupsAttribute, "saml-groups-attribute", "", "Name of the SAML attribute that lists the groups of a user (default \"groups\")")
+	cmd.Flags().StringVar(&opts.LDAPOptions.BindDN, "ldap-bind-dn", "", "DN of the service account used to search the LDAP directory")
+	cmd.Flags().StringVar(&opts.LDAPOptions.BindPassword, "ldap-bind-password", "", "Password of the LDAP service account")
+	cmd.Flags().StringVar(&opts.LDAPOptions.BaseDN, "ldap-base-dn", "", "DN of the entry to search for LDAP users")
+	cmd.Flags().StringVar(&opts.LDAPOptions.UserFilter, "ldap-user-filter", "", "Filter used to find an LDAP user, {username} is replaced with the username used to login")
+	cmd.Flags().StringVar(&opts.LDAPOptions.GroupBaseDN, "ldap-group-base-dn", "", "DN of the entry to search for LDAP groups (default is the base DN)")
+	cmd.Flags().BoolVar(&opts.LDAPOptions.StartTLS, "ldap-start-tls", false, "Upgrade an ldap:// connection to TLS using StartTLS")
+	cmd.Flags().Var((*types.StringOrFile)(&opts.LDAPOptions.CACertificate), "ldap-ca-cert", "CA certificate used to verify the LDAP server, can be a file or the PEM string directly")
+	cmd.Flags().StringSliceVar(&opts.GitHubOrgs, "github-org", nil, "GitHub organization whose members are allowed to login, can be repeated")
 	return cmd
 }
 
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -237,6 +239,120 @@ func TestProvidersAddCmd(t *testing.T) {
 		assert.ErrorContains(t, err, "missing value for required flags: url, client-id, client-secret")
 	})
 
+	t.Run("saml provider with metadata file", func(t *testing.T) {
+		ch, _ := setup(t)
+
+		metadataFile := filepath.Join(t.TempDir(), "metadata.xml")
+		err := os.WriteFile(metadataFile, []byte("<EntityDescriptor/>"), 0o600)
+		assert.NilError(t, err)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err = Run(ctx,
+			"providers", "add", "adfs",
+			"--kind", "saml",
+			"--saml-metadata", metadataFile,
+			"--saml-groups-attribute", "memberOf",
+		)
+		assert.NilError(t, err)
+
+		createProviderRequest := <-ch
+
+		expected := api.CreateProviderRequest{
+			Name: "adfs",
+			Kind: "saml",
+			API:  &api.ProviderAPICredentials{},
+			SAML: &api.ProviderSAML{
+				Metadata:        "<EntityDescriptor/>",
+				GroupsAttribute: "memberOf",
+			},
+		}
+		assert.DeepEqual(t, createProviderRequest, expected)
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), "/saml/acs"), bufs.Stdout.String())
+	})
+
+	t.Run("saml provider missing metadata", func(t *testing.T) {
+		err := Run(context.Background(), "providers", "add", "adfs", "--kind", "saml")
+		assert.ErrorContains(t, err, "one of saml-metadata-url or saml-metadata is required")
+	})
+
+	t.Run("ldap provider", func(t *testing.T) {
+		ch, _ := setup(t)
+
+		err := Run(context.Background(),
+			"providers", "add", "directory",
+			"--kind", "ldap",
+			"--url", "ldap://dc1.example.com",
+			"--ldap-base-dn", "DC=example,DC=com",
+			"--ldap-bind-dn", "CN=infra,DC=example,DC=com",
+			"--ldap-bind-password", "p4ssw0rd",
+			"--ldap-start-tls",
+		)
+		assert.NilError(t, err)
+
+		createProviderRequest := <-ch
+
+		expected := api.CreateProviderRequest{
+			Name: "directory",
+			URL:  "ldap://dc1.example.com",
+			Kind: "ldap",
+			API:  &api.ProviderAPICredentials{},
+			LDAP: &api.ProviderLDAP{
+				BindDN:       "CN=infra,DC=example,DC=com",
+				BindPassword: "p4ssw0rd",
+				BaseDN:       "DC=example,DC=com",
+				StartTLS:     true,
+			},
+		}
+		assert.DeepEqual(t, createProviderRequest, expected)
+	})
+
+	t.Run("ldap provider missing required flags", func(t *testing.T) {
+		err := Run(context.Background(), "providers", "add", "directory", "--kind", "ldap")
+		assert.ErrorContains(t, err, "missing value for required flags: url, ldap-base-dn")
+	})
+
+	t.Run("github provider", func(t *testing.T) {
+		ch, _ := setup(t)
+
+		err := Run(context.Background(),
+			"providers", "add", "github",
+			"--kind", "github",
+			"--client-id", "aaa",
+			"--client-secret", "bbb",
+			"--github-org", "infrahq",
+			"--github-org", "contractors",
+		)
+		assert.NilError(t, err)
+
+		createProviderRequest := <-ch
+
+		expected := api.CreateProviderRequest{
+			Name:         "github",
+			ClientID:     "aaa",
+			ClientSecret: "bbb",
+			Kind:         "github",
+			API:          &api.ProviderAPICredentials{},
+			GitHub:       &api.ProviderGitHub{Organizations: []string{"infrahq", "contractors"}},
+		}
+		assert.DeepEqual(t, createProviderRequest, expected)
+	})
+
+	t.Run("github provider missing required flags", func(t *testing.T) {
+		err := Run(context.Background(), "providers", "add", "github", "--kind", "github")
+		assert.ErrorContains(t, err, "missing value for required flags: client-id, client-secret, github-org")
+	})
+
+	t.Run("saml flags cannot be specified for non-saml kind", func(t *testing.T) {
+		err := Run(context.Background(),
+			"providers", "add", "okta",
+			"--url", "example.okta.com",
+			"--client-id", "aaa",
+			"--client-secret", "bbb",
+			"--saml-metadata-url", "https://example.com/metadata",
+		)
+		assert.ErrorContains(t, err, "saml flags are only applicable to SAML identity providers")
+	})
+
 	t.Run("list with json", func(t *testing.T) {
 		setup(t)
 
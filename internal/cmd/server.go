@@ -115,9 +115,18 @@ func defaultServerOptions(infraDir string) server.Options {
 			Port: 6379,
 		},
 
+		Login: server.LoginOptions{
+			MaxFailures:      10,
+			MaxFailuresPerIP: 100,
+			Lockout:          time.Minute,
+			MaxLockout:       time.Hour,
+		},
+
 		API: server.APIOptions{
 			RequestTimeout:         time.Minute,
 			BlockingRequestTimeout: 5 * time.Minute,
+			OrganizationQuota:      5000,
+			AccessKeyQuota:         1000,
 		},
 	}
 }
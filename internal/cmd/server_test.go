@@ -209,9 +209,19 @@ redis:
   username: myuser
   password: mypassword
 
+login:
+  maxFailures: 5
+  lockout: 5m
+
+trustedProxies:
+  - 10.0.0.0/8
+  - 192.0.2.7
+
 api:
   requestTimeout: 2m
   blockingRequestTimeout: 4m
+  organizationQuota: 100
+  accessKeyQuota: 10
 
 `
 
@@ -284,9 +294,20 @@ api:
 						Password: "mypassword",
 					},
 
+					Login: server.LoginOptions{
+						MaxFailures:      5,
+						MaxFailuresPerIP: 100,
+						Lockout:          5 * time.Minute,
+						MaxLockout:       time.Hour,
+					},
+
+					TrustedProxies: []string{"10.0.0.0/8", "192.0.2.7"},
+
 					API: server.APIOptions{
 						RequestTimeout:         2 * time.Minute,
 						BlockingRequestTimeout: 4 * time.Minute,
+						OrganizationQuota:      100,
+						AccessKeyQuota:         10,
 					},
 				}
 			},
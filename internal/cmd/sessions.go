@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/format"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
+)
+
+func newSessionsCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sessions",
+		Short:   "Manage login sessions",
+		Aliases: []string{"session"},
+		GroupID: groupManagement,
+	}
+
+	cmd.AddCommand(newSessionsListCmd(cli))
+	cmd.AddCommand(newSessionsRevokeCmd(cli))
+
+	return cmd
+}
+
+// sessionsUserID returns the user to manage sessions for, which is the
+// current user unless userName is set.
+func sessionsUserID(client *api.Client, userName string) (api.IDOrSelf, error) {
+	if userName == "" {
+		return api.IDOrSelf{IsSelf: true}, nil
+	}
+	user, err := getUserByNameOrID(client, userName)
+	if err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.Debugf("%s", err.Error())
+			return api.IDOrSelf{}, Error{
+				Message: "Cannot list sessions: missing privileges for GetUser",
+			}
+		}
+		return api.IDOrSelf{}, err
+	}
+	return api.IDOrSelf{ID: user.ID}, nil
+}
+
+type sessionsListOptions struct {
+	UserName string
+}
+
+func newSessionsListCmd(cli *CLI) *cobra.Command {
+	var options sessionsListOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List login sessions",
+		Long: `List the active login sessions of a user, with the client that last used
+each session.`,
+		Example: `# List your sessions
+$ infra sessions list
+
+# List the sessions of another user
+$ infra sessions list --user janedoe@example.com
+`,
+		Args: NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			userID, err := sessionsUserID(client, options.UserName)
+			if err != nil {
+				return err
+			}
+
+			logging.Debugf("call server: list sessions")
+			sessions, err := listAll(ctx, client.ListSessions, api.ListSessionsRequest{UserID: userID})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.Debugf("%s", err.Error())
+					return Error{
+						Message: "Cannot list sessions: missing privileges for ListSessions",
+					}
+				}
+				return err
+			}
+
+			type row struct {
+				ID        string `header:"ID"`
+				Provider  string `header:"PROVIDER"`
+				ClientIP  string `header:"CLIENT IP"`
+				UserAgent string `header:"USER AGENT"`
+				Created   string `header:"CREATED"`
+				LastUsed  string `header:"LAST USED"`
+			}
+
+			var rows []row
+			for _, session := range sessions {
+				id := session.ID.String()
+				if session.Current {
+					id += " (current)"
+				}
+				provider := session.ProviderName
+				if provider == "" {
+					provider = session.ProviderID.String()
+				}
+				rows = append(rows, row{
+					ID:        id,
+					Provider:  provider,
+					ClientIP:  session.ClientIP,
+					UserAgent: session.UserAgent,
+					Created:   format.HumanTime(session.Created.Time(), "unknown"),
+					LastUsed:  format.HumanTime(session.LastUsed.Time(), "never"),
+				})
+			}
+
+			if len(rows) > 0 {
+				printTable(rows, cli.Stdout)
+			} else {
+				cli.Output("No sessions found")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.UserName, "user", "", "The name of a user to list sessions for")
+	return cmd
+}
+
+type sessionsRevokeOptions struct {
+	UserName string
+	All      bool
+}
+
+func newSessionsRevokeCmd(cli *CLI) *cobra.Command {
+	var options sessionsRevokeOptions
+
+	cmd := &cobra.Command{
+		Use:   "revoke [ID]",
+		Short: "Revoke login sessions",
+		Long: `Revoke a login session by ID, or all the sessions of a user with --all.
+
+When revoking all of your own sessions, the session used by the CLI is kept.
+Use 'infra logout' to end it.`,
+		Example: `# Revoke one of your sessions
+$ infra sessions revoke 4yJ3n3D8E2
+
+# Revoke all of your other sessions
+$ infra sessions revoke --all
+
+# Revoke all the sessions of another user
+$ infra sessions revoke --all --user janedoe@example.com
+`,
+		Args: MaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case options.All && len(args) > 0:
+				return Error{Message: "Cannot use a session ID with --all"}
+			case !options.All && len(args) == 0:
+				return Error{Message: "A session ID or --all is required"}
+			}
+
+			client, err := cli.apiClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+
+			userID, err := sessionsUserID(client, options.UserName)
+			if err != nil {
+				return err
+			}
+
+			if options.All {
+				logging.Debugf("call server: delete sessions")
+				err = client.DeleteSessions(ctx, api.DeleteSessionsRequest{UserID: userID, ExceptCurrent: userID.IsSelf})
+			} else {
+				var sessionID uid.ID
+				sessionID, err = uid.Parse([]byte(args[0]))
+				if err != nil {
+					return Error{Message: fmt.Sprintf("Invalid session ID %q", args[0])}
+				}
+				logging.Debugf("call server: delete session %v", sessionID)
+				err = client.DeleteSession(ctx, api.DeleteSessionRequest{UserID: userID, SessionID: sessionID})
+			}
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.Debugf("%s", err.Error())
+					return Error{
+						Message: "Cannot revoke sessions: missing privileges for DeleteSessions",
+					}
+				}
+				if api.ErrorStatusCode(err) == 404 && !options.All {
+					return Error{Message: fmt.Sprintf("Session %q does not exist", args[0])}
+				}
+				return err
+			}
+
+			switch {
+			case !options.All:
+				cli.Output("Revoked session %q", args[0])
+			case userID.IsSelf:
+				cli.Output("Revoked all other sessions")
+			default:
+				cli.Output("Revoked all sessions for %q", options.UserName)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.UserName, "user", "", "The name of the user who owns the sessions")
+	cmd.Flags().BoolVar(&options.All, "all", false, "Revoke all sessions")
+	return cmd
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestSessionsCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	base := time.Now().Add(-24 * time.Hour)
+
+	setup := func(t *testing.T) chan *http.Request {
+		requestCh := make(chan *http.Request, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			// the command does a lookup for user ID
+			if requestMatches(req, http.MethodGet, "/api/users") {
+				if req.URL.Query().Get("name") != "my-user" {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{
+						{ID: uid.ID(12345678)},
+					},
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			if !strings.Contains(req.URL.Path, "/sessions") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			requestCh <- req
+			if req.Method == http.MethodDelete {
+				resp.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			resp.WriteHeader(http.StatusOK)
+			err := json.NewEncoder(resp).Encode(api.ListResponse[api.Session]{
+				Count: 2,
+				Items: []api.Session{
+					{
+						ID:           uid.ID(1001),
+						Created:      api.Time(base),
+						LastUsed:     api.Time(base.Add(2 * time.Hour)),
+						ProviderID:   uid.ID(4),
+						ProviderName: "okta",
+						LoginMethod:  "oidc",
+						ClientIP:     "192.0.2.10",
+						UserAgent:    "Mozilla/5.0",
+						Current:      true,
+					},
+					{
+						ID:          uid.ID(1002),
+						Created:     api.Time(base.Add(time.Hour)),
+						ProviderID:  uid.ID(5),
+						LoginMethod: "credentials",
+						ClientIP:    "198.51.100.7",
+						UserAgent:   "Infra CLI/0.21.0",
+					},
+				},
+			})
+			assert.Check(t, err)
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("list", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "list")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/self/sessions")
+		golden.Assert(t, bufs.Stdout.String(), t.Name())
+	})
+
+	t.Run("list for another user", func(t *testing.T) {
+		ch := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "list", "--user=my-user")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/"+uid.ID(12345678).String()+"/sessions")
+	})
+
+	t.Run("revoke one", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", uid.ID(1002).String())
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodDelete)
+		assert.Equal(t, req.URL.Path, "/api/users/self/sessions/"+uid.ID(1002).String())
+		assert.Equal(t, bufs.Stdout.String(), `Revoked session "`+uid.ID(1002).String()+"\"\n")
+	})
+
+	t.Run("revoke all of your own sessions keeps the current one", func(t *testing.T) {
+		ch := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", "--all")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/self/sessions")
+		assert.Equal(t, req.URL.Query().Get("exceptCurrent"), "true")
+	})
+
+	t.Run("revoke all for another user", func(t *testing.T) {
+		ch := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", "--all", "--user=my-user")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/"+uid.ID(12345678).String()+"/sessions")
+		assert.Equal(t, req.URL.Query().Get("exceptCurrent"), "false")
+	})
+
+	t.Run("revoke requires an ID or --all", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "sessions", "revoke")
+		assert.ErrorContains(t, err, "A session ID or --all is required")
+
+		err = Run(context.Background(), "sessions", "revoke", "--all", uid.ID(1002).String())
+		assert.ErrorContains(t, err, "Cannot use a session ID with --all")
+	})
+}
@@ -19,7 +19,7 @@ import (
 
 type authenticator struct {
 	mu          sync.Mutex
-	key         *jose.JSONWebKey
+	keys        []jose.JSONWebKey
 	lastChecked time.Time
 
 	client          httpClient
@@ -42,6 +42,11 @@ func newAuthenticator(options Options) *authenticator {
 
 var JWKCacheRefresh = 5 * time.Minute
 
+// jwkUnknownKeyRefresh is the minimum time between requests for the keys when
+// a token is signed by a key that is not in the cache, which happens after the
+// server rotates its signing key.
+var jwkUnknownKeyRefresh = 10 * time.Second
+
 func (j *authenticator) Authenticate(req *http.Request) (claims.Custom, error) {
 	c := claims.Custom{}
 	authHeader := req.Header.Get("Authorization")
@@ -56,7 +61,12 @@ func (j *authenticator) Authenticate(req *http.Request) (claims.Custom, error) {
 		return c, fmt.Errorf("invalid JWT signature: %w", err)
 	}
 
-	key, err := j.getJWK()
+	var kid string
+	if len(tok.Headers) > 0 {
+		kid = tok.Headers[0].KeyID
+	}
+
+	keys, err := j.getJWK(kid)
 	if err != nil {
 		return c, fmt.Errorf("get JWK from server: %w", err)
 	}
@@ -65,7 +75,12 @@ func (j *authenticator) Authenticate(req *http.Request) (claims.Custom, error) {
 		jwt.Claims
 		claims.Custom
 	}
-	if err := tok.Claims(key, &allClaims); err != nil {
+	for _, key := range keys {
+		if err = tok.Claims(key, &allClaims); err == nil {
+			break
+		}
+	}
+	if err != nil {
 		return c, fmt.Errorf("invalid token claims: %w", err)
 	}
 
@@ -84,12 +99,21 @@ func (j *authenticator) Authenticate(req *http.Request) (claims.Custom, error) {
 	return allClaims.Custom, nil
 }
 
-func (j *authenticator) getJWK() (*jose.JSONWebKey, error) {
+// getJWK returns the keys from the server with the kid, or all the keys when
+// kid is empty. The keys are cached, and fetched again when the cache is old,
+// or when there is no key with the kid.
+func (j *authenticator) getJWK(kid string) ([]jose.JSONWebKey, error) {
 	j.mu.Lock()
 	defer j.mu.Unlock()
 
 	if !j.lastChecked.IsZero() && time.Now().Before(j.lastChecked.Add(JWKCacheRefresh)) {
-		return j.key, nil
+		keys := keysWithID(j.keys, kid)
+		if len(keys) > 0 {
+			return keys, nil
+		}
+		if time.Now().Before(j.lastChecked.Add(jwkUnknownKeyRefresh)) {
+			return nil, fmt.Errorf("no jwk with key ID %q", kid)
+		}
 	}
 
 	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, fmt.Sprintf("%s/.well-known/jwks.json", j.baseURL), nil)
@@ -127,7 +151,24 @@ func (j *authenticator) getJWK() (*jose.JSONWebKey, error) {
 	}
 
 	j.lastChecked = time.Now().UTC()
-	j.key = &response.Keys[0]
+	j.keys = response.Keys
 
-	return &response.Keys[0], nil
+	keys := keysWithID(j.keys, kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no jwk with key ID %q", kid)
+	}
+	return keys, nil
+}
+
+// keysWithID returns the keys with the kid, or all the keys when kid is empty.
+func keysWithID(keys []jose.JSONWebKey, kid string) []jose.JSONWebKey {
+	if kid == "" {
+		return keys
+	}
+	for _, key := range keys {
+		if key.KeyID == kid {
+			return []jose.JSONWebKey{key}
+		}
+	}
+	return nil
 }
@@ -229,7 +229,7 @@ func runKubernetesConnector(ctx context.Context, options Options) error {
 		}
 		waiter := repeat.NewWaiter(backOff)
 		fn := func(ctx context.Context, grants []api.Grant) error {
-			return updateRoles(ctx, con.client, con.k8s, grants)
+			return updateRoles(ctx, con.client, con.k8s, con.destination.Name, grants)
 		}
 		return syncGrantsToDestination(ctx, con, waiter, fn)
 	})
@@ -545,8 +545,10 @@ func syncGrantsToDestination(
 	}
 }
 
-// UpdateRoles converts infra grants to role-bindings in the current cluster
-func updateRoles(ctx context.Context, c apiClient, k kubeClient, grants []api.Grant) error {
+// UpdateRoles converts infra grants to role-bindings in the current cluster.
+// Grants with a resource that does not match the destination, either exactly
+// or as a pattern, are ignored.
+func updateRoles(ctx context.Context, c apiClient, k kubeClient, destination string, grants []api.Grant) error {
 	logging.Debugf("syncing local grants from infra configuration")
 
 	crSubjects := make(map[string][]rbacv1.Subject)                           // cluster-role: subject
@@ -559,6 +561,11 @@ func updateRoles(ctx context.Context, c apiClient, k kubeClient, grants []api.Gr
 			continue
 		}
 
+		if !api.GrantMatchesDestination(g.Resource, destination) {
+			logging.Debugf("skipping grant for another destination: %s", g.Resource)
+			continue
+		}
+
 		switch {
 		case g.Group != 0:
 			group, err := c.GetGroup(ctx, g.Group)
@@ -144,6 +144,40 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 	}
 }
 
+func TestAuthenticator_Authenticate_KeyRotation(t *testing.T) {
+	pub, priv := generateJWK(t)
+	newPub, newPriv := generateJWK(t)
+
+	opts := Options{
+		Server: ServerOptions{SkipTLSVerify: true, AccessKey: "the-access-key"},
+	}
+	assert.NilError(t, opts.Server.URL.Set("https://127.0.0.1:12345"))
+	authn := newAuthenticator(opts)
+	authn.client = fakeClient{key: *pub}
+
+	authenticate := func(t *testing.T, priv *jose.JSONWebKey) error {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/apis", nil)
+		req.Header.Set("Authorization", "Bearer "+generateJWT(t, priv, "test@example.com", time.Now().Add(time.Hour)))
+		_, err := authn.Authenticate(req)
+		return err
+	}
+
+	assert.NilError(t, authenticate(t, priv))
+
+	// the server rotates the key
+	authn.client = fakeClient{keys: []jose.JSONWebKey{*newPub, *pub}}
+
+	// the keys were fetched too recently to fetch them again
+	err := authenticate(t, newPriv)
+	assert.ErrorContains(t, err, "no jwk with key ID")
+
+	authn.lastChecked = time.Now().Add(-time.Minute)
+	assert.NilError(t, authenticate(t, newPriv))
+	// tokens signed by the previous key are still valid
+	assert.NilError(t, authenticate(t, priv))
+}
+
 func generateJWK(t *testing.T) (pub *jose.JSONWebKey, priv *jose.JSONWebKey) {
 	t.Helper()
 	pubkey, key, err := ed25519.GenerateKey(rand.Reader)
@@ -182,6 +216,7 @@ func generateJWT(t *testing.T, priv *jose.JSONWebKey, email string, expiry time.
 
 type fakeClient struct {
 	key        jose.JSONWebKey
+	keys       []jose.JSONWebKey // used instead of key when set
 	err        error
 	statusCode int
 }
@@ -192,6 +227,9 @@ func (f fakeClient) Do(req *http.Request) (*http.Response, error) {
 	}
 
 	r := server.WellKnownJWKResponse{Keys: []jose.JSONWebKey{f.key}}
+	if len(f.keys) > 0 {
+		r.Keys = f.keys
+	}
 
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(r)
@@ -276,7 +314,7 @@ func TestSyncGrantsToDestination_KubeBindings(t *testing.T) {
 		}
 
 		fn := func(ctx context.Context, grants []api.Grant) error {
-			return updateRoles(ctx, con.client, con.k8s, grants)
+			return updateRoles(ctx, con.client, con.k8s, con.destination.Name, grants)
 		}
 		err := syncGrantsToDestination(ctx, con, waiter, fn)
 		assert.ErrorIs(t, err, errDone)
@@ -291,8 +329,8 @@ func TestSyncGrantsToDestination_KubeBindings(t *testing.T) {
 			fakeAPI: &fakeAPIClient{
 				listGrantsResult: &api.ListResponse[api.Grant]{
 					Items: []api.Grant{
-						{User: uid.ID(123), Resource: "the-test", Privilege: "view"},
-						{User: uid.ID(124), Resource: "the-test.ns1", Privilege: "logs"},
+						{User: uid.ID(123), Resource: "the-dest", Privilege: "view"},
+						{User: uid.ID(124), Resource: "the-dest.ns1", Privilege: "logs"},
 					},
 					LastUpdateIndex: api.LastUpdateIndex{Index: 42},
 				},
@@ -320,7 +358,7 @@ func TestSyncGrantsToDestination_KubeBindings(t *testing.T) {
 			fakeAPI: &fakeAPIClient{
 				listGrantsResult: &api.ListResponse[api.Grant]{
 					Items: []api.Grant{
-						{User: uid.ID(123), Resource: "the-test", Privilege: "view"},
+						{User: uid.ID(123), Resource: "the-dest", Privilege: "view"},
 					},
 					LastUpdateIndex: api.LastUpdateIndex{Index: 42},
 				},
@@ -339,6 +377,35 @@ func TestSyncGrantsToDestination_KubeBindings(t *testing.T) {
 	}
 }
 
+func TestUpdateRoles(t *testing.T) {
+	ctx := context.Background()
+	fakeAPI := &fakeAPIClient{}
+	fakeKube := &fakeKubeClient{}
+
+	grants := []api.Grant{
+		{User: uid.ID(123), Resource: "prod-us-1", Privilege: "view"},
+		{User: uid.ID(123), Resource: "prod-*", Privilege: "edit"},
+		{Group: uid.ID(124), Resource: "*-us-*.monitoring", Privilege: "admin"},
+		{User: uid.ID(123), Resource: "staging-*", Privilege: "admin"},
+		{User: uid.ID(123), Resource: "prod-*", Privilege: "connect"},
+	}
+	err := updateRoles(ctx, fakeAPI, fakeKube, "prod-us-1", grants)
+	assert.NilError(t, err)
+
+	user := rbacv1.Subject{APIGroup: "rbac.authorization.k8s.io", Kind: rbacv1.UserKind, Name: "theuser@example.com"}
+	group := rbacv1.Subject{APIGroup: "rbac.authorization.k8s.io", Kind: rbacv1.GroupKind, Name: "the-group"}
+
+	expectedClusterRoleBindings := []map[string][]rbacv1.Subject{
+		{"view": {user}, "edit": {user}},
+	}
+	assert.DeepEqual(t, fakeKube.updateClusterRoleBindingsArgs, expectedClusterRoleBindings)
+
+	expectedRoleBindings := []map[kubernetes.ClusterRoleNamespace][]rbacv1.Subject{
+		{{ClusterRole: "admin", Namespace: "monitoring"}: {group}},
+	}
+	assert.DeepEqual(t, fakeKube.updateRoleBindingsArgs, expectedRoleBindings)
+}
+
 type fakeWaiter struct {
 	index      int
 	resets     []int
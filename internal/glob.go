@@ -0,0 +1,27 @@
+package internal
+
+import "strings"
+
+// MatchGlob returns true if s matches pattern, where each * in pattern
+// matches any sequence of characters. All other characters in pattern must
+// match exactly.
+func MatchGlob(pattern, s string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == s
+	}
+
+	parts := strings.Split(pattern, "*")
+	first, last := parts[0], parts[len(parts)-1]
+	if !strings.HasPrefix(s, first) {
+		return false
+	}
+	remaining := s[len(first):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(remaining, part)
+		if i < 0 {
+			return false
+		}
+		remaining = remaining[i+len(part):]
+	}
+	return strings.HasSuffix(remaining, last)
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	type testCase struct {
+		pattern  string
+		value    string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{pattern: "prod", value: "prod", expected: true},
+		{pattern: "prod", value: "prod-us-1", expected: false},
+		{pattern: "prod-*", value: "prod-us-1", expected: true},
+		{pattern: "prod-*", value: "prod-", expected: true},
+		{pattern: "prod-*", value: "staging-us-1", expected: false},
+		{pattern: "*-us-*", value: "prod-us-1", expected: true},
+		{pattern: "*-us-*", value: "prod-eu-1", expected: false},
+		{pattern: "*-1", value: "prod-us-1", expected: true},
+		{pattern: "a*a", value: "a", expected: false},
+		{pattern: "repo:infrahq/*:environment:production", value: "repo:infrahq/infra:environment:production", expected: true},
+		{pattern: "repo:infrahq/*:environment:production", value: "repo:infrahq/infra:environment:staging", expected: false},
+		{pattern: "*", value: "anything", expected: true},
+	}
+
+	for _, tc := range testCases {
+		actual := MatchGlob(tc.pattern, tc.value)
+		assert.Equal(t, actual, tc.expected, "pattern=%v value=%v", tc.pattern, tc.value)
+	}
+}
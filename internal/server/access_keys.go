@@ -44,6 +44,7 @@ func (a *API) CreateAccessKey(rCtx access.RequestContext, r *api.CreateAccessKey
 		ExpiresAt:           time.Now().UTC().Add(time.Duration(r.Expiry)),
 		InactivityExtension: time.Duration(r.InactivityTimeout),
 		InactivityTimeout:   time.Now().UTC().Add(time.Duration(r.InactivityTimeout)),
+		Scopes:              r.Scopes,
 	}
 
 	raw, err := access.CreateAccessKey(rCtx, accessKey)
@@ -59,6 +60,7 @@ func (a *API) CreateAccessKey(rCtx access.RequestContext, r *api.CreateAccessKey
 		IssuedForKind:     accessKey.IssuedForKind.String(),
 		Expires:           api.Time(accessKey.ExpiresAt),
 		InactivityTimeout: api.Time(accessKey.InactivityTimeout),
+		Scopes:            accessKey.Scopes,
 		AccessKey:         raw,
 	}, nil
 }
@@ -160,7 +160,8 @@ func TestAPI_CreateAccessKey(t *testing.T) {
 						"inactivityTimeout": "%[3]v",
 						"accessKey": "<any-valid-access-key>",
 						"name": "connector",
-						"providerID": ""
+						"providerID": "",
+						"scopes": null
 					}`,
 					connector.ID,
 					time.Now().UTC().Format(time.RFC3339),
@@ -194,7 +195,8 @@ func TestAPI_CreateAccessKey(t *testing.T) {
 						"inactivityTimeout": "%[3]v",
 						"accessKey": "<any-valid-access-key>",
 						"name": "user",
-						"providerID": ""
+						"providerID": "",
+						"scopes": null
 					}`,
 					user.ID,
 					time.Now().UTC().Format(time.RFC3339),
@@ -227,7 +229,8 @@ func TestAPI_CreateAccessKey(t *testing.T) {
 						"inactivityTimeout": "%[4]v",
 						"accessKey": "<any-valid-access-key>",
 						"name": "%[2]s-<any-string>",
-						"providerID": ""
+						"providerID": "",
+						"scopes": null
 					}`,
 					admin.ID,
 					admin.Name,
@@ -261,7 +264,8 @@ func TestAPI_CreateAccessKey(t *testing.T) {
 						"inactivityTimeout": "%[4]v",
 						"accessKey": "<any-valid-access-key>",
 						"name": "%[2]s-<any-string>",
-						"providerID": ""
+						"providerID": "",
+						"scopes": null
 					}`,
 					user.ID,
 					user.Name,
@@ -292,6 +296,75 @@ func TestAPI_CreateAccessKey(t *testing.T) {
 				assert.DeepEqual(t, respBody.Message, "you do not have permission to create access key, requires role admin")
 			},
 		},
+		{
+			name: "create access key with scopes",
+			setup: func(t *testing.T) (io.Reader, string) {
+				return jsonBody(t, &api.CreateAccessKeyRequest{
+					IssuedForID:       user.ID,
+					IssuedForKind:     api.KeyIssuedForKindUser,
+					Name:              "ci",
+					Expiry:            api.Duration(time.Minute),
+					InactivityTimeout: api.Duration(time.Minute),
+					Scopes:            []string{"grants:read", "groups:write:" + user.ID.String()},
+				}), userAccessKey
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+				respBody := &api.CreateAccessKeyResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, respBody.Scopes, []string{"grants:read", "groups:write:" + user.ID.String()})
+			},
+		},
+		{
+			name: "invalid scope",
+			setup: func(t *testing.T) (io.Reader, string) {
+				return jsonBody(t, &api.CreateAccessKeyRequest{
+					IssuedForID:       user.ID,
+					IssuedForKind:     api.KeyIssuedForKindUser,
+					Expiry:            api.Duration(time.Minute),
+					InactivityTimeout: api.Duration(time.Minute),
+					Scopes:            []string{"create-key"},
+				}), userAccessKey
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+				respBody := &api.Error{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+
+				expected := []api.FieldError{
+					{FieldName: "scopes", Errors: []string{`scope "create-key" must be in the form resource:action or resource:action:id`}},
+				}
+				assert.DeepEqual(t, respBody.FieldErrors, expected)
+			},
+		},
+		{
+			name: "access key with scopes can not create access keys",
+			setup: func(t *testing.T) (io.Reader, string) {
+				scopedKey := &models.AccessKey{
+					IssuedForID:   admin.ID,
+					IssuedForKind: models.IssuedForKindUser,
+					ExpiresAt:     time.Now().Add(time.Hour),
+					Scopes:        []string{"access-keys:write"},
+				}
+				scopedAccessKey, err := data.CreateAccessKey(srv.DB(), scopedKey)
+				assert.NilError(t, err)
+
+				return jsonBody(t, &api.CreateAccessKeyRequest{
+					IssuedForID:       connector.ID,
+					IssuedForKind:     api.KeyIssuedForKindOrganization,
+					Expiry:            api.Duration(time.Minute),
+					InactivityTimeout: api.Duration(time.Minute),
+				}), scopedAccessKey
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+				assert.Assert(t, strings.Contains(resp.Body.String(), "cannot use an access key with scopes to create other access keys"))
+			},
+		},
 		{
 			name: "migration from <= 0.18.0",
 			setup: func(t *testing.T) (io.Reader, string) {
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func (a *API) ListAccessRequests(rCtx access.RequestContext, r *api.ListAccessRequestsRequest) (*api.ListResponse[api.AccessRequest], error) {
+	p := PaginationFromRequest(r.PaginationRequest)
+	opts := data.ListAccessRequestsOptions{
+		ByUserID:   r.UserID,
+		ByStatus:   models.AccessRequestStatus(r.Status),
+		Pagination: &p,
+	}
+	requests, err := access.ListAccessRequests(rCtx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(requests, PaginationToResponse(p), func(req models.AccessRequest) api.AccessRequest {
+		return *req.ToAPI()
+	})
+	return result, nil
+}
+
+func (a *API) GetAccessRequest(rCtx access.RequestContext, r *api.Resource) (*api.AccessRequest, error) {
+	req, err := access.GetAccessRequest(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return req.ToAPI(), nil
+}
+
+func (a *API) CreateAccessRequest(rCtx access.RequestContext, r *api.CreateAccessRequestRequest) (*api.AccessRequest, error) {
+	req := &models.AccessRequest{
+		Privilege:     r.Privilege,
+		Resource:      r.Resource,
+		Justification: r.Justification,
+		Duration:      time.Duration(r.Duration),
+	}
+	if err := access.CreateAccessRequest(rCtx, req); err != nil {
+		return nil, err
+	}
+	return req.ToAPI(), nil
+}
+
+func (a *API) ApproveAccessRequest(rCtx access.RequestContext, r *api.Resource) (*api.AccessRequest, error) {
+	req, err := access.ApproveAccessRequest(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return req.ToAPI(), nil
+}
+
+func (a *API) DenyAccessRequest(rCtx access.RequestContext, r *api.Resource) (*api.AccessRequest, error) {
+	req, err := access.DenyAccessRequest(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return req.ToAPI(), nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_AccessRequests(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	user := &models.Identity{Name: "requester@example.com"}
+	other := &models.Identity{Name: "other@example.com"}
+	createIdentities(t, srv.DB(), user, other)
+
+	userKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: user.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	otherKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: other.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	do := func(t *testing.T, method, path, key string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var req *http.Request
+		if body != nil {
+			req = httptest.NewRequest(method, path, jsonBody(t, body))
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	createRequest := func(t *testing.T, resource string) api.AccessRequest {
+		t.Helper()
+		resp := do(t, http.MethodPost, "/api/access-requests", userKey, api.CreateAccessRequestRequest{
+			Privilege:     "view",
+			Resource:      resource,
+			Justification: "incident 1234",
+			Duration:      api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.AccessRequest
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&created))
+		return created
+	}
+
+	t.Run("create", func(t *testing.T) {
+		created := createRequest(t, "production")
+		assert.Equal(t, created.UserID, user.ID)
+		assert.Equal(t, created.Status, api.AccessRequestStatusPending)
+		assert.Equal(t, created.Duration, api.Duration(time.Hour))
+	})
+
+	t.Run("create missing justification", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/api/access-requests", userKey, api.CreateAccessRequestRequest{
+			Privilege: "view",
+			Resource:  "production",
+		})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("list own requests", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/access-requests?userID="+user.ID.String(), userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var actual api.ListResponse[api.AccessRequest]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		assert.Assert(t, len(actual.Items) > 0)
+	})
+
+	t.Run("list requests of another user", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/access-requests?userID="+user.ID.String(), otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("get request of another user", func(t *testing.T) {
+		created := createRequest(t, "staging")
+		resp := do(t, http.MethodGet, "/api/access-requests/"+created.ID.String(), otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("approve requires admin", func(t *testing.T) {
+		created := createRequest(t, "staging.web")
+		resp := do(t, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/approve", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("approve creates grant", func(t *testing.T) {
+		created := createRequest(t, "development")
+		resp := do(t, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/approve", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var approved api.AccessRequest
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&approved))
+		assert.Equal(t, approved.Status, api.AccessRequestStatusApproved)
+		assert.Assert(t, approved.GrantID != 0)
+		assert.Assert(t, approved.ReviewedBy != 0)
+
+		grant, err := data.GetGrant(srv.DB(), data.GetGrantOptions{ByID: approved.GrantID})
+		assert.NilError(t, err)
+		assert.Equal(t, grant.Subject, models.NewSubjectForUser(user.ID))
+		assert.Equal(t, grant.Privilege, "view")
+		assert.Equal(t, grant.Resource, "development")
+		assert.Assert(t, !grant.ExpiresAt.IsZero())
+
+		// a request can only be reviewed once
+		resp = do(t, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/deny", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		created := createRequest(t, "sandbox")
+		resp := do(t, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/deny", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var denied api.AccessRequest
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&denied))
+		assert.Equal(t, denied.Status, api.AccessRequestStatusDenied)
+		assert.Equal(t, denied.GrantID, uid.ID(0))
+
+		_, err := data.GetGrant(srv.DB(), data.GetGrantOptions{
+			BySubject:   models.NewSubjectForUser(user.ID),
+			ByPrivilege: "view",
+			ByResource:  "sandbox",
+		})
+		assert.ErrorContains(t, err, "not found")
+	})
+}
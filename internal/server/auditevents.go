@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func (a *API) ListAuditEvents(rCtx access.RequestContext, r *api.ListAuditEventsRequest) (*api.ListResponse[api.AuditEvent], error) {
+	p := PaginationFromRequest(r.PaginationRequest)
+	opts := data.ListAuditEventsOptions{
+		ByActorID:  r.ActorID,
+		ByTargetID: r.TargetID,
+		ByRoute:    r.Route,
+		ByOutcome:  models.AuditEventOutcome(r.Outcome),
+		Pagination: &p,
+	}
+	events, err := access.ListAuditEvents(rCtx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(events, PaginationToResponse(p), func(event models.AuditEvent) api.AuditEvent {
+		return *event.ToAPI()
+	})
+	return result, nil
+}
+
+// createAuditEventInNewTxn records event in its own transaction. It is used
+// when the transaction for the request is rolled back. Errors are logged
+// because the request has already failed.
+func createAuditEventInNewTxn(db *data.DB, event *models.AuditEvent) {
+	if event.OrganizationID == 0 {
+		logAuditEvent(event)
+		return
+	}
+	tx, err := db.Begin(context.Background(), nil)
+	if err != nil {
+		logging.L.Warn().Err(err).Msg("failed to start audit event transaction")
+		return
+	}
+	defer logError(tx.Rollback, "failed to rollback audit event transaction")
+
+	if err := data.CreateAuditEvent(tx.WithOrgID(event.OrganizationID), event); err != nil {
+		logging.L.Warn().Err(err).Msg("failed to create audit event")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logging.L.Warn().Err(err).Msg("failed to commit audit event")
+	}
+}
+
+// logAuditEvent writes an audit event that does not belong to any
+// organization, like a failed signup, to the server log. These events can not
+// be stored, because every stored event is listed by an organization.
+func logAuditEvent(event *models.AuditEvent) {
+	logging.L.Info().
+		Str("method", event.Method).
+		Str("route", event.Route).
+		Str("outcome", string(event.Outcome)).
+		Int("statusCode", event.StatusCode).
+		Msg("audit event without an organization")
+}
+
+// newAuditEvent builds an audit event for a call to the route. handlerErr is
+// the error returned by the route handler, or nil if the call was successful.
+//
+// Routes that authenticate the user, like login and signup, are called
+// without a user. The actor of those events, and the organization for
+// signup, are read from the response.
+func newAuditEvent(rCtx access.RequestContext, routeID routeIdentifier, req, resp any, handlerErr error) *models.AuditEvent {
+	event := &models.AuditEvent{
+		Method:     routeID.method,
+		Route:      routeID.path,
+		TargetID:   targetIDFromValue(req),
+		Request:    redactedRequestSummary(req),
+		Outcome:    models.AuditEventOutcomeSuccess,
+		StatusCode: responseStatusCode(routeID.method, resp),
+	}
+	if org := rCtx.Authenticated.Organization; org != nil {
+		event.OrganizationID = org.ID
+	}
+	if user := rCtx.Authenticated.User; user != nil {
+		event.ActorID = user.ID
+		event.ActorName = user.Name
+	}
+	if handlerErr == nil {
+		switch r := resp.(type) {
+		case *api.LoginResponse:
+			if event.ActorID == 0 && r.UserID != 0 {
+				event.ActorID = r.UserID
+				event.ActorName = r.Name
+			}
+		case *api.SignupResponse:
+			if event.OrganizationID == 0 && r.Organization != nil {
+				event.OrganizationID = r.Organization.ID
+			}
+			if event.ActorID == 0 && r.User != nil {
+				event.ActorID = r.User.ID
+				event.ActorName = r.User.Name
+			}
+		}
+	}
+	if event.TargetID == 0 && handlerErr == nil {
+		event.TargetID = targetIDFromValue(resp)
+	}
+	if handlerErr != nil {
+		apiError, _ := apiErrorFromError(http.Header{}, handlerErr)
+		event.Outcome = models.AuditEventOutcomeFailure
+		event.StatusCode = int(apiError.Code)
+	}
+	return event
+}
+
+var reflectTypeUID = reflect.TypeOf(uid.ID(0))
+
+// targetIDFromValue returns the value of the ID field of v, or 0 if v is not
+// a struct with an ID field.
+func targetIDFromValue(v any) uid.ID {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return 0
+	}
+	structField, ok := value.Type().FieldByName("ID")
+	if !ok || structField.Type != reflectTypeUID {
+		return 0
+	}
+	// the field may be promoted from a nil embedded struct pointer
+	field, err := value.FieldByIndexErr(structField.Index)
+	if err != nil {
+		return 0
+	}
+	return uid.ID(field.Int())
+}
+
+// maxRequestSummaryLength limits the size of the request stored in an audit
+// event, so that large requests do not fill the audit log.
+const maxRequestSummaryLength = 2048
+
+const redacted = "REDACTED"
+
+// redactedRequestSummary returns req encoded as JSON, with the value of any
+// field that may contain a credential replaced by a placeholder.
+func redactedRequestSummary(req any) string {
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	var fields any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ""
+	}
+	raw, err = json.Marshal(redactSensitiveFields(fields))
+	if err != nil {
+		return ""
+	}
+	if len(raw) > maxRequestSummaryLength {
+		return string(raw[:maxRequestSummaryLength])
+	}
+	return string(raw)
+}
+
+func redactSensitiveFields(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, field := range value {
+			switch field.(type) {
+			case map[string]any, []any:
+				value[key] = redactSensitiveFields(field)
+			case nil:
+			default:
+				if isSensitiveField(key) {
+					value[key] = redacted
+				}
+			}
+		}
+	case []any:
+		for i := range value {
+			value[i] = redactSensitiveFields(value[i])
+		}
+	}
+	return v
+}
+
+var sensitiveFieldNames = []string{"password", "secret", "token", "accesskey", "privatekey", "code"}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveFieldNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
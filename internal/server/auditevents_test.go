@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_ListAuditEvents(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	user := &models.Identity{Name: "user@example.com"}
+	createIdentities(t, srv.DB(), user)
+
+	userKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: user.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	do := func(t *testing.T, method, path, key string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var req *http.Request
+		if body != nil {
+			req = httptest.NewRequest(method, path, jsonBody(t, body))
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	listEvents := func(t *testing.T, query string) []api.AuditEvent {
+		t.Helper()
+		resp := do(t, http.MethodGet, "/api/audit-events?"+query, adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var actual api.ListResponse[api.AuditEvent]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		return actual.Items
+	}
+
+	t.Run("successful call is recorded", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/api/grants", adminAccessKey(srv), api.GrantRequest{
+			User:      user.ID,
+			Privilege: "view",
+			Resource:  "production",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var grant api.CreateGrantResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&grant))
+
+		events := listEvents(t, "targetID="+grant.ID.String())
+		assert.Equal(t, len(events), 1)
+		event := events[0]
+		assert.Equal(t, event.Method, http.MethodPost)
+		assert.Equal(t, event.Route, "/api/grants")
+		assert.Equal(t, event.ActorName, "admin@example.com")
+		assert.Equal(t, event.Outcome, api.AuditEventOutcomeSuccess)
+		assert.Equal(t, event.StatusCode, http.StatusCreated)
+		assert.Assert(t, is.Contains(event.Request, `"resource":"production"`))
+	})
+
+	t.Run("failed call is recorded", func(t *testing.T) {
+		missing := uid.New()
+		resp := do(t, http.MethodDelete, "/api/grants/"+missing.String(), userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		events := listEvents(t, "targetID="+missing.String())
+		assert.Equal(t, len(events), 1)
+		event := events[0]
+		assert.Equal(t, event.Method, http.MethodDelete)
+		assert.Equal(t, event.Route, "/api/grants/:id")
+		assert.Equal(t, event.ActorID, user.ID)
+		assert.Equal(t, event.Outcome, api.AuditEventOutcomeFailure)
+		assert.Equal(t, event.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("login is recorded with the user as the actor", func(t *testing.T) {
+		hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.DB(), &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+
+		body := jsonBody(t, api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: user.Name, Password: "password"},
+		})
+		// nolint:noctx
+		req := httptest.NewRequest(http.MethodPost, "/api/login", body)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		events := listEvents(t, "route=/api/login")
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].ActorID, user.ID)
+		assert.Equal(t, events[0].ActorName, user.Name)
+		assert.Equal(t, events[0].Outcome, api.AuditEventOutcomeSuccess)
+	})
+
+	t.Run("read only calls are not recorded", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/grants", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		for _, event := range listEvents(t, "") {
+			assert.Assert(t, event.Method != http.MethodGet, event)
+		}
+	})
+
+	t.Run("filter by outcome", func(t *testing.T) {
+		for _, event := range listEvents(t, "outcome=failure") {
+			assert.Equal(t, event.Outcome, api.AuditEventOutcomeFailure)
+		}
+	})
+
+	t.Run("not authorized", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/audit-events", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+}
+
+func TestRedactedRequestSummary(t *testing.T) {
+	t.Run("redacts sensitive fields", func(t *testing.T) {
+		req := &api.LoginRequest{
+			AccessKey: "aaaaaaaaaa.bbbbbbbbbbbbbbbbbbbbbbbb",
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{
+				Name:     "admin@example.com",
+				Password: "hunter2",
+				MFACode:  "123456",
+			},
+			LDAP: &api.LoginRequestLDAP{
+				ProviderID: 1234,
+				Name:       "admin",
+				Password:   "hunter2",
+			},
+			WorkloadIdentity: &api.LoginRequestWorkloadIdentity{
+				Token: "eyJhbGciOiJSUzI1NiJ9.e30.c2ln",
+			},
+		}
+		actual := redactedRequestSummary(req)
+		expected := `{"accessKey":"REDACTED","ldap":{"name":"admin","password":"REDACTED","providerID":"nh"},"oidc":null,"passwordCredentials":{"mfaCode":"REDACTED","name":"admin@example.com","password":"REDACTED"},"workloadIdentity":{"token":"REDACTED"}}`
+		assert.Equal(t, actual, expected)
+	})
+
+	t.Run("truncates large requests", func(t *testing.T) {
+		req := &api.CreateAccessRequestRequest{
+			Justification: strings.Repeat("a", 2*maxRequestSummaryLength),
+		}
+		actual := redactedRequestSummary(req)
+		assert.Equal(t, len(actual), maxRequestSummaryLength)
+	})
+}
+
+func TestTargetIDFromValue(t *testing.T) {
+	assert.Equal(t, targetIDFromValue(&api.Resource{ID: 1234}), uid.ID(1234))
+	assert.Equal(t, targetIDFromValue(&api.User{ID: 5678}), uid.ID(5678))
+	assert.Equal(t, targetIDFromValue(&api.GrantRequest{User: 1234}), uid.ID(0))
+	assert.Equal(t, targetIDFromValue(nil), uid.ID(0))
+	assert.Equal(t, targetIDFromValue(&api.CreateGrantResponse{}), uid.ID(0))
+}
+
+func TestNewAuditEvent_Signup(t *testing.T) {
+	routeID := routeIdentifier{method: http.MethodPost, path: "/api/signup"}
+	resp := &api.SignupResponse{
+		User:         &api.User{ID: 1234, Name: "admin@example.com"},
+		Organization: &api.Organization{ID: 5678},
+	}
+
+	event := newAuditEvent(access.RequestContext{}, routeID, &api.SignupRequest{}, resp, nil)
+	assert.Equal(t, event.OrganizationID, uid.ID(5678))
+	assert.Equal(t, event.ActorID, uid.ID(1234))
+	assert.Equal(t, event.ActorName, "admin@example.com")
+
+	event = newAuditEvent(access.RequestContext{}, routeID, &api.SignupRequest{}, nil, internal.ErrBadRequest)
+	assert.Equal(t, event.OrganizationID, uid.ID(0))
+	assert.Equal(t, event.Outcome, models.AuditEventOutcomeFailure)
+}
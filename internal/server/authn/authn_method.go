@@ -26,6 +26,13 @@ type LoginMethod interface {
 
 type AuthScope struct {
 	PasswordResetOnly bool
+	MFAEnrollmentOnly bool
+	// ResourceScopes limit the access key issued by the login to the routes
+	// for some resources. The key can not be used to create other keys.
+	ResourceScopes []string
+	// DenyCreateAccessKey prevents the access key issued by the login from
+	// being used to create other keys, even when it has no ResourceScopes.
+	DenyCreateAccessKey bool
 }
 
 type LoginResult struct {
@@ -33,6 +40,7 @@ type LoginResult struct {
 	Bearer                   string
 	User                     *models.Identity
 	CredentialUpdateRequired bool
+	MFAEnrollmentRequired    bool
 	OrganizationName         string
 }
 
@@ -42,6 +50,7 @@ func Login(
 	loginMethod LoginMethod,
 	requestedExpiry time.Time,
 	inactivityTimeout time.Duration,
+	client data.AccessKeyClient,
 ) (LoginResult, error) {
 	// challenge the user to authenticate
 	authenticated, err := loginMethod.Authenticate(ctx, db, requestedExpiry)
@@ -60,11 +69,23 @@ func Login(
 		InactivityTimeout:   time.Now().UTC().Add(inactivityTimeout),
 		InactivityExtension: inactivityTimeout,
 		Scopes:              models.CommaSeparatedStrings{models.ScopeAllowCreateAccessKey},
+		LoginMethod:         loginMethod.Name(),
+		ClientIP:            client.IP,
+		UserAgent:           client.UserAgent,
 	}
 
+	if authenticated.AuthScope.DenyCreateAccessKey {
+		accessKey.Scopes = nil
+	}
+	if len(authenticated.AuthScope.ResourceScopes) > 0 {
+		accessKey.Scopes = authenticated.AuthScope.ResourceScopes
+	}
 	if authenticated.AuthScope.PasswordResetOnly {
 		accessKey.Scopes = append(accessKey.Scopes, models.ScopePasswordReset)
 	}
+	if authenticated.AuthScope.MFAEnrollmentOnly {
+		accessKey.Scopes = append(accessKey.Scopes, models.ScopeMFAEnrollment)
+	}
 
 	bearer, err := data.CreateAccessKey(db, accessKey)
 	if err != nil {
@@ -86,6 +107,7 @@ func Login(
 		Bearer:                   bearer,
 		User:                     authenticated.Identity,
 		CredentialUpdateRequired: authenticated.CredentialUpdateRequired,
+		MFAEnrollmentRequired:    authenticated.AuthScope.MFAEnrollmentOnly,
 		OrganizationName:         org.Name,
 	}, nil
 }
@@ -57,23 +57,27 @@ func TestLogin(t *testing.T) {
 	assert.NilError(t, err)
 
 	t.Run("failed login does not create access key", func(t *testing.T) {
-		authn := NewPasswordCredentialAuthentication(username, "invalid password")
-		result, err := Login(ctx, tx, authn, time.Now().Add(1*time.Minute), time.Minute)
+		authn := NewPasswordCredentialAuthentication(username, "invalid password", "")
+		result, err := Login(ctx, tx, authn, time.Now().Add(1*time.Minute), time.Minute, data.AccessKeyClient{})
 
 		assert.ErrorContains(t, err, "failed to login")
 		assert.Equal(t, result.Bearer, "")
 	})
 
 	t.Run("successful login does creates access key for authenticated identity", func(t *testing.T) {
-		authn := NewPasswordCredentialAuthentication("gohan@example.com", password)
+		authn := NewPasswordCredentialAuthentication("gohan@example.com", password, "")
 		exp := time.Now().Add(1 * time.Minute)
 		ext := 1 * time.Minute
-		result, err := Login(ctx, tx, authn, exp, ext)
+		client := data.AccessKeyClient{IP: "192.0.2.10", UserAgent: "infra/0.21.0"}
+		result, err := Login(ctx, tx, authn, exp, ext, client)
 		assert.NilError(t, err)
 		assert.Assert(t, result.Bearer != "")
 		assert.Equal(t, result.AccessKey.IssuedForID, user.ID)
 		assert.Equal(t, result.AccessKey.ExpiresAt, exp)
 		assert.Equal(t, result.AccessKey.InactivityExtension, ext)
+		assert.Equal(t, result.AccessKey.LoginMethod, "credentials")
+		assert.Equal(t, result.AccessKey.ClientIP, "192.0.2.10")
+		assert.Equal(t, result.AccessKey.UserAgent, "infra/0.21.0")
 		assert.Equal(t, result.User.ID, user.ID)
 	})
 }
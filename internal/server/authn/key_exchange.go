@@ -21,7 +21,7 @@ func NewKeyExchangeAuthentication(requestingAccessKey string) LoginMethod {
 }
 
 func (a *keyExchangeAuthn) Authenticate(_ context.Context, db *data.Transaction, requestedExpiry time.Time) (AuthenticatedIdentity, error) {
-	validatedRequestKey, err := data.ValidateRequestAccessKey(db, a.RequestingAccessKey)
+	validatedRequestKey, err := data.ValidateRequestAccessKey(db, a.RequestingAccessKey, data.AccessKeyClient{})
 	if err != nil {
 		return AuthenticatedIdentity{}, fmt.Errorf("invalid access key in exchange: %w", err)
 	}
@@ -38,11 +38,17 @@ func (a *keyExchangeAuthn) Authenticate(_ context.Context, db *data.Transaction,
 		return AuthenticatedIdentity{}, fmt.Errorf("user is not valid: %w", err) // the user was probably deleted
 	}
 
-	return AuthenticatedIdentity{
+	authnIdentity := AuthenticatedIdentity{
 		Identity:      identity,
 		Provider:      data.InfraProvider(db),
 		SessionExpiry: sessionExpiry,
-	}, nil
+	}
+
+	// the new key must be limited to the same resources as the requesting key
+	for _, scope := range validatedRequestKey.ResourceScopes() {
+		authnIdentity.AuthScope.ResourceScopes = append(authnIdentity.AuthScope.ResourceScopes, scope.String())
+	}
+	return authnIdentity, nil
 }
 
 func (a *keyExchangeAuthn) Name() string {
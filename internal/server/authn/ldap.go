@@ -0,0 +1,77 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+)
+
+type LDAPAuthn struct {
+	Provider *models.Provider
+	Username string
+	Password string
+}
+
+func NewLDAPAuthentication(provider *models.Provider, username, password string) (LoginMethod, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("nil provider in ldap authentication")
+	}
+	return &LDAPAuthn{
+		Provider: provider,
+		Username: username,
+		Password: password,
+	}, nil
+}
+
+func (a *LDAPAuthn) Authenticate(ctx context.Context, db *data.Transaction, requestedExpiry time.Time) (AuthenticatedIdentity, error) {
+	claims, err := providers.NewLDAPClient(*a.Provider).Login(ctx, a.Username, a.Password)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return AuthenticatedIdentity{}, fmt.Errorf("%w: %s", internal.ErrBadGateway, err.Error())
+		}
+		return AuthenticatedIdentity{}, fmt.Errorf("ldap login: %w", err)
+	}
+
+	identity, err := data.GetIdentity(db, data.GetIdentityOptions{ByName: claims.Email, LoadGroups: true})
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			return AuthenticatedIdentity{}, fmt.Errorf("get user: %w", err)
+		}
+
+		identity = &models.Identity{Name: claims.Email}
+
+		if err := data.CreateIdentity(db, identity); err != nil {
+			return AuthenticatedIdentity{}, fmt.Errorf("create user: %w", err)
+		}
+	}
+
+	providerUser, err := data.CreateProviderUser(db, a.Provider, identity)
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("add user for provider login: %w", err)
+	}
+
+	// group membership is read from the directory on every login
+	groups, err := data.AssignIdentityToGroups(db, providerUser, claims.Groups)
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("assign groups on login: %w", err)
+	}
+
+	// the groups were set in the database, update the identity we have in memory here
+	identity.Groups = groups
+
+	return AuthenticatedIdentity{
+		Identity:      identity,
+		Provider:      a.Provider,
+		SessionExpiry: requestedExpiry,
+	}, nil
+}
+
+func (a *LDAPAuthn) Name() string {
+	return "ldap"
+}
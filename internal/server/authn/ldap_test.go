@@ -0,0 +1,92 @@
+package authn
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/internal/testing/ldapserver"
+)
+
+func TestLDAPAuthenticate(t *testing.T) {
+	tx := setupDB(t)
+	srv := ldapserver.New(t,
+		ldapserver.Entry{
+			DN:       "cn=infra,dc=example,dc=com",
+			Password: "service-password",
+		},
+		ldapserver.Entry{
+			DN:       "uid=carol,ou=people,dc=example,dc=com",
+			Password: "carol-password",
+			Attributes: map[string][]string{
+				"uid":  {"carol"},
+				"mail": {"carol@example.com"},
+			},
+		},
+		ldapserver.Entry{
+			DN: "cn=developers,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"developers"},
+				"member":      {"uid=carol,ou=people,dc=example,dc=com"},
+			},
+		},
+		ldapserver.Entry{
+			DN: "cn=engineering,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"engineering"},
+				"member":      {"cn=developers,ou=groups,dc=example,dc=com"},
+			},
+		},
+	)
+
+	provider := &models.Provider{
+		Name:             "corp",
+		Kind:             models.ProviderKindLDAP,
+		URL:              srv.URL,
+		LDAPBindDN:       "cn=infra,dc=example,dc=com",
+		LDAPBindPassword: "service-password",
+		LDAPBaseDN:       "dc=example,dc=com",
+	}
+	assert.NilError(t, data.CreateProvider(tx, provider))
+
+	t.Run("nil provider", func(t *testing.T) {
+		_, err := NewLDAPAuthentication(nil, "carol", "carol-password")
+		assert.ErrorContains(t, err, "nil provider in ldap authentication")
+	})
+
+	t.Run("successful authentication", func(t *testing.T) {
+		ldapAuthn, err := NewLDAPAuthentication(provider, "carol", "carol-password")
+		assert.NilError(t, err)
+		authnIdentity, err := ldapAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+
+		assert.Equal(t, authnIdentity.Identity.Name, "carol@example.com")
+		assert.Equal(t, authnIdentity.Provider.ID, provider.ID)
+
+		var groupNames []string
+		for _, g := range authnIdentity.Identity.Groups {
+			groupNames = append(groupNames, g.Name)
+		}
+		sort.Strings(groupNames)
+		assert.DeepEqual(t, groupNames, []string{"developers", "engineering"})
+
+		providerUser, err := data.GetProviderUser(tx, provider.ID, authnIdentity.Identity.ID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string(providerUser.Groups), []string{"developers", "engineering"})
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		ldapAuthn, err := NewLDAPAuthentication(provider, "carol", "wrong")
+		assert.NilError(t, err)
+		_, err = ldapAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.ErrorIs(t, err, providers.ErrLDAPInvalidCredentials)
+	})
+}
@@ -19,11 +19,12 @@ type OIDCAuthn struct {
 	Provider           *models.Provider
 	RedirectURL        string
 	Code               string
+	CodeVerifier       string
 	OIDCProviderClient providers.OIDCClient
 	AllowedDomains     []string
 }
 
-func NewOIDCAuthentication(provider *models.Provider, redirectURL string, code string, oidcProviderClient providers.OIDCClient, allowedDomains []string) (LoginMethod, error) {
+func NewOIDCAuthentication(provider *models.Provider, redirectURL, code, codeVerifier string, oidcProviderClient providers.OIDCClient, allowedDomains []string) (LoginMethod, error) {
 	if provider == nil {
 		return nil, fmt.Errorf("nil provider in oidc authentication")
 	}
@@ -31,6 +32,7 @@ func NewOIDCAuthentication(provider *models.Provider, redirectURL string, code s
 		Provider:           provider,
 		RedirectURL:        redirectURL,
 		Code:               code,
+		CodeVerifier:       codeVerifier,
 		OIDCProviderClient: oidcProviderClient,
 		AllowedDomains:     allowedDomains,
 	}, nil
@@ -38,7 +40,7 @@ func NewOIDCAuthentication(provider *models.Provider, redirectURL string, code s
 
 func (a *OIDCAuthn) Authenticate(ctx context.Context, db *data.Transaction, requestedExpiry time.Time) (AuthenticatedIdentity, error) {
 	// exchange code for tokens from identity provider (these tokens are for the IDP, not Infra)
-	idpAuth, err := a.OIDCProviderClient.ExchangeAuthCodeForProviderTokens(ctx, a.Code)
+	idpAuth, err := a.OIDCProviderClient.ExchangeAuthCodeForProviderTokens(ctx, a.Code, a.CodeVerifier)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return AuthenticatedIdentity{}, fmt.Errorf("%w: %s", internal.ErrBadGateway, err.Error())
@@ -88,6 +90,8 @@ func (a *OIDCAuthn) Authenticate(ctx context.Context, db *data.Transaction, requ
 	providerUser.AccessToken = models.EncryptedAtRest(idpAuth.AccessToken)
 	providerUser.RefreshToken = models.EncryptedAtRest(idpAuth.RefreshToken)
 	providerUser.ExpiresAt = idpAuth.AccessTokenExpiry
+	// a user deactivated by the directory sync is active again after they login
+	providerUser.Active = true
 	err = data.UpdateProviderUser(db, providerUser)
 	if err != nil {
 		return AuthenticatedIdentity{}, fmt.Errorf("UpdateProviderUser: %w", err)
@@ -29,7 +29,7 @@ func (m *mockOIDCImplementation) AuthServerInfo(_ context.Context) (*providers.A
 	return &providers.AuthServerInfo{AuthURL: "example.com/v1/auth", ScopesSupported: []string{"openid", "email"}}, nil
 }
 
-func (m *mockOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _ string) (*providers.IdentityProviderAuth, error) {
+func (m *mockOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _, _ string) (*providers.IdentityProviderAuth, error) {
 	return &providers.IdentityProviderAuth{
 		AccessToken:       "acc",
 		RefreshToken:      "ref",
@@ -60,12 +60,12 @@ func TestOIDCAuthenticate(t *testing.T) {
 	}
 
 	t.Run("nil provider", func(t *testing.T) {
-		_, err := NewOIDCAuthentication(nil, "localhost:8031", "1234", oidc, []string{})
+		_, err := NewOIDCAuthentication(nil, "localhost:8031", "1234", "", oidc, []string{})
 		assert.ErrorContains(t, err, "nil provider in oidc authentication")
 	})
 
 	t.Run("successful authentication", func(t *testing.T) {
-		oidcAuthn, err := NewOIDCAuthentication(mocktaProvider, "localhost:8031", "1234", oidc, []string{})
+		oidcAuthn, err := NewOIDCAuthentication(mocktaProvider, "localhost:8031", "1234", "", oidc, []string{})
 		assert.NilError(t, err)
 		authnIdentity, err := oidcAuthn.Authenticate(context.Background(), tx, time.Now().Add(1*time.Minute))
 
@@ -259,7 +259,7 @@ func TestExchangeAuthCodeForProviderTokens(t *testing.T) {
 			assert.NilError(t, err)
 
 			mockOIDC := tc.setup(t, tx)
-			loginMethod, err := NewOIDCAuthentication(provider, "mockOIDC.example.com/redirect", "AAA", mockOIDC, []string{})
+			loginMethod, err := NewOIDCAuthentication(provider, "mockOIDC.example.com/redirect", "AAA", "", mockOIDC, []string{})
 			assert.NilError(t, err)
 
 			a, err := loginMethod.Authenticate(context.Background(), tx, sessionExpiry)
@@ -345,7 +345,7 @@ func TestExchangeAuthCodeForProviderTokensAllowedDomains(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			loginMethod, err := NewOIDCAuthentication(provider, "mockOIDC.example.com/redirect", "AAA", tc.client, []string{"example.com", "infrahq.com"})
+			loginMethod, err := NewOIDCAuthentication(provider, "mockOIDC.example.com/redirect", "AAA", "", tc.client, []string{"example.com", "infrahq.com"})
 			assert.NilError(t, err)
 
 			a, err := loginMethod.Authenticate(context.Background(), tx, sessionExpiry)
@@ -2,24 +2,34 @@ package authn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
 )
 
+// ErrMFARequired is returned when the password was valid, but the user has
+// enrolled in multi-factor authentication and no MFA code was provided.
+var ErrMFARequired = errors.New("multi-factor authentication code required")
+
 // passwordCredentialAuthn allows presenting username/password credentials in exchange for an access key
 type passwordCredentialAuthn struct {
 	Username string
 	Password string
+	// MFACode is a TOTP or recovery code, required when the user has enrolled
+	// in multi-factor authentication.
+	MFACode string
 }
 
-func NewPasswordCredentialAuthentication(username, password string) LoginMethod {
+func NewPasswordCredentialAuthentication(username, password, mfaCode string) LoginMethod {
 	return &passwordCredentialAuthn{
 		Username: username,
 		Password: password,
+		MFACode:  mfaCode,
 	}
 }
 
@@ -51,7 +61,24 @@ func (a *passwordCredentialAuthn) Authenticate(ctx context.Context, db *data.Tra
 		SessionExpiry: requestedExpiry,
 	}
 
-	if userCredential.OneTimePassword {
+	org, err := data.GetOrganization(db, data.GetOrganizationOptions{ByID: db.OrganizationID()})
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("get organization: %w", err)
+	}
+
+	if userCredential.MFAEnabled {
+		if a.MFACode == "" {
+			return AuthenticatedIdentity{}, ErrMFARequired
+		}
+		if err := verifyMFACode(db, userCredential, a.MFACode); err != nil {
+			return AuthenticatedIdentity{}, err
+		}
+	} else if org.RequireMFA {
+		// scope the login down to MFA enrollment only
+		authnIdentity.AuthScope.MFAEnrollmentOnly = true
+	}
+
+	if userCredential.OneTimePassword || passwordExpired(org.PasswordPolicy, userCredential) {
 		// scope the login down to Password Reset Only
 		authnIdentity.AuthScope.PasswordResetOnly = true
 		authnIdentity.CredentialUpdateRequired = true
@@ -64,3 +91,21 @@ func (a *passwordCredentialAuthn) Authenticate(ctx context.Context, db *data.Tra
 func (a *passwordCredentialAuthn) Name() string {
 	return "credentials"
 }
+
+// passwordExpired returns true if the password of the credential is older
+// than the maximum age allowed by the password policy.
+func passwordExpired(policy models.PasswordPolicy, credential *models.Credential) bool {
+	if policy.PasswordMaxAge == 0 || credential.PasswordUpdatedAt.IsZero() {
+		return false
+	}
+	return time.Since(credential.PasswordUpdatedAt) > policy.PasswordMaxAge
+}
+
+// verifyMFACode checks code with Credential.UseMFACode, and saves the
+// credential so that the code can not be used again.
+func verifyMFACode(tx data.WriteTxn, credential *models.Credential, code string) error {
+	if err := credential.UseMFACode(code, time.Now()); err != nil {
+		return err
+	}
+	return data.UpdateCredential(tx, credential)
+}
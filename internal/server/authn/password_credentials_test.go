@@ -42,7 +42,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, oneTimePassword)
+				return NewPasswordCredentialAuthentication(username, oneTimePassword, "")
 			},
 			expected: func(t *testing.T, authnIdentity AuthenticatedIdentity) {
 				assert.Equal(t, "goku@example.com", authnIdentity.Identity.Name)
@@ -70,7 +70,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, password)
+				return NewPasswordCredentialAuthentication(username, password, "")
 			},
 			expected: func(t *testing.T, authnIdentity AuthenticatedIdentity) {
 				assert.Equal(t, "bulma@example.com", authnIdentity.Identity.Name)
@@ -98,7 +98,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				userPassLogin := NewPasswordCredentialAuthentication(username, password)
+				userPassLogin := NewPasswordCredentialAuthentication(username, password, "")
 
 				_, err = userPassLogin.Authenticate(context.Background(), db, time.Now().Add(1*time.Minute))
 				assert.NilError(t, err)
@@ -118,7 +118,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err := data.CreateIdentity(db, user)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "")
+				return NewPasswordCredentialAuthentication(username, "", "")
 			},
 			expectedErr: "record not found",
 		},
@@ -142,7 +142,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "invalidPassword")
+				return NewPasswordCredentialAuthentication(username, "invalidPassword", "")
 			},
 			expectedErr: "hashedPassword is not the hash of the given password",
 		},
@@ -166,13 +166,13 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "")
+				return NewPasswordCredentialAuthentication(username, "", "")
 			},
 			expectedErr: "hashedPassword is not the hash of the given password",
 		},
 		"EmptyUsernameAndPasswordFails": {
 			setup: func(t *testing.T, db *data.Transaction) LoginMethod {
-				return NewPasswordCredentialAuthentication("", "whatever")
+				return NewPasswordCredentialAuthentication("", "whatever", "")
 			},
 			expectedErr: "username required for password authentication",
 		},
@@ -192,3 +192,51 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordCredentialAuthentication_PasswordMaxAge(t *testing.T) {
+	tx := setupDB(t)
+
+	org, err := data.GetOrganization(tx, data.GetOrganizationOptions{ByID: tx.OrganizationID()})
+	assert.NilError(t, err)
+	org.PasswordMaxAge = 24 * time.Hour
+	assert.NilError(t, data.UpdateOrganization(tx, org))
+
+	createUser := func(t *testing.T, name string, updatedAt time.Time) {
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(tx, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		creds := &models.Credential{IdentityID: user.ID, PasswordHash: hash, PasswordUpdatedAt: updatedAt}
+		assert.NilError(t, data.CreateCredential(tx, creds))
+	}
+
+	t.Run("expired password must be changed", func(t *testing.T) {
+		createUser(t, "vegeta@example.com", time.Now().Add(-48*time.Hour))
+
+		login := NewPasswordCredentialAuthentication("vegeta@example.com", "password123", "")
+		authnIdentity, err := login.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+		assert.Assert(t, authnIdentity.AuthScope.PasswordResetOnly)
+		assert.Assert(t, authnIdentity.CredentialUpdateRequired)
+	})
+
+	t.Run("password that has not expired", func(t *testing.T) {
+		createUser(t, "trunks@example.com", time.Now().Add(-time.Hour))
+
+		login := NewPasswordCredentialAuthentication("trunks@example.com", "password123", "")
+		authnIdentity, err := login.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+		assert.Assert(t, !authnIdentity.AuthScope.PasswordResetOnly)
+		assert.Assert(t, !authnIdentity.CredentialUpdateRequired)
+	})
+}
+
+func TestPasswordExpired(t *testing.T) {
+	policy := models.PasswordPolicy{PasswordMaxAge: time.Hour}
+
+	assert.Assert(t, passwordExpired(policy, &models.Credential{PasswordUpdatedAt: time.Now().Add(-2 * time.Hour)}))
+	assert.Assert(t, !passwordExpired(policy, &models.Credential{PasswordUpdatedAt: time.Now().Add(-time.Minute)}))
+	assert.Assert(t, !passwordExpired(models.PasswordPolicy{}, &models.Credential{PasswordUpdatedAt: time.Now().Add(-2 * time.Hour)}))
+	assert.Assert(t, !passwordExpired(policy, &models.Credential{}))
+}
@@ -0,0 +1,86 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+)
+
+type SAMLAuthn struct {
+	Provider        *models.Provider
+	ServiceProvider *providers.SAMLServiceProvider
+	SAMLResponse    string
+	// RequestID is the ID of the authentication request that started the
+	// login in this browser
+	RequestID string
+}
+
+func NewSAMLAuthentication(provider *models.Provider, serviceProvider *providers.SAMLServiceProvider, samlResponse, requestID string) (LoginMethod, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("nil provider in saml authentication")
+	}
+	return &SAMLAuthn{
+		Provider:        provider,
+		ServiceProvider: serviceProvider,
+		SAMLResponse:    samlResponse,
+		RequestID:       requestID,
+	}, nil
+}
+
+func (a *SAMLAuthn) Authenticate(_ context.Context, db *data.Transaction, requestedExpiry time.Time) (AuthenticatedIdentity, error) {
+	resp, err := a.ServiceProvider.ParseResponse(a.SAMLResponse, a.RequestID, time.Now())
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("validate saml response: %w", err)
+	}
+
+	// the request and the assertion can each only be used once, so that a
+	// response can not be replayed
+	if err := data.UseSAMLRequest(db, a.Provider.ID, a.RequestID, resp.AssertionID); err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("use saml request: %w", err)
+	}
+	claims := resp.Claims
+
+	identity, err := data.GetIdentity(db, data.GetIdentityOptions{ByName: claims.Email, LoadGroups: true})
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			return AuthenticatedIdentity{}, fmt.Errorf("get user: %w", err)
+		}
+
+		identity = &models.Identity{Name: claims.Email}
+
+		if err := data.CreateIdentity(db, identity); err != nil {
+			return AuthenticatedIdentity{}, fmt.Errorf("create user: %w", err)
+		}
+	}
+
+	providerUser, err := data.CreateProviderUser(db, a.Provider, identity)
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("add user for provider login: %w", err)
+	}
+
+	// the assertion is the only source of group membership for a SAML
+	// provider, so the groups are updated on every login
+	groups, err := data.AssignIdentityToGroups(db, providerUser, claims.Groups)
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("assign groups on login: %w", err)
+	}
+
+	// the groups were set in the database, update the identity we have in memory here
+	identity.Groups = groups
+
+	return AuthenticatedIdentity{
+		Identity:      identity,
+		Provider:      a.Provider,
+		SessionExpiry: requestedExpiry,
+	}, nil
+}
+
+func (a *SAMLAuthn) Name() string {
+	return "saml"
+}
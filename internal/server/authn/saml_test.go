@@ -0,0 +1,117 @@
+package authn
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/internal/testing/samlidp"
+)
+
+func TestSAMLAuthenticate(t *testing.T) {
+	tx := setupDB(t)
+	idp := samlidp.New(t)
+
+	provider := &models.Provider{
+		Name:         "saml",
+		Kind:         models.ProviderKindSAML,
+		SAMLMetadata: idp.Metadata(),
+	}
+	assert.NilError(t, data.CreateProvider(tx, provider))
+
+	sp, err := providers.NewSAMLServiceProvider(*provider, "https://infra.example.com")
+	assert.NilError(t, err)
+
+	// newRequest records an authentication request, as it would be when the
+	// login is started
+	newRequest := func(t *testing.T, requestID string) string {
+		t.Helper()
+		assert.NilError(t, data.CreateSAMLRequest(tx, provider.ID, requestID, time.Now().Add(time.Minute)))
+		return requestID
+	}
+
+	response := func(requestID string, groups ...string) string {
+		return idp.Response(t, samlidp.Assertion{
+			Recipient:    sp.ACSURL,
+			Audience:     sp.EntityID,
+			InResponseTo: requestID,
+			NameID:       "carol@example.com",
+			Groups:       groups,
+		})
+	}
+
+	groupNames := func(groups []models.Group) []string {
+		var names []string
+		for _, g := range groups {
+			names = append(names, g.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("nil provider", func(t *testing.T) {
+		_, err := NewSAMLAuthentication(nil, sp, "", "")
+		assert.ErrorContains(t, err, "nil provider in saml authentication")
+	})
+
+	t.Run("successful authentication", func(t *testing.T) {
+		requestID := newRequest(t, "id-first")
+		samlAuthn, err := NewSAMLAuthentication(provider, sp, response(requestID, "Everyone", "developers"), requestID)
+		assert.NilError(t, err)
+		authnIdentity, err := samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+
+		assert.Equal(t, authnIdentity.Identity.Name, "carol@example.com")
+		assert.Equal(t, authnIdentity.Provider.ID, provider.ID)
+		assert.DeepEqual(t, groupNames(authnIdentity.Identity.Groups), []string{"Everyone", "developers"})
+
+		providerUser, err := data.GetProviderUser(tx, provider.ID, authnIdentity.Identity.ID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string(providerUser.Groups), []string{"Everyone", "developers"})
+	})
+
+	t.Run("groups are updated on login", func(t *testing.T) {
+		requestID := newRequest(t, "id-second")
+		samlAuthn, err := NewSAMLAuthentication(provider, sp, response(requestID, "developers"), requestID)
+		assert.NilError(t, err)
+		authnIdentity, err := samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, groupNames(authnIdentity.Identity.Groups), []string{"developers"})
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		samlAuthn, err := NewSAMLAuthentication(provider, sp, "invalid", newRequest(t, "id-invalid"))
+		assert.NilError(t, err)
+		_, err = samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.ErrorContains(t, err, "validate saml response")
+	})
+
+	t.Run("request was not recorded", func(t *testing.T) {
+		samlAuthn, err := NewSAMLAuthentication(provider, sp, response("id-unknown"), "id-unknown")
+		assert.NilError(t, err)
+		_, err = samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.ErrorContains(t, err, "use saml request")
+	})
+
+	t.Run("response is replayed", func(t *testing.T) {
+		requestID := newRequest(t, "id-replayed")
+		samlResponse := response(requestID)
+
+		samlAuthn, err := NewSAMLAuthentication(provider, sp, samlResponse, requestID)
+		assert.NilError(t, err)
+		_, err = samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+
+		samlAuthn, err = NewSAMLAuthentication(provider, sp, samlResponse, requestID)
+		assert.NilError(t, err)
+		_, err = samlAuthn.Authenticate(context.Background(), tx, time.Now().Add(time.Minute))
+		assert.ErrorContains(t, err, "use saml request")
+	})
+}
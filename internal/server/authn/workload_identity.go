@@ -0,0 +1,144 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+)
+
+// workloadIdentityMaxSessionDuration is the longest time an access key issued
+// to a workload is valid. Workloads are expected to login again with a new
+// token when they need another key.
+const workloadIdentityMaxSessionDuration = time.Hour
+
+// workloadIdentitySigningAlgs are the algorithms accepted for the signature of
+// a workload token.
+var workloadIdentitySigningAlgs = []string{
+	oidc.RS256, oidc.RS384, oidc.RS512,
+	oidc.ES256, oidc.ES384, oidc.ES512,
+	oidc.PS256, oidc.PS384, oidc.PS512,
+}
+
+// workloadKeySets caches the key set of each JWKS URL, so that the keys of an
+// issuer are only fetched again when a token is signed by a key that is not
+// in the cache.
+var workloadKeySets = &keySetCache{sets: make(map[string]*oidc.RemoteKeySet)}
+
+type keySetCache struct {
+	mu   sync.Mutex
+	sets map[string]*oidc.RemoteKeySet
+}
+
+// get returns the key set for jwksURL. The key set outlives the request, so
+// it is created with the values of ctx, like the HTTP client set by
+// oidc.ClientContext, but not its deadline or cancellation.
+func (c *keySetCache) get(ctx context.Context, jwksURL string) *oidc.RemoteKeySet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keySet, ok := c.sets[jwksURL]
+	if !ok {
+		keySet = oidc.NewRemoteKeySet(valuesOnlyContext{ctx}, jwksURL)
+		c.sets[jwksURL] = keySet
+	}
+	return keySet
+}
+
+// valuesOnlyContext is a context with the values of another context, that is
+// never cancelled.
+type valuesOnlyContext struct {
+	context.Context
+}
+
+func (valuesOnlyContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (valuesOnlyContext) Done() <-chan struct{}       { return nil }
+func (valuesOnlyContext) Err() error                  { return nil }
+
+// workloadIdentityAuthn exchanges a token issued to a workload, like a CI job,
+// by a trusted OIDC issuer for an access key.
+type workloadIdentityAuthn struct {
+	Token string
+}
+
+func NewWorkloadIdentityAuthentication(token string) LoginMethod {
+	return &workloadIdentityAuthn{Token: token}
+}
+
+func (a *workloadIdentityAuthn) Authenticate(ctx context.Context, db *data.Transaction, requestedExpiry time.Time) (AuthenticatedIdentity, error) {
+	// the issuer is read before the signature is verified, to find the
+	// workload identities, and the keys to verify the signature with.
+	token, err := jwt.ParseSigned(a.Token)
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("invalid workload token: %w", err)
+	}
+	var unverified jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("invalid workload token: %w", err)
+	}
+	if unverified.Issuer == "" {
+		return AuthenticatedIdentity{}, fmt.Errorf("invalid workload token: missing issuer")
+	}
+
+	workloads, err := data.ListWorkloadIdentities(db, data.ListWorkloadIdentitiesOptions{ByIssuer: unverified.Issuer})
+	if err != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("list workload identities: %w", err)
+	}
+	if len(workloads) == 0 {
+		return AuthenticatedIdentity{}, fmt.Errorf("no workload identity trusts issuer %q", unverified.Issuer)
+	}
+
+	var verifyErr error
+	for _, workload := range workloads {
+		keySet := workloadKeySets.get(ctx, workload.JWKSURL)
+		verifier := oidc.NewVerifier(workload.Issuer, keySet, &oidc.Config{
+			ClientID:             workload.Audience,
+			SupportedSigningAlgs: workloadIdentitySigningAlgs,
+		})
+		verified, err := verifier.Verify(ctx, a.Token)
+		if err != nil {
+			verifyErr = err
+			continue
+		}
+		if !internal.MatchGlob(workload.Subject, verified.Subject) {
+			continue
+		}
+
+		identity, err := data.GetIdentity(db, data.GetIdentityOptions{ByID: workload.IdentityID})
+		if err != nil {
+			return AuthenticatedIdentity{}, fmt.Errorf("user for workload identity %v is not valid: %w", workload.Name, err)
+		}
+
+		sessionExpiry := time.Now().UTC().Add(workloadIdentityMaxSessionDuration)
+		if requestedExpiry.Before(sessionExpiry) {
+			sessionExpiry = requestedExpiry
+		}
+
+		return AuthenticatedIdentity{
+			Identity:      identity,
+			Provider:      data.InfraProvider(db),
+			SessionExpiry: sessionExpiry,
+			AuthScope: AuthScope{
+				ResourceScopes: workload.Scopes,
+				// workload keys are short lived, they must not be used to
+				// create long lived keys.
+				DenyCreateAccessKey: true,
+			},
+		}, nil
+	}
+
+	if verifyErr != nil {
+		return AuthenticatedIdentity{}, fmt.Errorf("verify workload token: %w", verifyErr)
+	}
+	return AuthenticatedIdentity{}, errors.New("workload token subject does not match a workload identity")
+}
+
+func (a *workloadIdentityAuthn) Name() string {
+	return "workloadIdentity"
+}
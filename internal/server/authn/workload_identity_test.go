@@ -0,0 +1,146 @@
+package authn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+const workloadTestIssuer = "https://token.actions.githubusercontent.com"
+
+// newWorkloadTokenSigner starts a server that serves the JWKS of a new key,
+// and returns a function that signs tokens with the key.
+func newWorkloadTokenSigner(t *testing.T) (*httptest.Server, func(claims jwt.Claims) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+
+	jwk := jose.JSONWebKey{Key: key.Public(), KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.Check(t, json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{jwk}}))
+	}))
+	t.Cleanup(srv.Close)
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	assert.NilError(t, err)
+
+	sign := func(claims jwt.Claims) string {
+		raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		assert.NilError(t, err)
+		return raw
+	}
+	return srv, sign
+}
+
+func TestWorkloadIdentityAuthentication(t *testing.T) {
+	tx := setupDB(t)
+	srv, sign := newWorkloadTokenSigner(t)
+	ctx := oidc.ClientContext(context.Background(), srv.Client())
+
+	user := &models.Identity{Name: "deploy@example.com"}
+	assert.NilError(t, data.CreateIdentity(tx, user))
+
+	workload := &models.WorkloadIdentity{
+		Name:       "github-deploy",
+		Issuer:     workloadTestIssuer,
+		JWKSURL:    srv.URL,
+		Audience:   "https://infra.example.com",
+		Subject:    "repo:infrahq/infra:ref:refs/heads/*",
+		IdentityID: user.ID,
+		Scopes:     models.CommaSeparatedStrings{"grants:read"},
+	}
+	assert.NilError(t, data.CreateWorkloadIdentity(tx, workload))
+
+	validClaims := func() jwt.Claims {
+		return jwt.Claims{
+			Issuer:   workloadTestIssuer,
+			Subject:  "repo:infrahq/infra:ref:refs/heads/main",
+			Audience: jwt.Audience{"https://infra.example.com"},
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			Expiry:   jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		}
+	}
+
+	t.Run("successful authentication", func(t *testing.T) {
+		loginMethod := NewWorkloadIdentityAuthentication(sign(validClaims()))
+		authnIdentity, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(24*time.Hour))
+		assert.NilError(t, err)
+
+		assert.Equal(t, authnIdentity.Identity.ID, user.ID)
+		assert.Equal(t, authnIdentity.Provider.ID, data.InfraProvider(tx).ID)
+		assert.DeepEqual(t, authnIdentity.AuthScope.ResourceScopes, []string{"grants:read"})
+		assert.Assert(t, authnIdentity.AuthScope.DenyCreateAccessKey)
+		// the session is limited to the max duration
+		assert.Assert(t, authnIdentity.SessionExpiry.Before(time.Now().Add(workloadIdentityMaxSessionDuration+time.Second)))
+	})
+
+	t.Run("requested expiry is shorter than the max", func(t *testing.T) {
+		expiry := time.Now().Add(time.Minute)
+		loginMethod := NewWorkloadIdentityAuthentication(sign(validClaims()))
+		authnIdentity, err := loginMethod.Authenticate(ctx, tx, expiry)
+		assert.NilError(t, err)
+		assert.Equal(t, authnIdentity.SessionExpiry, expiry)
+	})
+
+	t.Run("issuer is not trusted", func(t *testing.T) {
+		claims := validClaims()
+		claims.Issuer = "https://gitlab.com"
+		loginMethod := NewWorkloadIdentityAuthentication(sign(claims))
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, `no workload identity trusts issuer "https://gitlab.com"`)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims.Audience = jwt.Audience{"https://other.example.com"}
+		loginMethod := NewWorkloadIdentityAuthentication(sign(claims))
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, "expected audience")
+	})
+
+	t.Run("subject does not match", func(t *testing.T) {
+		claims := validClaims()
+		claims.Subject = "repo:infrahq/infra:pull_request"
+		loginMethod := NewWorkloadIdentityAuthentication(sign(claims))
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, "workload token subject does not match a workload identity")
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		claims := validClaims()
+		claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		loginMethod := NewWorkloadIdentityAuthentication(sign(claims))
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, "token is expired")
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		_, signOther := newWorkloadTokenSigner(t)
+		loginMethod := NewWorkloadIdentityAuthentication(signOther(validClaims()))
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, "failed to verify signature")
+	})
+
+	t.Run("not a jwt", func(t *testing.T) {
+		loginMethod := NewWorkloadIdentityAuthentication("not-a-token")
+		_, err := loginMethod.Authenticate(ctx, tx, time.Now().Add(time.Hour))
+		assert.ErrorContains(t, err, "invalid workload token")
+	})
+}
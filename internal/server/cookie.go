@@ -11,6 +11,7 @@ import (
 const (
 	cookieAuthorizationName       = "auth"
 	cookieSignupName              = "signup"
+	cookieSAMLRequestName         = "saml-request"
 	cookiePath                    = "/"
 	cookieMaxAgeDeleteImmediately = -1 // <0: delete immediately
 	cookieMaxAgeNoExpiry          = 0  // zero has special meaning of "no expiry"
@@ -21,6 +22,8 @@ type cookieConfig struct {
 	Value   string
 	Domain  string
 	Expires time.Time
+	// SameSite defaults to http.SameSiteStrictMode
+	SameSite http.SameSite
 }
 
 func setCookie(req *http.Request, resp http.ResponseWriter, config cookieConfig) {
@@ -30,18 +33,24 @@ func setCookie(req *http.Request, resp http.ResponseWriter, config cookieConfig)
 	}
 
 	secure := true
-	if req.TLS == nil {
-		// if the request came over HTTP, then the cookie will need to be sent unsecured
+	if req.TLS == nil && config.SameSite != http.SameSiteNoneMode {
+		// if the request came over HTTP, then the cookie will need to be sent unsecured.
+		// Browsers only accept SameSite=None cookies that are secure.
 		secure = false
 	}
 
+	sameSite := config.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteStrictMode
+	}
+
 	http.SetCookie(resp, &http.Cookie{
 		Name:     config.Name,
 		Value:    url.QueryEscape(config.Value),
 		MaxAge:   maxAge,
 		Path:     cookiePath,
 		Domain:   config.Domain,
-		SameSite: http.SameSiteStrictMode,
+		SameSite: sameSite,
 		Secure:   secure,
 		HttpOnly: true, // not accessible by javascript
 	})
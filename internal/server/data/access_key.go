@@ -21,15 +21,15 @@ func (a accessKeyTable) Table() string {
 }
 
 func (a accessKeyTable) Columns() []string {
-	return []string{"created_at", "deleted_at", "expires_at", "inactivity_extension", "inactivity_timeout", "id", "issued_for_id", "issued_for_kind", "key_id", "name", "organization_id", "provider_id", "scopes", "secret_checksum", "updated_at"}
+	return []string{"client_ip", "created_at", "deleted_at", "expires_at", "inactivity_extension", "inactivity_timeout", "id", "issued_for_id", "issued_for_kind", "key_id", "login_method", "name", "organization_id", "provider_id", "scopes", "secret_checksum", "updated_at", "user_agent"}
 }
 
 func (a accessKeyTable) Values() []any {
-	return []any{a.CreatedAt, a.DeletedAt, a.ExpiresAt, a.InactivityExtension, a.InactivityTimeout, a.ID, a.IssuedForID, a.IssuedForKind, a.KeyID, a.Name, a.OrganizationID, a.ProviderID, a.Scopes, a.SecretChecksum, a.UpdatedAt}
+	return []any{(optionalString)(a.ClientIP), a.CreatedAt, a.DeletedAt, a.ExpiresAt, a.InactivityExtension, a.InactivityTimeout, a.ID, a.IssuedForID, a.IssuedForKind, a.KeyID, (optionalString)(a.LoginMethod), a.Name, a.OrganizationID, a.ProviderID, a.Scopes, a.SecretChecksum, a.UpdatedAt, (optionalString)(a.UserAgent)}
 }
 
 func (a *accessKeyTable) ScanFields() []any {
-	return []any{&a.CreatedAt, &a.DeletedAt, &a.ExpiresAt, &a.InactivityExtension, &a.InactivityTimeout, &a.ID, &a.IssuedForID, &a.IssuedForKind, &a.KeyID, &a.Name, &a.OrganizationID, &a.ProviderID, &a.Scopes, &a.SecretChecksum, &a.UpdatedAt}
+	return []any{(*optionalString)(&a.ClientIP), &a.CreatedAt, &a.DeletedAt, &a.ExpiresAt, &a.InactivityExtension, &a.InactivityTimeout, &a.ID, &a.IssuedForID, &a.IssuedForKind, &a.KeyID, (*optionalString)(&a.LoginMethod), &a.Name, &a.OrganizationID, &a.ProviderID, &a.Scopes, &a.SecretChecksum, &a.UpdatedAt, (*optionalString)(&a.UserAgent)}
 }
 
 var (
@@ -132,7 +132,10 @@ type ListAccessKeyOptions struct {
 	IncludeExpired bool
 	ByIssuedForID  uid.ID
 	ByName         string
-	Pagination     *Pagination
+	// OnlySessions instructs ListAccessKeys to only return keys that were
+	// issued by a login.
+	OnlySessions bool
+	Pagination   *Pagination
 }
 
 func ListAccessKeys(tx ReadTxn, opts ListAccessKeyOptions) ([]models.AccessKey, error) {
@@ -160,6 +163,9 @@ func ListAccessKeys(tx ReadTxn, opts ListAccessKeyOptions) ([]models.AccessKey,
 	if opts.ByName != "" {
 		query.B("AND access_keys.name = ?", opts.ByName)
 	}
+	if opts.OnlySessions {
+		query.B("AND access_keys.login_method is not null")
+	}
 	query.B("ORDER BY access_keys.name ASC")
 	if opts.Pagination != nil {
 		opts.Pagination.PaginateQuery(query)
@@ -244,6 +250,11 @@ type DeleteAccessKeysOptions struct {
 	// ByProviderID instructs DeleteAccessKeys to delete keys issued by this
 	// provider.
 	ByProviderID uid.ID
+	// OnlySessions instructs DeleteAccessKeys to only delete keys that were
+	// issued by a login.
+	OnlySessions bool
+	// ExcludeID instructs DeleteAccessKeys to keep the key with this ID.
+	ExcludeID uid.ID
 }
 
 func DeleteAccessKeys(tx WriteTxn, opts DeleteAccessKeysOptions) error {
@@ -263,13 +274,25 @@ func DeleteAccessKeys(tx WriteTxn, opts DeleteAccessKeysOptions) error {
 	if opts.ByProviderID != 0 {
 		query.B("AND provider_id = ?", opts.ByProviderID)
 	}
+	if opts.OnlySessions {
+		query.B("AND login_method is not null")
+	}
+	if opts.ExcludeID != 0 {
+		query.B("AND id != ?", opts.ExcludeID)
+	}
 
 	_, err := tx.Exec(query.String(), query.Args...)
 	return err
 }
 
+// AccessKeyClient identifies the client that made a request with an access key.
+type AccessKeyClient struct {
+	IP        string
+	UserAgent string
+}
+
 // TODO: move this to access package?
-func ValidateRequestAccessKey(tx WriteTxn, authnKey string) (*models.AccessKey, error) {
+func ValidateRequestAccessKey(tx WriteTxn, authnKey string, client AccessKeyClient) (*models.AccessKey, error) {
 	keyID, secret, ok := strings.Cut(authnKey, ".")
 	if !ok {
 		return nil, fmt.Errorf("invalid access key format")
@@ -297,7 +320,7 @@ func ValidateRequestAccessKey(tx WriteTxn, authnKey string) (*models.AccessKey,
 		t.InactivityTimeout = now.Add(t.InactivityExtension)
 	}
 
-	err = updateAccessKeyOnUse(tx, t)
+	err = updateAccessKeyOnUse(tx, t, client)
 	return t, err
 }
 
@@ -307,15 +330,25 @@ func ValidateRequestAccessKey(tx WriteTxn, authnKey string) (*models.AccessKey,
 // in a short period of time.
 const lastSeenUpdateThreshold = 2 * time.Second
 
-// updateAccessKeyOnUse sets key.UpdatedAt to now and then updates the
+// updateAccessKeyOnUse sets key.UpdatedAt to now, records the client that
+// used the key, and then updates the
 // user row in the database to match the specified key. Updates are throttled to once every 2 seconds.
 // If the access key was updated recently, or the database row is already locked, the
-// update will be skipped.
+// update will be skipped. A change of client is always recorded.
 //
 // Unlike most functions in this package, this function uses key.OrganizationID
 // not tx.OrganizationID.
-func updateAccessKeyOnUse(tx WriteTxn, key *models.AccessKey) error {
-	if time.Since(key.UpdatedAt) < lastSeenUpdateThreshold {
+func updateAccessKeyOnUse(tx WriteTxn, key *models.AccessKey, client AccessKeyClient) error {
+	clientChanged := false
+	if client.IP != "" && client.IP != key.ClientIP {
+		key.ClientIP = client.IP
+		clientChanged = true
+	}
+	if client.UserAgent != "" && client.UserAgent != key.UserAgent {
+		key.UserAgent = client.UserAgent
+		clientChanged = true
+	}
+	if !clientChanged && time.Since(key.UpdatedAt) < lastSeenUpdateThreshold {
 		return nil
 	}
 
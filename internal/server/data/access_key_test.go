@@ -170,17 +170,42 @@ func TestValidateRequestAccessKey(t *testing.T) {
 		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
 		body, _ := createTestAccessKey(t, tx, time.Hour*5)
 
-		_, err := ValidateRequestAccessKey(tx, body)
+		_, err := ValidateRequestAccessKey(tx, body, AccessKeyClient{})
 		assert.NilError(t, err)
 
 		random := generate.MathRandom(models.AccessKeySecretLength, generate.CharsetAlphaNumeric)
 		authorization := fmt.Sprintf("%s.%s", strings.Split(body, ".")[0], random)
 
-		_, err = ValidateRequestAccessKey(tx, authorization)
+		_, err = ValidateRequestAccessKey(tx, authorization, AccessKeyClient{})
 		assert.Error(t, err, "access key invalid secret")
 	})
 }
 
+func TestValidateRequestAccessKey_RecordsClient(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+		body, key := createTestAccessKey(t, tx, time.Hour*5)
+
+		client := AccessKeyClient{IP: "192.0.2.10", UserAgent: "infra/0.21.0"}
+		_, err := ValidateRequestAccessKey(tx, body, client)
+		assert.NilError(t, err)
+
+		actual, err := GetAccessKey(tx, GetAccessKeysOptions{ByID: key.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, actual.ClientIP, "192.0.2.10")
+		assert.Equal(t, actual.UserAgent, "infra/0.21.0")
+
+		// a change of client is recorded even when the key was just used
+		client = AccessKeyClient{IP: "198.51.100.7", UserAgent: "infra/0.21.0"}
+		_, err = ValidateRequestAccessKey(tx, body, client)
+		assert.NilError(t, err)
+
+		actual, err = GetAccessKey(tx, GetAccessKeysOptions{ByID: key.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, actual.ClientIP, "198.51.100.7")
+	})
+}
+
 func TestDeleteAccessKeys(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		provider := &models.Provider{Name: "azure", Kind: models.ProviderKindAzure}
@@ -250,6 +275,33 @@ func TestDeleteAccessKeys(t *testing.T) {
 			assert.DeepEqual(t, remaining, expected, cmpModelByID)
 		})
 
+		t.Run("only sessions", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+			session := &models.AccessKey{IssuedForID: user.ID, ProviderID: provider.ID, LoginMethod: "oidc"}
+			current := &models.AccessKey{IssuedForID: user.ID, ProviderID: provider.ID, LoginMethod: "oidc"}
+			toKeep := &models.AccessKey{IssuedForID: user.ID, ProviderID: provider.ID}
+			createAccessKeys(t, tx, session, current, toKeep)
+
+			err := DeleteAccessKeys(tx, DeleteAccessKeysOptions{
+				ByIssuedForID: user.ID,
+				OnlySessions:  true,
+				ExcludeID:     current.ID,
+			})
+			assert.NilError(t, err)
+
+			remaining, err := ListAccessKeys(tx, ListAccessKeyOptions{ByIssuedForID: user.ID})
+			assert.NilError(t, err)
+			expected := []models.AccessKey{
+				{Model: models.Model{ID: current.ID}},
+				{Model: models.Model{ID: toKeep.ID}},
+			}
+			assert.DeepEqual(t, remaining, expected, cmpModelByID)
+
+			sessions, err := ListAccessKeys(tx, ListAccessKeyOptions{ByIssuedForID: user.ID, OnlySessions: true})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, sessions, []models.AccessKey{{Model: models.Model{ID: current.ID}}}, cmpModelByID)
+		})
+
 		t.Run("already deleted", func(t *testing.T) {
 			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
 			key1 := &models.AccessKey{
@@ -290,7 +342,7 @@ func TestCheckAccessKeyExpired(t *testing.T) {
 		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
 		body, _ := createTestAccessKey(t, tx, -1*time.Hour)
 
-		_, err := ValidateRequestAccessKey(tx, body)
+		_, err := ValidateRequestAccessKey(tx, body, AccessKeyClient{})
 		assert.ErrorIs(t, err, ErrAccessKeyExpired)
 	})
 }
@@ -300,7 +352,7 @@ func TestCheckAccessKeyPastInactivityTimeout(t *testing.T) {
 		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
 		body, _ := createAccessKeyWithInactivityTimeout(t, tx, 1*time.Hour, -1*time.Hour)
 
-		_, err := ValidateRequestAccessKey(tx, body)
+		_, err := ValidateRequestAccessKey(tx, body, AccessKeyClient{})
 		assert.ErrorIs(t, err, ErrAccessInactivityTimeout)
 	})
 }
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+type accessRequestsTable models.AccessRequest
+
+func (a accessRequestsTable) Table() string {
+	return "access_requests"
+}
+
+func (a accessRequestsTable) Columns() []string {
+	return []string{"created_at", "deleted_at", "duration", "grant_id", "id", "justification", "organization_id", "privilege", "resource", "reviewed_at", "reviewed_by", "status", "updated_at", "user_id"}
+}
+
+func (a accessRequestsTable) Values() []any {
+	return []any{a.CreatedAt, a.DeletedAt, a.Duration, a.GrantID, a.ID, a.Justification, a.OrganizationID, a.Privilege, a.Resource, optionalTime(a.ReviewedAt), a.ReviewedBy, a.Status, a.UpdatedAt, a.UserID}
+}
+
+func (a *accessRequestsTable) ScanFields() []any {
+	return []any{&a.CreatedAt, &a.DeletedAt, &a.Duration, &a.GrantID, &a.ID, &a.Justification, &a.OrganizationID, &a.Privilege, &a.Resource, (*optionalTime)(&a.ReviewedAt), &a.ReviewedBy, &a.Status, &a.UpdatedAt, &a.UserID}
+}
+
+func validateAccessRequest(req *models.AccessRequest) error {
+	switch {
+	case req.UserID == 0:
+		return fmt.Errorf("userID is required")
+	case req.Privilege == "":
+		return fmt.Errorf("privilege is required")
+	case req.Resource == "":
+		return fmt.Errorf("resource is required")
+	}
+	return nil
+}
+
+func CreateAccessRequest(tx WriteTxn, req *models.AccessRequest) error {
+	if err := validateAccessRequest(req); err != nil {
+		return err
+	}
+	if req.Status == "" {
+		req.Status = models.AccessRequestStatusPending
+	}
+	return insert(tx, (*accessRequestsTable)(req))
+}
+
+func GetAccessRequest(tx ReadTxn, id uid.ID) (*models.AccessRequest, error) {
+	table := &accessRequestsTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	query.B("FROM access_requests")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+	query.B("AND id = ?", id)
+
+	err := tx.QueryRow(query.String(), query.Args...).Scan(table.ScanFields()...)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return (*models.AccessRequest)(table), nil
+}
+
+type ListAccessRequestsOptions struct {
+	// ByUserID instructs ListAccessRequests to return only the requests made
+	// by this user.
+	ByUserID uid.ID
+	// ByStatus instructs ListAccessRequests to return only the requests with
+	// this status.
+	ByStatus models.AccessRequestStatus
+
+	Pagination *Pagination
+}
+
+func ListAccessRequests(tx ReadTxn, opts ListAccessRequestsOptions) ([]models.AccessRequest, error) {
+	table := accessRequestsTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	if opts.Pagination != nil {
+		query.B(", count(*) OVER()")
+	}
+	query.B("FROM access_requests")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+
+	if opts.ByUserID != 0 {
+		query.B("AND user_id = ?", opts.ByUserID)
+	}
+	if opts.ByStatus != "" {
+		query.B("AND status = ?", opts.ByStatus)
+	}
+
+	query.B("ORDER BY id ASC")
+	if opts.Pagination != nil {
+		opts.Pagination.PaginateQuery(query)
+	}
+
+	rows, err := tx.Query(query.String(), query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, func(req *models.AccessRequest) []any {
+		fields := (*accessRequestsTable)(req).ScanFields()
+		if opts.Pagination != nil {
+			fields = append(fields, &opts.Pagination.TotalCount)
+		}
+		return fields
+	})
+}
+
+func UpdateAccessRequest(tx WriteTxn, req *models.AccessRequest) error {
+	if err := validateAccessRequest(req); err != nil {
+		return err
+	}
+	return update(tx, (*accessRequestsTable)(req))
+}
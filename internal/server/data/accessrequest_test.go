@@ -0,0 +1,145 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestCreateAccessRequest(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		t.Run("success", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			actual := models.AccessRequest{
+				UserID:        uid.ID(1234),
+				Privilege:     "view",
+				Resource:      "production",
+				Justification: "incident 1234",
+				Duration:      4 * time.Hour,
+			}
+			err := CreateAccessRequest(tx, &actual)
+			assert.NilError(t, err)
+			assert.Assert(t, actual.ID != 0)
+
+			expected := models.AccessRequest{
+				Model: models.Model{
+					ID:        uid.ID(999),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				OrganizationMember: models.OrganizationMember{OrganizationID: defaultOrganizationID},
+				UserID:             uid.ID(1234),
+				Privilege:          "view",
+				Resource:           "production",
+				Justification:      "incident 1234",
+				Duration:           4 * time.Hour,
+				Status:             models.AccessRequestStatusPending,
+			}
+			assert.DeepEqual(t, actual, expected, cmpModel)
+
+			fromDB, err := GetAccessRequest(tx, actual.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, fromDB, &expected, cmpModel)
+		})
+		t.Run("missing resource", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			err := CreateAccessRequest(tx, &models.AccessRequest{
+				UserID:    uid.ID(1234),
+				Privilege: "view",
+			})
+			assert.ErrorContains(t, err, "resource is required")
+		})
+	})
+}
+
+func TestListAccessRequests(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		first := &models.AccessRequest{
+			UserID:    uid.ID(1234),
+			Privilege: "view",
+			Resource:  "production",
+		}
+		second := &models.AccessRequest{
+			UserID:    uid.ID(1234),
+			Privilege: "admin",
+			Resource:  "staging",
+			Status:    models.AccessRequestStatusDenied,
+		}
+		other := &models.AccessRequest{
+			UserID:    uid.ID(5678),
+			Privilege: "view",
+			Resource:  "production",
+		}
+		for _, req := range []*models.AccessRequest{first, second, other} {
+			assert.NilError(t, CreateAccessRequest(tx, req))
+		}
+
+		otherOrg := &models.Organization{Name: "other", Domain: "other.example.org"}
+		assert.NilError(t, CreateOrganization(tx, otherOrg))
+		assert.NilError(t, CreateAccessRequest(tx.WithOrgID(otherOrg.ID), &models.AccessRequest{
+			UserID:    uid.ID(1234),
+			Privilege: "view",
+			Resource:  "production",
+		}))
+
+		t.Run("all", func(t *testing.T) {
+			actual, err := ListAccessRequests(tx, ListAccessRequestsOptions{})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AccessRequest{*first, *second, *other}, cmpModelByID)
+		})
+		t.Run("by user", func(t *testing.T) {
+			actual, err := ListAccessRequests(tx, ListAccessRequestsOptions{ByUserID: uid.ID(1234)})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AccessRequest{*first, *second}, cmpModelByID)
+		})
+		t.Run("by status", func(t *testing.T) {
+			actual, err := ListAccessRequests(tx, ListAccessRequestsOptions{
+				ByStatus: models.AccessRequestStatusPending,
+			})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AccessRequest{*first, *other}, cmpModelByID)
+		})
+		t.Run("with pagination", func(t *testing.T) {
+			pagination := &Pagination{Limit: 2}
+			actual, err := ListAccessRequests(tx, ListAccessRequestsOptions{Pagination: pagination})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AccessRequest{*first, *second}, cmpModelByID)
+			assert.Equal(t, pagination.TotalCount, 3)
+		})
+	})
+}
+
+func TestUpdateAccessRequest(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		req := &models.AccessRequest{
+			UserID:    uid.ID(1234),
+			Privilege: "view",
+			Resource:  "production",
+		}
+		assert.NilError(t, CreateAccessRequest(tx, req))
+
+		req.Status = models.AccessRequestStatusApproved
+		req.ReviewedBy = uid.ID(9999)
+		req.ReviewedAt = time.Now().Truncate(time.Millisecond)
+		req.GrantID = uid.ID(4321)
+		assert.NilError(t, UpdateAccessRequest(tx, req))
+
+		actual, err := GetAccessRequest(tx, req.ID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual, req, cmpModel)
+
+		_, err = GetAccessRequest(tx, uid.ID(1))
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+	})
+}
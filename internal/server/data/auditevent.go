@@ -0,0 +1,95 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+type auditEventsTable models.AuditEvent
+
+func (a auditEventsTable) Table() string {
+	return "audit_events"
+}
+
+func (a auditEventsTable) Columns() []string {
+	return []string{"actor_id", "actor_name", "created_at", "deleted_at", "id", "method", "organization_id", "outcome", "request", "route", "status_code", "target_id", "updated_at"}
+}
+
+func (a auditEventsTable) Values() []any {
+	return []any{a.ActorID, a.ActorName, a.CreatedAt, a.DeletedAt, a.ID, a.Method, a.OrganizationID, a.Outcome, a.Request, a.Route, a.StatusCode, a.TargetID, a.UpdatedAt}
+}
+
+func (a *auditEventsTable) ScanFields() []any {
+	return []any{&a.ActorID, &a.ActorName, &a.CreatedAt, &a.DeletedAt, &a.ID, &a.Method, &a.OrganizationID, &a.Outcome, &a.Request, &a.Route, &a.StatusCode, &a.TargetID, &a.UpdatedAt}
+}
+
+func CreateAuditEvent(tx WriteTxn, event *models.AuditEvent) error {
+	switch {
+	case event.Method == "":
+		return fmt.Errorf("method is required")
+	case event.Route == "":
+		return fmt.Errorf("route is required")
+	case event.Outcome == "":
+		return fmt.Errorf("outcome is required")
+	}
+	return insert(tx, (*auditEventsTable)(event))
+}
+
+type ListAuditEventsOptions struct {
+	// ByActorID instructs ListAuditEvents to return only the events for API
+	// calls made by this identity.
+	ByActorID uid.ID
+	// ByTargetID instructs ListAuditEvents to return only the events for API
+	// calls that acted on this resource.
+	ByTargetID uid.ID
+	ByRoute    string
+	ByOutcome  models.AuditEventOutcome
+
+	Pagination *Pagination
+}
+
+// ListAuditEvents returns audit events ordered from newest to oldest.
+func ListAuditEvents(tx ReadTxn, opts ListAuditEventsOptions) ([]models.AuditEvent, error) {
+	table := auditEventsTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	if opts.Pagination != nil {
+		query.B(", count(*) OVER()")
+	}
+	query.B("FROM audit_events")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+
+	if opts.ByActorID != 0 {
+		query.B("AND actor_id = ?", opts.ByActorID)
+	}
+	if opts.ByTargetID != 0 {
+		query.B("AND target_id = ?", opts.ByTargetID)
+	}
+	if opts.ByRoute != "" {
+		query.B("AND route = ?", opts.ByRoute)
+	}
+	if opts.ByOutcome != "" {
+		query.B("AND outcome = ?", opts.ByOutcome)
+	}
+
+	query.B("ORDER BY created_at DESC, id DESC")
+	if opts.Pagination != nil {
+		opts.Pagination.PaginateQuery(query)
+	}
+
+	rows, err := tx.Query(query.String(), query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, func(event *models.AuditEvent) []any {
+		fields := (*auditEventsTable)(event).ScanFields()
+		if opts.Pagination != nil {
+			fields = append(fields, &opts.Pagination.TotalCount)
+		}
+		return fields
+	})
+}
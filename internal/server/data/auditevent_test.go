@@ -0,0 +1,126 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestCreateAuditEvent(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		t.Run("success", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			actual := models.AuditEvent{
+				ActorID:    uid.ID(1234),
+				ActorName:  "admin@example.com",
+				Method:     "DELETE",
+				Route:      "/api/grants/:id",
+				TargetID:   uid.ID(5678),
+				Request:    `{"ID":"5678"}`,
+				Outcome:    models.AuditEventOutcomeSuccess,
+				StatusCode: 204,
+			}
+			err := CreateAuditEvent(tx, &actual)
+			assert.NilError(t, err)
+
+			expected := actual
+			expected.Model = models.Model{
+				ID:        uid.ID(999),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			expected.OrganizationMember = models.OrganizationMember{OrganizationID: defaultOrganizationID}
+
+			events, err := ListAuditEvents(tx, ListAuditEventsOptions{})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, events, []models.AuditEvent{expected}, cmpModel)
+		})
+		t.Run("missing outcome", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			err := CreateAuditEvent(tx, &models.AuditEvent{Method: "POST", Route: "/api/grants"})
+			assert.ErrorContains(t, err, "outcome is required")
+		})
+	})
+}
+
+func TestListAuditEvents(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		base := time.Now().Add(-time.Hour)
+		first := &models.AuditEvent{
+			Model:    models.Model{CreatedAt: base},
+			ActorID:  uid.ID(1234),
+			Method:   "POST",
+			Route:    "/api/grants",
+			TargetID: uid.ID(5678),
+			Outcome:  models.AuditEventOutcomeSuccess,
+		}
+		second := &models.AuditEvent{
+			Model:    models.Model{CreatedAt: base.Add(time.Minute)},
+			ActorID:  uid.ID(1234),
+			Method:   "DELETE",
+			Route:    "/api/grants/:id",
+			TargetID: uid.ID(5678),
+			Outcome:  models.AuditEventOutcomeFailure,
+		}
+		third := &models.AuditEvent{
+			Model:   models.Model{CreatedAt: base.Add(2 * time.Minute)},
+			ActorID: uid.ID(4321),
+			Method:  "POST",
+			Route:   "/api/users",
+			Outcome: models.AuditEventOutcomeSuccess,
+		}
+		for _, event := range []*models.AuditEvent{first, second, third} {
+			assert.NilError(t, CreateAuditEvent(tx, event))
+		}
+
+		otherOrg := &models.Organization{Name: "other", Domain: "other.example.org"}
+		assert.NilError(t, CreateOrganization(tx, otherOrg))
+		assert.NilError(t, CreateAuditEvent(tx.WithOrgID(otherOrg.ID), &models.AuditEvent{
+			ActorID: uid.ID(1234),
+			Method:  "POST",
+			Route:   "/api/grants",
+			Outcome: models.AuditEventOutcomeSuccess,
+		}))
+
+		t.Run("all", func(t *testing.T) {
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*third, *second, *first}, cmpModelByID)
+		})
+		t.Run("by actor", func(t *testing.T) {
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{ByActorID: uid.ID(1234)})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*second, *first}, cmpModelByID)
+		})
+		t.Run("by target", func(t *testing.T) {
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{ByTargetID: uid.ID(5678)})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*second, *first}, cmpModelByID)
+		})
+		t.Run("by route", func(t *testing.T) {
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{ByRoute: "/api/users"})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*third}, cmpModelByID)
+		})
+		t.Run("by outcome", func(t *testing.T) {
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{ByOutcome: models.AuditEventOutcomeFailure})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*second}, cmpModelByID)
+		})
+		t.Run("with pagination", func(t *testing.T) {
+			pagination := &Pagination{Limit: 2}
+			actual, err := ListAuditEvents(tx, ListAuditEventsOptions{Pagination: pagination})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.AuditEvent{*third, *second}, cmpModelByID)
+			assert.Equal(t, pagination.TotalCount, 3)
+		})
+	})
+}
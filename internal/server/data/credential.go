@@ -16,15 +16,15 @@ func (credentialsTable) Table() string {
 }
 
 func (c credentialsTable) Columns() []string {
-	return []string{"created_at", "deleted_at", "id", "identity_id", "one_time_password", "organization_id", "password_hash", "updated_at"}
+	return []string{"created_at", "deleted_at", "id", "identity_id", "mfa_enabled", "mfa_last_step", "mfa_recovery_codes", "mfa_secret", "one_time_password", "organization_id", "password_hash", "password_hash_history", "password_updated_at", "updated_at"}
 }
 
 func (c credentialsTable) Values() []any {
-	return []any{c.CreatedAt, c.DeletedAt, c.ID, c.IdentityID, c.OneTimePassword, c.OrganizationID, c.PasswordHash, c.UpdatedAt}
+	return []any{c.CreatedAt, c.DeletedAt, c.ID, c.IdentityID, c.MFAEnabled, c.MFALastStep, c.MFARecoveryCodes, c.MFASecret, c.OneTimePassword, c.OrganizationID, c.PasswordHash, c.PasswordHashHistory, c.PasswordUpdatedAt, c.UpdatedAt}
 }
 
 func (c *credentialsTable) ScanFields() []any {
-	return []any{&c.CreatedAt, &c.DeletedAt, &c.ID, &c.IdentityID, &c.OneTimePassword, &c.OrganizationID, &c.PasswordHash, &c.UpdatedAt}
+	return []any{&c.CreatedAt, &c.DeletedAt, &c.ID, &c.IdentityID, &c.MFAEnabled, &c.MFALastStep, &c.MFARecoveryCodes, &c.MFASecret, &c.OneTimePassword, &c.OrganizationID, &c.PasswordHash, &c.PasswordHashHistory, &c.PasswordUpdatedAt, &c.UpdatedAt}
 }
 
 func validateCredential(c *models.Credential) error {
@@ -41,6 +41,9 @@ func CreateCredential(tx WriteTxn, credential *models.Credential) error {
 	if err := validateCredential(credential); err != nil {
 		return err
 	}
+	if credential.PasswordUpdatedAt.IsZero() {
+		credential.PasswordUpdatedAt = time.Now()
+	}
 	return insert(tx, (*credentialsTable)(credential))
 }
 
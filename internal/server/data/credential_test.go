@@ -33,9 +33,10 @@ func TestCreateCredential(t *testing.T) {
 			OrganizationMember: models.OrganizationMember{
 				OrganizationID: db.DefaultOrg.ID,
 			},
-			IdentityID:      7145,
-			PasswordHash:    []byte("password-hash"),
-			OneTimePassword: true,
+			IdentityID:       7145,
+			PasswordHash:     []byte("password-hash"),
+			OneTimePassword:  true,
+			MFARecoveryCodes: models.CommaSeparatedStrings{},
 		}
 		assert.DeepEqual(t, expected, created, cmpModel)
 	})
@@ -63,6 +64,10 @@ func TestUpdateCredential(t *testing.T) {
 			updated := *cred // shallow copy
 			updated.PasswordHash = []byte("new-hash")
 			updated.OneTimePassword = false
+			updated.MFASecret = "JBSWY3DPEHPK3PXP"
+			updated.MFAEnabled = true
+			updated.MFALastStep = 56789
+			updated.MFARecoveryCodes = models.CommaSeparatedStrings{"checksum1", "checksum2"}
 
 			err := UpdateCredential(tx, &updated)
 			assert.NilError(t, err)
@@ -79,8 +84,12 @@ func TestUpdateCredential(t *testing.T) {
 				OrganizationMember: models.OrganizationMember{
 					OrganizationID: db.DefaultOrg.ID,
 				},
-				IdentityID:   7145,
-				PasswordHash: []byte("new-hash"),
+				IdentityID:       7145,
+				PasswordHash:     []byte("new-hash"),
+				MFASecret:        "JBSWY3DPEHPK3PXP",
+				MFAEnabled:       true,
+				MFALastStep:      56789,
+				MFARecoveryCodes: models.CommaSeparatedStrings{"checksum1", "checksum2"},
 			}
 			assert.DeepEqual(t, expected, actual, cmpModel)
 		})
@@ -112,9 +121,10 @@ func TestGetCredentialByUserID(t *testing.T) {
 				OrganizationMember: models.OrganizationMember{
 					OrganizationID: db.DefaultOrg.ID,
 				},
-				IdentityID:      7145,
-				PasswordHash:    []byte("password-hash"),
-				OneTimePassword: true,
+				IdentityID:       7145,
+				PasswordHash:     []byte("password-hash"),
+				OneTimePassword:  true,
+				MFARecoveryCodes: models.CommaSeparatedStrings{},
 			}
 			assert.DeepEqual(t, actual, expected, cmpModel)
 		})
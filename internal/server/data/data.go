@@ -359,16 +359,17 @@ func handleError(err error) error {
 			// constraintFields maps the name of a unique constraint, to the
 			// user facing name of that field.
 			constraintFields := map[string]string{
-				"idx_identities_name":         "name",
-				"idx_identities_verified":     "verificationToken",
-				"idx_groups_name":             "name",
-				"idx_providers_name":          "name",
-				"idx_access_keys_name":        "name",
-				"idx_destinations_unique_id":  "uniqueID",
-				"idx_access_keys_key_id":      "keyId",
-				"idx_credentials_identity_id": "identityID",
-				"idx_organizations_domain":    "domain",
-				"idx_user_ssh_login_name":     "sshLoginName",
+				"idx_identities_name":          "name",
+				"idx_identities_verified":      "verificationToken",
+				"idx_groups_name":              "name",
+				"idx_providers_name":           "name",
+				"idx_access_keys_name":         "name",
+				"idx_destinations_unique_id":   "uniqueID",
+				"idx_access_keys_key_id":       "keyId",
+				"idx_credentials_identity_id":  "identityID",
+				"idx_organizations_domain":     "domain",
+				"idx_user_ssh_login_name":      "sshLoginName",
+				"idx_workload_identities_name": "name",
 			}
 
 			columnName := constraintFields[pgErr.ConstraintName]
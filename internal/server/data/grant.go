@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 
+	"github.com/infrahq/infra/internal/logging"
 	"github.com/infrahq/infra/internal/server/data/querybuilder"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -20,15 +21,15 @@ func (g grantsTable) Table() string {
 }
 
 func (g grantsTable) Columns() []string {
-	return []string{"created_at", "created_by", "deleted_at", "id", "organization_id", "privilege", "resource", "subject_id", "subject_kind", "updated_at"}
+	return []string{"created_at", "created_by", "deleted_at", "expires_at", "id", "organization_id", "privilege", "resource", "subject_id", "subject_kind", "updated_at"}
 }
 
 func (g grantsTable) Values() []any {
-	return []any{g.CreatedAt, g.CreatedBy, g.DeletedAt, g.ID, g.OrganizationID, g.Privilege, g.Resource, g.Subject.ID, g.Subject.Kind, g.UpdatedAt}
+	return []any{g.CreatedAt, g.CreatedBy, g.DeletedAt, optionalTime(g.ExpiresAt), g.ID, g.OrganizationID, g.Privilege, g.Resource, g.Subject.ID, g.Subject.Kind, g.UpdatedAt}
 }
 
 func (g *grantsTable) ScanFields() []any {
-	return []any{&g.CreatedAt, &g.CreatedBy, &g.DeletedAt, &g.ID, &g.OrganizationID, &g.Privilege, &g.Resource, &g.Subject.ID, &g.Subject.Kind, &g.UpdatedAt}
+	return []any{&g.CreatedAt, &g.CreatedBy, &g.DeletedAt, (*optionalTime)(&g.ExpiresAt), &g.ID, &g.OrganizationID, &g.Privilege, &g.Resource, &g.Subject.ID, &g.Subject.Kind, &g.UpdatedAt}
 }
 
 func CreateGrant(tx WriteTxn, grant *models.Grant) error {
@@ -126,8 +127,9 @@ type ListGrantsOptions struct {
 	ByDestination string
 
 	// IncludeInheritedFromGroups instructs ListGrants to include grants from
-	// groups where the user is a member. This option can only be used when
-	// BySubject is a non-zero userID.
+	// groups where the user is a member, or when BySubject is a group, the
+	// groups that contain the group. This option can only be used when
+	// BySubject is a non-zero ID.
 	IncludeInheritedFromGroups bool
 
 	// ExcludeConnectorGrant instructs ListGrants to exclude grants where
@@ -148,6 +150,7 @@ func ListGrants(tx ReadTxn, opts ListGrantsOptions) ([]models.Grant, error) {
 	query.B("FROM grants")
 	query.B("WHERE deleted_at is null")
 	query.B("AND organization_id = ?", tx.OrganizationID())
+	query.B("AND (expires_at is null OR expires_at > ?)", time.Now())
 
 	if opts.BySubject.ID != 0 {
 		if opts.BySubject.Kind == 0 {
@@ -158,17 +161,25 @@ func ListGrants(tx ReadTxn, opts ListGrantsOptions) ([]models.Grant, error) {
 			query.B("AND subject_id = ? AND subject_kind = ?",
 				opts.BySubject.ID, opts.BySubject.Kind)
 		} else {
-			subjects := []uid.ID{opts.BySubject.ID}
+			var subjects []uid.ID
 
-			if opts.BySubject.Kind != models.SubjectKindUser {
-				return nil, fmt.Errorf("IncludeInheritedFromGroups requires a userId subject")
-			}
 			// TODO: replace this with a sub-select or join.
-			groupIDs, err := ListGroupIDsForUser(tx, opts.BySubject.ID)
-			if err != nil {
-				return nil, err
+			switch opts.BySubject.Kind {
+			case models.SubjectKindUser:
+				groupIDs, err := ListGroupIDsForUser(tx, opts.BySubject.ID)
+				if err != nil {
+					return nil, err
+				}
+				subjects = append([]uid.ID{opts.BySubject.ID}, groupIDs...)
+			case models.SubjectKindGroup:
+				groupIDs, err := listAncestorGroupIDs(tx, opts.BySubject.ID)
+				if err != nil {
+					return nil, err
+				}
+				subjects = groupIDs
+			default:
+				return nil, fmt.Errorf("IncludeInheritedFromGroups requires a user or group subject")
 			}
-			subjects = append(subjects, groupIDs...)
 			query.B(`AND subject_id IN`)
 			queryInClause(query, subjects)
 		}
@@ -205,8 +216,15 @@ func ListGrants(tx ReadTxn, opts ListGrantsOptions) ([]models.Grant, error) {
 	})
 }
 
+// grantsByDestination filters the query to grants for the destination, or for
+// a resource within the destination. A * in the destination name of a grant
+// resource matches any sequence of characters, the same as
+// api.GrantMatchesDestination.
 func grantsByDestination(query *querybuilder.Query, destination string) {
-	query.B("AND (resource = ? OR resource LIKE ?)", destination, destination+".%")
+	query.B("AND (resource = ? OR resource LIKE ?", destination, destination+".%")
+	// Underscore is a valid character in a destination name, but is a wildcard
+	// in LIKE, so it is escaped before * is converted to the LIKE wildcard.
+	query.B(`OR (resource LIKE '%*%' AND ? LIKE replace(replace(split_part(resource, '.', 1), '_', '\_'), '*', '%')))`, destination)
 }
 
 type GrantsMaxUpdateIndexOptions struct {
@@ -250,8 +268,13 @@ type DeleteGrantsOptions struct {
 	// BySubject instructs DeleteGrants to delete all grants that match this
 	// subject. When set other fields below this on this struct are ignored.
 	BySubject models.Subject
+	// ByResource instructs DeleteGrants to delete all grants with exactly
+	// this resource. When set other fields below this on this struct are
+	// ignored.
+	ByResource string
 	// ByDestination instructs DeleteGrants to delete all grants that match
-	// this destination in their resource, including namespaces.
+	// this destination in their resource, including namespaces. Grants with
+	// a pattern in their resource are not deleted.
 	ByDestination string
 }
 
@@ -271,8 +294,12 @@ func DeleteGrants(tx WriteTxn, opts DeleteGrantsOptions) error {
 		}
 		query.B("AND subject_id = ? AND subject_kind = ?",
 			opts.BySubject.ID, opts.BySubject.Kind)
+	case opts.ByResource != "":
+		query.B("AND resource = ?", opts.ByResource)
 	case opts.ByDestination != "":
-		grantsByDestination(query, opts.ByDestination)
+		// A grant with a pattern in the resource may apply to other
+		// destinations, so it is not deleted along with one destination.
+		query.B("AND (resource = ? OR resource LIKE ?)", opts.ByDestination, opts.ByDestination+".%")
 	default:
 		return fmt.Errorf("DeleteGrants requires an ID to delete")
 	}
@@ -390,6 +417,31 @@ func deleteGrantsBulk(tx WriteTxn, grants []*models.Grant) error {
 	return err
 }
 
+// DeleteExpiredGrants soft-deletes all grants that have expired. Deleting the
+// grants sets a new update_index, so that any blocking list requests will be
+// notified of the change.
+func DeleteExpiredGrants(tx WriteTxn) error {
+	now := time.Now()
+	query := querybuilder.New("UPDATE grants")
+	query.B("SET deleted_at = ?,", now)
+	query.B("update_index = nextval('seq_update_index')")
+	query.B("WHERE deleted_at is null")
+	query.B("AND expires_at <= ?", now)
+
+	result, err := tx.Exec(query.String(), query.Args...)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		logging.L.Info().Int64("count", count).Msg("removed expired grants")
+	}
+	return nil
+}
+
 func CountAllGrants(tx ReadTxn) (int64, error) {
 	return countRows(tx, grantsTable{})
 }
@@ -178,7 +178,8 @@ func TestDeleteGrants(t *testing.T) {
 			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
 
 			toKeep := &models.Grant{Subject: models.NewSubjectForUser(5555), Privilege: "view", Resource: "somethingelse"}
-			createGrants(t, tx, toKeep)
+			pattern := &models.Grant{Subject: models.NewSubjectForUser(5555), Privilege: "view", Resource: "an*"}
+			createGrants(t, tx, toKeep, pattern)
 
 			grants := []*models.Grant{
 				{Subject: models.NewSubjectForUser(5555), Privilege: "view", Resource: "any"},
@@ -189,7 +190,7 @@ func TestDeleteGrants(t *testing.T) {
 
 			actual, err := ListGrants(tx, ListGrantsOptions{BySubject: models.NewSubjectForUser(5555)})
 			assert.NilError(t, err)
-			assert.Equal(t, len(actual), 4)
+			assert.Equal(t, len(actual), 5)
 
 			err = DeleteGrants(tx, DeleteGrantsOptions{ByDestination: "any"})
 			assert.NilError(t, err)
@@ -198,6 +199,7 @@ func TestDeleteGrants(t *testing.T) {
 			assert.NilError(t, err)
 			expected := []models.Grant{
 				{Model: models.Model{ID: toKeep.ID}},
+				{Model: models.Model{ID: pattern.ID}},
 			}
 			assert.DeepEqual(t, actual, expected, cmpModelByID)
 		})
@@ -514,7 +516,14 @@ func TestListGrants(t *testing.T) {
 		}
 		deleted.DeletedAt.Time = time.Now()
 		deleted.DeletedAt.Valid = true
-		createGrants(t, tx, grant1, grant2, grant3, grant4, grant5, deleted)
+		expired := &models.Grant{
+			Subject:   models.NewSubjectForUser(userID),
+			Privilege: "expired",
+			Resource:  "any",
+			CreatedBy: uid.ID(777),
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}
+		createGrants(t, tx, grant1, grant2, grant3, grant4, grant5, deleted, expired)
 
 		assert.NilError(t, AddUsersToGroup(tx, uid.ID(111), []uid.ID{userID}))
 		assert.NilError(t, AddUsersToGroup(tx, uid.ID(112), []uid.ID{userID}))
@@ -622,6 +631,21 @@ func TestListGrants(t *testing.T) {
 			expected := []models.Grant{*grant1, *grant3, *grant4, *gGrant1, *gGrant2}
 			assert.DeepEqual(t, actual, expected, cmpModelByID)
 		})
+		t.Run("by group subject with include inherited", func(t *testing.T) {
+			assert.NilError(t, AddGroupsToGroup(tx, uid.ID(113), []uid.ID{uid.ID(111)}))
+			t.Cleanup(func() {
+				assert.NilError(t, RemoveGroupsFromGroup(tx, uid.ID(113), []uid.ID{uid.ID(111)}))
+			})
+
+			actual, err := ListGrants(tx, ListGrantsOptions{
+				BySubject:                  models.NewSubjectForGroup(111),
+				IncludeInheritedFromGroups: true,
+			})
+			assert.NilError(t, err)
+
+			expected := []models.Grant{*gGrant1, *gGrant3}
+			assert.DeepEqual(t, actual, expected, cmpModelByID)
+		})
 		t.Run("exclude connector grant", func(t *testing.T) {
 			actual, err := ListGrants(tx, ListGrantsOptions{ExcludeConnectorGrant: true})
 			assert.NilError(t, err)
@@ -675,6 +699,49 @@ func TestListGrants(t *testing.T) {
 	})
 }
 
+func TestDeleteExpiredGrants(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		expired := &models.Grant{
+			Subject:   models.NewSubjectForUser(1234),
+			Privilege: "view",
+			Resource:  "expired",
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}
+		notExpired := &models.Grant{
+			Subject:   models.NewSubjectForUser(1234),
+			Privilege: "view",
+			Resource:  "not-expired",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		noExpiry := &models.Grant{
+			Subject:   models.NewSubjectForUser(1234),
+			Privilege: "view",
+			Resource:  "no-expiry",
+		}
+		createGrants(t, tx, expired, notExpired, noExpiry)
+
+		before, err := GetGrant(tx, GetGrantOptions{ByID: expired.ID})
+		assert.NilError(t, err)
+
+		err = DeleteExpiredGrants(tx)
+		assert.NilError(t, err)
+
+		_, err = GetGrant(tx, GetGrantOptions{ByID: expired.ID})
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+
+		actual, err := ListGrants(tx, ListGrantsOptions{BySubject: models.NewSubjectForUser(1234)})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual, []models.Grant{*notExpired, *noExpiry}, cmpModelByID)
+
+		var updateIndex int64
+		err = tx.QueryRow(`SELECT update_index FROM grants WHERE id = ?`, expired.ID).Scan(&updateIndex)
+		assert.NilError(t, err)
+		assert.Assert(t, updateIndex > before.UpdateIndex)
+	})
+}
+
 func TestGrantsMaxUpdateIndex(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		t.Run("no results match the query", func(t *testing.T) {
@@ -796,6 +863,29 @@ func TestListenForGrantsNotify(t *testing.T) {
 						},
 						expectMatch: true,
 					},
+					{
+						name: "grant resource pattern match",
+						run: func(t *testing.T, tx WriteTxn) {
+							err := CreateGrant(tx, &models.Grant{
+								Subject:   models.NewSubjectForUser(1999),
+								Resource:  "my*.ns1",
+								Privilege: "admin",
+							})
+							assert.NilError(t, err)
+						},
+						expectMatch: true,
+					},
+					{
+						name: "grant resource pattern does not match",
+						run: func(t *testing.T, tx WriteTxn) {
+							err := CreateGrant(tx, &models.Grant{
+								Subject:   models.NewSubjectForUser(1999),
+								Resource:  "other*",
+								Privilege: "admin",
+							})
+							assert.NilError(t, err)
+						},
+					},
 					{
 						name: "different org",
 						run: func(t *testing.T, tx WriteTxn) {
@@ -843,6 +933,37 @@ func isNotBlocked[T any](t *testing.T, ch chan T) (result T) {
 	}
 }
 
+func TestListGrants_ByDestinationPattern(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		user := models.NewSubjectForUser(4321)
+		var (
+			exact     = &models.Grant{Subject: user, Privilege: "view", Resource: "prod-us-1"}
+			namespace = &models.Grant{Subject: user, Privilege: "view", Resource: "prod-us-1.default"}
+			prefix    = &models.Grant{Subject: user, Privilege: "view", Resource: "prod-*"}
+			middle    = &models.Grant{Subject: user, Privilege: "view", Resource: "*-us-*.monitoring"}
+			other     = &models.Grant{Subject: user, Privilege: "view", Resource: "staging-*"}
+			similar   = &models.Grant{Subject: user, Privilege: "view", Resource: "prod_us_1"}
+			escaped   = &models.Grant{Subject: user, Privilege: "view", Resource: "prod_*"}
+		)
+		createGrants(t, tx, exact, namespace, prefix, middle, other, similar, escaped)
+
+		actual, err := ListGrants(tx, ListGrantsOptions{ByDestination: "prod-us-1"})
+		assert.NilError(t, err)
+		expected := []models.Grant{*exact, *namespace, *prefix, *middle}
+		assert.DeepEqual(t, actual, expected, cmpModelByID)
+
+		actual, err = ListGrants(tx, ListGrantsOptions{ByDestination: "staging-eu"})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual, []models.Grant{*other}, cmpModelByID)
+
+		maxIndex, err := GrantsMaxUpdateIndex(tx, GrantsMaxUpdateIndexOptions{ByDestination: "staging-eu"})
+		assert.NilError(t, err)
+		assert.Equal(t, maxIndex, actual[0].UpdateIndex)
+	})
+}
+
 func TestCountAllGrants(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		createGrants(t, db,
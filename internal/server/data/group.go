@@ -1,9 +1,11 @@
 package data
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data/querybuilder"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -16,15 +18,15 @@ func (g groupsTable) Table() string {
 }
 
 func (g groupsTable) Columns() []string {
-	return []string{"created_at", "created_by", "created_by_provider", "deleted_at", "id", "name", "organization_id", "updated_at"}
+	return []string{"created_at", "created_by", "created_by_provider", "deleted_at", "id", "name", "organization_id", "provisioned_by_scim", "updated_at"}
 }
 
 func (g groupsTable) Values() []any {
-	return []any{g.CreatedAt, g.CreatedBy, g.CreatedByProvider, g.DeletedAt, g.ID, g.Name, g.OrganizationID, g.UpdatedAt}
+	return []any{g.CreatedAt, g.CreatedBy, g.CreatedByProvider, g.DeletedAt, g.ID, g.Name, g.OrganizationID, g.ProvisionedBySCIM, g.UpdatedAt}
 }
 
 func (g *groupsTable) ScanFields() []any {
-	return []any{&g.CreatedAt, &g.CreatedBy, &g.CreatedByProvider, &g.DeletedAt, &g.ID, &g.Name, &g.OrganizationID, &g.UpdatedAt}
+	return []any{&g.CreatedAt, &g.CreatedBy, &g.CreatedByProvider, &g.DeletedAt, &g.ID, &g.Name, &g.OrganizationID, &g.ProvisionedBySCIM, &g.UpdatedAt}
 }
 
 func CreateGroup(tx WriteTxn, group *models.Group) error {
@@ -75,15 +77,21 @@ type ListGroupsOptions struct {
 	// ByGroupMember instructs ListGroups to return groups where this user ID
 	// is a member of the group.
 	ByGroupMember uid.ID
+	// ByCreatedByProvider instructs ListGroups to return groups created by
+	// this provider.
+	ByCreatedByProvider uid.ID
 
 	Pagination *Pagination
+	// SCIMParameters filters and paginates the groups for a SCIM request. It
+	// is used instead of Pagination.
+	SCIMParameters *SCIMParameters
 }
 
 func ListGroups(tx ReadTxn, opts ListGroupsOptions) ([]models.Group, error) {
 	table := groupsTable{}
 	query := querybuilder.New("SELECT")
 	query.B(columnsForSelect(table))
-	if opts.Pagination != nil {
+	if opts.Pagination != nil || opts.SCIMParameters != nil {
 		query.B(", count(*) OVER()")
 	}
 	query.B("FROM groups")
@@ -101,11 +109,30 @@ func ListGroups(tx ReadTxn, opts ListGroupsOptions) ([]models.Group, error) {
 		query.B("AND groups.id IN")
 		queryInClause(query, opts.ByIDs)
 	}
+	if opts.ByCreatedByProvider != 0 {
+		query.B("AND created_by_provider = ?", opts.ByCreatedByProvider)
+	}
+	if opts.SCIMParameters != nil && opts.SCIMParameters.Filter != nil {
+		query.B("AND (")
+		if err := filterSQL(opts.SCIMParameters.Filter, query, groupSQLColumn); err != nil {
+			return nil, fmt.Errorf("apply filter: %w", err)
+		}
+		query.B(")")
+	}
 
 	query.B("ORDER BY name ASC")
 	if opts.Pagination != nil {
 		opts.Pagination.PaginateQuery(query)
 	}
+	if opts.SCIMParameters != nil {
+		if opts.SCIMParameters.Count != 0 {
+			query.B("LIMIT ?", opts.SCIMParameters.Count)
+		}
+		if opts.SCIMParameters.StartIndex > 0 {
+			offset := opts.SCIMParameters.StartIndex - 1 // start index begins at 1, not 0
+			query.B("OFFSET ?", offset)
+		}
+	}
 
 	rows, err := tx.Query(query.String(), query.Args...)
 	if err != nil {
@@ -113,14 +140,20 @@ func ListGroups(tx ReadTxn, opts ListGroupsOptions) ([]models.Group, error) {
 	}
 	result, err := scanRows(rows, func(group *models.Group) []any {
 		fields := (*groupsTable)(group).ScanFields()
-		if opts.Pagination != nil {
+		switch {
+		case opts.Pagination != nil:
 			fields = append(fields, &opts.Pagination.TotalCount)
+		case opts.SCIMParameters != nil:
+			fields = append(fields, &opts.SCIMParameters.TotalCount)
 		}
 		return fields
 	})
 	if err != nil {
 		return nil, err
 	}
+	if opts.SCIMParameters != nil && opts.SCIMParameters.Count == 0 {
+		opts.SCIMParameters.Count = opts.SCIMParameters.TotalCount
+	}
 
 	// TODO: do this in a single query
 	for i := range result {
@@ -133,9 +166,52 @@ func ListGroups(tx ReadTxn, opts ListGroupsOptions) ([]models.Group, error) {
 	return result, nil
 }
 
+// ListGroupIDsForUser returns the IDs of all the groups the user belongs to,
+// either directly or through a group nested in another group.
 func ListGroupIDsForUser(tx ReadTxn, userID uid.ID) ([]uid.ID, error) {
-	stmt := `SELECT DISTINCT group_id FROM identities_groups WHERE identity_id = ?`
-	rows, err := tx.Query(stmt, userID)
+	// UNION (instead of UNION ALL) removes duplicate rows, which stops the
+	// recursion if the groups contain a cycle.
+	stmt := `
+		WITH RECURSIVE user_groups(group_id) AS (
+			SELECT group_id FROM identities_groups WHERE identity_id = ?
+			UNION
+			SELECT groups_groups.parent_id FROM groups_groups
+			JOIN user_groups ON groups_groups.child_id = user_groups.group_id
+		)
+		SELECT group_id FROM user_groups`
+	return scanGroupIDs(tx, stmt, userID)
+}
+
+// ListDescendantGroupIDs returns the ID of the group, and the IDs of all the
+// groups nested in the group, either directly or through another nested group.
+func ListDescendantGroupIDs(tx ReadTxn, groupID uid.ID) ([]uid.ID, error) {
+	stmt := `
+		WITH RECURSIVE descendants(group_id) AS (
+			SELECT CAST(? AS bigint)
+			UNION
+			SELECT groups_groups.child_id FROM groups_groups
+			JOIN descendants ON groups_groups.parent_id = descendants.group_id
+		)
+		SELECT group_id FROM descendants`
+	return scanGroupIDs(tx, stmt, groupID)
+}
+
+// listAncestorGroupIDs returns the ID of the group, and the IDs of all the
+// groups that contain the group, either directly or through another group.
+func listAncestorGroupIDs(tx ReadTxn, groupID uid.ID) ([]uid.ID, error) {
+	stmt := `
+		WITH RECURSIVE ancestors(group_id) AS (
+			SELECT CAST(? AS bigint)
+			UNION
+			SELECT groups_groups.parent_id FROM groups_groups
+			JOIN ancestors ON groups_groups.child_id = ancestors.group_id
+		)
+		SELECT group_id FROM ancestors`
+	return scanGroupIDs(tx, stmt, groupID)
+}
+
+func scanGroupIDs(tx ReadTxn, stmt string, args ...any) ([]uid.ID, error) {
+	rows, err := tx.Query(stmt, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -152,17 +228,60 @@ func ListGroupIDsForUser(tx ReadTxn, userID uid.ID) ([]uid.ID, error) {
 	return result, rows.Err()
 }
 
+// UpdateGroup updates the name of a group. Grants to the group use the name of
+// the group as the resource, so they are updated to use the new name.
+func UpdateGroup(tx WriteTxn, group *models.Group) error {
+	current, err := GetGroup(tx, GetGroupOptions{ByID: group.ID})
+	if err != nil {
+		return err
+	}
+	if err := update(tx, (*groupsTable)(group)); err != nil {
+		return err
+	}
+	if current.Name == group.Name {
+		return nil
+	}
+
+	query := querybuilder.New("UPDATE grants")
+	query.B("SET resource = ?, updated_at = ?,", models.GroupResourcePrefix+group.Name, time.Now())
+	query.B("update_index = nextval('seq_update_index')")
+	query.B("WHERE organization_id = ?", tx.OrganizationID())
+	query.B("AND deleted_at is null")
+	query.B("AND resource = ?", models.GroupResourcePrefix+current.Name)
+	_, err = tx.Exec(query.String(), query.Args...)
+	return handleError(err)
+}
+
 func DeleteGroup(tx WriteTxn, id uid.ID) error {
 	err := DeleteGrants(tx, DeleteGrantsOptions{BySubject: models.NewSubjectForGroup(id)})
 	if err != nil {
 		return fmt.Errorf("remove grants: %w", err)
 	}
 
+	// remove grants to the group, so that they do not apply to a new group
+	// created with the same name
+	group, err := GetGroup(tx, GetGroupOptions{ByID: id})
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		err := DeleteGrants(tx, DeleteGrantsOptions{ByResource: models.GroupResourcePrefix + group.Name})
+		if err != nil {
+			return fmt.Errorf("remove grants to group: %w", err)
+		}
+	}
+
 	_, err = tx.Exec(`DELETE from identities_groups WHERE group_id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("remove users from group: %w", err)
 	}
 
+	_, err = tx.Exec(`DELETE from groups_groups WHERE parent_id = ? OR child_id = ?`, id, id)
+	if err != nil {
+		return fmt.Errorf("remove nested groups: %w", err)
+	}
+
 	stmt := `
 		UPDATE groups
 		SET deleted_at = ?
@@ -200,6 +319,51 @@ func RemoveUsersFromGroup(tx WriteTxn, groupID uid.ID, idsToRemove []uid.ID) err
 	return handleError(err)
 }
 
+// ErrGroupCycle is returned by AddGroupsToGroup when adding a group would
+// cause a group to contain itself.
+var ErrGroupCycle = errors.New("a group can not contain itself")
+
+// AddGroupsToGroup nests each group listed in idsToAdd in the group with ID
+// parentID. Members of the nested groups inherit the grants of the parent
+// group.
+func AddGroupsToGroup(tx WriteTxn, parentID uid.ID, idsToAdd []uid.ID) error {
+	ancestors, err := listAncestorGroupIDs(tx, parentID)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range ancestors {
+		for _, id := range idsToAdd {
+			if id == ancestor {
+				return fmt.Errorf("%w: group %v contains group %v", ErrGroupCycle, id, parentID)
+			}
+		}
+	}
+
+	query := querybuilder.New("INSERT INTO groups_groups(parent_id, child_id)")
+	query.B("VALUES")
+	for i, id := range idsToAdd {
+		query.B("(?, ?)", parentID, id)
+		if i+1 != len(idsToAdd) {
+			query.B(",")
+		}
+	}
+	query.B("ON CONFLICT DO NOTHING")
+
+	_, err = tx.Exec(query.String(), query.Args...)
+	return handleError(err)
+}
+
+// RemoveGroupsFromGroup removes any group ID listed in idsToRemove from the
+// group with ID parentID.
+func RemoveGroupsFromGroup(tx WriteTxn, parentID uid.ID, idsToRemove []uid.ID) error {
+	query := querybuilder.New(`DELETE FROM groups_groups`)
+	query.B(`WHERE parent_id = ?`, parentID)
+	query.B(`AND child_id IN`)
+	queryInClause(query, idsToRemove)
+	_, err := tx.Exec(query.String(), query.Args...)
+	return handleError(err)
+}
+
 func countUsersInGroup(tx ReadTxn, groupID uid.ID) (int64, error) {
 	stmt := `SELECT count(*) FROM identities_groups WHERE group_id = ?`
 	var count int64
@@ -7,6 +7,7 @@ import (
 
 	gocmp "github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/scim2/filter-parser/v2"
 	"gotest.tools/v3/assert"
 
 	"github.com/infrahq/infra/internal"
@@ -243,7 +244,12 @@ func TestDeleteGroup(t *testing.T) {
 			Privilege: "admin",
 			Resource:  "any",
 		}
-		createGrants(t, tx, groupGrant)
+		ownerGrant := &models.Grant{
+			Subject:   models.NewSubjectForUser(someone.ID),
+			Privilege: models.GroupOwnerRole,
+			Resource:  models.GroupResourcePrefix + everyone.Name,
+		}
+		createGrants(t, tx, groupGrant, ownerGrant)
 
 		otherOrgGroup := &models.Group{Name: "Everyone"}
 		createGroups(t, tx.WithOrgID(otherOrg.ID), otherOrgGroup)
@@ -272,6 +278,10 @@ func TestDeleteGroup(t *testing.T) {
 			grants, err := ListGrants(tx, ListGrantsOptions{BySubject: groupGrant.Subject})
 			assert.NilError(t, err)
 			assert.DeepEqual(t, grants, []models.Grant{}, cmpopts.EquateEmpty())
+
+			grants, err = ListGrants(tx, ListGrantsOptions{ByResource: ownerGrant.Resource})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, grants, []models.Grant{}, cmpopts.EquateEmpty())
 		})
 		t.Run("delete non-existent", func(t *testing.T) {
 			err := DeleteGroup(tx, uid.ID(1234))
@@ -286,6 +296,76 @@ func TestDeleteGroup(t *testing.T) {
 	})
 }
 
+func TestUpdateGroup(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		group := &models.Group{Name: "Everyone", CreatedByProvider: uid.ID(2022)}
+		createGroups(t, tx, group)
+
+		groupGrant := &models.Grant{
+			Subject:   models.NewSubjectForGroup(group.ID),
+			Privilege: "admin",
+			Resource:  "any",
+		}
+		ownerGrant := &models.Grant{
+			Subject:   models.NewSubjectForUser(uid.ID(2023)),
+			Privilege: models.GroupOwnerRole,
+			Resource:  models.GroupResourcePrefix + group.Name,
+		}
+		createGrants(t, tx, groupGrant, ownerGrant)
+
+		group.Name = "Everybody"
+		assert.NilError(t, UpdateGroup(tx, group))
+
+		actual, err := GetGroup(tx, GetGroupOptions{ByID: group.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, actual.Name, "Everybody")
+		assert.Equal(t, actual.CreatedByProvider, uid.ID(2022))
+
+		// grants to the group use the new name
+		grants, err := ListGrants(tx, ListGrantsOptions{ByResource: models.GroupResourcePrefix + "Everybody"})
+		assert.NilError(t, err)
+		assert.Equal(t, len(grants), 1)
+		assert.Equal(t, grants[0].ID, ownerGrant.ID)
+
+		grants, err = ListGrants(tx, ListGrantsOptions{ByResource: ownerGrant.Resource})
+		assert.NilError(t, err)
+		assert.Equal(t, len(grants), 0)
+	})
+}
+
+func TestListGroups_SCIMParameters(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		var (
+			everyone    = &models.Group{Name: "Everyone", CreatedByProvider: uid.ID(2022)}
+			engineering = &models.Group{Name: "Engineering", CreatedByProvider: uid.ID(2022)}
+			product     = &models.Group{Name: "Product", CreatedByProvider: uid.ID(2022)}
+			manual      = &models.Group{Name: "Manual"}
+		)
+		createGroups(t, tx, everyone, engineering, product, manual)
+
+		p := &SCIMParameters{StartIndex: 2}
+		groups, err := ListGroups(tx, ListGroupsOptions{ByCreatedByProvider: uid.ID(2022), SCIMParameters: p})
+		assert.NilError(t, err)
+		assert.Equal(t, len(groups), 2)
+		assert.Equal(t, groups[0].Name, "Everyone")
+		assert.Equal(t, groups[1].Name, "Product")
+		assert.Equal(t, p.TotalCount, 3)
+		assert.Equal(t, p.Count, 3)
+
+		exp, err := filter.ParseFilter([]byte(`displayName eq "Product"`))
+		assert.NilError(t, err)
+		p = &SCIMParameters{Filter: exp}
+		groups, err = ListGroups(tx, ListGroupsOptions{ByCreatedByProvider: uid.ID(2022), SCIMParameters: p})
+		assert.NilError(t, err)
+		assert.Equal(t, len(groups), 1)
+		assert.Equal(t, groups[0].ID, product.ID)
+	})
+}
+
 func TestRecreateGroupSameName(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		var (
@@ -395,6 +475,101 @@ func TestRemoveUsersFromGroup(t *testing.T) {
 	})
 }
 
+func TestNestedGroups(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		var (
+			platform = models.Group{Name: "platform"}
+			sre      = models.Group{Name: "sre"}
+			oncall   = models.Group{Name: "oncall"}
+			other    = models.Group{Name: "other"}
+		)
+		createGroups(t, tx, &platform, &sre, &oncall, &other)
+
+		pager := models.Identity{
+			Name:   "pager@example.com",
+			Groups: []models.Group{oncall},
+		}
+		createIdentities(t, tx, &pager)
+
+		platformGrant := &models.Grant{
+			Subject:   models.NewSubjectForGroup(platform.ID),
+			Privilege: "view",
+			Resource:  "prod",
+		}
+		createGrants(t, tx, platformGrant)
+
+		sortIDs := cmpopts.SortSlices(func(a, b uid.ID) bool { return a < b })
+
+		assert.NilError(t, AddGroupsToGroup(tx, platform.ID, []uid.ID{sre.ID}))
+		assert.NilError(t, AddGroupsToGroup(tx, sre.ID, []uid.ID{oncall.ID}))
+
+		t.Run("list groups for user is transitive", func(t *testing.T) {
+			groupIDs, err := ListGroupIDsForUser(tx, pager.ID)
+			assert.NilError(t, err)
+			expected := []uid.ID{oncall.ID, sre.ID, platform.ID}
+			assert.DeepEqual(t, groupIDs, expected, sortIDs)
+		})
+
+		t.Run("list descendant groups", func(t *testing.T) {
+			groupIDs, err := ListDescendantGroupIDs(tx, platform.ID)
+			assert.NilError(t, err)
+			expected := []uid.ID{platform.ID, sre.ID, oncall.ID}
+			assert.DeepEqual(t, groupIDs, expected, sortIDs)
+
+			groupIDs, err = ListDescendantGroupIDs(tx, other.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, groupIDs, []uid.ID{other.ID})
+		})
+
+		t.Run("grants are inherited from parent groups", func(t *testing.T) {
+			grants, err := ListGrants(tx, ListGrantsOptions{
+				BySubject:                  models.NewSubjectForUser(pager.ID),
+				IncludeInheritedFromGroups: true,
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, len(grants), 1)
+			assert.Equal(t, grants[0].ID, platformGrant.ID)
+		})
+
+		t.Run("adding an existing child is a no-op", func(t *testing.T) {
+			err := AddGroupsToGroup(tx, platform.ID, []uid.ID{sre.ID})
+			assert.NilError(t, err)
+		})
+
+		t.Run("cycles are rejected", func(t *testing.T) {
+			err := AddGroupsToGroup(tx, oncall.ID, []uid.ID{platform.ID})
+			assert.Assert(t, errors.Is(err, ErrGroupCycle), err)
+
+			err = AddGroupsToGroup(tx, other.ID, []uid.ID{other.ID})
+			assert.Assert(t, errors.Is(err, ErrGroupCycle), err)
+		})
+
+		t.Run("remove groups from group", func(t *testing.T) {
+			err := RemoveGroupsFromGroup(tx, sre.ID, []uid.ID{oncall.ID})
+			assert.NilError(t, err)
+
+			groupIDs, err := ListGroupIDsForUser(tx, pager.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, groupIDs, []uid.ID{oncall.ID})
+		})
+
+		t.Run("delete group removes nesting", func(t *testing.T) {
+			err := DeleteGroup(tx, sre.ID)
+			assert.NilError(t, err)
+
+			groupIDs, err := ListGroupIDsForUser(tx, pager.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, groupIDs, []uid.ID{oncall.ID})
+
+			groupIDs, err = ListDescendantGroupIDs(tx, platform.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, groupIDs, []uid.ID{platform.ID})
+		})
+	})
+}
+
 func TestCountAllGroups(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		createGroups(t, db,
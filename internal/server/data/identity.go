@@ -61,13 +61,26 @@ func deduplicate(groups []string) []string {
 }
 
 // AssignIdentityToGroups updates the identity's group membership relations based on the provider user's groups
-// and returns the identity's current groups after the update has persisted them
+// and returns the identity's current groups after the update has persisted them.
+// The group mapping of the provider is applied to newGroups.
 func AssignIdentityToGroups(tx WriteTxn, user *models.ProviderUser, newGroups []string) ([]models.Group, error) {
 	identity, err := GetIdentity(tx, GetIdentityOptions{ByID: user.IdentityID, LoadGroups: true})
 	if err != nil {
 		return nil, err
 	}
 
+	// the social login provider is not stored, and has no group mapping
+	if len(newGroups) > 0 && user.ProviderID != models.InternalGoogleProviderID {
+		provider, err := GetProvider(tx, GetProviderOptions{ByID: user.ProviderID})
+		if err != nil {
+			return nil, fmt.Errorf("get provider: %w", err)
+		}
+		newGroups, err = provider.GroupMapping.Apply(provider.Name, newGroups)
+		if err != nil {
+			return nil, fmt.Errorf("group mapping: %w", err)
+		}
+	}
+
 	newGroups = deduplicate(newGroups)
 	oldGroups := user.Groups
 
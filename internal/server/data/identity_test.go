@@ -3,6 +3,7 @@ package data
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -842,6 +843,45 @@ func TestAssignIdentityToGroups(t *testing.T) {
 	})
 }
 
+func TestAssignIdentityToGroups_GroupMapping(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		provider := &models.Provider{
+			Name: "okta",
+			Kind: models.ProviderKindOkta,
+			GroupMapping: models.GroupMapping{
+				Include:            "^AAD-",
+				Rename:             []models.GroupRenameRule{{Match: "^AAD-(.+)-RW$", Replace: "$1"}},
+				PrefixProviderName: true,
+			},
+		}
+		assert.NilError(t, CreateProvider(tx, provider))
+
+		saved, err := GetProvider(tx, GetProviderOptions{ByID: provider.ID})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, saved.GroupMapping, provider.GroupMapping)
+
+		identity := &models.Identity{Name: "mapped@example.com"}
+		assert.NilError(t, CreateIdentity(tx, identity))
+		pu, err := CreateProviderUser(tx, provider, identity)
+		assert.NilError(t, err)
+
+		groups, err := AssignIdentityToGroups(tx, pu, []string{"AAD-Platform-RW", "AAD-Web-RO", "Everyone"})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(groups), 2)
+
+		actual := []string(pu.Groups)
+		sort.Strings(actual)
+		assert.DeepEqual(t, actual, []string{"okta/AAD-Web-RO", "okta/Platform"})
+
+		persisted, err := ListGroups(tx, ListGroupsOptions{ByGroupMember: identity.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, len(persisted), 2)
+	})
+}
+
 func TestCountAllIdentities(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *DB) {
 		createIdentities(t, db,
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+)
+
+// loginFailureWindow is how long failed login attempts are counted. The count
+// starts over after this much time without a failed attempt.
+const loginFailureWindow = 24 * time.Hour
+
+type LoginFailureKind string
+
+const (
+	// LoginFailureUser counts failed attempts to login with a username.
+	LoginFailureUser LoginFailureKind = "user"
+	// LoginFailureIP counts failed attempts to login from a source IP
+	// address, for any username.
+	LoginFailureIP LoginFailureKind = "ip"
+)
+
+// LoginFailureKey identifies a username or a source IP address that has
+// failed attempts to login.
+type LoginFailureKey struct {
+	Kind LoginFailureKind
+	Name string
+}
+
+type LoginLockoutOptions struct {
+	// MaxFailures is the number of failed attempts after which the key is
+	// locked. The key is never locked when MaxFailures is 0.
+	MaxFailures int
+	// Lockout is how long the key is locked after MaxFailures failed
+	// attempts. It doubles with every failed attempt after that, up to
+	// MaxLockout.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// lockoutDuration returns how long a key with failures is locked, or 0 if it
+// is not locked.
+func (o LoginLockoutOptions) lockoutDuration(failures int) time.Duration {
+	if o.MaxFailures == 0 || failures < o.MaxFailures {
+		return 0
+	}
+	lockout := float64(o.Lockout) * math.Pow(2, float64(failures-o.MaxFailures))
+	if o.MaxLockout > 0 && lockout > float64(o.MaxLockout) {
+		return o.MaxLockout
+	}
+	return time.Duration(lockout)
+}
+
+// LoginLockedUntil returns the latest time until which any of the keys are
+// locked. It returns a zero time if none of the keys are locked.
+func LoginLockedUntil(tx ReadTxn, keys ...LoginFailureKey) (time.Time, error) {
+	if len(keys) == 0 {
+		return time.Time{}, nil
+	}
+
+	query := querybuilder.New("SELECT max(locked_until) FROM login_failures")
+	query.B("WHERE organization_id = ? AND locked_until > ? AND (", tx.OrganizationID(), time.Now())
+	for i, key := range keys {
+		if i > 0 {
+			query.B("OR")
+		}
+		query.B("(kind = ? AND name = ?)", key.Kind, key.Name)
+	}
+	query.B(")")
+
+	var lockedUntil sql.NullTime
+	if err := tx.QueryRow(query.String(), query.Args...).Scan(&lockedUntil); err != nil {
+		return time.Time{}, handleError(err)
+	}
+	return lockedUntil.Time, nil
+}
+
+// RecordLoginFailure counts a failed attempt to login for the key, and locks
+// the key when there have been too many failed attempts. It returns the time
+// until which the key is locked, or a zero time if it is not locked.
+func RecordLoginFailure(tx WriteTxn, key LoginFailureKey, opts LoginLockoutOptions) (time.Time, error) {
+	now := time.Now()
+	stmt := `
+		INSERT INTO login_failures (organization_id, kind, name, failures, updated_at)
+		VALUES (?, ?, ?, 1, ?)
+		ON CONFLICT (organization_id, kind, name) DO UPDATE SET
+			failures = CASE WHEN login_failures.updated_at < ? THEN 1 ELSE login_failures.failures + 1 END,
+			updated_at = excluded.updated_at
+		RETURNING failures`
+
+	var failures int
+	err := tx.QueryRow(stmt, tx.OrganizationID(), key.Kind, key.Name, now, now.Add(-loginFailureWindow)).Scan(&failures)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("record login failure: %w", handleError(err))
+	}
+
+	lockout := opts.lockoutDuration(failures)
+	if lockout == 0 {
+		return time.Time{}, nil
+	}
+
+	lockedUntil := now.Add(lockout)
+	stmt = `UPDATE login_failures SET locked_until = ? WHERE organization_id = ? AND kind = ? AND name = ?`
+	if _, err := tx.Exec(stmt, lockedUntil, tx.OrganizationID(), key.Kind, key.Name); err != nil {
+		return time.Time{}, fmt.Errorf("lock login: %w", handleError(err))
+	}
+	return lockedUntil, nil
+}
+
+// DeleteLoginFailures removes the count of failed attempts for the key, which
+// also unlocks it.
+func DeleteLoginFailures(tx WriteTxn, key LoginFailureKey) error {
+	stmt := `DELETE FROM login_failures WHERE organization_id = ? AND kind = ? AND name = ?`
+	_, err := tx.Exec(stmt, tx.OrganizationID(), key.Kind, key.Name)
+	return handleError(err)
+}
+
+// DeleteExpiredLoginFailures removes failed attempts that are no longer
+// counted from all organizations.
+func DeleteExpiredLoginFailures(tx WriteTxn) error {
+	now := time.Now()
+	stmt := `
+		DELETE FROM login_failures
+		WHERE updated_at < ? AND (locked_until IS NULL OR locked_until < ?)`
+	_, err := tx.Exec(stmt, now.Add(-loginFailureWindow), now)
+	return err
+}
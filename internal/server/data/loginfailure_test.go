@@ -0,0 +1,124 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		opts := LoginLockoutOptions{MaxFailures: 3, Lockout: time.Minute, MaxLockout: 3 * time.Minute}
+		user := LoginFailureKey{Kind: LoginFailureUser, Name: "alice@example.com"}
+		ip := LoginFailureKey{Kind: LoginFailureIP, Name: "192.0.2.10"}
+
+		t.Run("locked after max failures", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			for i := 0; i < 2; i++ {
+				lockedUntil, err := RecordLoginFailure(tx, user, opts)
+				assert.NilError(t, err)
+				assert.Assert(t, lockedUntil.IsZero())
+			}
+
+			lockedUntil, err := LoginLockedUntil(tx, user, ip)
+			assert.NilError(t, err)
+			assert.Assert(t, lockedUntil.IsZero())
+
+			lockedUntil, err = RecordLoginFailure(tx, user, opts)
+			assert.NilError(t, err)
+			assert.Assert(t, lockedUntil.After(time.Now().Add(50*time.Second)))
+
+			actual, err := LoginLockedUntil(tx, ip, user)
+			assert.NilError(t, err)
+			assert.Equal(t, actual.Unix(), lockedUntil.Unix())
+
+			// the ip address is counted separately
+			actual, err = LoginLockedUntil(tx, ip)
+			assert.NilError(t, err)
+			assert.Assert(t, actual.IsZero())
+		})
+
+		t.Run("lockout doubles up to the max", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			var lockedUntil time.Time
+			for i := 0; i < 4; i++ {
+				var err error
+				lockedUntil, err = RecordLoginFailure(tx, user, opts)
+				assert.NilError(t, err)
+			}
+			assert.Assert(t, lockedUntil.After(time.Now().Add(110*time.Second)))
+
+			for i := 0; i < 3; i++ {
+				var err error
+				lockedUntil, err = RecordLoginFailure(tx, user, opts)
+				assert.NilError(t, err)
+			}
+			assert.Assert(t, lockedUntil.Before(time.Now().Add(3*time.Minute+time.Second)))
+		})
+
+		t.Run("delete unlocks", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			for i := 0; i < 3; i++ {
+				_, err := RecordLoginFailure(tx, user, opts)
+				assert.NilError(t, err)
+			}
+
+			assert.NilError(t, DeleteLoginFailures(tx, user))
+
+			lockedUntil, err := LoginLockedUntil(tx, user)
+			assert.NilError(t, err)
+			assert.Assert(t, lockedUntil.IsZero())
+
+			// the count starts over
+			lockedUntil, err = RecordLoginFailure(tx, user, opts)
+			assert.NilError(t, err)
+			assert.Assert(t, lockedUntil.IsZero())
+		})
+
+		t.Run("failures are scoped to the organization", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			for i := 0; i < 3; i++ {
+				_, err := RecordLoginFailure(tx, user, opts)
+				assert.NilError(t, err)
+			}
+
+			lockedUntil, err := LoginLockedUntil(tx.WithOrgID(12345), user)
+			assert.NilError(t, err)
+			assert.Assert(t, lockedUntil.IsZero())
+		})
+	})
+}
+
+func TestDeleteExpiredLoginFailures(t *testing.T) {
+	tx := setupDB(t)
+	opts := LoginLockoutOptions{MaxFailures: 1, Lockout: time.Minute}
+
+	expired := LoginFailureKey{Kind: LoginFailureUser, Name: "expired@example.com"}
+	_, err := RecordLoginFailure(tx, expired, LoginLockoutOptions{})
+	assert.NilError(t, err)
+	locked := LoginFailureKey{Kind: LoginFailureUser, Name: "locked@example.com"}
+	_, err = RecordLoginFailure(tx, locked, opts)
+	assert.NilError(t, err)
+
+	_, err = tx.Exec(`UPDATE login_failures SET updated_at = ?`, time.Now().Add(-25*time.Hour))
+	assert.NilError(t, err)
+
+	assert.NilError(t, DeleteExpiredLoginFailures(tx))
+
+	var names []string
+	rows, err := tx.Query(`SELECT name FROM login_failures`)
+	assert.NilError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		assert.NilError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.NilError(t, rows.Err())
+	assert.DeepEqual(t, names, []string{"locked@example.com"})
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -90,6 +91,25 @@ func migrations() []*migrator.Migration {
 		moveSettingsJWKOrganizations(),
 		addAccessKeyIssuedForKind(),
 		storeProviderUserGroupsArray(),
+		addGrantsExpiresAt(),
+		addAccessRequestsTable(),
+		addAuditEventsTable(),
+		addGroupsGroupsTable(),
+		addMFAColumns(),
+		addProviderSAMLColumns(),
+		addProviderLDAPColumns(),
+		addProviderGitHubColumns(),
+		addOrganizationPasswordPolicy(),
+		addLoginFailuresTable(),
+		addWorkloadIdentitiesTable(),
+		addAccessKeySessionColumns(),
+		addSigningKeysTable(),
+		addProviderUserEnterpriseColumns(),
+		addProviderSyncsTable(),
+		addProviderGroupMappingColumn(),
+		addProviderDomainsColumn(),
+		addSAMLRequestsTable(),
+		addGroupsProvisionedBySCIM(),
 		// next one here, then run `go test -run TestMigrations ./internal/server/data -update`
 	}
 }
@@ -1375,3 +1395,444 @@ func storeProviderUserGroupsArray() *migrator.Migration {
 		},
 	}
 }
+
+func addGrantsExpiresAt() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-03T10:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE grants ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+				CREATE INDEX IF NOT EXISTS idx_grants_expires_at ON grants USING btree (expires_at) WHERE (deleted_at IS NULL);
+			`)
+			return err
+		},
+	}
+}
+
+func addAccessRequestsTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-05T14:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS access_requests (
+	id bigint NOT NULL,
+	created_at timestamp with time zone,
+	updated_at timestamp with time zone,
+	deleted_at timestamp with time zone,
+	organization_id bigint NOT NULL,
+	user_id bigint NOT NULL,
+	privilege text NOT NULL,
+	resource text NOT NULL,
+	justification text,
+	duration bigint,
+	status text NOT NULL,
+	reviewed_by bigint,
+	reviewed_at timestamp with time zone,
+	grant_id bigint
+);
+
+ALTER TABLE ONLY access_requests DROP CONSTRAINT IF EXISTS access_requests_pkey;
+ALTER TABLE ONLY access_requests
+	ADD CONSTRAINT access_requests_pkey PRIMARY KEY (id);
+
+CREATE INDEX IF NOT EXISTS idx_access_requests_status ON access_requests
+	USING btree (organization_id, status) WHERE (deleted_at IS NULL);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addAuditEventsTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-07T09:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS audit_events (
+	id bigint NOT NULL,
+	created_at timestamp with time zone,
+	updated_at timestamp with time zone,
+	deleted_at timestamp with time zone,
+	organization_id bigint NOT NULL,
+	actor_id bigint,
+	actor_name text,
+	method text NOT NULL,
+	route text NOT NULL,
+	target_id bigint,
+	request text,
+	outcome text NOT NULL,
+	status_code integer
+);
+
+ALTER TABLE ONLY audit_events DROP CONSTRAINT IF EXISTS audit_events_pkey;
+ALTER TABLE ONLY audit_events
+	ADD CONSTRAINT audit_events_pkey PRIMARY KEY (id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events
+	USING btree (organization_id, created_at) WHERE (deleted_at IS NULL);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addGroupsGroupsTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-10T11:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS groups_groups (
+	parent_id bigint NOT NULL,
+	child_id bigint NOT NULL
+);
+
+ALTER TABLE ONLY groups_groups DROP CONSTRAINT IF EXISTS groups_groups_pkey;
+ALTER TABLE ONLY groups_groups
+	ADD CONSTRAINT groups_groups_pkey PRIMARY KEY (parent_id, child_id);
+
+CREATE INDEX IF NOT EXISTS idx_groups_groups_child_id ON groups_groups USING btree (child_id);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addMFAColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-12T09:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS mfa_secret text DEFAULT ''::text;
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS mfa_enabled boolean DEFAULT false NOT NULL;
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS mfa_last_step bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS mfa_recovery_codes text DEFAULT ''::text;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS require_mfa boolean DEFAULT false NOT NULL;
+			`)
+			return err
+		},
+	}
+}
+
+func addProviderSAMLColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-14T09:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS saml_metadata text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS saml_groups_attribute text DEFAULT ''::text;
+			`)
+			return err
+		},
+	}
+}
+
+func addProviderLDAPColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-21T10:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_bind_dn text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_bind_password text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_base_dn text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_user_filter text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_group_base_dn text DEFAULT ''::text;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_start_tls boolean DEFAULT false NOT NULL;
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS ldap_ca_certificate text DEFAULT ''::text;
+			`)
+			return err
+		},
+	}
+}
+
+func addProviderGitHubColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-25T10:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE providers ADD COLUMN IF NOT EXISTS github_organizations text DEFAULT ''::text;
+			`)
+			return err
+		},
+	}
+}
+
+func addOrganizationPasswordPolicy() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-27T10:00",
+		Migrate: func(tx migrator.DB) error {
+			_, err := tx.Exec(`
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_min_length bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_lowercase_min bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_uppercase_min bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_number_min bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_symbol_min bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_max_age bigint DEFAULT 0 NOT NULL;
+				ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_history bigint DEFAULT 0 NOT NULL;
+
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS password_updated_at timestamp with time zone;
+				UPDATE credentials SET password_updated_at = COALESCE(updated_at, created_at, now())
+					WHERE password_updated_at IS NULL;
+				ALTER TABLE credentials ALTER COLUMN password_updated_at SET NOT NULL;
+				ALTER TABLE credentials ADD COLUMN IF NOT EXISTS password_hash_history text DEFAULT ''::text;
+			`)
+			return err
+		},
+	}
+}
+
+func addLoginFailuresTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-07-31T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS login_failures (
+	organization_id bigint NOT NULL,
+	kind text NOT NULL,
+	name text NOT NULL,
+	failures bigint DEFAULT 0 NOT NULL,
+	locked_until timestamp with time zone,
+	updated_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE ONLY login_failures DROP CONSTRAINT IF EXISTS login_failures_pkey;
+ALTER TABLE ONLY login_failures
+	ADD CONSTRAINT login_failures_pkey PRIMARY KEY (organization_id, kind, name);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_updated_at ON login_failures USING btree (updated_at);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addWorkloadIdentitiesTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-02T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS workload_identities (
+	id bigint NOT NULL,
+	created_at timestamp with time zone,
+	updated_at timestamp with time zone,
+	deleted_at timestamp with time zone,
+	organization_id bigint NOT NULL,
+	name text NOT NULL,
+	issuer text NOT NULL,
+	jwks_url text NOT NULL,
+	audience text NOT NULL,
+	subject text NOT NULL,
+	identity_id bigint NOT NULL,
+	scopes text
+);
+
+ALTER TABLE ONLY workload_identities DROP CONSTRAINT IF EXISTS workload_identities_pkey;
+ALTER TABLE ONLY workload_identities
+	ADD CONSTRAINT workload_identities_pkey PRIMARY KEY (id);
+
+CREATE INDEX IF NOT EXISTS idx_workload_identities_issuer ON workload_identities
+	USING btree (organization_id, issuer) WHERE (deleted_at IS NULL);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workload_identities_name ON workload_identities
+	USING btree (organization_id, name) WHERE (deleted_at IS NULL);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addAccessKeySessionColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-04T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS login_method text;
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS client_ip text;
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS user_agent text;
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addSigningKeysTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-06T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS signing_keys (
+	id bigint NOT NULL,
+	created_at timestamp with time zone,
+	updated_at timestamp with time zone,
+	deleted_at timestamp with time zone,
+	organization_id bigint NOT NULL,
+	key_id text NOT NULL,
+	private_jwk bytea NOT NULL,
+	public_jwk bytea NOT NULL,
+	state text NOT NULL
+);
+
+ALTER TABLE ONLY signing_keys DROP CONSTRAINT IF EXISTS signing_keys_pkey;
+ALTER TABLE ONLY signing_keys
+	ADD CONSTRAINT signing_keys_pkey PRIMARY KEY (id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_active ON signing_keys
+	USING btree (organization_id) WHERE (state = 'active' AND deleted_at IS NULL);
+`
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+
+			// the key of each existing organization becomes its active signing key
+			rows, err := tx.Query(`
+				SELECT id, private_jwk, public_jwk FROM organizations
+				WHERE deleted_at is null AND private_jwk is not null
+				AND id NOT IN (SELECT organization_id FROM signing_keys)`)
+			if err != nil {
+				return err
+			}
+			type orgKey struct {
+				orgID      uid.ID
+				privateJWK []byte
+				publicJWK  []byte
+			}
+			var keys []orgKey
+			for rows.Next() {
+				var key orgKey
+				if err := rows.Scan(&key.orgID, &key.privateJWK, &key.publicJWK); err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+			if err := rows.Close(); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			for _, key := range keys {
+				var pub struct {
+					KeyID string `json:"kid"`
+				}
+				if err := json.Unmarshal(key.publicJWK, &pub); err != nil {
+					return fmt.Errorf("public key of organization %v: %w", key.orgID, err)
+				}
+				_, err := tx.Exec(`
+					INSERT INTO signing_keys(id, created_at, updated_at, organization_id, key_id, private_jwk, public_jwk, state)
+					VALUES (?, ?, ?, ?, ?, ?, ?, 'active')`,
+					uid.New(), now, now, key.orgID, pub.KeyID, key.privateJWK, key.publicJWK)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func addProviderUserEnterpriseColumns() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-14T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+ALTER TABLE provider_users ADD COLUMN IF NOT EXISTS department text DEFAULT '';
+ALTER TABLE provider_users ADD COLUMN IF NOT EXISTS employee_number text DEFAULT '';
+ALTER TABLE provider_users ADD COLUMN IF NOT EXISTS manager text DEFAULT '';
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addProviderSyncsTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-16T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS provider_syncs (
+	provider_id bigint NOT NULL,
+	organization_id bigint NOT NULL,
+	status text NOT NULL,
+	started_at timestamp with time zone NOT NULL,
+	finished_at timestamp with time zone,
+	users_synced bigint DEFAULT 0 NOT NULL,
+	users_deactivated bigint DEFAULT 0 NOT NULL,
+	users_failed bigint DEFAULT 0 NOT NULL,
+	error text DEFAULT '' NOT NULL
+);
+
+ALTER TABLE ONLY provider_syncs DROP CONSTRAINT IF EXISTS provider_syncs_pkey;
+ALTER TABLE ONLY provider_syncs
+	ADD CONSTRAINT provider_syncs_pkey PRIMARY KEY (provider_id);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addProviderGroupMappingColumn() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-18T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+ALTER TABLE providers ADD COLUMN IF NOT EXISTS group_mapping jsonb DEFAULT '{}'::jsonb NOT NULL;
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addProviderDomainsColumn() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-21T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `ALTER TABLE providers ADD COLUMN IF NOT EXISTS domains text DEFAULT ''`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addSAMLRequestsTable() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-28T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `
+CREATE TABLE IF NOT EXISTS saml_requests (
+	organization_id bigint NOT NULL,
+	provider_id bigint NOT NULL,
+	request_id text NOT NULL,
+	assertion_id text,
+	expires_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE ONLY saml_requests DROP CONSTRAINT IF EXISTS saml_requests_pkey;
+ALTER TABLE ONLY saml_requests
+	ADD CONSTRAINT saml_requests_pkey PRIMARY KEY (organization_id, request_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saml_requests_assertion_id ON saml_requests USING btree (provider_id, assertion_id);
+CREATE INDEX IF NOT EXISTS idx_saml_requests_expires_at ON saml_requests USING btree (expires_at);
+`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
+
+func addGroupsProvisionedBySCIM() *migrator.Migration {
+	return &migrator.Migration{
+		ID: "2023-08-30T10:00",
+		Migrate: func(tx migrator.DB) error {
+			stmt := `ALTER TABLE groups ADD COLUMN IF NOT EXISTS provisioned_by_scim boolean DEFAULT false`
+			_, err := tx.Exec(stmt)
+			return err
+		},
+	}
+}
@@ -1238,6 +1238,154 @@ INSERT INTO providers(id, name) VALUES (12345, 'okta');
 				assert.DeepEqual(t, expectedKey, providerUser)
 			},
 		},
+		{
+			label: testCaseLine(addGrantsExpiresAt().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addAccessRequestsTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addAuditEventsTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addGroupsGroupsTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addMFAColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderSAMLColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderLDAPColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderGitHubColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addOrganizationPasswordPolicy().ID),
+			setup: func(t *testing.T, tx WriteTxn) {
+				stmt := `
+INSERT INTO credentials(id, created_at, updated_at, identity_id, password_hash, organization_id)
+VALUES (1001, '2023-01-01 00:00:00+00', '2023-02-01 00:00:00+00', 1002, 'hash', 1000);`
+				_, err := tx.Exec(stmt)
+				assert.NilError(t, err)
+			},
+			expected: func(t *testing.T, tx WriteTxn) {
+				var updatedAt time.Time
+				err := tx.QueryRow(`SELECT password_updated_at FROM credentials WHERE id = 1001`).Scan(&updatedAt)
+				assert.NilError(t, err)
+				assert.Equal(t, updatedAt.UTC(), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+			},
+		},
+		{
+			label: testCaseLine(addLoginFailuresTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addWorkloadIdentitiesTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addAccessKeySessionColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addSigningKeysTable().ID),
+			setup: func(t *testing.T, tx WriteTxn) {
+				_, err := tx.Exec(`
+					INSERT INTO organizations(id, name, domain, private_jwk, public_jwk)
+					VALUES (3001, '202308061000', '202308061000.example.com', ?, ?)`,
+					models.EncryptedAtRest("the-private-key"), []byte(`{"kid":"the-key-id"}`))
+				assert.NilError(t, err)
+			},
+			cleanup: func(t *testing.T, tx WriteTxn) {
+				_, err := tx.Exec(`DELETE FROM signing_keys WHERE organization_id = 3001`)
+				assert.NilError(t, err)
+				_, err = tx.Exec(`DELETE FROM organizations WHERE id = 3001`)
+				assert.NilError(t, err)
+			},
+			expected: func(t *testing.T, tx WriteTxn) {
+				var keyID, state string
+				var privateJWK models.EncryptedAtRest
+				var publicJWK []byte
+				err := tx.QueryRow(`
+					SELECT key_id, private_jwk, public_jwk, state FROM signing_keys
+					WHERE organization_id = 3001`).Scan(&keyID, &privateJWK, &publicJWK, &state)
+				assert.NilError(t, err)
+
+				assert.Equal(t, keyID, "the-key-id")
+				assert.Equal(t, string(privateJWK), "the-private-key")
+				assert.Equal(t, string(publicJWK), `{"kid":"the-key-id"}`)
+				assert.Equal(t, state, "active")
+			},
+		},
+		{
+			label: testCaseLine(addProviderUserEnterpriseColumns().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderSyncsTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderGroupMappingColumn().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addProviderDomainsColumn().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addSAMLRequestsTable().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
+		{
+			label: testCaseLine(addGroupsProvisionedBySCIM().ID),
+			expected: func(t *testing.T, tx WriteTxn) {
+				// schema changes are tested with schema comparison
+			},
+		},
 	}
 
 	ids := make(map[string]struct{}, len(testCases))
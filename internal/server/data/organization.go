@@ -1,10 +1,6 @@
 package data
 
 import (
-	"crypto"
-	"crypto/ed25519"
-	"crypto/rand"
-	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -22,15 +18,15 @@ func (organizationsTable) Table() string {
 }
 
 func (o organizationsTable) Columns() []string {
-	return []string{"created_at", "created_by", "deleted_at", "domain", "id", "name", "updated_at", "allowed_domains", "private_jwk", "public_jwk", "install_id"}
+	return []string{"created_at", "created_by", "deleted_at", "domain", "id", "name", "updated_at", "allowed_domains", "private_jwk", "public_jwk", "install_id", "require_mfa", "password_min_length", "password_lowercase_min", "password_uppercase_min", "password_number_min", "password_symbol_min", "password_max_age", "password_history"}
 }
 
 func (o organizationsTable) Values() []any {
-	return []any{o.CreatedAt, o.CreatedBy, o.DeletedAt, o.Domain, o.ID, o.Name, o.UpdatedAt, o.AllowedDomains, o.PrivateJWK, o.PublicJWK, o.InstallID}
+	return []any{o.CreatedAt, o.CreatedBy, o.DeletedAt, o.Domain, o.ID, o.Name, o.UpdatedAt, o.AllowedDomains, o.PrivateJWK, o.PublicJWK, o.InstallID, o.RequireMFA, o.PasswordMinLength, o.PasswordLowercaseMin, o.PasswordUppercaseMin, o.PasswordNumberMin, o.PasswordSymbolMin, o.PasswordMaxAge, o.PasswordHistory}
 }
 
 func (o *organizationsTable) ScanFields() []any {
-	return []any{&o.CreatedAt, &o.CreatedBy, &o.DeletedAt, &o.Domain, &o.ID, &o.Name, &o.UpdatedAt, &o.AllowedDomains, &o.PrivateJWK, &o.PublicJWK, &o.InstallID}
+	return []any{&o.CreatedAt, &o.CreatedBy, &o.DeletedAt, &o.Domain, &o.ID, &o.Name, &o.UpdatedAt, &o.AllowedDomains, &o.PrivateJWK, &o.PublicJWK, &o.InstallID, &o.RequireMFA, &o.PasswordMinLength, &o.PasswordLowercaseMin, &o.PasswordUppercaseMin, &o.PasswordNumberMin, &o.PasswordSymbolMin, &o.PasswordMaxAge, &o.PasswordHistory}
 }
 
 // CreateOrganization creates a new organization, and initializes it with
@@ -40,35 +36,24 @@ func CreateOrganization(tx WriteTxn, org *models.Organization) error {
 		return fmt.Errorf("Organization.Name is required")
 	}
 
+	signingKey, err := newSigningKey()
+	if err != nil {
+		return err
+	}
 	if org.PrivateJWK == "" && org.PublicJWK == nil {
-		pubkey, seckey, err := ed25519.GenerateKey(rand.Reader)
-		if err != nil {
-			return err
-		}
-
-		sec := jose.JSONWebKey{Key: seckey, KeyID: "", Algorithm: string(jose.ED25519), Use: "sig"}
-
-		thumb, err := sec.Thumbprint(crypto.SHA256)
-		if err != nil {
-			return err
-		}
-
-		sec.KeyID = base64.URLEncoding.EncodeToString(thumb)
-
-		pub := jose.JSONWebKey{Key: pubkey, KeyID: sec.KeyID, Algorithm: string(jose.ED25519), Use: "sig"}
-
-		secs, err := sec.MarshalJSON()
-		if err != nil {
-			return err
-		}
-
-		pubs, err := pub.MarshalJSON()
-		if err != nil {
-			return err
+		// the first signing key is also stored on the organization, so that
+		// servers that do not read signing keys can continue to sign tokens
+		// while the servers are upgraded.
+		org.PrivateJWK = signingKey.PrivateJWK
+		org.PublicJWK = signingKey.PublicJWK
+	} else {
+		signingKey.PrivateJWK = org.PrivateJWK
+		signingKey.PublicJWK = org.PublicJWK
+		var pub jose.JSONWebKey
+		if err := pub.UnmarshalJSON(org.PublicJWK); err != nil {
+			return fmt.Errorf("organization public key: %w", err)
 		}
-
-		org.PrivateJWK = models.EncryptedAtRest(secs)
-		org.PublicJWK = pubs
+		signingKey.KeyID = pub.KeyID
 	}
 
 	if org.InstallID == 0 {
@@ -79,6 +64,11 @@ func CreateOrganization(tx WriteTxn, org *models.Organization) error {
 		return fmt.Errorf("creating org: %w", err)
 	}
 
+	signingKey.OrganizationID = org.ID
+	if err := createSigningKey(tx, signingKey); err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
 	infraProvider := &models.Provider{
 		Name:               models.InternalInfraProviderName,
 		Kind:               models.ProviderKindInfra,
@@ -99,7 +89,7 @@ func CreateOrganization(tx WriteTxn, org *models.Organization) error {
 		return fmt.Errorf("failed to create connector identity while creating org: %w", err)
 	}
 
-	err := CreateGrant(tx, &models.Grant{
+	err = CreateGrant(tx, &models.Grant{
 		Subject:            models.NewSubjectForUser(connector.ID),
 		Privilege:          models.InfraConnectorRole,
 		Resource:           "infra",
@@ -18,15 +18,15 @@ func (p providersTable) Table() string {
 }
 
 func (p providersTable) Columns() []string {
-	return []string{"auth_url", "client_email", "client_id", "client_secret", "created_at", "created_by", "deleted_at", "domain_admin_email", "id", "kind", "name", "organization_id", "private_key", "scopes", "updated_at", "url"}
+	return []string{"auth_url", "client_email", "client_id", "client_secret", "created_at", "created_by", "deleted_at", "domain_admin_email", "domains", "github_organizations", "group_mapping", "id", "kind", "ldap_base_dn", "ldap_bind_dn", "ldap_bind_password", "ldap_ca_certificate", "ldap_group_base_dn", "ldap_start_tls", "ldap_user_filter", "name", "organization_id", "private_key", "saml_groups_attribute", "saml_metadata", "scopes", "updated_at", "url"}
 }
 
 func (p providersTable) Values() []any {
-	return []any{p.AuthURL, p.ClientEmail, p.ClientID, p.ClientSecret, p.CreatedAt, p.CreatedBy, p.DeletedAt, p.DomainAdminEmail, p.ID, p.Kind, p.Name, p.OrganizationID, p.PrivateKey, p.Scopes, p.UpdatedAt, p.URL}
+	return []any{p.AuthURL, p.ClientEmail, p.ClientID, p.ClientSecret, p.CreatedAt, p.CreatedBy, p.DeletedAt, p.DomainAdminEmail, p.Domains, p.GitHubOrganizations, p.GroupMapping, p.ID, p.Kind, p.LDAPBaseDN, p.LDAPBindDN, p.LDAPBindPassword, p.LDAPCACertificate, p.LDAPGroupBaseDN, p.LDAPStartTLS, p.LDAPUserFilter, p.Name, p.OrganizationID, p.PrivateKey, p.SAMLGroupsAttribute, p.SAMLMetadata, p.Scopes, p.UpdatedAt, p.URL}
 }
 
 func (p *providersTable) ScanFields() []any {
-	return []any{&p.AuthURL, &p.ClientEmail, &p.ClientID, &p.ClientSecret, &p.CreatedAt, &p.CreatedBy, &p.DeletedAt, &p.DomainAdminEmail, &p.ID, &p.Kind, &p.Name, &p.OrganizationID, &p.PrivateKey, &p.Scopes, &p.UpdatedAt, &p.URL}
+	return []any{&p.AuthURL, &p.ClientEmail, &p.ClientID, &p.ClientSecret, &p.CreatedAt, &p.CreatedBy, &p.DeletedAt, &p.DomainAdminEmail, &p.Domains, &p.GitHubOrganizations, &p.GroupMapping, &p.ID, &p.Kind, &p.LDAPBaseDN, &p.LDAPBindDN, &p.LDAPBindPassword, &p.LDAPCACertificate, &p.LDAPGroupBaseDN, &p.LDAPStartTLS, &p.LDAPUserFilter, &p.Name, &p.OrganizationID, &p.PrivateKey, &p.SAMLGroupsAttribute, &p.SAMLMetadata, &p.Scopes, &p.UpdatedAt, &p.URL}
 }
 
 func validateProvider(p *models.Provider) error {
@@ -95,6 +95,9 @@ type ListProvidersOptions struct {
 	ByName               string
 	ExcludeInfraProvider bool
 	ByIDs                []uid.ID
+	// ByDomain instructs ListProviders to return the providers that have
+	// this email domain in their list of domains.
+	ByDomain string
 
 	Pagination *Pagination
 }
@@ -120,6 +123,9 @@ func ListProviders(tx ReadTxn, opts ListProvidersOptions) ([]models.Provider, er
 		query.B("AND id IN")
 		queryInClause(query, opts.ByIDs)
 	}
+	if opts.ByDomain != "" {
+		query.B("AND ? = ANY(string_to_array(domains, ','))", opts.ByDomain)
+	}
 
 	query.B("ORDER BY name ASC")
 	if opts.Pagination != nil {
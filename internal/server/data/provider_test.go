@@ -165,6 +165,7 @@ func TestListProviders(t *testing.T) {
 			URL:       "prod.okta.com",
 			Kind:      models.ProviderKindOkta,
 			CreatedBy: 777,
+			Domains:   []string{"example.com", "corp.example.com"},
 		}
 		deleted := &models.Provider{
 			Name:      "deleted",
@@ -224,6 +225,19 @@ func TestListProviders(t *testing.T) {
 			expected := []models.Provider{*providerInfra, *providerDev}
 			assert.DeepEqual(t, expected, actual, cmpModelByID)
 		})
+		t.Run("by domain", func(t *testing.T) {
+			actual, err := ListProviders(db, ListProvidersOptions{
+				ByDomain: "corp.example.com",
+			})
+			assert.NilError(t, err)
+
+			expected := []models.Provider{*providerProd}
+			assert.DeepEqual(t, expected, actual, cmpModelByID)
+
+			actual, err = ListProviders(db, ListProvidersOptions{ByDomain: "example.org"})
+			assert.NilError(t, err)
+			assert.Equal(t, len(actual), 0)
+		})
 		t.Run("pagination", func(t *testing.T) {
 			page := Pagination{Page: 2, Limit: 2}
 			actual, err := ListProviders(db, ListProvidersOptions{Pagination: &page})
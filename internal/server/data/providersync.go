@@ -0,0 +1,122 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// ProviderSyncTimeout is how long a directory sync can run before another sync
+// of the same provider can start. It allows a new sync to start when the
+// server running the previous sync stopped before it finished.
+var ProviderSyncTimeout = time.Hour
+
+// ErrProviderSyncRunning is returned by StartProviderSync when a sync of the
+// provider is already running.
+var ErrProviderSyncRunning = errors.New("a sync of this provider is already running")
+
+type providerSyncsTable models.ProviderSync
+
+func (p providerSyncsTable) Table() string {
+	return "provider_syncs"
+}
+
+func (p providerSyncsTable) Columns() []string {
+	return []string{"error", "finished_at", "organization_id", "provider_id", "started_at", "status", "users_deactivated", "users_failed", "users_synced"}
+}
+
+func (p providerSyncsTable) Values() []any {
+	return []any{p.Error, optionalTime(p.FinishedAt), p.OrganizationID, p.ProviderID, p.StartedAt, p.Status, p.UsersDeactivated, p.UsersFailed, p.UsersSynced}
+}
+
+func (p *providerSyncsTable) ScanFields() []any {
+	return []any{&p.Error, (*optionalTime)(&p.FinishedAt), &p.OrganizationID, &p.ProviderID, &p.StartedAt, &p.Status, &p.UsersDeactivated, &p.UsersFailed, &p.UsersSynced}
+}
+
+// GetProviderSync returns the last directory sync of the provider. It returns
+// ErrNotFound if the provider has never been synced.
+func GetProviderSync(tx ReadTxn, providerID uid.ID) (*models.ProviderSync, error) {
+	sync := &providerSyncsTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(sync))
+	query.B("FROM provider_syncs")
+	query.B("WHERE organization_id = ? AND provider_id = ?", tx.OrganizationID(), providerID)
+
+	err := tx.QueryRow(query.String(), query.Args...).Scan(sync.ScanFields()...)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return (*models.ProviderSync)(sync), nil
+}
+
+// StartProviderSync records the start of a directory sync of the provider. It
+// returns ErrProviderSyncRunning if another sync of the provider started less
+// than ProviderSyncTimeout ago, and has not finished.
+func StartProviderSync(tx WriteTxn, providerID uid.ID) (*models.ProviderSync, error) {
+	sync := &providerSyncsTable{
+		OrganizationMember: models.OrganizationMember{OrganizationID: tx.OrganizationID()},
+		ProviderID:         providerID,
+		Status:             models.ProviderSyncStatusRunning,
+		StartedAt:          time.Now(),
+	}
+
+	query := querybuilder.New("INSERT INTO provider_syncs")
+	query.B("(provider_id, organization_id, status, started_at)")
+	query.B("VALUES (?, ?, ?, ?)", sync.ProviderID, sync.OrganizationID, sync.Status, sync.StartedAt)
+	query.B("ON CONFLICT (provider_id) DO UPDATE SET")
+	query.B("status = excluded.status, started_at = excluded.started_at, finished_at = null,")
+	query.B("users_synced = 0, users_deactivated = 0, users_failed = 0, error = ''")
+	query.B("WHERE provider_syncs.status <> ? OR provider_syncs.started_at < ?",
+		models.ProviderSyncStatusRunning, sync.StartedAt.Add(-ProviderSyncTimeout))
+	query.B("RETURNING provider_id")
+
+	var id uid.ID
+	err := tx.QueryRow(query.String(), query.Args...).Scan(&id)
+	switch {
+	case errors.Is(handleError(err), internal.ErrNotFound):
+		return nil, ErrProviderSyncRunning
+	case err != nil:
+		return nil, fmt.Errorf("start provider sync: %w", handleError(err))
+	}
+	return (*models.ProviderSync)(sync), nil
+}
+
+// FinishProviderSync records the result of a directory sync.
+func FinishProviderSync(tx WriteTxn, sync *models.ProviderSync) error {
+	sync.FinishedAt = time.Now()
+	query := querybuilder.New("UPDATE provider_syncs SET")
+	query.B("status = ?, finished_at = ?,", sync.Status, sync.FinishedAt)
+	query.B("users_synced = ?, users_deactivated = ?, users_failed = ?, error = ?",
+		sync.UsersSynced, sync.UsersDeactivated, sync.UsersFailed, sync.Error)
+	query.B("WHERE organization_id = ? AND provider_id = ?", tx.OrganizationID(), sync.ProviderID)
+	_, err := tx.Exec(query.String(), query.Args...)
+	return handleError(err)
+}
+
+// ListProvidersToSync returns the providers from all organizations that
+// support directory sync, and have not started a sync in the last interval.
+func ListProvidersToSync(tx ReadTxn, interval time.Duration) ([]models.Provider, error) {
+	table := providersTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	query.B("FROM providers")
+	query.B("LEFT JOIN provider_syncs ON provider_syncs.provider_id = providers.id")
+	query.B("WHERE providers.deleted_at is null")
+	query.B("AND providers.kind IN")
+	queryInClause(query, models.DirectorySyncProviderKinds)
+	query.B("AND (provider_syncs.started_at is null OR provider_syncs.started_at < ?)", time.Now().Add(-interval))
+	query.B("ORDER BY providers.id")
+
+	rows, err := tx.Query(query.String(), query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, func(provider *models.Provider) []any {
+		return (*providersTable)(provider).ScanFields()
+	})
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestProviderSync(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		okta := &models.Provider{Name: "okta", Kind: models.ProviderKindOkta}
+		assert.NilError(t, CreateProvider(tx, okta))
+		saml := &models.Provider{Name: "saml", Kind: models.ProviderKindSAML}
+		assert.NilError(t, CreateProvider(tx, saml))
+
+		_, err := GetProviderSync(tx, okta.ID)
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound), err)
+
+		toSync, err := ListProvidersToSync(tx, time.Hour)
+		assert.NilError(t, err)
+		assert.Equal(t, len(toSync), 1)
+		assert.Equal(t, toSync[0].ID, okta.ID)
+
+		sync, err := StartProviderSync(tx, okta.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, sync.Status, models.ProviderSyncStatusRunning)
+
+		t.Run("only one sync runs at a time", func(t *testing.T) {
+			_, err := StartProviderSync(tx, okta.ID)
+			assert.ErrorIs(t, err, ErrProviderSyncRunning)
+		})
+
+		t.Run("started providers are not listed", func(t *testing.T) {
+			toSync, err := ListProvidersToSync(tx, time.Hour)
+			assert.NilError(t, err)
+			assert.Equal(t, len(toSync), 0)
+		})
+
+		sync.Status = models.ProviderSyncStatusSucceeded
+		sync.UsersSynced = 3
+		sync.UsersDeactivated = 1
+		assert.NilError(t, FinishProviderSync(tx, sync))
+
+		actual, err := GetProviderSync(tx, okta.ID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual, sync, cmpTimeWithDBPrecision)
+
+		t.Run("finished sync can be started again", func(t *testing.T) {
+			again, err := StartProviderSync(tx, okta.ID)
+			assert.NilError(t, err)
+			assert.Equal(t, again.Status, models.ProviderSyncStatusRunning)
+
+			actual, err := GetProviderSync(tx, okta.ID)
+			assert.NilError(t, err)
+			assert.Equal(t, actual.UsersSynced, 0)
+			assert.Assert(t, actual.FinishedAt.IsZero())
+		})
+
+		t.Run("sync that timed out can be started again", func(t *testing.T) {
+			_, err := tx.Exec(`UPDATE provider_syncs SET started_at = ? WHERE provider_id = ?`,
+				time.Now().Add(-2*ProviderSyncTimeout), okta.ID)
+			assert.NilError(t, err)
+
+			toSync, err := ListProvidersToSync(tx, time.Hour)
+			assert.NilError(t, err)
+			assert.Equal(t, len(toSync), 1)
+
+			_, err = StartProviderSync(tx, okta.ID)
+			assert.NilError(t, err)
+		})
+	})
+}
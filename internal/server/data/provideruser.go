@@ -23,15 +23,15 @@ func (p providerUserTable) Table() string {
 }
 
 func (p providerUserTable) Columns() []string {
-	return []string{"identity_id", "provider_id", "email", "groups", "last_update", "redirect_url", "access_token", "refresh_token", "expires_at", "given_name", "family_name", "active"}
+	return []string{"identity_id", "provider_id", "email", "groups", "last_update", "redirect_url", "access_token", "refresh_token", "expires_at", "given_name", "family_name", "active", "department", "employee_number", "manager"}
 }
 
 func (p providerUserTable) Values() []any {
-	return []any{p.IdentityID, p.ProviderID, p.Email, p.Groups, p.LastUpdate, p.RedirectURL, p.AccessToken, p.RefreshToken, p.ExpiresAt, p.GivenName, p.FamilyName, p.Active}
+	return []any{p.IdentityID, p.ProviderID, p.Email, p.Groups, p.LastUpdate, p.RedirectURL, p.AccessToken, p.RefreshToken, p.ExpiresAt, p.GivenName, p.FamilyName, p.Active, p.Department, p.EmployeeNumber, p.Manager}
 }
 
 func (p *providerUserTable) ScanFields() []any {
-	return []any{&p.IdentityID, &p.ProviderID, &p.Email, &p.Groups, &p.LastUpdate, &p.RedirectURL, &p.AccessToken, &p.RefreshToken, &p.ExpiresAt, &p.GivenName, &p.FamilyName, &p.Active}
+	return []any{&p.IdentityID, &p.ProviderID, &p.Email, &p.Groups, &p.LastUpdate, &p.RedirectURL, &p.AccessToken, &p.RefreshToken, &p.ExpiresAt, &p.GivenName, &p.FamilyName, &p.Active, &p.Department, &p.EmployeeNumber, &p.Manager}
 }
 
 func (p *providerUserTable) OnInsert() error {
@@ -168,7 +168,7 @@ func ListProviderUsers(tx ReadTxn, opts ListProviderUsersOptions) ([]models.Prov
 	}
 	if opts.SCIMParameters != nil && opts.SCIMParameters.Filter != nil {
 		query.B("AND (")
-		err := filterSQL(opts.SCIMParameters.Filter, query)
+		err := filterSQL(opts.SCIMParameters.Filter, query, sqlColumn)
 		if err != nil {
 			return nil, fmt.Errorf("apply filter: %w", err)
 		}
@@ -301,6 +301,18 @@ func ProvisionProviderUser(tx WriteTxn, user *models.ProviderUser) error {
 }
 
 func SyncProviderUser(ctx context.Context, tx WriteTxn, user *models.ProviderUser, oidcClient providers.OIDCClient) ([]models.Group, error) {
+	info, err := GetProviderUserInfo(ctx, tx, user, oidcClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return AssignIdentityToGroups(tx, user, info.Groups)
+}
+
+// GetProviderUserInfo returns the user info from the identity provider,
+// including the groups of the user before the group mapping of the provider is
+// applied. The access token of the user is refreshed if it has expired.
+func GetProviderUserInfo(ctx context.Context, tx WriteTxn, user *models.ProviderUser, oidcClient providers.OIDCClient) (*providers.UserInfoClaims, error) {
 	accessToken, expiry, err := oidcClient.RefreshAccessToken(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("refresh provider access: %w", err)
@@ -323,8 +335,7 @@ func SyncProviderUser(ctx context.Context, tx WriteTxn, user *models.ProviderUse
 	if err != nil {
 		return nil, fmt.Errorf("oidc user sync failed: %w", err)
 	}
-
-	return AssignIdentityToGroups(tx, user, info.Groups)
+	return info, nil
 }
 
 type SCIMParameters struct {
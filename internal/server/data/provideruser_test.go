@@ -31,7 +31,7 @@ func (m *mockOIDCImplementation) AuthServerInfo(_ context.Context) (*providers.A
 	return &providers.AuthServerInfo{AuthURL: "example.com/v1/auth", ScopesSupported: []string{"openid", "email"}}, nil
 }
 
-func (m *mockOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _ string) (*providers.IdentityProviderAuth, error) {
+func (m *mockOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _, _ string) (*providers.IdentityProviderAuth, error) {
 	return &providers.IdentityProviderAuth{
 		AccessToken:       "acc",
 		RefreshToken:      "ref",
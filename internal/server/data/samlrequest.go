@@ -0,0 +1,55 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateSAMLRequest records an authentication request sent to a SAML identity
+// provider, so that the response can be checked against it.
+func CreateSAMLRequest(tx WriteTxn, providerID uid.ID, requestID string, expiresAt time.Time) error {
+	stmt := `
+		INSERT INTO saml_requests (organization_id, provider_id, request_id, expires_at)
+		VALUES (?, ?, ?, ?)`
+	_, err := tx.Exec(stmt, tx.OrganizationID(), providerID, requestID, expiresAt)
+	return handleError(err)
+}
+
+// UseSAMLRequest records that the assertion was received in response to the
+// authentication request. A request can only be used once, and an assertion
+// can only be used with one request. It returns ErrNotFound if the request
+// does not exist, has expired, or was already used.
+func UseSAMLRequest(tx WriteTxn, providerID uid.ID, requestID, assertionID string) error {
+	stmt := `
+		UPDATE saml_requests SET assertion_id = ?
+		WHERE organization_id = ? AND provider_id = ? AND request_id = ?
+		AND assertion_id IS NULL AND expires_at > ?`
+	result, err := tx.Exec(stmt, assertionID, tx.OrganizationID(), providerID, requestID, time.Now())
+	if err != nil {
+		var ucErr UniqueConstraintError
+		if errors.As(handleError(err), &ucErr) {
+			return fmt.Errorf("saml assertion %v was already used", assertionID)
+		}
+		return handleError(err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: saml request %v", internal.ErrNotFound, requestID)
+	}
+	return nil
+}
+
+// DeleteExpiredSAMLRequests removes authentication requests that can no
+// longer be used from all organizations.
+func DeleteExpiredSAMLRequests(tx WriteTxn) error {
+	_, err := tx.Exec(`DELETE FROM saml_requests WHERE expires_at <= ?`, time.Now())
+	return handleError(err)
+}
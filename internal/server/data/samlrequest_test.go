@@ -0,0 +1,61 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestUseSAMLRequest(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		providerID := uid.ID(12345)
+		expires := time.Now().Add(10 * time.Minute)
+
+		t.Run("used once", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+			assert.NilError(t, CreateSAMLRequest(tx, providerID, "id-first", expires))
+
+			assert.NilError(t, UseSAMLRequest(tx, providerID, "id-first", "_assertion-first"))
+
+			err := UseSAMLRequest(tx, providerID, "id-first", "_assertion-second")
+			assert.ErrorIs(t, err, internal.ErrNotFound)
+		})
+
+		t.Run("assertion already used", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+			assert.NilError(t, CreateSAMLRequest(tx, providerID, "id-first", expires))
+			assert.NilError(t, CreateSAMLRequest(tx, providerID, "id-second", expires))
+
+			assert.NilError(t, UseSAMLRequest(tx, providerID, "id-first", "_assertion"))
+
+			err := UseSAMLRequest(tx, providerID, "id-second", "_assertion")
+			assert.Error(t, err, "saml assertion _assertion was already used")
+		})
+
+		t.Run("wrong provider", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+			assert.NilError(t, CreateSAMLRequest(tx, providerID, "id-first", expires))
+
+			err := UseSAMLRequest(tx, providerID+1, "id-first", "_assertion")
+			assert.ErrorIs(t, err, internal.ErrNotFound)
+		})
+
+		t.Run("expired", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+			assert.NilError(t, CreateSAMLRequest(tx, providerID, "id-expired", time.Now().Add(-time.Minute)))
+
+			err := UseSAMLRequest(tx, providerID, "id-expired", "_assertion")
+			assert.ErrorIs(t, err, internal.ErrNotFound)
+
+			assert.NilError(t, DeleteExpiredSAMLRequests(tx))
+			var count int
+			err = tx.QueryRow(`SELECT count(*) FROM saml_requests WHERE request_id = 'id-expired'`).Scan(&count)
+			assert.NilError(t, err)
+			assert.Equal(t, count, 0)
+		})
+	})
+}
@@ -122,7 +122,43 @@ CREATE TABLE access_keys (
     secret_checksum bytea,
     scopes text,
     organization_id bigint,
-    issued_for_kind smallint DEFAULT 1
+    issued_for_kind smallint DEFAULT 1,
+    login_method text,
+    client_ip text,
+    user_agent text
+);
+
+CREATE TABLE access_requests (
+    id bigint NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    privilege text NOT NULL,
+    resource text NOT NULL,
+    justification text,
+    duration bigint,
+    status text NOT NULL,
+    reviewed_by bigint,
+    reviewed_at timestamp with time zone,
+    grant_id bigint
+);
+
+CREATE TABLE audit_events (
+    id bigint NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    organization_id bigint NOT NULL,
+    actor_id bigint,
+    actor_name text,
+    method text NOT NULL,
+    route text NOT NULL,
+    target_id bigint,
+    request text,
+    outcome text NOT NULL,
+    status_code integer
 );
 
 CREATE TABLE credentials (
@@ -133,7 +169,13 @@ CREATE TABLE credentials (
     identity_id bigint,
     password_hash bytea,
     one_time_password boolean,
-    organization_id bigint
+    organization_id bigint,
+    mfa_secret text DEFAULT ''::text,
+    mfa_enabled boolean DEFAULT false NOT NULL,
+    mfa_last_step bigint DEFAULT 0 NOT NULL,
+    mfa_recovery_codes text DEFAULT ''::text,
+    password_updated_at timestamp with time zone NOT NULL,
+    password_hash_history text DEFAULT ''::text
 );
 
 CREATE TABLE destination_credentials (
@@ -200,7 +242,8 @@ CREATE TABLE grants (
     organization_id bigint,
     update_index bigint,
     subject_id bigint NOT NULL,
-    subject_kind smallint NOT NULL
+    subject_kind smallint NOT NULL,
+    expires_at timestamp with time zone
 );
 
 CREATE TABLE groups (
@@ -211,7 +254,13 @@ CREATE TABLE groups (
     name text,
     created_by bigint,
     created_by_provider bigint,
-    organization_id bigint
+    organization_id bigint,
+    provisioned_by_scim boolean DEFAULT false
+);
+
+CREATE TABLE groups_groups (
+    parent_id bigint NOT NULL,
+    child_id bigint NOT NULL
 );
 
 CREATE TABLE identities (
@@ -233,6 +282,15 @@ CREATE TABLE identities_groups (
     group_id bigint NOT NULL
 );
 
+CREATE TABLE login_failures (
+    organization_id bigint NOT NULL,
+    kind text NOT NULL,
+    name text NOT NULL,
+    failures bigint DEFAULT 0 NOT NULL,
+    locked_until timestamp with time zone,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE organizations (
     id bigint NOT NULL,
     created_at timestamp with time zone,
@@ -244,7 +302,15 @@ CREATE TABLE organizations (
     allowed_domains text DEFAULT ''::text,
     private_jwk bytea,
     public_jwk bytea,
-    install_id bigint
+    install_id bigint,
+    require_mfa boolean DEFAULT false NOT NULL,
+    password_min_length bigint DEFAULT 0 NOT NULL,
+    password_lowercase_min bigint DEFAULT 0 NOT NULL,
+    password_uppercase_min bigint DEFAULT 0 NOT NULL,
+    password_number_min bigint DEFAULT 0 NOT NULL,
+    password_symbol_min bigint DEFAULT 0 NOT NULL,
+    password_max_age bigint DEFAULT 0 NOT NULL,
+    password_history bigint DEFAULT 0 NOT NULL
 );
 
 CREATE TABLE password_reset_tokens (
@@ -255,6 +321,18 @@ CREATE TABLE password_reset_tokens (
     organization_id bigint
 );
 
+CREATE TABLE provider_syncs (
+    provider_id bigint NOT NULL,
+    organization_id bigint NOT NULL,
+    status text NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone,
+    users_synced bigint DEFAULT 0 NOT NULL,
+    users_deactivated bigint DEFAULT 0 NOT NULL,
+    users_failed bigint DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL
+);
+
 CREATE TABLE provider_users (
     identity_id bigint NOT NULL,
     provider_id bigint NOT NULL,
@@ -267,7 +345,10 @@ CREATE TABLE provider_users (
     given_name text DEFAULT ''::text,
     family_name text DEFAULT ''::text,
     active boolean DEFAULT true,
-    groups jsonb
+    groups jsonb,
+    department text DEFAULT ''::text,
+    employee_number text DEFAULT ''::text,
+    manager text DEFAULT ''::text
 );
 
 CREATE TABLE providers (
@@ -286,7 +367,27 @@ CREATE TABLE providers (
     private_key text,
     client_email text,
     domain_admin_email text,
-    organization_id bigint
+    organization_id bigint,
+    saml_metadata text DEFAULT ''::text,
+    saml_groups_attribute text DEFAULT ''::text,
+    ldap_bind_dn text DEFAULT ''::text,
+    ldap_bind_password text DEFAULT ''::text,
+    ldap_base_dn text DEFAULT ''::text,
+    ldap_user_filter text DEFAULT ''::text,
+    ldap_group_base_dn text DEFAULT ''::text,
+    ldap_start_tls boolean DEFAULT false NOT NULL,
+    ldap_ca_certificate text DEFAULT ''::text,
+    github_organizations text DEFAULT ''::text,
+    group_mapping jsonb DEFAULT '{}'::jsonb NOT NULL,
+    domains text DEFAULT ''::text
+);
+
+CREATE TABLE saml_requests (
+    organization_id bigint NOT NULL,
+    provider_id bigint NOT NULL,
+    request_id text NOT NULL,
+    assertion_id text,
+    expires_at timestamp with time zone NOT NULL
 );
 
 CREATE SEQUENCE seq_update_index
@@ -296,6 +397,18 @@ CREATE SEQUENCE seq_update_index
     NO MAXVALUE
     CACHE 1;
 
+CREATE TABLE signing_keys (
+    id bigint NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    organization_id bigint NOT NULL,
+    key_id text NOT NULL,
+    private_jwk bytea NOT NULL,
+    public_jwk bytea NOT NULL,
+    state text NOT NULL
+);
+
 CREATE TABLE user_public_keys (
     id bigint NOT NULL,
     user_id bigint NOT NULL,
@@ -309,9 +422,30 @@ CREATE TABLE user_public_keys (
     deleted_at timestamp with time zone
 );
 
+CREATE TABLE workload_identities (
+    id bigint NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    organization_id bigint NOT NULL,
+    name text NOT NULL,
+    issuer text NOT NULL,
+    jwks_url text NOT NULL,
+    audience text NOT NULL,
+    subject text NOT NULL,
+    identity_id bigint NOT NULL,
+    scopes text
+);
+
 ALTER TABLE ONLY access_keys
     ADD CONSTRAINT access_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY access_requests
+    ADD CONSTRAINT access_requests_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY audit_events
+    ADD CONSTRAINT audit_events_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY credentials
     ADD CONSTRAINT credentials_pkey PRIMARY KEY (id);
 
@@ -324,6 +458,9 @@ ALTER TABLE ONLY encryption_keys
 ALTER TABLE ONLY grants
     ADD CONSTRAINT grants_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY groups_groups
+    ADD CONSTRAINT groups_groups_pkey PRIMARY KEY (parent_id, child_id);
+
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_pkey PRIMARY KEY (id);
 
@@ -333,27 +470,46 @@ ALTER TABLE ONLY identities_groups
 ALTER TABLE ONLY identities
     ADD CONSTRAINT identities_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY login_failures
+    ADD CONSTRAINT login_failures_pkey PRIMARY KEY (organization_id, kind, name);
+
 ALTER TABLE ONLY organizations
     ADD CONSTRAINT organizations_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY password_reset_tokens
     ADD CONSTRAINT password_reset_tokens_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY provider_syncs
+    ADD CONSTRAINT provider_syncs_pkey PRIMARY KEY (provider_id);
+
 ALTER TABLE ONLY provider_users
     ADD CONSTRAINT provider_users_pkey PRIMARY KEY (provider_id, identity_id);
 
 ALTER TABLE ONLY providers
     ADD CONSTRAINT providers_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY saml_requests
+    ADD CONSTRAINT saml_requests_pkey PRIMARY KEY (organization_id, request_id);
+
+ALTER TABLE ONLY signing_keys
+    ADD CONSTRAINT signing_keys_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY user_public_keys
     ADD CONSTRAINT user_public_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workload_identities
+    ADD CONSTRAINT workload_identities_pkey PRIMARY KEY (id);
+
 CREATE INDEX idx_access_keys_expires_at ON access_keys USING btree (expires_at);
 
 CREATE UNIQUE INDEX idx_access_keys_issued_for ON access_keys USING btree (organization_id, issued_for_id, name) WHERE (deleted_at IS NULL);
 
 CREATE UNIQUE INDEX idx_access_keys_key_id ON access_keys USING btree (key_id) WHERE (deleted_at IS NULL);
 
+CREATE INDEX idx_access_requests_status ON access_requests USING btree (organization_id, status) WHERE (deleted_at IS NULL);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events USING btree (organization_id, created_at) WHERE (deleted_at IS NULL);
+
 CREATE INDEX idx_cred_req_org_dest ON destination_credentials USING btree (organization_id, destination_id);
 
 CREATE UNIQUE INDEX idx_credentials_identity_id ON credentials USING btree (organization_id, identity_id) WHERE (deleted_at IS NULL);
@@ -370,16 +526,22 @@ CREATE UNIQUE INDEX idx_emails_providers_identities ON provider_users USING btre
 
 CREATE UNIQUE INDEX idx_encryption_keys_key_id ON encryption_keys USING btree (key_id);
 
+CREATE INDEX idx_grants_expires_at ON grants USING btree (expires_at) WHERE (deleted_at IS NULL);
+
 CREATE UNIQUE INDEX idx_grants_subject_privilege_resource ON grants USING btree (organization_id, subject_id, privilege, resource) WHERE (deleted_at IS NULL);
 
 CREATE INDEX idx_grants_update_index ON grants USING btree (organization_id, update_index);
 
+CREATE INDEX idx_groups_groups_child_id ON groups_groups USING btree (child_id);
+
 CREATE UNIQUE INDEX idx_groups_name ON groups USING btree (organization_id, name) WHERE (deleted_at IS NULL);
 
 CREATE UNIQUE INDEX idx_identities_name ON identities USING btree (organization_id, name) WHERE (deleted_at IS NULL);
 
 CREATE UNIQUE INDEX idx_identities_verified ON identities USING btree (organization_id, verification_token) WHERE (deleted_at IS NULL);
 
+CREATE INDEX idx_login_failures_updated_at ON login_failures USING btree (updated_at);
+
 CREATE UNIQUE INDEX idx_organizations_domain ON organizations USING btree (domain) WHERE (deleted_at IS NULL);
 
 CREATE INDEX idx_password_reset_tokens_expires_at ON password_reset_tokens USING btree (expires_at);
@@ -388,12 +550,22 @@ CREATE UNIQUE INDEX idx_password_reset_tokens_token ON password_reset_tokens USI
 
 CREATE UNIQUE INDEX idx_providers_name ON providers USING btree (organization_id, name) WHERE (deleted_at IS NULL);
 
+CREATE UNIQUE INDEX idx_saml_requests_assertion_id ON saml_requests USING btree (provider_id, assertion_id);
+
+CREATE INDEX idx_saml_requests_expires_at ON saml_requests USING btree (expires_at);
+
+CREATE UNIQUE INDEX idx_signing_keys_active ON signing_keys USING btree (organization_id) WHERE ((state = 'active'::text) AND (deleted_at IS NULL));
+
 CREATE UNIQUE INDEX idx_user_public_keys_user_fingerprint ON user_public_keys USING btree (fingerprint) WHERE (deleted_at IS NULL);
 
 CREATE INDEX idx_user_public_keys_user_id ON user_public_keys USING btree (user_id) WHERE (deleted_at IS NULL);
 
 CREATE UNIQUE INDEX idx_user_ssh_login_name ON identities USING btree (organization_id, ssh_login_name) WHERE (deleted_at IS NULL);
 
+CREATE INDEX idx_workload_identities_issuer ON workload_identities USING btree (organization_id, issuer) WHERE (deleted_at IS NULL);
+
+CREATE UNIQUE INDEX idx_workload_identities_name ON workload_identities USING btree (organization_id, name) WHERE (deleted_at IS NULL);
+
 CREATE TRIGGER credreq_notify_insert_trigger AFTER INSERT ON destination_credentials FOR EACH ROW EXECUTE FUNCTION destination_credential_insert_notify();
 
 CREATE TRIGGER credreq_notify_update_trigger AFTER UPDATE ON destination_credentials FOR EACH ROW EXECUTE FUNCTION destination_credential_update_notify();
@@ -8,10 +8,13 @@ import (
 	"github.com/infrahq/infra/internal/server/data/querybuilder"
 )
 
-func filterSQL(e filter.Expression, query *querybuilder.Query) error {
+// sqlColumnFunc writes the database column for a SCIM attribute to query.
+type sqlColumnFunc func(a filter.AttributePath, query *querybuilder.Query) error
+
+func filterSQL(e filter.Expression, query *querybuilder.Query, column sqlColumnFunc) error {
 	switch v := e.(type) {
 	case *filter.LogicalExpression:
-		err := filterSQL(v.Left, query)
+		err := filterSQL(v.Left, query, column)
 		if err != nil {
 			return fmt.Errorf("left: %w", err)
 		}
@@ -23,13 +26,13 @@ func filterSQL(e filter.Expression, query *querybuilder.Query) error {
 		default:
 			return fmt.Errorf("unsupported operator %q", v.Operator)
 		}
-		err = filterSQL(v.Right, query)
+		err = filterSQL(v.Right, query, column)
 		if err != nil {
 			return fmt.Errorf("right: %w", err)
 		}
 		return nil
 	case *filter.AttributeExpression:
-		err := sqlColumn(v.AttributePath, query)
+		err := column(v.AttributePath, query)
 		if err != nil {
 			return fmt.Errorf("attribute path: %w", err)
 		}
@@ -57,6 +60,23 @@ func sqlColumn(a filter.AttributePath, query *querybuilder.Query) error {
 		query.B("familyName")
 	case "active":
 		query.B("active")
+	case "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department":
+		query.B("department")
+	case "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber":
+		query.B("employee_number")
+	case "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value":
+		query.B("manager")
+	default:
+		return fmt.Errorf("unsupported filter attribute: %q", a)
+	}
+	return nil
+}
+
+// groupSQLColumn maps SCIM input filters to group database columns
+func groupSQLColumn(a filter.AttributePath, query *querybuilder.Query) error {
+	switch a.String() {
+	case "displayName":
+		query.B("name")
 	default:
 		return fmt.Errorf("unsupported filter attribute: %q", a)
 	}
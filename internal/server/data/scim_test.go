@@ -60,6 +60,18 @@ func TestFilterParser(t *testing.T) {
 			expectedQuery: " email = ? AND email = ? ",
 			expectedArgs:  []any{"M", "W"},
 		},
+		{
+			name:          "enterprise extension",
+			expression:    "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq \"Sales\"",
+			expectedQuery: " department = ? ",
+			expectedArgs:  []any{"Sales"},
+		},
+		{
+			name:          "enterprise extension sub attribute",
+			expression:    "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value eq \"m123\"",
+			expectedQuery: " manager = ? ",
+			expectedArgs:  []any{"m123"},
+		},
 		{
 			name:          "logical or",
 			expression:    "(email eq \"M\") or (email eq \"W\")",
@@ -73,7 +85,7 @@ func TestFilterParser(t *testing.T) {
 			exp, err := filter.ParseFilter([]byte(tc.expression))
 			assert.NilError(t, err)
 			query := querybuilder.New("")
-			err = filterSQL(exp, query)
+			err = filterSQL(exp, query, sqlColumn)
 			assert.NilError(t, err)
 			assert.Equal(t, query.String(), tc.expectedQuery)
 			if tc.expectedArgs != nil {
@@ -83,6 +95,21 @@ func TestFilterParser(t *testing.T) {
 	}
 }
 
+func TestFilterParser_Groups(t *testing.T) {
+	exp, err := filter.ParseFilter([]byte(`displayName eq "Engineering"`))
+	assert.NilError(t, err)
+	query := querybuilder.New("")
+	err = filterSQL(exp, query, groupSQLColumn)
+	assert.NilError(t, err)
+	assert.Equal(t, query.String(), " name = ? ")
+	assert.DeepEqual(t, query.Args, []any{"Engineering"})
+
+	exp, err = filter.ParseFilter([]byte(`userName eq "Engineering"`))
+	assert.NilError(t, err)
+	err = filterSQL(exp, querybuilder.New(""), groupSQLColumn)
+	assert.ErrorContains(t, err, `unsupported filter attribute: "userName"`)
+}
+
 func TestFilterParserError(t *testing.T) {
 	type testCase struct {
 		name       string
@@ -141,7 +168,7 @@ func TestFilterSQLError(t *testing.T) {
 			exp, err := filter.ParseFilter([]byte(tc.expression))
 			assert.NilError(t, err)
 			query := querybuilder.New("")
-			err = filterSQL(exp, query)
+			err = filterSQL(exp, query, sqlColumn)
 			assert.ErrorContains(t, err, tc.expectedErrMsg)
 		})
 	}
@@ -167,7 +194,7 @@ func FuzzFilter_Terminates(f *testing.F) {
 			exp, _ := filter.ParseFilter([]byte(input))
 			if exp != nil {
 				// if an expression can be parsed attempt to build a query on it
-				if err := filterSQL(exp, query); err == nil {
+				if err := filterSQL(exp, query, sqlColumn); err == nil {
 					assert.Assert(t, query.String() != "")
 				}
 			}
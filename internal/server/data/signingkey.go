@@ -0,0 +1,231 @@
+package data
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// SigningKeyMaxAge is how long a signing key is active before it is rotated
+// by RotateExpiredSigningKeys.
+var SigningKeyMaxAge = 90 * 24 * time.Hour
+
+// SigningKeyVerifyPeriod is how long a signing key is published after it was
+// replaced by a new active key. It must be longer than the lifetime of the
+// tokens signed by the key.
+var SigningKeyVerifyPeriod = time.Hour
+
+type signingKeysTable models.SigningKey
+
+func (s signingKeysTable) Table() string {
+	return "signing_keys"
+}
+
+func (s signingKeysTable) Columns() []string {
+	return []string{"created_at", "deleted_at", "id", "key_id", "organization_id", "private_jwk", "public_jwk", "state", "updated_at"}
+}
+
+func (s signingKeysTable) Values() []any {
+	return []any{s.CreatedAt, s.DeletedAt, s.ID, s.KeyID, s.OrganizationID, s.PrivateJWK, s.PublicJWK, s.State, s.UpdatedAt}
+}
+
+func (s *signingKeysTable) ScanFields() []any {
+	return []any{&s.CreatedAt, &s.DeletedAt, &s.ID, &s.KeyID, &s.OrganizationID, &s.PrivateJWK, &s.PublicJWK, &s.State, &s.UpdatedAt}
+}
+
+// newSigningKey generates a new ed25519 key. The kid of the key is the
+// thumbprint of the key.
+func newSigningKey() (*models.SigningKey, error) {
+	pubkey, seckey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sec := jose.JSONWebKey{Key: seckey, KeyID: "", Algorithm: string(jose.ED25519), Use: "sig"}
+
+	thumb, err := sec.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	sec.KeyID = base64.URLEncoding.EncodeToString(thumb)
+
+	pub := jose.JSONWebKey{Key: pubkey, KeyID: sec.KeyID, Algorithm: string(jose.ED25519), Use: "sig"}
+
+	secs, err := sec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	pubs, err := pub.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KeyID:      sec.KeyID,
+		PrivateJWK: models.EncryptedAtRest(secs),
+		PublicJWK:  pubs,
+		State:      models.SigningKeyStateActive,
+	}, nil
+}
+
+func createSigningKey(tx WriteTxn, key *models.SigningKey) error {
+	switch {
+	case key.KeyID == "":
+		return fmt.Errorf("keyID is required")
+	case key.PrivateJWK == "" || len(key.PublicJWK) == 0:
+		return fmt.Errorf("private and public keys are required")
+	case key.State == "":
+		return fmt.Errorf("state is required")
+	}
+	return insert(tx, (*signingKeysTable)(key))
+}
+
+type ListSigningKeysOptions struct {
+	// ByStates instructs ListSigningKeys to return only the keys in one of
+	// these states.
+	ByStates []models.SigningKeyState
+
+	Pagination *Pagination
+}
+
+// ListSigningKeys returns the signing keys of the organization, newest first.
+func ListSigningKeys(tx ReadTxn, opts ListSigningKeysOptions) ([]models.SigningKey, error) {
+	table := signingKeysTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	if opts.Pagination != nil {
+		query.B(", count(*) OVER()")
+	}
+	query.B("FROM signing_keys")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+
+	if len(opts.ByStates) > 0 {
+		query.B("AND state IN")
+		queryInClause(query, opts.ByStates)
+	}
+
+	query.B("ORDER BY created_at DESC, id DESC")
+	if opts.Pagination != nil {
+		opts.Pagination.PaginateQuery(query)
+	}
+
+	rows, err := tx.Query(query.String(), query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, func(key *models.SigningKey) []any {
+		fields := (*signingKeysTable)(key).ScanFields()
+		if opts.Pagination != nil {
+			fields = append(fields, &opts.Pagination.TotalCount)
+		}
+		return fields
+	})
+}
+
+// getActiveSigningKey returns the key used to sign new tokens for the
+// organization.
+func getActiveSigningKey(tx ReadTxn, orgID uid.ID) (*models.SigningKey, error) {
+	table := &signingKeysTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	query.B("FROM signing_keys")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", orgID)
+	query.B("AND state = ?", models.SigningKeyStateActive)
+
+	err := tx.QueryRow(query.String(), query.Args...).Scan(table.ScanFields()...)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return (*models.SigningKey)(table), nil
+}
+
+// RotateSigningKey creates a new active signing key for the organization. The
+// previous active key is kept for verifying tokens, and keys that have been
+// verifying for longer than SigningKeyVerifyPeriod are retired.
+func RotateSigningKey(tx WriteTxn) (*models.SigningKey, error) {
+	return rotateSigningKey(tx, tx.OrganizationID())
+}
+
+func rotateSigningKey(tx WriteTxn, orgID uid.ID) (*models.SigningKey, error) {
+	now := time.Now().UTC()
+
+	stmt := `
+		UPDATE signing_keys SET state = ?, updated_at = ?
+		WHERE organization_id = ? AND state = ? AND deleted_at is null
+	`
+	_, err := tx.Exec(stmt, models.SigningKeyStateVerifying, now, orgID, models.SigningKeyStateActive)
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	stmt = `
+		UPDATE signing_keys SET state = ?, updated_at = ?
+		WHERE organization_id = ? AND state = ? AND updated_at < ? AND deleted_at is null
+	`
+	_, err = tx.Exec(stmt, models.SigningKeyStateRetired, now,
+		orgID, models.SigningKeyStateVerifying, now.Add(-SigningKeyVerifyPeriod))
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	key, err := newSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	key.OrganizationID = orgID
+	if err := createSigningKey(tx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RotateExpiredSigningKeys rotates the active signing keys that are older than
+// SigningKeyMaxAge, and retires keys that have been verifying for longer than
+// SigningKeyVerifyPeriod, for all organizations.
+func RotateExpiredSigningKeys(tx WriteTxn) error {
+	now := time.Now().UTC()
+
+	stmt := `
+		UPDATE signing_keys SET state = ?, updated_at = ?
+		WHERE state = ? AND updated_at < ? AND deleted_at is null
+	`
+	_, err := tx.Exec(stmt, models.SigningKeyStateRetired, now,
+		models.SigningKeyStateVerifying, now.Add(-SigningKeyVerifyPeriod))
+	if err != nil {
+		return handleError(err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT organization_id FROM signing_keys
+		WHERE state = ? AND created_at < ? AND deleted_at is null`,
+		models.SigningKeyStateActive, now.Add(-SigningKeyMaxAge))
+	if err != nil {
+		return err
+	}
+	orgIDs, err := scanRows(rows, func(id *uid.ID) []any {
+		return []any{id}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, orgID := range orgIDs {
+		if _, err := rotateSigningKey(tx, orgID); err != nil {
+			return fmt.Errorf("rotate signing key of organization %v: %w", orgID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestCreateOrganization_SigningKey(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		org := &models.Organization{Name: "signing", Domain: "signing.example.com"}
+		assert.NilError(t, CreateOrganization(tx, org))
+
+		keys, err := ListSigningKeys(tx.WithOrgID(org.ID), ListSigningKeysOptions{})
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+		assert.Equal(t, keys[0].State, models.SigningKeyStateActive)
+		assert.Assert(t, keys[0].KeyID != "")
+		// the organization keys are kept for servers that have not been upgraded
+		assert.Equal(t, keys[0].PrivateJWK, org.PrivateJWK)
+		assert.DeepEqual(t, keys[0].PublicJWK, org.PublicJWK)
+	})
+}
+
+func TestRotateSigningKey(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		first, err := getActiveSigningKey(tx, db.DefaultOrg.ID)
+		assert.NilError(t, err)
+
+		second, err := RotateSigningKey(tx)
+		assert.NilError(t, err)
+		assert.Assert(t, second.KeyID != first.KeyID)
+
+		active, err := getActiveSigningKey(tx, db.DefaultOrg.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, active.ID, second.ID)
+
+		keys, err := ListSigningKeys(tx, ListSigningKeysOptions{})
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 2)
+		assert.Equal(t, keys[0].ID, second.ID)
+		assert.Equal(t, keys[1].ID, first.ID)
+		assert.Equal(t, keys[1].State, models.SigningKeyStateVerifying)
+
+		t.Run("verifying keys are retired after the verify period", func(t *testing.T) {
+			_, err := tx.Exec(`UPDATE signing_keys SET updated_at = ? WHERE id = ?`,
+				time.Now().Add(-2*SigningKeyVerifyPeriod), first.ID)
+			assert.NilError(t, err)
+
+			third, err := RotateSigningKey(tx)
+			assert.NilError(t, err)
+
+			keys, err := ListSigningKeys(tx, ListSigningKeysOptions{
+				ByStates: []models.SigningKeyState{models.SigningKeyStateActive, models.SigningKeyStateVerifying},
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, len(keys), 2)
+			assert.Equal(t, keys[0].ID, third.ID)
+			assert.Equal(t, keys[1].ID, second.ID)
+
+			keys, err = ListSigningKeys(tx, ListSigningKeysOptions{
+				ByStates: []models.SigningKeyState{models.SigningKeyStateRetired},
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, len(keys), 1)
+			assert.Equal(t, keys[0].ID, first.ID)
+		})
+	})
+}
+
+func TestRotateExpiredSigningKeys(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		first, err := getActiveSigningKey(tx, db.DefaultOrg.ID)
+		assert.NilError(t, err)
+
+		// the key is not old enough to rotate
+		assert.NilError(t, RotateExpiredSigningKeys(tx))
+		active, err := getActiveSigningKey(tx, db.DefaultOrg.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, active.ID, first.ID)
+
+		_, err = tx.Exec(`UPDATE signing_keys SET created_at = ? WHERE id = ?`,
+			time.Now().Add(-SigningKeyMaxAge-time.Hour), first.ID)
+		assert.NilError(t, err)
+
+		assert.NilError(t, RotateExpiredSigningKeys(tx))
+		active, err = getActiveSigningKey(tx, db.DefaultOrg.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, active.ID != first.ID)
+
+		keys, err := ListSigningKeys(tx, ListSigningKeysOptions{
+			ByStates: []models.SigningKeyState{models.SigningKeyStateVerifying},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+		assert.Equal(t, keys[0].ID, first.ID)
+	})
+}
@@ -15,6 +15,8 @@ import (
 
 var tables = []tabler{
 	accessKeyTable{},
+	accessRequestsTable{},
+	auditEventsTable{},
 	credentialsTable{},
 	destinationsTable{},
 	encryptionKeysTable{},
@@ -24,8 +26,11 @@ var tables = []tabler{
 	organizationsTable{},
 	passwordResetToken{},
 	providersTable{},
+	providerSyncsTable{},
 	providerUserTable{},
+	signingKeysTable{},
 	userPublicKeysTable{},
+	workloadIdentitiesTable{},
 }
 
 type tabler interface {
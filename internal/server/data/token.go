@@ -16,8 +16,13 @@ var signatureAlgorithmFromKeyAlgorithm = map[string]string{
 }
 
 func createJWT(db ReadTxn, organization *models.Organization, identity *models.Identity, groups []string, expires time.Time) (string, error) {
+	key, err := getActiveSigningKey(db, organization.ID)
+	if err != nil {
+		return "", fmt.Errorf("get signing key: %w", err)
+	}
+
 	var sec jose.JSONWebKey
-	if err := sec.UnmarshalJSON([]byte(organization.PrivateJWK)); err != nil {
+	if err := sec.UnmarshalJSON([]byte(key.PrivateJWK)); err != nil {
 		return "", err
 	}
 
@@ -28,6 +33,7 @@ func createJWT(db ReadTxn, organization *models.Organization, identity *models.I
 
 	options := &jose.SignerOptions{}
 
+	// the kid of sec is included in the header of the token
 	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.SignatureAlgorithm(algo), Key: sec}, options.WithType("JWT"))
 	if err != nil {
 		return "", err
@@ -55,14 +61,20 @@ func createJWT(db ReadTxn, organization *models.Organization, identity *models.I
 }
 
 func CreateIdentityToken(db ReadTxn, organization *models.Organization, identity *models.Identity) (token *models.Token, err error) {
-	identityGroups, err := ListGroups(db, ListGroupsOptions{ByGroupMember: identity.ID})
+	groupIDs, err := ListGroupIDsForUser(db, identity.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	var groups []string
-	for _, g := range identityGroups {
-		groups = append(groups, g.Name)
+	if len(groupIDs) > 0 {
+		identityGroups, err := ListGroups(db, ListGroupsOptions{ByIDs: groupIDs})
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range identityGroups {
+			groups = append(groups, g.Name)
+		}
 	}
 
 	expires := time.Now().Add(time.Minute * 5).UTC()
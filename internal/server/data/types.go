@@ -3,6 +3,7 @@ package data
 import (
 	"database/sql"
 	"database/sql/driver"
+	"time"
 )
 
 // optionalString has the behaviour of sql.NullString. A null entry
@@ -34,3 +35,28 @@ func (s optionalString) Value() (driver.Value, error) {
 	}
 	return string(s), nil
 }
+
+// optionalTime has the behaviour of sql.NullTime. A null entry in a database
+// column is scanned as the zero value of time.Time, and a zero time.Time is
+// saved as a null. Like optionalString, it allows us to wrap a regular
+// time.Time field on a struct instead of using sql.NullTime.
+type optionalTime time.Time
+
+func (t *optionalTime) Scan(value any) error {
+	if value == nil {
+		*t = optionalTime{}
+		return nil
+	}
+
+	var nt sql.NullTime
+	err := nt.Scan(value)
+	*t = optionalTime(nt.Time)
+	return err
+}
+
+func (t optionalTime) Value() (driver.Value, error) {
+	if time.Time(t).IsZero() {
+		return nil, nil
+	}
+	return time.Time(t), nil
+}
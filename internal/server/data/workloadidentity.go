@@ -0,0 +1,119 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/internal/server/data/querybuilder"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+type workloadIdentitiesTable models.WorkloadIdentity
+
+func (w workloadIdentitiesTable) Table() string {
+	return "workload_identities"
+}
+
+func (w workloadIdentitiesTable) Columns() []string {
+	return []string{"audience", "created_at", "deleted_at", "id", "identity_id", "issuer", "jwks_url", "name", "organization_id", "scopes", "subject", "updated_at"}
+}
+
+func (w workloadIdentitiesTable) Values() []any {
+	return []any{w.Audience, w.CreatedAt, w.DeletedAt, w.ID, w.IdentityID, w.Issuer, w.JWKSURL, w.Name, w.OrganizationID, w.Scopes, w.Subject, w.UpdatedAt}
+}
+
+func (w *workloadIdentitiesTable) ScanFields() []any {
+	return []any{&w.Audience, &w.CreatedAt, &w.DeletedAt, &w.ID, &w.IdentityID, &w.Issuer, &w.JWKSURL, &w.Name, &w.OrganizationID, &w.Scopes, &w.Subject, &w.UpdatedAt}
+}
+
+func validateWorkloadIdentity(w *models.WorkloadIdentity) error {
+	switch {
+	case w.Name == "":
+		return fmt.Errorf("name is required")
+	case w.Issuer == "":
+		return fmt.Errorf("issuer is required")
+	case w.JWKSURL == "":
+		return fmt.Errorf("jwksURL is required")
+	case w.Audience == "":
+		return fmt.Errorf("audience is required")
+	case w.Subject == "":
+		return fmt.Errorf("subject is required")
+	case w.IdentityID == 0:
+		return fmt.Errorf("identityID is required")
+	}
+	return nil
+}
+
+func CreateWorkloadIdentity(tx WriteTxn, w *models.WorkloadIdentity) error {
+	if err := validateWorkloadIdentity(w); err != nil {
+		return err
+	}
+	return insert(tx, (*workloadIdentitiesTable)(w))
+}
+
+func GetWorkloadIdentity(tx ReadTxn, id uid.ID) (*models.WorkloadIdentity, error) {
+	table := &workloadIdentitiesTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	query.B("FROM workload_identities")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+	query.B("AND id = ?", id)
+
+	err := tx.QueryRow(query.String(), query.Args...).Scan(table.ScanFields()...)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return (*models.WorkloadIdentity)(table), nil
+}
+
+type ListWorkloadIdentitiesOptions struct {
+	// ByIssuer instructs ListWorkloadIdentities to return only the workload
+	// identities that trust tokens from this issuer.
+	ByIssuer string
+
+	Pagination *Pagination
+}
+
+func ListWorkloadIdentities(tx ReadTxn, opts ListWorkloadIdentitiesOptions) ([]models.WorkloadIdentity, error) {
+	table := workloadIdentitiesTable{}
+	query := querybuilder.New("SELECT")
+	query.B(columnsForSelect(table))
+	if opts.Pagination != nil {
+		query.B(", count(*) OVER()")
+	}
+	query.B("FROM workload_identities")
+	query.B("WHERE deleted_at is null")
+	query.B("AND organization_id = ?", tx.OrganizationID())
+
+	if opts.ByIssuer != "" {
+		query.B("AND issuer = ?", opts.ByIssuer)
+	}
+
+	query.B("ORDER BY name")
+	if opts.Pagination != nil {
+		opts.Pagination.PaginateQuery(query)
+	}
+
+	rows, err := tx.Query(query.String(), query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, func(w *models.WorkloadIdentity) []any {
+		fields := (*workloadIdentitiesTable)(w).ScanFields()
+		if opts.Pagination != nil {
+			fields = append(fields, &opts.Pagination.TotalCount)
+		}
+		return fields
+	})
+}
+
+func DeleteWorkloadIdentity(tx WriteTxn, id uid.ID) error {
+	stmt := `
+		UPDATE workload_identities SET deleted_at = ?
+		WHERE id = ? AND organization_id = ? AND deleted_at is null
+	`
+	_, err := tx.Exec(stmt, time.Now(), id, tx.OrganizationID())
+	return handleError(err)
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestCreateWorkloadIdentity(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		t.Run("success", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			actual := models.WorkloadIdentity{
+				Name:       "github-deploy",
+				Issuer:     "https://token.actions.githubusercontent.com",
+				JWKSURL:    "https://token.actions.githubusercontent.com/.well-known/jwks",
+				Audience:   "https://infrahq.com",
+				Subject:    "repo:infrahq/infra:*",
+				IdentityID: uid.ID(1234),
+				Scopes:     models.CommaSeparatedStrings{"grants:read"},
+			}
+			err := CreateWorkloadIdentity(tx, &actual)
+			assert.NilError(t, err)
+			assert.Assert(t, actual.ID != 0)
+
+			expected := models.WorkloadIdentity{
+				Model: models.Model{
+					ID:        uid.ID(999),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+				OrganizationMember: models.OrganizationMember{OrganizationID: defaultOrganizationID},
+				Name:               "github-deploy",
+				Issuer:             "https://token.actions.githubusercontent.com",
+				JWKSURL:            "https://token.actions.githubusercontent.com/.well-known/jwks",
+				Audience:           "https://infrahq.com",
+				Subject:            "repo:infrahq/infra:*",
+				IdentityID:         uid.ID(1234),
+				Scopes:             models.CommaSeparatedStrings{"grants:read"},
+			}
+			assert.DeepEqual(t, actual, expected, cmpModel)
+
+			fromDB, err := GetWorkloadIdentity(tx, actual.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, fromDB, &expected, cmpModel)
+		})
+		t.Run("duplicate name", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			w := models.WorkloadIdentity{
+				Name:       "gitlab",
+				Issuer:     "https://gitlab.com",
+				JWKSURL:    "https://gitlab.com/oauth/discovery/keys",
+				Audience:   "https://infrahq.com",
+				Subject:    "project_path:infrahq/infra:*",
+				IdentityID: uid.ID(1234),
+			}
+			assert.NilError(t, CreateWorkloadIdentity(tx, &w))
+
+			w.ID = 0
+			err := CreateWorkloadIdentity(tx, &w)
+			var ucErr UniqueConstraintError
+			assert.Assert(t, errors.As(err, &ucErr), "wrong error %v", err)
+			assert.DeepEqual(t, ucErr, UniqueConstraintError{Table: "workload_identities", Column: "name"})
+		})
+		t.Run("missing subject", func(t *testing.T) {
+			tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+			err := CreateWorkloadIdentity(tx, &models.WorkloadIdentity{
+				Name:       "gitlab",
+				Issuer:     "https://gitlab.com",
+				JWKSURL:    "https://gitlab.com/oauth/discovery/keys",
+				Audience:   "https://infrahq.com",
+				IdentityID: uid.ID(1234),
+			})
+			assert.ErrorContains(t, err, "subject is required")
+		})
+	})
+}
+
+func TestListWorkloadIdentities(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *DB) {
+		tx := txnForTestCase(t, db, db.DefaultOrg.ID)
+
+		github := &models.WorkloadIdentity{
+			Name:       "github",
+			Issuer:     "https://token.actions.githubusercontent.com",
+			JWKSURL:    "https://token.actions.githubusercontent.com/.well-known/jwks",
+			Audience:   "https://infrahq.com",
+			Subject:    "repo:infrahq/infra:*",
+			IdentityID: uid.ID(1234),
+		}
+		gitlab := &models.WorkloadIdentity{
+			Name:       "gitlab",
+			Issuer:     "https://gitlab.com",
+			JWKSURL:    "https://gitlab.com/oauth/discovery/keys",
+			Audience:   "https://infrahq.com",
+			Subject:    "project_path:infrahq/infra:*",
+			IdentityID: uid.ID(1234),
+		}
+		otherOrg := &models.WorkloadIdentity{
+			Name:               "github",
+			Issuer:             "https://token.actions.githubusercontent.com",
+			JWKSURL:            "https://token.actions.githubusercontent.com/.well-known/jwks",
+			Audience:           "https://infrahq.com",
+			Subject:            "repo:other/infra:*",
+			IdentityID:         uid.ID(1234),
+			OrganizationMember: models.OrganizationMember{OrganizationID: 2222},
+		}
+		deleted := &models.WorkloadIdentity{
+			Name:       "deleted",
+			Issuer:     "https://token.actions.githubusercontent.com",
+			JWKSURL:    "https://token.actions.githubusercontent.com/.well-known/jwks",
+			Audience:   "https://infrahq.com",
+			Subject:    "*",
+			IdentityID: uid.ID(1234),
+		}
+		for _, w := range []*models.WorkloadIdentity{github, gitlab, deleted} {
+			assert.NilError(t, CreateWorkloadIdentity(tx, w))
+		}
+		assert.NilError(t, CreateWorkloadIdentity(tx.WithOrgID(2222), otherOrg))
+		assert.NilError(t, DeleteWorkloadIdentity(tx, deleted.ID))
+
+		t.Run("all", func(t *testing.T) {
+			actual, err := ListWorkloadIdentities(tx, ListWorkloadIdentitiesOptions{})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.WorkloadIdentity{*github, *gitlab}, cmpTimeWithDBPrecision)
+		})
+		t.Run("by issuer", func(t *testing.T) {
+			actual, err := ListWorkloadIdentities(tx, ListWorkloadIdentitiesOptions{ByIssuer: "https://gitlab.com"})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual, []models.WorkloadIdentity{*gitlab}, cmpTimeWithDBPrecision)
+		})
+		t.Run("deleted", func(t *testing.T) {
+			_, err := GetWorkloadIdentity(tx, deleted.ID)
+			assert.ErrorIs(t, err, internal.ErrNotFound)
+		})
+	})
+}
@@ -100,7 +100,10 @@ func (a *API) GetDeviceFlowStatus(rCtx access.RequestContext, req *api.DeviceFlo
 		InactivityTimeout:   time.Now().UTC().Add(a.server.options.SessionInactivityTimeout),
 		InactivityExtension: a.server.options.SessionInactivityTimeout,
 		Scopes:              models.CommaSeparatedStrings{models.ScopeAllowCreateAccessKey},
+		LoginMethod:         "deviceflow",
 	}
+	client := a.server.accessKeyClient(rCtx.Request)
+	accessKey.ClientIP, accessKey.UserAgent = client.IP, client.UserAgent
 
 	bearer, err := data.CreateAccessKey(rCtx.DBTxn, accessKey)
 	if err != nil {
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+)
+
+func (a *API) ListUserAccess(rCtx access.RequestContext, r *api.ListUserAccessRequest) (*api.ListResponse[api.EffectiveAccess], error) {
+	items, err := access.ListUserAccess(rCtx, r.ID, r.Destination)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewListResponse(items, api.PaginationResponse{}, effectiveAccessToAPI), nil
+}
+
+func (a *API) ListDestinationAccess(rCtx access.RequestContext, r *api.Resource) (*api.ListResponse[api.EffectiveAccess], error) {
+	items, err := access.ListDestinationAccess(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewListResponse(items, api.PaginationResponse{}, effectiveAccessToAPI), nil
+}
+
+func effectiveAccessToAPI(item access.EffectiveAccess) api.EffectiveAccess {
+	result := api.EffectiveAccess{
+		UserID:    item.UserID,
+		UserName:  item.UserName,
+		Privilege: item.Grant.Privilege,
+		Resource:  item.Grant.Resource,
+		GrantID:   item.Grant.ID,
+		Expires:   api.Time(item.Grant.ExpiresAt),
+	}
+	if item.Group != nil {
+		result.GroupID = item.Group.ID
+		result.GroupName = item.Group.Name
+	}
+	return result
+}
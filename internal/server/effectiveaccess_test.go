@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_EffectiveAccess(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+	db := srv.DB()
+
+	userKey, user := createAccessKey(t, db, "user@example.com")
+	otherKey, other := createAccessKey(t, db, "other@example.com")
+
+	developers := &models.Group{Name: "developers"}
+	createGroups(t, db, developers)
+	assert.NilError(t, data.AddUsersToGroup(db, developers.ID, []uid.ID{user.ID, other.ID}))
+
+	dest := &models.Destination{Name: "production", UniqueID: "production", Kind: models.DestinationKindKubernetes}
+	assert.NilError(t, data.CreateDestination(db, dest))
+
+	direct := &models.Grant{Subject: models.NewSubjectForUser(user.ID), Privilege: "view", Resource: "production"}
+	inherited := &models.Grant{Subject: models.NewSubjectForGroup(developers.ID), Privilege: "edit", Resource: "production.web"}
+	unrelated := &models.Grant{Subject: models.NewSubjectForUser(user.ID), Privilege: "admin", Resource: "staging"}
+	for _, grant := range []*models.Grant{direct, inherited, unrelated} {
+		assert.NilError(t, data.CreateGrant(db, grant))
+	}
+
+	list := func(t *testing.T, path, key string) (int, []api.EffectiveAccess) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			return resp.Code, nil
+		}
+
+		var actual api.ListResponse[api.EffectiveAccess]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		return resp.Code, actual.Items
+	}
+
+	userAccess := []api.EffectiveAccess{
+		{UserID: user.ID, UserName: user.Name, Privilege: "view", Resource: "production", GrantID: direct.ID},
+		{UserID: user.ID, UserName: user.Name, Privilege: "edit", Resource: "production.web", GrantID: inherited.ID, GroupID: developers.ID, GroupName: "developers"},
+	}
+
+	t.Run("user access", func(t *testing.T) {
+		code, actual := list(t, "/api/users/"+user.ID.String()+"/access", adminAccessKey(srv))
+		assert.Equal(t, code, http.StatusOK)
+		expected := append(userAccess[:2:2], api.EffectiveAccess{
+			UserID: user.ID, UserName: user.Name, Privilege: "admin", Resource: "staging", GrantID: unrelated.ID,
+		})
+		assert.DeepEqual(t, actual, expected)
+	})
+
+	t.Run("user access by destination", func(t *testing.T) {
+		code, actual := list(t, "/api/users/"+user.ID.String()+"/access?destination=production", adminAccessKey(srv))
+		assert.Equal(t, code, http.StatusOK)
+		assert.DeepEqual(t, actual, userAccess)
+	})
+
+	t.Run("user can view their own access", func(t *testing.T) {
+		code, actual := list(t, "/api/users/"+user.ID.String()+"/access?destination=production", userKey)
+		assert.Equal(t, code, http.StatusOK)
+		assert.DeepEqual(t, actual, userAccess)
+	})
+
+	t.Run("user can not view access of others", func(t *testing.T) {
+		code, _ := list(t, "/api/users/"+user.ID.String()+"/access", otherKey)
+		assert.Equal(t, code, http.StatusForbidden)
+	})
+
+	t.Run("destination access", func(t *testing.T) {
+		code, actual := list(t, "/api/destinations/"+dest.ID.String()+"/access", adminAccessKey(srv))
+		assert.Equal(t, code, http.StatusOK)
+		expected := []api.EffectiveAccess{
+			{UserID: other.ID, UserName: other.Name, Privilege: "edit", Resource: "production.web", GrantID: inherited.ID, GroupID: developers.ID, GroupName: "developers"},
+			userAccess[0],
+			userAccess[1],
+		}
+		assert.DeepEqual(t, actual, expected)
+	})
+
+	t.Run("destination access requires admin", func(t *testing.T) {
+		code, _ := list(t, "/api/destinations/"+dest.ID.String()+"/access", userKey)
+		assert.Equal(t, code, http.StatusForbidden)
+	})
+}
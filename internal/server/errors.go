@@ -9,6 +9,8 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/rs/zerolog"
+
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/access"
@@ -22,6 +24,32 @@ import (
 // response body using api.Error, then sends both as a response to the active
 // request.
 func sendAPIError(writer http.ResponseWriter, req *http.Request, err error) {
+	resp, level := apiErrorFromError(writer.Header(), err)
+
+	logging.L.WithLevel(level).CallerSkipFrame(1).
+		Err(err).
+		Str("method", req.Method).
+		Str("path", req.URL.Path).
+		Int32("statusCode", resp.Code).
+		Str("remoteAddr", req.RemoteAddr).
+		Msg("api request error")
+
+	if resp.Code == http.StatusNotModified {
+		writer.WriteHeader(int(resp.Code))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.WriteHeader(int(resp.Code))
+	if err := json.NewEncoder(writer).Encode(resp); err != nil {
+		logging.L.Error().Err(err).Msg("failed to write error response")
+	}
+}
+
+// apiErrorFromError translates err into an api.Error with the appropriate HTTP
+// status code, and returns the level that should be used to log the error. Any
+// response headers required by the error are set on header.
+func apiErrorFromError(header http.Header, err error) (*api.Error, zerolog.Level) {
 	resp := &api.Error{
 		Code:    http.StatusInternalServerError,
 		Message: "internal server error", // don't leak any info by default
@@ -33,7 +61,7 @@ func sendAPIError(writer http.ResponseWriter, req *http.Request, err error) {
 	var authnError AuthenticationError
 	var apiError api.Error
 
-	log := logging.L.Debug()
+	level := zerolog.DebugLevel
 
 	switch {
 	case errors.As(err, &apiError):
@@ -45,7 +73,7 @@ func sendAPIError(writer http.ResponseWriter, req *http.Request, err error) {
 		// hide the error text, it may contain sensitive information
 		resp.Message = "unauthorized"
 		// log the error at info because it is not in the response
-		log = logging.L.Info()
+		level = zerolog.InfoLevel
 
 	case errors.As(err, &authnError):
 		resp.Code = http.StatusUnauthorized
@@ -97,7 +125,7 @@ func sendAPIError(writer http.ResponseWriter, req *http.Request, err error) {
 		resp.Message = err.Error()
 
 	case errors.As(err, &overLimitError):
-		writer.Header().Set("Retry-After", strconv.Itoa(int(overLimitError.RetryAfter.Seconds())))
+		header.Set("Retry-After", strconv.Itoa(int(overLimitError.RetryAfter.Seconds())))
 		resp.Code = http.StatusTooManyRequests
 		resp.Message = err.Error()
 
@@ -111,27 +139,9 @@ func sendAPIError(writer http.ResponseWriter, req *http.Request, err error) {
 		resp.Message = fmt.Sprintf("client closed the request: %v", err)
 
 	default:
-		log = logging.L.Error()
-	}
-
-	log.CallerSkipFrame(1).
-		Err(err).
-		Str("method", req.Method).
-		Str("path", req.URL.Path).
-		Int32("statusCode", resp.Code).
-		Str("remoteAddr", req.RemoteAddr).
-		Msg("api request error")
-
-	if resp.Code == http.StatusNotModified {
-		writer.WriteHeader(int(resp.Code))
-		return
-	}
-
-	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
-	writer.WriteHeader(int(resp.Code))
-	if err := json.NewEncoder(writer).Encode(resp); err != nil {
-		logging.L.Error().Err(err).Msg("failed to write error response")
+		level = zerolog.ErrorLevel
 	}
+	return resp, level
 }
 
 func newAPIErrorForUniqueConstraintError(ucErr data.UniqueConstraintError, msg string) api.Error {
@@ -5,11 +5,10 @@ import (
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/email"
-	"github.com/infrahq/infra/internal/server/redis"
 )
 
 func (a *API) RequestForgotDomains(rCtx access.RequestContext, r *api.ForgotDomainRequest) (*api.EmptyResponse, error) {
-	if err := redis.NewLimiter(a.server.redis).RateOK(r.Email, 10); err != nil {
+	if err := a.server.limiter.RateOK(r.Email, 10); err != nil {
 		return nil, err
 	}
 
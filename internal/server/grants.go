@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -192,11 +193,15 @@ func getGrantFromGrantRequest(rCtx access.RequestContext, r api.GrantRequest) (*
 		return nil, fmt.Errorf("%w: must specify privilege", internal.ErrBadRequest)
 	}
 
-	return &models.Grant{
+	grant := &models.Grant{
 		Subject:   subject,
 		Resource:  r.Resource,
 		Privilege: r.Privilege,
-	}, nil
+	}
+	if r.Duration > 0 {
+		grant.ExpiresAt = time.Now().Add(time.Duration(r.Duration))
+	}
+	return grant, nil
 }
 
 // See docs/dev/api-versioned-handlers.md for a guide to adding new version handlers.
@@ -426,6 +426,7 @@ func TestAPI_ListGrants(t *testing.T) {
 							"createdBy": "%[1]v",
 							"privilege": "custom1",
 							"resource": "res1",
+							"expires": null,
 							"user": "%[2]v",
 							"created": "%[3]v",
 							"updated": "%[3]v"
@@ -1053,6 +1054,7 @@ func TestAPI_CreateGrant(t *testing.T) {
 					"createdBy": "%[1]v",
 					"privilege": "%[2]v",
 					"resource": "some-cluster",
+					"expires": null,
 					"user": "%[3]v",
 					"created": "%[4]v",
 					"updated": "%[4]v",
@@ -1085,6 +1087,7 @@ func TestAPI_CreateGrant(t *testing.T) {
 					"createdBy": "%[1]v",
 					"privilege": "%[2]v",
 					"resource": "some-big-cluster",
+					"expires": null,
 					"user": "%[3]v",
 					"created": "%[4]v",
 					"updated": "%[4]v",
@@ -1099,6 +1102,42 @@ func TestAPI_CreateGrant(t *testing.T) {
 				assert.DeepEqual(t, actual, expected, cmpAPIGrantJSON)
 			},
 		},
+		"success w/ duration": {
+			setup: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+			},
+			body: api.GrantRequest{
+				User:      someUser.ID,
+				Privilege: models.InfraViewRole,
+				Resource:  "some-temporary-cluster",
+				Duration:  api.Duration(time.Hour),
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+				respBody := &api.CreateGrantResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+
+				expires := respBody.Expires.Time()
+				assert.Assert(t, expires.After(time.Now().Add(59*time.Minute)), expires)
+				assert.Assert(t, expires.Before(time.Now().Add(61*time.Minute)), expires)
+			},
+		},
+		"negative duration": {
+			setup: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+			},
+			body: api.GrantRequest{
+				User:      someUser.ID,
+				Privilege: models.InfraViewRole,
+				Resource:  "some-temporary-cluster",
+				Duration:  api.Duration(-time.Hour),
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+			},
+		},
 		"failure w/ username": {
 			setup: func(t *testing.T, req *http.Request) {
 				req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
@@ -1138,6 +1177,7 @@ func TestAPI_CreateGrant(t *testing.T) {
 					"createdBy": "%[1]v",
 					"privilege": "%[2]v",
 					"resource": "some-resource",
+					"expires": null,
 					"group": "%[3]v",
 					"created": "%[4]v",
 					"updated": "%[4]v",
@@ -1204,6 +1244,7 @@ func TestAPI_CreateGrant(t *testing.T) {
 					"createdBy": "%[1]v",
 					"privilege": "%[2]v",
 					"resource": "infra",
+					"expires": null,
 					"user": "%[3]v",
 					"created": "%[4]v",
 					"updated": "%[4]v",
@@ -1741,3 +1782,185 @@ func TestAPI_UpdateGrants(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_DelegatedAdmin(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	var (
+		lead    = models.Identity{Name: "lead@example.com"}
+		someone = models.Identity{Name: "someone@example.com"}
+	)
+	createIdentities(t, srv.DB(), &lead, &someone)
+
+	var (
+		sre      = models.Group{Name: "sre"}
+		platform = models.Group{Name: "platform"}
+		ops      = models.Group{Name: "ops"}
+		admins   = models.Group{Name: "admins"}
+		leads    = models.Group{Name: "leads"}
+		oncall   = models.Group{Name: "oncall"}
+	)
+	createGroups(t, srv.DB(), &sre, &platform, &ops, &admins, &leads, &oncall)
+	assert.NilError(t, data.AddGroupsToGroup(srv.DB(), admins.ID, []uid.ID{ops.ID}))
+	assert.NilError(t, data.AddGroupsToGroup(srv.DB(), leads.ID, []uid.ID{oncall.ID}))
+
+	for _, grant := range []*models.Grant{
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GrantAdminRole, Resource: "prod-*"},
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GroupOwnerRole, Resource: "group:sre"},
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GroupOwnerRole, Resource: "group:ops"},
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GroupOwnerRole, Resource: "group:admins"},
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GroupOwnerRole, Resource: "group:leads"},
+		{Subject: models.NewSubjectForUser(lead.ID), Privilege: models.GroupOwnerRole, Resource: "group:oncall"},
+		{Subject: models.NewSubjectForGroup(admins.ID), Privilege: models.InfraAdminRole, Resource: "infra"},
+		{Subject: models.NewSubjectForGroup(leads.ID), Privilege: models.GrantAdminRole, Resource: "staging"},
+		{Subject: models.NewSubjectForGroup(leads.ID), Privilege: models.GroupOwnerRole, Resource: "group:platform"},
+	} {
+		assert.NilError(t, data.CreateGrant(srv.DB(), grant))
+	}
+
+	accessKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: lead.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	})
+	assert.NilError(t, err)
+
+	call := func(t *testing.T, method, path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, jsonBody(t, body))
+		req.Header.Set("Authorization", "Bearer "+accessKey)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("create grant within scope", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/grants", api.GrantRequest{
+			UserName:  someone.Name,
+			Privilege: "view",
+			Resource:  "prod-us-1.default",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var grant api.Grant
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &grant))
+
+		resp = call(t, http.MethodGet, "/api/grants?destination=prod-us-1", nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = call(t, http.MethodDelete, "/api/grants/"+grant.ID.String(), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+	})
+
+	t.Run("list grants only returns grants within scope", func(t *testing.T) {
+		inScope := &models.Grant{Subject: models.NewSubjectForUser(someone.ID), Privilege: "view", Resource: "prod-us-1"}
+		allDestinations := &models.Grant{Subject: models.NewSubjectForUser(someone.ID), Privilege: "view", Resource: "*"}
+		assert.NilError(t, data.CreateGrant(srv.DB(), inScope))
+		assert.NilError(t, data.CreateGrant(srv.DB(), allDestinations))
+
+		resp := call(t, http.MethodGet, "/api/grants?destination=prod-us-1", nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var grants api.ListResponse[api.Grant]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &grants))
+		var ids []uid.ID
+		for _, grant := range grants.Items {
+			ids = append(ids, grant.ID)
+		}
+		assert.DeepEqual(t, ids, []uid.ID{inScope.ID})
+	})
+
+	t.Run("create grant outside scope", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/grants", api.GrantRequest{
+			UserName:  someone.Name,
+			Privilege: "view",
+			Resource:  "staging",
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = call(t, http.MethodGet, "/api/grants?destination=staging", nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("create grant for infra", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/grants", api.GrantRequest{
+			UserName:  someone.Name,
+			Privilege: models.InfraAdminRole,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("create scoped admin grant", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/grants", api.GrantRequest{
+			UserName:  someone.Name,
+			Privilege: models.GrantAdminRole,
+			Resource:  "prod-us-1",
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("scoped role with the wrong kind of resource", func(t *testing.T) {
+		body := api.GrantRequest{UserName: someone.Name, Privilege: models.GroupOwnerRole, Resource: "prod"}
+		req := httptest.NewRequest(http.MethodPost, "/api/grants", jsonBody(t, body))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("update members of owned group", func(t *testing.T) {
+		resp := call(t, http.MethodPatch, "/api/groups/"+sre.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{someone.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("update members of owned group with an infra grant", func(t *testing.T) {
+		resp := call(t, http.MethodPatch, "/api/groups/"+admins.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{lead.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		// the infra grant is inherited from the admins group
+		resp = call(t, http.MethodPatch, "/api/groups/"+ops.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{lead.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = call(t, http.MethodPatch, "/api/groups/"+ops.ID.String()+"/groups", api.UpdateGroupsInGroupRequest{
+			GroupIDsToAdd: []uid.ID{sre.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("update members of owned group with a scoped admin grant", func(t *testing.T) {
+		resp := call(t, http.MethodPatch, "/api/groups/"+leads.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{lead.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		// the scoped admin grants are inherited from the leads group
+		resp = call(t, http.MethodPatch, "/api/groups/"+oncall.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{lead.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = call(t, http.MethodPatch, "/api/groups/"+oncall.ID.String()+"/groups", api.UpdateGroupsInGroupRequest{
+			GroupIDsToAdd: []uid.ID{sre.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("update members of another group", func(t *testing.T) {
+		resp := call(t, http.MethodPatch, "/api/groups/"+platform.ID.String()+"/users", api.UpdateUsersInGroupRequest{
+			UserIDsToAdd: []uid.ID{someone.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+}
@@ -53,3 +53,7 @@ func (a *API) DeleteGroup(rCtx access.RequestContext, r *api.Resource) (*api.Emp
 func (a *API) UpdateUsersInGroup(rCtx access.RequestContext, r *api.UpdateUsersInGroupRequest) (*api.EmptyResponse, error) {
 	return nil, access.UpdateUsersInGroup(rCtx, r.GroupID, r.UserIDsToAdd, r.UserIDsToRemove)
 }
+
+func (a *API) UpdateGroupsInGroup(rCtx access.RequestContext, r *api.UpdateGroupsInGroupRequest) (*api.EmptyResponse, error) {
+	return nil, access.UpdateGroupsInGroup(rCtx, r.GroupID, r.GroupIDsToAdd, r.GroupIDsToRemove)
+}
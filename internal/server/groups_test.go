@@ -450,6 +450,108 @@ func TestAPI_UpdateUsersInGroup(t *testing.T) {
 	}
 }
 
+func TestAPI_UpdateGroupsInGroup(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	var (
+		platform = models.Group{Name: "platform"}
+		sre      = models.Group{Name: "sre"}
+	)
+	createGroups(t, srv.DB(), &platform, &sre)
+
+	type testCase struct {
+		urlPath  string
+		setup    func(t *testing.T, req *http.Request)
+		expected func(t *testing.T, resp *httptest.ResponseRecorder)
+		body     api.UpdateGroupsInGroupRequest
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		body := jsonBody(t, tc.body)
+		// nolint:noctx
+		req := httptest.NewRequest(http.MethodPatch, tc.urlPath, body)
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Add("Infra-Version", apiVersionLatest)
+
+		if tc.setup != nil {
+			tc.setup(t, req)
+		}
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+
+		tc.expected(t, resp)
+	}
+
+	testCases := map[string]testCase{
+		"not authenticated": {
+			urlPath: fmt.Sprintf("/api/groups/%s/groups", platform.ID.String()),
+			setup: func(t *testing.T, req *http.Request) {
+				req.Header.Del("Authorization")
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusUnauthorized)
+			},
+		},
+		"add groups": {
+			urlPath: fmt.Sprintf("/api/groups/%s/groups", platform.ID.String()),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+				ids, err := data.ListDescendantGroupIDs(srv.DB(), platform.ID)
+				assert.NilError(t, err)
+				assert.Equal(t, len(ids), 2)
+			},
+			body: api.UpdateGroupsInGroupRequest{
+				GroupIDsToAdd: []uid.ID{sre.ID},
+			},
+		},
+		"add group that would create a cycle": {
+			urlPath: fmt.Sprintf("/api/groups/%s/groups", sre.ID.String()),
+			setup: func(t *testing.T, req *http.Request) {
+				err := data.AddGroupsToGroup(srv.DB(), platform.ID, []uid.ID{sre.ID})
+				assert.NilError(t, err)
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+			},
+			body: api.UpdateGroupsInGroupRequest{
+				GroupIDsToAdd: []uid.ID{platform.ID},
+			},
+		},
+		"remove groups": {
+			urlPath: fmt.Sprintf("/api/groups/%s/groups", platform.ID.String()),
+			setup: func(t *testing.T, req *http.Request) {
+				err := data.AddGroupsToGroup(srv.DB(), platform.ID, []uid.ID{sre.ID})
+				assert.NilError(t, err)
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+				ids, err := data.ListDescendantGroupIDs(srv.DB(), platform.ID)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, ids, []uid.ID{platform.ID})
+			},
+			body: api.UpdateGroupsInGroupRequest{
+				GroupIDsToRemove: []uid.ID{sre.ID},
+			},
+		},
+		"add unknown group": {
+			urlPath: fmt.Sprintf("/api/groups/%s/groups", platform.ID.String()),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+			},
+			body: api.UpdateGroupsInGroupRequest{
+				GroupIDsToAdd: []uid.ID{1337},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
+
 var cmpModelsIdentityShallow = cmp.Comparer(func(x, y models.Identity) bool {
 	return x.Name == y.Name
 })
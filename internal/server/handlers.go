@@ -18,7 +18,6 @@ import (
 	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
-	"github.com/infrahq/infra/internal/server/redis"
 )
 
 type API struct {
@@ -93,12 +92,22 @@ func getPublicJWK(rCtx access.RequestContext) ([]jose.JSONWebKey, error) {
 		return nil, fmt.Errorf("require organization")
 	}
 
-	var pub jose.JSONWebKey
-	if err := pub.UnmarshalJSON(rCtx.Authenticated.Organization.PublicJWK); err != nil {
+	signingKeys, err := data.ListSigningKeys(rCtx.DBTxn, data.ListSigningKeysOptions{
+		ByStates: []models.SigningKeyState{models.SigningKeyStateActive, models.SigningKeyStateVerifying},
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return []jose.JSONWebKey{pub}, nil
+	keys := make([]jose.JSONWebKey, 0, len(signingKeys))
+	for _, signingKey := range signingKeys {
+		var pub jose.JSONWebKey
+		if err := pub.UnmarshalJSON(signingKey.PublicJWK); err != nil {
+			return nil, err
+		}
+		keys = append(keys, pub)
+	}
+	return keys, nil
 }
 
 type WellKnownJWKResponse struct {
@@ -118,25 +127,31 @@ func (a *API) Login(rCtx access.RequestContext, r *api.LoginRequest) (*api.Login
 	case r.AccessKey != "":
 		loginMethod = authn.NewKeyExchangeAuthentication(r.AccessKey)
 	case r.PasswordCredentials != nil:
-		if err := redis.NewLimiter(a.server.redis).RateOK(r.PasswordCredentials.Name, 10); err != nil {
+		usernameWithOrganization := fmt.Sprintf("%s:%s", r.PasswordCredentials.Name, rCtx.Authenticated.Organization.ID)
+		limiter := a.server.limiter
+		if err := limiter.RateOK(usernameWithOrganization, 10); err != nil {
 			return nil, err
 		}
-
-		usernameWithOrganization := fmt.Sprintf("%s:%s", r.PasswordCredentials.Name, rCtx.Authenticated.Organization.ID)
-		limiter := redis.NewLimiter(a.server.redis)
 		if err := limiter.LoginOK(usernameWithOrganization); err != nil {
 			return nil, err
 		}
 
+		failureKeys := a.server.loginFailureKeys(rCtx.Request, r.PasswordCredentials.Name)
+		if err := checkLoginLockout(rCtx.DBTxn, failureKeys); err != nil {
+			return nil, err
+		}
+
 		onSuccess = func() {
 			limiter.LoginGood(usernameWithOrganization)
+			clearLoginFailures(rCtx, failureKeys)
 		}
 
 		onFailure = func() {
 			limiter.LoginBad(usernameWithOrganization, 10)
+			recordLoginFailure(a.server.db, rCtx.Authenticated.Organization.ID, a.server.options.Login, failureKeys)
 		}
 
-		loginMethod = authn.NewPasswordCredentialAuthentication(r.PasswordCredentials.Name, r.PasswordCredentials.Password)
+		loginMethod = authn.NewPasswordCredentialAuthentication(r.PasswordCredentials.Name, r.PasswordCredentials.Password, r.PasswordCredentials.MFACode)
 	case r.OIDC != nil:
 		var provider *models.Provider
 		if r.OIDC.ProviderID == models.InternalGoogleProviderID {
@@ -162,12 +177,50 @@ func (a *API) Login(rCtx access.RequestContext, r *api.LoginRequest) (*api.Login
 			provider,
 			r.OIDC.RedirectURL,
 			r.OIDC.Code,
+			r.OIDC.CodeVerifier,
 			providerClient,
 			rCtx.Authenticated.Organization.AllowedDomains,
 		)
 		if err != nil {
 			return nil, err
 		}
+	case r.LDAP != nil:
+		provider, err := data.GetProvider(rCtx.DBTxn, data.GetProviderOptions{ByID: r.LDAP.ProviderID})
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity provider: %w", err)
+		}
+		if provider.Kind != models.ProviderKindLDAP {
+			return nil, fmt.Errorf("%w: provider %v is not an ldap provider", internal.ErrBadRequest, provider.Name)
+		}
+
+		usernameWithProvider := fmt.Sprintf("%s:%s", r.LDAP.Name, provider.ID)
+		limiter := a.server.limiter
+		if err := limiter.LoginOK(usernameWithProvider); err != nil {
+			return nil, err
+		}
+
+		// the directory is expected to lock out its own users, so only the
+		// source IP address is counted
+		failureKeys := a.server.loginFailureKeys(rCtx.Request, "")
+		if err := checkLoginLockout(rCtx.DBTxn, failureKeys); err != nil {
+			return nil, err
+		}
+
+		onSuccess = func() {
+			limiter.LoginGood(usernameWithProvider)
+		}
+
+		onFailure = func() {
+			limiter.LoginBad(usernameWithProvider, 10)
+			recordLoginFailure(a.server.db, rCtx.Authenticated.Organization.ID, a.server.options.Login, failureKeys)
+		}
+
+		loginMethod, err = authn.NewLDAPAuthentication(provider, r.LDAP.Name, r.LDAP.Password)
+		if err != nil {
+			return nil, err
+		}
+	case r.WorkloadIdentity != nil:
+		loginMethod = authn.NewWorkloadIdentityAuthentication(r.WorkloadIdentity.Token)
 	default:
 		// make sure to always fail by default
 		return nil, fmt.Errorf("%w: missing login credentials", internal.ErrBadRequest)
@@ -175,8 +228,13 @@ func (a *API) Login(rCtx access.RequestContext, r *api.LoginRequest) (*api.Login
 
 	// do the actual login now that we know the method selected
 	expires := time.Now().UTC().Add(a.server.options.SessionDuration)
-	result, err := authn.Login(rCtx.Request.Context(), rCtx.DBTxn, loginMethod, expires, a.server.options.SessionInactivityTimeout)
+	result, err := authn.Login(rCtx.Request.Context(), rCtx.DBTxn, loginMethod, expires, a.server.options.SessionInactivityTimeout, a.server.accessKeyClient(rCtx.Request))
 	if err != nil {
+		if errors.Is(err, authn.ErrMFARequired) {
+			// the password was valid, the client must login again with an MFA code
+			return &api.LoginResponse{MFARequired: true}, nil
+		}
+
 		if onFailure != nil {
 			onFailure()
 		}
@@ -194,6 +252,23 @@ func (a *API) Login(rCtx access.RequestContext, r *api.LoginRequest) (*api.Login
 		onSuccess()
 	}
 
+	a.loginSucceeded(rCtx, loginMethod, result)
+
+	key := result.AccessKey
+	return &api.LoginResponse{
+		UserID:                 key.IssuedForID,
+		Name:                   key.IssuedForName,
+		AccessKey:              result.Bearer,
+		Expires:                api.Time(key.ExpiresAt),
+		PasswordUpdateRequired: result.CredentialUpdateRequired,
+		MFAEnrollmentRequired:  result.MFAEnrollmentRequired,
+		OrganizationName:       result.OrganizationName,
+	}, nil
+}
+
+// loginSucceeded sets the authentication cookie for a successful login, and
+// records the login.
+func (a *API) loginSucceeded(rCtx access.RequestContext, loginMethod authn.LoginMethod, result authn.LoginResult) {
 	cookie := cookieConfig{
 		Name:    cookieAuthorizationName,
 		Value:   result.Bearer,
@@ -213,15 +288,6 @@ func (a *API) Login(rCtx access.RequestContext, r *api.LoginRequest) (*api.Login
 	// Update the request context so that logging middleware can include the userID
 	rCtx.Authenticated.User = result.User
 	rCtx.Response.LoginUserID = result.User.ID
-
-	return &api.LoginResponse{
-		UserID:                 key.IssuedForID,
-		Name:                   key.IssuedForName,
-		AccessKey:              result.Bearer,
-		Expires:                api.Time(key.ExpiresAt),
-		PasswordUpdateRequired: result.CredentialUpdateRequired,
-		OrganizationName:       result.OrganizationName,
-	}, nil
 }
 
 func (a *API) Logout(rCtx access.RequestContext, _ *api.EmptyRequest) (*api.EmptyResponse, error) {
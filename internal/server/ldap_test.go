@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/testing/ldapserver"
+)
+
+func TestAPI_LDAP(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+	directory := ldapserver.New(t,
+		ldapserver.Entry{
+			DN:       "cn=infra,dc=example,dc=com",
+			Password: "service-password",
+		},
+		ldapserver.Entry{
+			DN:       "uid=dana,ou=people,dc=example,dc=com",
+			Password: "dana-password",
+			Attributes: map[string][]string{
+				"uid":  {"dana"},
+				"mail": {"dana@example.com"},
+			},
+		},
+		ldapserver.Entry{
+			DN: "cn=platform,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"platform"},
+				"member":      {"uid=dana,ou=people,dc=example,dc=com"},
+			},
+		},
+	)
+
+	ldapConfig := &api.ProviderLDAP{
+		BindDN:       "cn=infra,dc=example,dc=com",
+		BindPassword: "service-password",
+		BaseDN:       "dc=example,dc=com",
+	}
+
+	var provider api.Provider
+	t.Run("create provider", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/providers", jsonBody(t, api.CreateProviderRequest{
+			Name: "directory",
+			Kind: "ldap",
+			URL:  directory.URL,
+			LDAP: ldapConfig,
+		}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &provider))
+		assert.Equal(t, provider.Kind, "ldap")
+		assert.Equal(t, provider.URL, directory.URL)
+	})
+
+	t.Run("create provider with invalid service account", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/providers", jsonBody(t, api.CreateProviderRequest{
+			Name: "wrong-password",
+			Kind: "ldap",
+			URL:  directory.URL,
+			LDAP: &api.ProviderLDAP{
+				BindDN:       "cn=infra,dc=example,dc=com",
+				BindPassword: "wrong",
+				BaseDN:       "dc=example,dc=com",
+			},
+		}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		var apiError api.Error
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &apiError))
+		assert.Equal(t, len(apiError.FieldErrors), 1)
+		assert.Equal(t, apiError.FieldErrors[0].FieldName, "ldap")
+	})
+
+	login := func(t *testing.T, name, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/login", jsonBody(t, api.LoginRequest{
+			LDAP: &api.LoginRequestLDAP{ProviderID: provider.ID, Name: name, Password: password},
+		}))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("login", func(t *testing.T) {
+		resp := login(t, "dana", "dana-password")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+		assert.Equal(t, loginResp.Name, "dana@example.com")
+		assert.Assert(t, loginResp.AccessKey != "")
+
+		user, err := data.GetIdentity(srv.DB(), data.GetIdentityOptions{ByName: "dana@example.com", LoadGroups: true})
+		assert.NilError(t, err)
+		assert.Equal(t, len(user.Groups), 1)
+		assert.Equal(t, user.Groups[0].Name, "platform")
+	})
+
+	t.Run("login with wrong password", func(t *testing.T) {
+		resp := login(t, "dana", "wrong")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+}
@@ -308,3 +308,127 @@ func cmpApiTimeWithThreshold(threshold time.Duration) cmp.Option {
 		return delta <= threshold && delta >= -threshold
 	})
 }
+
+func TestAPI_Login_Lockout(t *testing.T) {
+	srv := setupServer(t, withAdminUser, func(_ *testing.T, opts *Options) {
+		opts.Login = LoginOptions{
+			MaxFailures:      3,
+			MaxFailuresPerIP: 5,
+			Lockout:          time.Minute,
+			MaxLockout:       time.Hour,
+		}
+	})
+	routes := srv.GenerateRoutes()
+
+	user := &models.Identity{Name: "lockout@example.com"}
+	assert.NilError(t, data.CreateIdentity(srv.DB(), user))
+	_, err := data.CreateProviderUser(srv.DB(), data.InfraProvider(srv.DB()), user)
+	assert.NilError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	assert.NilError(t, err)
+	assert.NilError(t, data.CreateCredential(srv.DB(), &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+
+	login := func(t *testing.T, name, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := jsonBody(t, api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: name, Password: password},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/login", body)
+		req.Header.Add("Infra-Version", apiVersionLatest)
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	for i := 0; i < 3; i++ {
+		resp := login(t, user.Name, "wrong-password")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	}
+
+	// the correct password is rejected while the user is locked out
+	resp := login(t, user.Name, "correct-password")
+	assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+	assert.Assert(t, resp.Header().Get("Retry-After") != "")
+
+	// other users can still login from the same address
+	resp = login(t, "other@example.com", "wrong-password")
+	assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+	t.Run("admin unlocks the user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/"+user.ID.String()+"/lockout", nil)
+		req.Header.Add("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Add("Infra-Version", apiVersionLatest)
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = login(t, user.Name, "correct-password")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("source address is locked out", func(t *testing.T) {
+		// one failure is left before the address reaches MaxFailuresPerIP
+		resp := login(t, "someone@example.com", "wrong-password")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		resp = login(t, user.Name, "correct-password")
+		assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	newRequest := func(remoteAddr string, forwarded ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+		req.RemoteAddr = remoteAddr
+		for _, value := range forwarded {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		return req
+	}
+
+	srv := &Server{}
+	var err error
+	srv.trustedProxies, err = parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.7"})
+	assert.NilError(t, err)
+
+	t.Run("remote address", func(t *testing.T) {
+		assert.Equal(t, srv.clientIP(newRequest("203.0.113.5:4040")), "203.0.113.5")
+	})
+	t.Run("forwarded by a trusted proxy", func(t *testing.T) {
+		req := newRequest("10.0.0.3:4040", "198.51.100.1, 203.0.113.5")
+		assert.Equal(t, srv.clientIP(req), "203.0.113.5")
+	})
+	t.Run("forwarded by a chain of trusted proxies", func(t *testing.T) {
+		req := newRequest("192.0.2.7:4040", "198.51.100.1, 203.0.113.5", "10.1.2.3")
+		assert.Equal(t, srv.clientIP(req), "203.0.113.5")
+	})
+	t.Run("forwarded header from an untrusted address is ignored", func(t *testing.T) {
+		req := newRequest("203.0.113.5:4040", "198.51.100.1")
+		assert.Equal(t, srv.clientIP(req), "203.0.113.5")
+	})
+	t.Run("private addresses are not trusted by default", func(t *testing.T) {
+		req := newRequest("127.0.0.1:4040", "198.51.100.1")
+		assert.Equal(t, (&Server{}).clientIP(req), "127.0.0.1")
+	})
+	t.Run("invalid forwarded address", func(t *testing.T) {
+		req := newRequest("10.0.0.3:4040", "198.51.100.1, not-an-ip")
+		assert.Equal(t, srv.clientIP(req), "10.0.0.3")
+	})
+	t.Run("invalid remote address", func(t *testing.T) {
+		assert.Equal(t, srv.clientIP(newRequest("pipe")), "")
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := parseTrustedProxies([]string{"10.1.2.3/8", " 192.0.2.7 ", "2001:db8::/32"})
+	assert.NilError(t, err)
+	var actual []string
+	for _, prefix := range prefixes {
+		actual = append(actual, prefix.String())
+	}
+	assert.DeepEqual(t, actual, []string{"10.0.0.0/8", "192.0.2.7/32", "2001:db8::/32"})
+
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err, `invalid trusted proxy "10.0.0.0/33", must be an IP address or CIDR range`)
+}
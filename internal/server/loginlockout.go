@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/redis"
+	"github.com/infrahq/infra/uid"
+)
+
+// loginFailureKeys returns the keys used to count failed password logins for
+// the request. The source IP address is always counted, and username is
+// counted when it is not empty.
+func (s *Server) loginFailureKeys(req *http.Request, username string) []data.LoginFailureKey {
+	var keys []data.LoginFailureKey
+	if username != "" {
+		keys = append(keys, data.LoginFailureKey{Kind: data.LoginFailureUser, Name: username})
+	}
+	if ip := s.clientIP(req); ip != "" {
+		keys = append(keys, data.LoginFailureKey{Kind: data.LoginFailureIP, Name: ip})
+	}
+	return keys
+}
+
+// checkLoginLockout returns a redis.OverLimitError if any of the keys are
+// locked out, so that the response is the same as the one from the Redis
+// limiter.
+func checkLoginLockout(tx data.ReadTxn, keys []data.LoginFailureKey) error {
+	lockedUntil, err := data.LoginLockedUntil(tx, keys...)
+	if err != nil {
+		return fmt.Errorf("check login lockout: %w", err)
+	}
+	if retryAfter := time.Until(lockedUntil); retryAfter > 0 {
+		return redis.OverLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login for each of the keys in its own
+// transaction, because the transaction for the request is rolled back when
+// the login fails. Errors are logged because the request has already failed.
+func recordLoginFailure(db *data.DB, orgID uid.ID, opts LoginOptions, keys []data.LoginFailureKey) {
+	tx, err := db.Begin(context.Background(), nil)
+	if err != nil {
+		logging.L.Warn().Err(err).Msg("failed to start login failure transaction")
+		return
+	}
+	defer logError(tx.Rollback, "failed to rollback login failure transaction")
+	tx = tx.WithOrgID(orgID)
+
+	for _, key := range keys {
+		lockoutOpts := data.LoginLockoutOptions{
+			MaxFailures: opts.MaxFailures,
+			Lockout:     opts.Lockout,
+			MaxLockout:  opts.MaxLockout,
+		}
+		if key.Kind == data.LoginFailureIP {
+			lockoutOpts.MaxFailures = opts.MaxFailuresPerIP
+		}
+
+		lockedUntil, err := data.RecordLoginFailure(tx, key, lockoutOpts)
+		if err != nil {
+			logging.L.Warn().Err(err).Msg("failed to record login failure")
+			return
+		}
+		if !lockedUntil.IsZero() {
+			logging.L.Info().
+				Str("kind", string(key.Kind)).
+				Str("name", key.Name).
+				Time("lockedUntil", lockedUntil).
+				Msg("login locked out after too many failures")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logging.L.Warn().Err(err).Msg("failed to commit login failure")
+	}
+}
+
+// clearLoginFailures removes the count of failed logins for the username
+// after a successful login. Failures from the source IP address continue to
+// count, so that a valid login can not be used to reset them.
+func clearLoginFailures(rCtx access.RequestContext, keys []data.LoginFailureKey) {
+	for _, key := range keys {
+		if key.Kind != data.LoginFailureUser {
+			continue
+		}
+		if err := data.DeleteLoginFailures(rCtx.DBTxn, key); err != nil {
+			logging.L.Warn().Err(err).Msg("failed to clear login failures")
+		}
+	}
+}
+
+// clientIP returns the IP address of the client. When the request comes from
+// one of the trusted proxies, like a load balancer or an ingress controller,
+// the address that proxy added to X-Forwarded-For is used instead. The
+// addresses are read from the end of X-Forwarded-For for as long as they are
+// trusted proxies, earlier addresses are set by the client and can not be
+// trusted. X-Forwarded-For is ignored when there are no trusted proxies.
+func (s *Server) clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return ""
+	}
+	ip = ip.Unmap()
+
+	var forwarded []string
+	for _, value := range req.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(value, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0 && s.isTrustedProxy(ip); i-- {
+		next, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		ip = next.Unmap()
+	}
+	return ip.String()
+}
+
+func (s *Server) isTrustedProxy(ip netip.Addr) bool {
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses the trusted proxies from the server options,
+// which are IP addresses or CIDR ranges.
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if ip, err := netip.ParseAddr(proxy); err == nil {
+			ip = ip.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q, must be an IP address or CIDR range", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/totp"
+	"github.com/infrahq/infra/uid"
+)
+
+func (a *API) CreateMFAEnrollment(rCtx access.RequestContext, r *api.CreateMFAEnrollmentRequest) (*api.MFAEnrollment, error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := access.CreateMFAEnrollment(rCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI("Infra", rCtx.Authenticated.User.Name, secret),
+	}, nil
+}
+
+func (a *API) VerifyMFAEnrollment(rCtx access.RequestContext, r *api.VerifyMFAEnrollmentRequest) (*api.MFARecoveryCodes, error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := access.VerifyMFAEnrollment(rCtx, userID, r.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &api.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (a *API) DeleteMFA(rCtx access.RequestContext, r *api.DeleteMFARequest) (*api.EmptyResponse, error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return nil, access.DeleteMFA(rCtx, userID, r.Code)
+}
+
+func userIDOrSelf(rCtx access.RequestContext, id api.IDOrSelf) (uid.ID, error) {
+	if !id.IsSelf {
+		return id.ID, nil
+	}
+	if rCtx.Authenticated.User == nil {
+		return 0, fmt.Errorf("no authenticated user")
+	}
+	return rCtx.Authenticated.User.ID, nil
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/totp"
+)
+
+func TestAPI_MFA(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	createPasswordUser := func(t *testing.T, name string) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(srv.DB(), user))
+
+		_, err := data.CreateProviderUser(srv.DB(), data.InfraProvider(srv.DB()), user)
+		assert.NilError(t, err)
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.DB(), &models.Credential{
+			IdentityID:   user.ID,
+			PasswordHash: hash,
+		}))
+		return user
+	}
+
+	call := func(t *testing.T, method, path, accessKey string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, jsonBody(t, body))
+		if accessKey != "" {
+			req.Header.Set("Authorization", "Bearer "+accessKey)
+		}
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func(t *testing.T, name, mfaCode string) (*httptest.ResponseRecorder, api.LoginResponse) {
+		t.Helper()
+		resp := call(t, http.MethodPost, "/api/login", "", api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{
+				Name:     name,
+				Password: "hunter2",
+				MFACode:  mfaCode,
+			},
+		})
+		var loginResp api.LoginResponse
+		if resp.Code == http.StatusCreated {
+			assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+		}
+		return resp, loginResp
+	}
+
+	user := createPasswordUser(t, "mfa@example.com")
+	_, loginResp := login(t, user.Name, "")
+	userKey := loginResp.AccessKey
+	assert.Assert(t, userKey != "")
+
+	var secret string
+	var recoveryCodes []string
+
+	t.Run("enroll", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/users/self/mfa", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var enrollment api.MFAEnrollment
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &enrollment))
+		assert.Assert(t, enrollment.Secret != "")
+		assert.Equal(t, enrollment.URI, totp.URI("Infra", user.Name, enrollment.Secret))
+		secret = enrollment.Secret
+
+		// not enabled until verified
+		_, loginResp := login(t, user.Name, "")
+		assert.Assert(t, !loginResp.MFARequired)
+
+		resp = call(t, http.MethodPost, "/api/users/self/mfa/verify", userKey, api.VerifyMFAEnrollmentRequest{Code: "000000"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		code, err := totp.Code(secret, totp.Step(time.Now()))
+		assert.NilError(t, err)
+		resp = call(t, http.MethodPost, "/api/users/self/mfa/verify", userKey, api.VerifyMFAEnrollmentRequest{Code: code})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var codes api.MFARecoveryCodes
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &codes))
+		assert.Equal(t, len(codes.RecoveryCodes), 10)
+		recoveryCodes = codes.RecoveryCodes
+	})
+
+	t.Run("login requires a code", func(t *testing.T) {
+		resp, loginResp := login(t, user.Name, "")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Assert(t, loginResp.MFARequired)
+		assert.Equal(t, loginResp.AccessKey, "")
+		assert.Equal(t, len(resp.Result().Cookies()), 0)
+	})
+
+	t.Run("login with invalid code", func(t *testing.T) {
+		resp, _ := login(t, user.Name, "000000")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("login with totp code", func(t *testing.T) {
+		// the code for the current step was used to verify enrollment
+		code, err := totp.Code(secret, totp.Step(time.Now())+1)
+		assert.NilError(t, err)
+
+		resp, loginResp := login(t, user.Name, code)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Equal(t, loginResp.UserID, user.ID)
+		assert.Assert(t, loginResp.AccessKey != "")
+
+		// a code can only be used once
+		resp, _ = login(t, user.Name, code)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("login with recovery code", func(t *testing.T) {
+		resp, loginResp := login(t, user.Name, recoveryCodes[0])
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Assert(t, loginResp.AccessKey != "")
+
+		resp, _ = login(t, user.Name, recoveryCodes[0])
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("enroll again while enabled", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/users/self/mfa", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("enroll other user", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/users/"+user.ID.String()+"/mfa", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("user removes mfa", func(t *testing.T) {
+		resp := call(t, http.MethodDelete, "/api/users/self/mfa", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = call(t, http.MethodDelete, "/api/users/self/mfa?code=000000", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		_, loginResp := login(t, user.Name, "")
+		assert.Assert(t, loginResp.MFARequired)
+
+		resp = call(t, http.MethodDelete, "/api/users/self/mfa?code="+recoveryCodes[1], userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		_, loginResp = login(t, user.Name, "")
+		assert.Assert(t, !loginResp.MFARequired)
+	})
+
+	t.Run("admin removes mfa", func(t *testing.T) {
+		resp := call(t, http.MethodPost, "/api/users/self/mfa", userKey, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		var enrollment api.MFAEnrollment
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &enrollment))
+
+		code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+		assert.NilError(t, err)
+		resp = call(t, http.MethodPost, "/api/users/self/mfa/verify", userKey, api.VerifyMFAEnrollmentRequest{Code: code})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		// a code is not required when an admin removes mfa for another user
+		resp = call(t, http.MethodDelete, "/api/users/"+user.ID.String()+"/mfa", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		_, loginResp := login(t, user.Name, "")
+		assert.Assert(t, !loginResp.MFARequired)
+		assert.Assert(t, loginResp.AccessKey != "")
+	})
+
+	t.Run("organization requires mfa", func(t *testing.T) {
+		org := srv.db.DefaultOrg
+		requireMFA := true
+		resp := call(t, http.MethodPut, "/api/organizations/"+org.ID.String(), adminAccessKey(srv), api.UpdateOrganizationRequest{
+			AllowedDomains: []string{"example.com"},
+			RequireMFA:     &requireMFA,
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		other := createPasswordUser(t, "required@example.com")
+		resp, loginResp := login(t, other.Name, "")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Assert(t, loginResp.MFAEnrollmentRequired)
+		key := loginResp.AccessKey
+
+		// the key can only be used to enroll
+		resp = call(t, http.MethodGet, "/api/users/self", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = call(t, http.MethodPost, "/api/users/self/mfa", key, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		var enrollment api.MFAEnrollment
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &enrollment))
+
+		code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+		assert.NilError(t, err)
+		resp = call(t, http.MethodPost, "/api/users/self/mfa/verify", key, api.VerifyMFAEnrollmentRequest{Code: code})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = call(t, http.MethodGet, "/api/users/self", key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+}
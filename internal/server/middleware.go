@@ -11,12 +11,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/logging"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
-	"github.com/infrahq/infra/internal/server/redis"
+	"github.com/infrahq/infra/uid"
 )
 
 func handleInfraDestinationHeader(tx *data.Transaction, authned access.Authenticated, headers http.Header) error {
@@ -68,7 +69,8 @@ const (
 // organization from the domain name when no access key is provided.
 //
 // If the request identifies an organization (which is required for most routes)
-// a rate limit will be applied to all requests from the same organization.
+// a rate limit will be applied to all requests from the same organization, and
+// requests with an access key are also limited by the quota for each key.
 func authenticateRequest(c *gin.Context, route routeSettings, srv *Server) (access.Authenticated, error) {
 	tx, err := srv.db.Begin(c.Request.Context(), nil)
 	if err != nil {
@@ -102,8 +104,13 @@ func authenticateRequest(c *gin.Context, route routeSettings, srv *Server) (acce
 	}
 
 	if org != nil {
-		// TODO: limit should be a per-organization setting
-		if err := redis.NewLimiter(srv.redis).RateOK(org.ID.String(), 5000); err != nil {
+		if err := srv.limiter.RateOK(org.ID.String(), srv.options.API.OrganizationQuota); err != nil {
+			return authned, err
+		}
+	}
+	if authned.AccessKey != nil {
+		key := "key:" + authned.AccessKey.ID.String()
+		if err := srv.limiter.RateOK(key, srv.options.API.AccessKeyQuota); err != nil {
 			return authned, err
 		}
 	}
@@ -184,7 +191,7 @@ func requireAccessKey(c *gin.Context, db data.WriteTxn, srv *Server) (access.Aut
 		return u, err
 	}
 
-	accessKey, err := data.ValidateRequestAccessKey(db, bearer)
+	accessKey, err := data.ValidateRequestAccessKey(db, bearer, srv.accessKeyClient(c.Request))
 	if err != nil {
 		if errors.Is(err, data.ErrAccessInactivityTimeout) {
 			return u, AuthenticationError{Message: "access key has expired due to inactivity"}
@@ -202,6 +209,13 @@ func requireAccessKey(c *gin.Context, db data.WriteTxn, srv *Server) (access.Aut
 		}
 	}
 
+	if accessKey.Scopes.Includes(models.ScopeMFAEnrollment) {
+		// POST /api/users/:id/mfa and /api/users/:id/mfa/verify only
+		if !isMFAEnrollmentRequest(c.Request, accessKey.IssuedForID) {
+			return u, fmt.Errorf("%w: multi-factor authentication enrollment is required by your organization", access.ErrNotAuthorized)
+		}
+	}
+
 	org, err := data.GetOrganization(db, data.GetOrganizationOptions{ByID: accessKey.OrganizationID})
 	if err != nil {
 		return u, fmt.Errorf("access key org lookup: %w", err)
@@ -237,6 +251,72 @@ func requireAccessKey(c *gin.Context, db data.WriteTxn, srv *Server) (access.Aut
 	return u, nil
 }
 
+// requireAccessKeyScope checks that one of the scopes of the access key
+// allows the route, when the key is limited to the routes for some resources.
+func requireAccessKeyScope(c *gin.Context, routeID routeIdentifier, authned access.Authenticated) error {
+	if authned.AccessKey == nil {
+		return nil
+	}
+	scopes := authned.AccessKey.ResourceScopes()
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	resource, action, ok := scopeForRoute(routeID)
+	if !ok {
+		return fmt.Errorf("%w: access key scopes do not allow %v %v", access.ErrNotAuthorized, routeID.method, routeID.path)
+	}
+
+	var id uid.ID
+	switch param := c.Param("id"); {
+	case param == "self" && authned.User != nil:
+		id = authned.User.ID
+	case param != "":
+		id, _ = uid.Parse([]byte(param))
+	}
+
+	for _, scope := range scopes {
+		if scope.Allows(resource, action, id) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: access key is missing a scope for %v:%v", access.ErrNotAuthorized, resource, action)
+}
+
+// scopeForRoute returns the resource and action that an access key scope must
+// allow to use the route. GET routes need the read action, and all others
+// need write. ok is false for routes that are not for a resource, which can
+// not be used by a key with resource scopes.
+func scopeForRoute(routeID routeIdentifier) (resource, action string, ok bool) {
+	if !strings.HasPrefix(routeID.path, "/api/") {
+		return "", "", false
+	}
+	resource, _, _ = strings.Cut(strings.TrimPrefix(routeID.path, "/api/"), "/")
+	for _, r := range api.AccessKeyScopeResources {
+		if r != resource {
+			continue
+		}
+		if routeID.method == http.MethodGet {
+			return resource, api.AccessKeyScopeRead, true
+		}
+		return resource, api.AccessKeyScopeWrite, true
+	}
+	return "", "", false
+}
+
+func isMFAEnrollmentRequest(req *http.Request, userID uid.ID) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	for _, id := range []string{"self", userID.String()} {
+		switch req.URL.Path {
+		case "/api/users/" + id + "/mfa", "/api/users/" + id + "/mfa/verify":
+			return true
+		}
+	}
+	return false
+}
+
 func getCookie(req *http.Request, name string) (string, error) {
 	cookie, err := req.Cookie(name)
 	if err != nil {
@@ -631,6 +631,194 @@ func TestAuthenticateRequest(t *testing.T) {
 	}
 }
 
+func TestRequireAccessKeyScope(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	admin := createAdmin(t, srv.db)
+	group := &models.Group{Name: "ci"}
+	otherGroup := &models.Group{Name: "other"}
+	createGroups(t, srv.db, group, otherGroup)
+
+	key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedForID:   admin.ID,
+		IssuedForKind: models.IssuedForKindUser,
+		ProviderID:    data.InfraProvider(srv.db).ID,
+		ExpiresAt:     time.Now().Add(time.Minute),
+		Scopes:        []string{"grants:read", "groups:write:" + group.ID.String()},
+	})
+	assert.NilError(t, err)
+
+	type testCase struct {
+		name     string
+		method   string
+		path     string
+		body     any
+		expected int
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		var body io.Reader
+		if tc.body != nil {
+			body = jsonBody(t, tc.body)
+		}
+		req := httptest.NewRequest(tc.method, tc.path, body)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, tc.expected, resp.Body.String())
+	}
+
+	testCases := []testCase{
+		{
+			name:     "read allowed by scope",
+			method:   http.MethodGet,
+			path:     "/api/grants",
+			expected: http.StatusOK,
+		},
+		{
+			name:     "write not allowed by read scope",
+			method:   http.MethodPost,
+			path:     "/api/grants",
+			body:     api.GrantRequest{User: admin.ID, Privilege: "view", Resource: "infra"},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "resource without a scope",
+			method:   http.MethodGet,
+			path:     "/api/users",
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "write allowed for the group in the scope",
+			method:   http.MethodPatch,
+			path:     "/api/groups/" + group.ID.String() + "/users",
+			body:     api.UpdateUsersInGroupRequest{UserIDsToAdd: []uid.ID{admin.ID}},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "read allowed by write scope",
+			method:   http.MethodGet,
+			path:     "/api/groups/" + group.ID.String(),
+			expected: http.StatusOK,
+		},
+		{
+			name:     "write not allowed for other groups",
+			method:   http.MethodPatch,
+			path:     "/api/groups/" + otherGroup.ID.String() + "/users",
+			body:     api.UpdateUsersInGroupRequest{UserIDsToAdd: []uid.ID{admin.ID}},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "route that is not for a resource",
+			method:   http.MethodPost,
+			path:     "/api/logout",
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
+
+func TestAuthenticateRequest_Quotas(t *testing.T) {
+	srv := setupServer(t, withAdminUser, func(_ *testing.T, opts *Options) {
+		opts.API.OrganizationQuota = 4
+		opts.API.AccessKeyQuota = 2
+	})
+	routes := srv.GenerateRoutes()
+
+	admin := createAdmin(t, srv.db)
+	createKey := func(t *testing.T) string {
+		t.Helper()
+		key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedForID:   admin.ID,
+			IssuedForKind: models.IssuedForKindUser,
+			ProviderID:    data.InfraProvider(srv.db).ID,
+			ExpiresAt:     time.Now().Add(time.Minute),
+		})
+		assert.NilError(t, err)
+		return key
+	}
+
+	get := func(t *testing.T, key string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/users/self", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := createKey(t)
+	for i := 0; i < 2; i++ {
+		resp := get(t, first)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	}
+
+	// the key is over its quota
+	resp := get(t, first)
+	assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+	assert.Assert(t, resp.Header().Get("Retry-After") != "")
+
+	// other keys have their own quota, until the organization is over its quota
+	second := createKey(t)
+	resp = get(t, second)
+	assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	resp = get(t, second)
+	assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+}
+
+func TestScopeForRoute(t *testing.T) {
+	type testCase struct {
+		routeID          routeIdentifier
+		expectedResource string
+		expectedAction   string
+		expectedOK       bool
+	}
+	testCases := []testCase{
+		{
+			routeID:          routeIdentifier{method: http.MethodGet, path: "/api/grants"},
+			expectedResource: "grants",
+			expectedAction:   "read",
+			expectedOK:       true,
+		},
+		{
+			routeID:          routeIdentifier{method: http.MethodPatch, path: "/api/groups/:id/users"},
+			expectedResource: "groups",
+			expectedAction:   "write",
+			expectedOK:       true,
+		},
+		{
+			routeID:          routeIdentifier{method: http.MethodDelete, path: "/api/destinations/:id"},
+			expectedResource: "destinations",
+			expectedAction:   "write",
+			expectedOK:       true,
+		},
+		{
+			routeID: routeIdentifier{method: http.MethodPost, path: "/api/logout"},
+		},
+		{
+			routeID: routeIdentifier{method: http.MethodGet, path: "/.well-known/jwks.json"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.routeID.method+" "+tc.routeID.path, func(t *testing.T) {
+			resource, action, ok := scopeForRoute(tc.routeID)
+			assert.Equal(t, resource, tc.expectedResource)
+			assert.Equal(t, action, tc.expectedAction)
+			assert.Equal(t, ok, tc.expectedOK)
+		})
+	}
+}
+
 func TestValidateRequestOrganization(t *testing.T) {
 	srv := setupServer(t, withAdminUser)
 	srv.options.EnableSignup = true // multi-tenant environment
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/infrahq/infra/api"
@@ -15,6 +16,7 @@ var (
 const (
 	ScopePasswordReset        string = "password-reset"
 	ScopeAllowCreateAccessKey string = "create-key"
+	ScopeMFAEnrollment        string = "mfa-enroll"
 )
 
 // AccessKey is a session token presented to the Infra server as proof of authentication
@@ -36,6 +38,13 @@ type AccessKey struct {
 	SecretChecksum []byte
 
 	Scopes CommaSeparatedStrings // if set, scopes limit what the key can be used for
+
+	// LoginMethod is the name of the login method that issued the key. It is
+	// only set for keys issued by a login, which are the sessions of a user.
+	LoginMethod string
+	// ClientIP and UserAgent are from the last request that used the key.
+	ClientIP  string
+	UserAgent string
 }
 
 func (ak *AccessKey) ToAPI() *api.AccessKey {
@@ -88,6 +97,24 @@ const (
 	IssuedForKindOrganization IssuedForKind = 3
 )
 
+// ResourceScopes returns the scopes that limit the key to the routes for some
+// resources. A key without any resource scopes can be used for any route.
+func (ak *AccessKey) ResourceScopes() []api.AccessKeyScope {
+	var scopes []api.AccessKeyScope
+	for _, raw := range ak.Scopes {
+		if !strings.Contains(raw, ":") {
+			continue // not a resource scope, like create-key
+		}
+		scope, err := api.ParseAccessKeyScope(raw)
+		if err != nil {
+			// keep the key limited, with a scope that allows nothing
+			scope = api.AccessKeyScope{}
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
 // Token is only set when creating a key from CreateAccessKey
 func (ak *AccessKey) Token() string {
 	if len(ak.Secret) == 0 {
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+type AccessRequestStatus string
+
+const (
+	AccessRequestStatusPending  AccessRequestStatus = "pending"
+	AccessRequestStatusApproved AccessRequestStatus = "approved"
+	AccessRequestStatusDenied   AccessRequestStatus = "denied"
+)
+
+// AccessRequest is a request from a user to be granted a privilege on a
+// resource. An admin approves or denies the request. Approving the request
+// creates a Grant.
+type AccessRequest struct {
+	Model
+	OrganizationMember
+
+	// UserID is the ID of the user who requested access.
+	UserID uid.ID
+	// Privilege is the role or permission being requested.
+	Privilege string
+	// Resource identifies the resource the privilege applies to.
+	Resource string
+	// Justification is the reason the user gave for needing access.
+	Justification string
+	// Duration is how long the access should last once it is approved. A zero
+	// value means the grant created on approval does not expire.
+	Duration time.Duration
+
+	Status AccessRequestStatus
+	// ReviewedBy is the ID of the user who approved or denied the request.
+	ReviewedBy uid.ID
+	ReviewedAt time.Time
+	// GrantID is the ID of the grant created when the request was approved.
+	GrantID uid.ID
+}
+
+func (r *AccessRequest) ToAPI() *api.AccessRequest {
+	return &api.AccessRequest{
+		ID:            r.ID,
+		Created:       api.Time(r.CreatedAt),
+		Updated:       api.Time(r.UpdatedAt),
+		UserID:        r.UserID,
+		Privilege:     r.Privilege,
+		Resource:      r.Resource,
+		Justification: r.Justification,
+		Duration:      api.Duration(r.Duration),
+		Status:        string(r.Status),
+		ReviewedBy:    r.ReviewedBy,
+		Reviewed:      api.Time(r.ReviewedAt),
+		GrantID:       r.GrantID,
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+type AuditEventOutcome string
+
+const (
+	AuditEventOutcomeSuccess AuditEventOutcome = "success"
+	AuditEventOutcomeFailure AuditEventOutcome = "failure"
+)
+
+// AuditEvent records a single API call that attempted to modify the state of
+// an organization.
+type AuditEvent struct {
+	Model
+	OrganizationMember
+
+	// ActorID is the ID of the identity that made the API call.
+	ActorID   uid.ID
+	ActorName string
+	Method    string
+	// Route is the path template of the API route, ex: /api/grants/:id
+	Route string
+	// TargetID is the ID of the resource the API call acted on, if the route
+	// acts on a single resource.
+	TargetID uid.ID
+	// Request is a JSON summary of the request, with any sensitive fields
+	// redacted.
+	Request    string
+	Outcome    AuditEventOutcome
+	StatusCode int
+}
+
+func (e *AuditEvent) ToAPI() *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:         e.ID,
+		Created:    api.Time(e.CreatedAt),
+		ActorID:    e.ActorID,
+		ActorName:  e.ActorName,
+		Method:     e.Method,
+		Route:      e.Route,
+		TargetID:   e.TargetID,
+		Request:    e.Request,
+		Outcome:    string(e.Outcome),
+		StatusCode: e.StatusCode,
+	}
+}
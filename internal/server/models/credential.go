@@ -1,6 +1,16 @@
 package models
 
-import "github.com/infrahq/infra/uid"
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/infrahq/infra/internal/totp"
+	"github.com/infrahq/infra/uid"
+)
 
 type Credential struct {
 	Model
@@ -9,4 +19,55 @@ type Credential struct {
 	IdentityID      uid.ID
 	PasswordHash    []byte
 	OneTimePassword bool
+	// PasswordUpdatedAt is the time the password was last set, used to expire
+	// passwords with a password policy.
+	PasswordUpdatedAt time.Time
+	// PasswordHashHistory are the hashes of the most recent passwords chosen
+	// by the user, most recent first. They are kept to prevent the reuse of
+	// passwords with a password policy. One-time passwords are not included.
+	PasswordHashHistory CommaSeparatedStrings
+
+	// MFASecret is the TOTP secret. It is set when enrollment starts, and
+	// MFAEnabled is set once the user has confirmed a code from the secret.
+	MFASecret  EncryptedAtRest
+	MFAEnabled bool
+	// MFALastStep is the TOTP time step of the last accepted code, used to
+	// prevent a code from being used more than once.
+	MFALastStep int64
+	// MFARecoveryCodes are the checksums of the unused recovery codes. See
+	// RecoveryCodeChecksum.
+	MFARecoveryCodes CommaSeparatedStrings
+}
+
+// RecoveryCodeChecksum returns the checksum of an MFA recovery code, as it is
+// stored in Credential.MFARecoveryCodes. Recovery codes are case-insensitive,
+// and any dashes or spaces are ignored.
+func RecoveryCodeChecksum(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseMFACode checks code against the TOTP secret and the unused recovery codes
+// of the credential. A TOTP code can only be used once, and a recovery code is
+// removed once it is used, so the caller must save the credential when
+// UseMFACode returns nil.
+func (c *Credential) UseMFACode(code string, now time.Time) error {
+	if step, ok := totp.Validate(string(c.MFASecret), code, now); ok {
+		if step <= c.MFALastStep {
+			return errors.New("mfa code has already been used")
+		}
+		c.MFALastStep = step
+		return nil
+	}
+
+	checksum := RecoveryCodeChecksum(code)
+	for i, stored := range c.MFARecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(checksum)) == 1 {
+			c.MFARecoveryCodes = append(c.MFARecoveryCodes[:i], c.MFARecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("invalid mfa code")
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/uid"
 )
@@ -10,8 +12,19 @@ const (
 	InfraAdminRole        = "admin"
 	InfraViewRole         = "view"
 	InfraConnectorRole    = "connector"
+
+	// GrantAdminRole allows a user to manage grants to the destinations that
+	// match the resource of the grant (ex: prod-*, or prod.monitoring).
+	GrantAdminRole = "grant-admin"
+	// GroupOwnerRole allows a user to manage the members of the group named by
+	// the resource of the grant (ex: group:Engineering).
+	GroupOwnerRole = "group-owner"
 )
 
+// GroupResourcePrefix is the prefix of a grant resource that names a group
+// instead of a destination. It is used by grants with the GroupOwnerRole.
+const GroupResourcePrefix = "group:"
+
 // BasePermissionConnect is the first-principle permission that all other permissions are defined from.
 // This permission gives you permission to authenticate with a destination
 const BasePermissionConnect = "connect"
@@ -29,6 +42,10 @@ type Grant struct {
 	Resource    string
 	CreatedBy   uid.ID
 	UpdateIndex int64 `db:"-"`
+
+	// ExpiresAt is the time when the grant stops being valid. A zero value
+	// means the grant does not expire.
+	ExpiresAt time.Time
 }
 
 type Subject struct {
@@ -62,6 +79,7 @@ func (r *Grant) ToAPI() *api.Grant {
 		CreatedBy: r.CreatedBy,
 		Privilege: r.Privilege,
 		Resource:  r.Resource,
+		Expires:   api.Time(r.ExpiresAt),
 	}
 
 	switch r.Subject.Kind {
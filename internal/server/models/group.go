@@ -12,6 +12,9 @@ type Group struct {
 	Name              string
 	CreatedBy         uid.ID
 	CreatedByProvider uid.ID
+	// ProvisionedBySCIM is true when the group was created by the identity
+	// provider with SCIM. The provider manages the members of these groups.
+	ProvisionedBySCIM bool
 
 	TotalUsers int `db:"-"`
 }
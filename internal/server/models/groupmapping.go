@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/infrahq/infra/api"
+)
+
+// GroupMapping are the rules that change the groups from an identity provider
+// before users are added to them.
+type GroupMapping struct {
+	// Include is a regular expression. When set, only the groups that match
+	// it are kept.
+	Include string `json:"include,omitempty"`
+	// Exclude is a regular expression. Groups that match it are removed.
+	Exclude string `json:"exclude,omitempty"`
+	// Rename rules are checked in order, and the first rule that matches a
+	// group renames it.
+	Rename []GroupRenameRule `json:"rename,omitempty"`
+	// PrefixProviderName adds the name of the provider and a slash to the
+	// start of each group name.
+	PrefixProviderName bool `json:"prefixProviderName,omitempty"`
+}
+
+// GroupRenameRule replaces the part of a group name that matches the regular
+// expression Match with Replace. Replace can refer to submatches of Match,
+// like $1 or ${name}.
+type GroupRenameRule struct {
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+}
+
+func (m GroupMapping) IsZero() bool {
+	return m.Include == "" && m.Exclude == "" && len(m.Rename) == 0 && !m.PrefixProviderName
+}
+
+// Apply returns the names of groups after applying the mapping rules of the
+// provider named providerName. The groups that are not included, are excluded,
+// or are renamed to an empty name are removed.
+func (m GroupMapping) Apply(providerName string, groups []string) ([]string, error) {
+	if m.IsZero() {
+		return groups, nil
+	}
+
+	include, err := compileOptional(m.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	exclude, err := compileOptional(m.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+	rename := make([]*regexp.Regexp, len(m.Rename))
+	for i, rule := range m.Rename {
+		if rename[i], err = regexp.Compile(rule.Match); err != nil {
+			return nil, fmt.Errorf("rename: %w", err)
+		}
+	}
+
+	result := make([]string, 0, len(groups))
+	for _, name := range groups {
+		if include != nil && !include.MatchString(name) {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(name) {
+			continue
+		}
+		for i, re := range rename {
+			if re.MatchString(name) {
+				name = re.ReplaceAllString(name, m.Rename[i].Replace)
+				break
+			}
+		}
+		if name == "" {
+			continue
+		}
+		if m.PrefixProviderName {
+			name = providerName + "/" + name
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+func (m GroupMapping) Value() (driver.Value, error) {
+	marshalled, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("convert group mapping to json: %w", err)
+	}
+	return string(marshalled), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *GroupMapping) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	s, ok := src.([]uint8)
+	if !ok {
+		return fmt.Errorf("cannot scan values which are not a byte array to a group mapping")
+	}
+	return json.Unmarshal(s, m)
+}
+
+func (m GroupMapping) ToAPI() *api.ProviderGroupMapping {
+	if m.IsZero() {
+		return nil
+	}
+	rename := make([]api.GroupRenameRule, 0, len(m.Rename))
+	for _, rule := range m.Rename {
+		rename = append(rename, api.GroupRenameRule{Match: rule.Match, Replace: rule.Replace})
+	}
+	return &api.ProviderGroupMapping{
+		Include:            m.Include,
+		Exclude:            m.Exclude,
+		Rename:             rename,
+		PrefixProviderName: m.PrefixProviderName,
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGroupMapping_Apply(t *testing.T) {
+	type testCase struct {
+		name     string
+		mapping  GroupMapping
+		groups   []string
+		expected []string
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		actual, err := tc.mapping.Apply("okta", tc.groups)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual, tc.expected)
+	}
+
+	groups := []string{"AAD-Eng-Platform-RW", "AAD-Eng-Web-RO", "AAD-Admins", "Everyone"}
+	testCases := []testCase{
+		{
+			name:     "no rules",
+			groups:   groups,
+			expected: groups,
+		},
+		{
+			name:     "include",
+			mapping:  GroupMapping{Include: "^AAD-Eng-"},
+			groups:   groups,
+			expected: []string{"AAD-Eng-Platform-RW", "AAD-Eng-Web-RO"},
+		},
+		{
+			name:     "exclude",
+			mapping:  GroupMapping{Exclude: "Admins$|^Everyone$"},
+			groups:   groups,
+			expected: []string{"AAD-Eng-Platform-RW", "AAD-Eng-Web-RO"},
+		},
+		{
+			name: "rename with the first rule that matches",
+			mapping: GroupMapping{
+				Include: "^AAD-",
+				Rename: []GroupRenameRule{
+					{Match: "^AAD-Eng-(.+)-(RW|RO)$", Replace: "${1}-$2"},
+					{Match: "^AAD-", Replace: ""},
+					{Match: "Platform", Replace: "never"},
+				},
+			},
+			groups:   groups,
+			expected: []string{"Platform-RW", "Web-RO", "Admins"},
+		},
+		{
+			name: "renamed to an empty name",
+			mapping: GroupMapping{
+				Rename: []GroupRenameRule{{Match: "^Everyone$", Replace: ""}},
+			},
+			groups:   []string{"Everyone", "Eng"},
+			expected: []string{"Eng"},
+		},
+		{
+			name: "prefix with the provider name",
+			mapping: GroupMapping{
+				Exclude:            "^AAD-",
+				PrefixProviderName: true,
+			},
+			groups:   groups,
+			expected: []string{"okta/Everyone"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+
+	t.Run("invalid regular expression", func(t *testing.T) {
+		_, err := GroupMapping{Include: "("}.Apply("okta", groups)
+		assert.ErrorContains(t, err, "include: error parsing regexp")
+	})
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/uid"
 )
@@ -14,9 +16,50 @@ type Organization struct {
 
 	CreatedBy uid.ID
 
+	// PrivateJWK and PublicJWK are the first signing key of the organization.
+	// Tokens are signed with the active SigningKey, which replaces this key
+	// when it is rotated.
 	PrivateJWK EncryptedAtRest
 	PublicJWK  []byte
 	InstallID  uid.ID
+
+	// RequireMFA requires users who login with an Infra password to enroll
+	// in multi-factor authentication.
+	RequireMFA bool
+
+	PasswordPolicy
+}
+
+// DefaultPasswordMinLength is the minimum length of a password. A password
+// policy can require longer passwords, but not shorter ones.
+const DefaultPasswordMinLength = 8
+
+// PasswordPolicy is the policy for the passwords of users who login with an
+// Infra password. The zero value only requires the default minimum length.
+type PasswordPolicy struct {
+	PasswordMinLength    int
+	PasswordLowercaseMin int
+	PasswordUppercaseMin int
+	PasswordNumberMin    int
+	PasswordSymbolMin    int
+	// PasswordMaxAge is how long a password can be used before the user must
+	// change it. Zero means passwords do not expire.
+	PasswordMaxAge time.Duration
+	// PasswordHistory is the number of previous passwords of a user that can
+	// not be used again.
+	PasswordHistory int
+}
+
+func (p PasswordPolicy) ToAPI() api.PasswordPolicy {
+	return api.PasswordPolicy{
+		MinLength:    p.PasswordMinLength,
+		LowercaseMin: p.PasswordLowercaseMin,
+		UppercaseMin: p.PasswordUppercaseMin,
+		NumberMin:    p.PasswordNumberMin,
+		SymbolMin:    p.PasswordSymbolMin,
+		MaxAge:       api.Duration(p.PasswordMaxAge),
+		HistoryCount: p.PasswordHistory,
+	}
 }
 
 func (o *Organization) ToAPI() *api.Organization {
@@ -27,6 +70,8 @@ func (o *Organization) ToAPI() *api.Organization {
 		Updated:        api.Time(o.UpdatedAt),
 		Domain:         o.Domain,
 		AllowedDomains: o.AllowedDomains,
+		RequireMFA:     o.RequireMFA,
+		PasswordPolicy: o.PasswordPolicy.ToAPI(),
 	}
 }
 
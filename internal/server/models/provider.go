@@ -19,12 +19,36 @@ const (
 	ProviderKindOkta   ProviderKind = "okta"
 	ProviderKindAzure  ProviderKind = "azure"
 	ProviderKindGoogle ProviderKind = "google"
+	ProviderKindSAML   ProviderKind = "saml"
+	ProviderKindLDAP   ProviderKind = "ldap"
+	ProviderKindGitHub ProviderKind = "github"
 )
 
 func (p ProviderKind) String() string {
 	return string(p)
 }
 
+// DirectorySyncProviderKinds are the kinds of providers that use an OIDC
+// client, and so can have their users synced in the background.
+var DirectorySyncProviderKinds = []ProviderKind{
+	ProviderKindOIDC,
+	ProviderKindOkta,
+	ProviderKindAzure,
+	ProviderKindGoogle,
+	ProviderKindGitHub,
+}
+
+// SupportsDirectorySync returns true if the users of providers of this kind
+// can be synced in the background.
+func (p ProviderKind) SupportsDirectorySync() bool {
+	for _, kind := range DirectorySyncProviderKinds {
+		if p == kind {
+			return true
+		}
+	}
+	return false
+}
+
 var providerKindMap = map[string]ProviderKind{
 	"":                          ProviderKindOIDC, // set empty provider kind to OIDC
 	ProviderKindInfra.String():  ProviderKindInfra,
@@ -32,6 +56,9 @@ var providerKindMap = map[string]ProviderKind{
 	ProviderKindOkta.String():   ProviderKindOkta,
 	ProviderKindAzure.String():  ProviderKindAzure,
 	ProviderKindGoogle.String(): ProviderKindGoogle,
+	ProviderKindSAML.String():   ProviderKindSAML,
+	ProviderKindLDAP.String():   ProviderKindLDAP,
+	ProviderKindGitHub.String(): ProviderKindGitHub,
 }
 
 // ParseProviderKind validates that a string is valid kind then returns the ProviderKind
@@ -56,11 +83,33 @@ type Provider struct {
 	Kind         ProviderKind
 	AuthURL      string
 	Scopes       CommaSeparatedStrings
+	Domains      CommaSeparatedStrings // the email domains of the users who login with this provider
 
 	// fields used to directly query an external API
 	PrivateKey       EncryptedAtRest
 	ClientEmail      string
 	DomainAdminEmail string
+
+	// fields used by SAML providers
+	SAMLMetadata        string // the metadata document of the identity provider
+	SAMLGroupsAttribute string
+
+	// fields used by LDAP providers, the URL of the LDAP server is stored in URL
+	LDAPBindDN        string // the service account used to search for users and groups
+	LDAPBindPassword  EncryptedAtRest
+	LDAPBaseDN        string
+	LDAPUserFilter    string
+	LDAPGroupBaseDN   string
+	LDAPStartTLS      bool
+	LDAPCACertificate string
+
+	// fields used by GitHub providers, the URL is github.com or the URL of a
+	// GitHub Enterprise Server
+	GitHubOrganizations CommaSeparatedStrings // only members of these organizations can login
+
+	// GroupMapping changes the groups from the provider before users are
+	// added to them
+	GroupMapping GroupMapping
 }
 
 func (p *Provider) ToAPI() *api.Provider {
@@ -75,5 +124,6 @@ func (p *Provider) ToAPI() *api.Provider {
 		Kind:     p.Kind.String(),
 		AuthURL:  p.AuthURL,
 		Scopes:   p.Scopes,
+		Domains:  p.Domains,
 	}
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+// ProviderSyncStatus is the status of the last directory sync of a provider.
+type ProviderSyncStatus string
+
+const (
+	ProviderSyncStatusRunning   ProviderSyncStatus = "running"
+	ProviderSyncStatusSucceeded ProviderSyncStatus = "succeeded"
+	// ProviderSyncStatusFailed is the status of a sync that could not sync
+	// some of the users of the provider.
+	ProviderSyncStatusFailed ProviderSyncStatus = "failed"
+)
+
+// ProviderSync is the last run of the directory sync of a provider. A
+// directory sync updates the groups of every user of the provider, and
+// deactivates the users that were removed from the provider.
+type ProviderSync struct {
+	OrganizationMember
+
+	ProviderID uid.ID
+	Status     ProviderSyncStatus
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	UsersSynced      int
+	UsersDeactivated int
+	UsersFailed      int
+	// Error is the last error from a user that failed to sync.
+	Error string
+}
+
+func (s *ProviderSync) ToAPI() *api.ProviderSync {
+	return &api.ProviderSync{
+		ProviderID:       s.ProviderID,
+		Status:           string(s.Status),
+		StartedAt:        api.Time(s.StartedAt),
+		FinishedAt:       api.Time(s.FinishedAt),
+		UsersSynced:      s.UsersSynced,
+		UsersDeactivated: s.UsersDeactivated,
+		UsersFailed:      s.UsersFailed,
+		Error:            s.Error,
+	}
+}
@@ -25,10 +25,17 @@ type ProviderUser struct {
 	ExpiresAt    time.Time
 
 	Active bool
+
+	// Department, EmployeeNumber, and Manager are attributes of the SCIM
+	// enterprise user extension. Manager is the ID of the manager in the
+	// identity provider.
+	Department     string
+	EmployeeNumber string
+	Manager        string
 }
 
 func (pu *ProviderUser) ToAPI() *api.SCIMUser {
-	return &api.SCIMUser{
+	result := &api.SCIMUser{
 		Schemas:  []string{api.UserSchema},
 		ID:       pu.IdentityID.String(),
 		UserName: pu.Email,
@@ -47,4 +54,15 @@ func (pu *ProviderUser) ToAPI() *api.SCIMUser {
 			ResourceType: "User",
 		},
 	}
+	if pu.Department != "" || pu.EmployeeNumber != "" || pu.Manager != "" {
+		result.Schemas = append(result.Schemas, api.EnterpriseUserSchema)
+		result.Enterprise = &api.SCIMEnterpriseUser{
+			EmployeeNumber: pu.EmployeeNumber,
+			Department:     pu.Department,
+		}
+		if pu.Manager != "" {
+			result.Enterprise.Manager = &api.SCIMManager{Value: pu.Manager}
+		}
+	}
+	return result
 }
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/infrahq/infra/api"
+)
+
+// SigningKeyState is the state of a SigningKey in the rotation.
+type SigningKeyState string
+
+const (
+	// SigningKeyStateActive is the state of the key used to sign new tokens.
+	// There is only one active key for each organization.
+	SigningKeyStateActive SigningKeyState = "active"
+	// SigningKeyStateVerifying is the state of a key that was replaced by a
+	// new active key. The key is still published so that tokens signed with
+	// it can be verified until they expire.
+	SigningKeyStateVerifying SigningKeyState = "verifying"
+	// SigningKeyStateRetired is the state of a key that is no longer
+	// published.
+	SigningKeyStateRetired SigningKeyState = "retired"
+)
+
+// SigningKey is a key used by an organization to sign the JWTs it issues to
+// users for destinations.
+type SigningKey struct {
+	Model
+	OrganizationMember
+
+	// KeyID is the kid of the key, and is included in the header of the
+	// tokens signed by the key.
+	KeyID      string
+	PrivateJWK EncryptedAtRest
+	PublicJWK  []byte
+	State      SigningKeyState
+}
+
+func (k *SigningKey) ToAPI() *api.SigningKey {
+	return &api.SigningKey{
+		ID:      k.ID,
+		Created: api.Time(k.CreatedAt),
+		Updated: api.Time(k.UpdatedAt),
+		KeyID:   k.KeyID,
+		State:   string(k.State),
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+// WorkloadIdentity trusts tokens issued to a workload, like a CI job, by an
+// external OIDC issuer. A token signed by a key from JWKSURL, with a matching
+// issuer, audience, and subject, can be exchanged for an access key for the
+// identity.
+type WorkloadIdentity struct {
+	Model
+	OrganizationMember
+
+	Name string
+	// Issuer must match the iss claim of the token.
+	Issuer string
+	// JWKSURL is the URL of the JSON Web Key Set used to verify the signature
+	// of the token.
+	JWKSURL string
+	// Audience must be one of the values of the aud claim of the token.
+	Audience string
+	// Subject must match the sub claim of the token. Each * in Subject matches
+	// any sequence of characters.
+	Subject string
+	// IdentityID is the ID of the user the workload logs in as.
+	IdentityID uid.ID
+	// Scopes are the access key scopes of the keys issued to the workload. The
+	// keys are not limited when Scopes is empty.
+	Scopes CommaSeparatedStrings
+}
+
+func (w *WorkloadIdentity) ToAPI() *api.WorkloadIdentity {
+	return &api.WorkloadIdentity{
+		ID:       w.ID,
+		Created:  api.Time(w.CreatedAt),
+		Updated:  api.Time(w.UpdatedAt),
+		Name:     w.Name,
+		Issuer:   w.Issuer,
+		JWKSURL:  w.JWKSURL,
+		Audience: w.Audience,
+		Subject:  w.Subject,
+		UserID:   w.IdentityID,
+		Scopes:   w.Scopes,
+	}
+}
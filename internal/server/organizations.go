@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/access"
@@ -81,6 +82,22 @@ func (a *API) UpdateOrganization(rCtx access.RequestContext, r *api.UpdateOrgani
 		domains[d] = true
 	}
 
+	if r.RequireMFA != nil {
+		org.RequireMFA = *r.RequireMFA
+	}
+
+	if policy := r.PasswordPolicy; policy != nil {
+		org.PasswordPolicy = models.PasswordPolicy{
+			PasswordMinLength:    policy.MinLength,
+			PasswordLowercaseMin: policy.LowercaseMin,
+			PasswordUppercaseMin: policy.UppercaseMin,
+			PasswordNumberMin:    policy.NumberMin,
+			PasswordSymbolMin:    policy.SymbolMin,
+			PasswordMaxAge:       time.Duration(policy.MaxAge),
+			PasswordHistory:      policy.HistoryCount,
+		}
+	}
+
 	err = access.UpdateOrganization(rCtx, org)
 	if err != nil {
 		return nil, err
@@ -622,6 +622,60 @@ func TestAPI_UpdateOrganization(t *testing.T) {
 				assert.DeepEqual(t, actual, expected)
 			},
 		},
+		"can update the password policy": {
+			urlPath: "/api/organizations/" + org.ID.String(),
+			body: api.UpdateOrganizationRequest{
+				AllowedDomains: []string{"hello.example.com"},
+				PasswordPolicy: &api.PasswordPolicy{
+					MinLength:    12,
+					UppercaseMin: 1,
+					SymbolMin:    1,
+					MaxAge:       api.Duration(90 * 24 * time.Hour),
+					HistoryCount: 5,
+				},
+			},
+			setup: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+adminKey)
+				req.Host = "update.example.com"
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+				actual := &api.Organization{}
+				err := json.Unmarshal(resp.Body.Bytes(), actual)
+				assert.NilError(t, err)
+				expected := api.PasswordPolicy{
+					MinLength:    12,
+					UppercaseMin: 1,
+					SymbolMin:    1,
+					MaxAge:       api.Duration(90 * 24 * time.Hour),
+					HistoryCount: 5,
+				}
+				assert.DeepEqual(t, actual.PasswordPolicy, expected)
+			},
+		},
+		"fails to update the password policy with a short minimum length": {
+			urlPath: "/api/organizations/" + org.ID.String(),
+			body: api.UpdateOrganizationRequest{
+				AllowedDomains: []string{"hello.example.com"},
+				PasswordPolicy: &api.PasswordPolicy{MinLength: 4},
+			},
+			setup: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+adminKey)
+				req.Host = "update.example.com"
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+				respBody := &api.Error{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+
+				expected := []api.FieldError{
+					{FieldName: "passwordPolicy.minLength", Errors: []string{"value 4 must be at least 8"}},
+				}
+				assert.DeepEqual(t, respBody.FieldErrors, expected)
+			},
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -10,12 +10,11 @@ import (
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/email"
-	"github.com/infrahq/infra/internal/server/redis"
 )
 
 func (a *API) RequestPasswordReset(rCtx access.RequestContext, r *api.PasswordResetRequest) (*api.EmptyResponse, error) {
 	// no authorization required
-	if err := redis.NewLimiter(a.server.redis).RateOK(r.Email, 10); err != nil {
+	if err := a.server.limiter.RateOK(r.Email, 10); err != nil {
 		return nil, err
 	}
 
@@ -7,12 +7,17 @@ import (
 	"regexp"
 	"strings"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/generate"
 	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/email"
 	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/internal/validate"
 )
 
 // caution: this endpoint is unauthenticated, do not return sensitive info
@@ -33,8 +38,13 @@ func (a *API) ListProviders(rCtx access.RequestContext, r *api.ListProvidersRequ
 		providers = append(providers, *a.server.Google)
 	}
 
+	withGroupMapping := isAdmin(rCtx)
 	result := api.NewListResponse(providers, PaginationToResponse(p), func(provider models.Provider) api.Provider {
-		return *provider.ToAPI()
+		resp := provider.ToAPI()
+		if withGroupMapping {
+			resp.GroupMapping = provider.GroupMapping.ToAPI()
+		}
+		return *resp
 	})
 
 	return result, nil
@@ -47,7 +57,48 @@ func (a *API) GetProvider(rCtx access.RequestContext, r *api.Resource) (*api.Pro
 		return nil, err
 	}
 
-	return provider.ToAPI(), nil
+	resp := provider.ToAPI()
+	if isAdmin(rCtx) {
+		resp.GroupMapping = provider.GroupMapping.ToAPI()
+	}
+	return resp, nil
+}
+
+// DiscoverLogin returns the providers that a user logs in with, based on the
+// domain of their email. The social login provider is returned for the allowed
+// domains of the organization.
+// caution: this endpoint is unauthenticated, do not return sensitive info
+func (a *API) DiscoverLogin(rCtx access.RequestContext, r *api.DiscoverLoginRequest) (*api.DiscoverLoginResponse, error) {
+	domain, err := email.Domain(r.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+	}
+	domain = strings.ToLower(domain)
+
+	providers, err := data.ListProviders(rCtx.DBTxn, data.ListProvidersOptions{
+		ByDomain:             domain,
+		ExcludeInfraProvider: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.server.Google != nil && slices.Contains(rCtx.Authenticated.Organization.AllowedDomains, domain) {
+		providers = append(providers, *a.server.Google)
+	}
+
+	result := &api.DiscoverLoginResponse{Providers: make([]api.Provider, 0, len(providers))}
+	for _, provider := range providers {
+		result.Providers = append(result.Providers, *provider.ToAPI())
+	}
+	return result, nil
+}
+
+// isAdmin returns true if the request is from an admin. The group mapping of
+// providers is only returned to admins, because the endpoints to get providers
+// are unauthenticated.
+func isAdmin(rCtx access.RequestContext) bool {
+	return rCtx.Authenticated.User != nil && access.IsAuthorized(rCtx, models.InfraAdminRole) == nil
 }
 
 var (
@@ -69,6 +120,8 @@ func (a *API) CreateProvider(rCtx access.RequestContext, r *api.CreateProviderRe
 		URL:          cleanupURL(r.URL),
 		ClientID:     r.ClientID,
 		ClientSecret: models.EncryptedAtRest(r.ClientSecret),
+		GroupMapping: groupMappingFromAPI(r.GroupMapping),
+		Domains:      normalizeDomains(r.Domains),
 	}
 
 	if r.API != nil {
@@ -84,6 +137,11 @@ func (a *API) CreateProvider(rCtx access.RequestContext, r *api.CreateProviderRe
 	}
 	provider.Kind = kind
 
+	if kind == models.ProviderKindGitHub {
+		// keep the scheme, so that a GitHub Enterprise Server can be used over http
+		provider.URL = strings.TrimSuffix(strings.TrimSpace(r.URL), "/")
+	}
+
 	// If name is not provided, generate based on provider kind
 	if provider.Name == "" {
 		provider.Name = provider.Kind.String()
@@ -105,7 +163,7 @@ func (a *API) CreateProvider(rCtx access.RequestContext, r *api.CreateProviderRe
 		}
 	}
 
-	if err := a.setProviderInfoFromServer(rCtx.Request.Context(), provider); err != nil {
+	if err := a.setProviderInfo(rCtx.Request.Context(), provider, r.SAML, r.LDAP, r.GitHub); err != nil {
 		return nil, err
 	}
 
@@ -113,7 +171,7 @@ func (a *API) CreateProvider(rCtx access.RequestContext, r *api.CreateProviderRe
 		return nil, err
 	}
 
-	return provider.ToAPI(), nil
+	return providerWithGroupMapping(provider), nil
 }
 
 func (a *API) PatchProvider(rCtx access.RequestContext, r *api.PatchProviderRequest) (*api.Provider, error) {
@@ -127,10 +185,16 @@ func (a *API) PatchProvider(rCtx access.RequestContext, r *api.PatchProviderRequ
 	if r.ClientSecret != "" {
 		provider.ClientSecret = models.EncryptedAtRest(r.ClientSecret)
 	}
+	if r.GroupMapping != nil {
+		provider.GroupMapping = groupMappingFromAPI(r.GroupMapping)
+	}
+	if r.Domains != nil {
+		provider.Domains = normalizeDomains(r.Domains)
+	}
 	if err = access.SaveProvider(rCtx, provider); err != nil {
 		return nil, err
 	}
-	return provider.ToAPI(), nil
+	return providerWithGroupMapping(provider), nil
 }
 
 func (a *API) UpdateProvider(rCtx access.RequestContext, r *api.UpdateProviderRequest) (*api.Provider, error) {
@@ -142,6 +206,8 @@ func (a *API) UpdateProvider(rCtx access.RequestContext, r *api.UpdateProviderRe
 		URL:          cleanupURL(r.URL),
 		ClientID:     r.ClientID,
 		ClientSecret: models.EncryptedAtRest(r.ClientSecret),
+		GroupMapping: groupMappingFromAPI(r.GroupMapping),
+		Domains:      normalizeDomains(r.Domains),
 	}
 
 	if r.API != nil {
@@ -157,7 +223,12 @@ func (a *API) UpdateProvider(rCtx access.RequestContext, r *api.UpdateProviderRe
 	}
 	provider.Kind = kind
 
-	if err := a.setProviderInfoFromServer(rCtx.Request.Context(), provider); err != nil {
+	if kind == models.ProviderKindGitHub {
+		// keep the scheme, so that a GitHub Enterprise Server can be used over http
+		provider.URL = strings.TrimSuffix(strings.TrimSpace(r.URL), "/")
+	}
+
+	if err := a.setProviderInfo(rCtx.Request.Context(), provider, r.SAML, r.LDAP, r.GitHub); err != nil {
 		return nil, err
 	}
 
@@ -165,13 +236,169 @@ func (a *API) UpdateProvider(rCtx access.RequestContext, r *api.UpdateProviderRe
 		return nil, err
 	}
 
-	return provider.ToAPI(), nil
+	return providerWithGroupMapping(provider), nil
 }
 
 func (a *API) DeleteProvider(rCtx access.RequestContext, r *api.Resource) (*api.EmptyResponse, error) {
 	return nil, access.DeleteProvider(rCtx, r.ID)
 }
 
+func (a *API) PreviewGroupMapping(rCtx access.RequestContext, r *api.PreviewGroupMappingRequest) (*api.PreviewGroupMappingResponse, error) {
+	provider, user, err := access.GetProviderToPreviewGroupMapping(rCtx, r.ID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := r.Groups
+	if user != nil {
+		ctx := rCtx.Request.Context()
+		client, err := a.server.providerClient(ctx, provider, user.RedirectURL)
+		if err != nil {
+			return nil, err
+		}
+		info, err := data.GetProviderUserInfo(ctx, rCtx.DBTxn, user, client)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", internal.ErrBadGateway, err)
+		}
+		groups = info.Groups
+	}
+
+	mapping := provider.GroupMapping
+	if r.GroupMapping != nil {
+		mapping = groupMappingFromAPI(r.GroupMapping)
+	}
+	mapped, err := mapping.Apply(provider.Name, groups)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+	}
+	return &api.PreviewGroupMappingResponse{ProviderGroups: groups, Groups: mapped}, nil
+}
+
+// providerWithGroupMapping returns the response to an admin who changed the
+// provider.
+func providerWithGroupMapping(provider *models.Provider) *api.Provider {
+	resp := provider.ToAPI()
+	resp.GroupMapping = provider.GroupMapping.ToAPI()
+	return resp
+}
+
+// normalizeDomains returns the email domains in lower case, without duplicates.
+func normalizeDomains(domains []string) models.CommaSeparatedStrings {
+	result := models.CommaSeparatedStrings{}
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if !slices.Contains(result, domain) {
+			result = append(result, domain)
+		}
+	}
+	return result
+}
+
+func groupMappingFromAPI(m *api.ProviderGroupMapping) models.GroupMapping {
+	if m == nil {
+		return models.GroupMapping{}
+	}
+	mapping := models.GroupMapping{
+		Include:            m.Include,
+		Exclude:            m.Exclude,
+		PrefixProviderName: m.PrefixProviderName,
+	}
+	for _, rule := range m.Rename {
+		mapping.Rename = append(mapping.Rename, models.GroupRenameRule{Match: rule.Match, Replace: rule.Replace})
+	}
+	return mapping
+}
+
+// setProviderInfo sets the fields of the provider that are read from the
+// identity provider, using SAML metadata, the LDAP server, or the OIDC server.
+func (a *API) setProviderInfo(ctx context.Context, provider *models.Provider, saml *api.ProviderSAML, ldap *api.ProviderLDAP, github *api.ProviderGitHub) error {
+	switch provider.Kind {
+	case models.ProviderKindSAML:
+		return setProviderInfoFromSAMLMetadata(ctx, provider, saml)
+	case models.ProviderKindLDAP:
+		return setProviderInfoFromLDAP(ctx, provider, ldap)
+	case models.ProviderKindGitHub:
+		return a.setProviderInfoFromGitHub(ctx, provider, github)
+	default:
+		return a.setProviderInfoFromServer(ctx, provider)
+	}
+}
+
+func setProviderInfoFromSAMLMetadata(ctx context.Context, provider *models.Provider, saml *api.ProviderSAML) error {
+	if saml == nil {
+		return fmt.Errorf("%w: saml configuration is required for a saml provider", internal.ErrBadRequest)
+	}
+
+	metadata := saml.Metadata
+	if saml.MetadataURL != "" {
+		raw, err := providers.FetchSAMLMetadata(ctx, saml.MetadataURL)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("%w: %s", internal.ErrBadGateway, err)
+			}
+			return validate.Error{"saml.metadataURL": {err.Error()}}
+		}
+		metadata = string(raw)
+	}
+
+	idp, err := providers.ParseSAMLMetadata([]byte(metadata))
+	if err != nil {
+		return validate.Error{"saml.metadata": {err.Error()}}
+	}
+
+	provider.SAMLMetadata = metadata
+	provider.SAMLGroupsAttribute = saml.GroupsAttribute
+	provider.URL = idp.EntityID
+	provider.AuthURL = idp.SSOURL
+	provider.ClientID = ""
+	provider.ClientSecret = ""
+	provider.Scopes = nil
+	return nil
+}
+
+// setProviderInfoFromLDAP sets the directory settings of an LDAP provider, and
+// checks that the service account can bind to the LDAP server.
+func setProviderInfoFromLDAP(ctx context.Context, provider *models.Provider, ldap *api.ProviderLDAP) error {
+	if ldap == nil {
+		return fmt.Errorf("%w: ldap configuration is required for an ldap provider", internal.ErrBadRequest)
+	}
+	if !strings.HasPrefix(provider.URL, "ldap://") && !strings.HasPrefix(provider.URL, "ldaps://") {
+		return validate.Error{"url": {"must be a URL with a scheme of ldap or ldaps"}}
+	}
+
+	provider.LDAPBindDN = ldap.BindDN
+	provider.LDAPBindPassword = models.EncryptedAtRest(ldap.BindPassword)
+	provider.LDAPBaseDN = ldap.BaseDN
+	provider.LDAPUserFilter = ldap.UserFilter
+	provider.LDAPGroupBaseDN = ldap.GroupBaseDN
+	provider.LDAPStartTLS = ldap.StartTLS
+	provider.LDAPCACertificate = strings.ReplaceAll(string(ldap.CACertificate), "\\n", "\n")
+	provider.ClientID = ""
+	provider.ClientSecret = ""
+	provider.Scopes = nil
+
+	if err := providers.NewLDAPClient(*provider).Validate(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s", internal.ErrBadGateway, err)
+		}
+		return validate.Error{"ldap": {err.Error()}}
+	}
+	return nil
+}
+
+// setProviderInfoFromGitHub sets the organizations that are allowed to login
+// with a GitHub provider, and checks the client credentials with GitHub.
+func (a *API) setProviderInfoFromGitHub(ctx context.Context, provider *models.Provider, github *api.ProviderGitHub) error {
+	if github == nil {
+		return fmt.Errorf("%w: github configuration is required for a github provider", internal.ErrBadRequest)
+	}
+	if provider.URL == "" {
+		provider.URL = providers.GitHubDefaultURL
+	}
+	provider.GitHubOrganizations = github.Organizations
+	return a.setProviderInfoFromServer(ctx, provider)
+}
+
 // setProviderInfoFromServer checks information provided by an OIDC server
 func (a *API) setProviderInfoFromServer(ctx context.Context, provider *models.Provider) error {
 	// create a provider client to validate the server and get its info
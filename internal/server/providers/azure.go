@@ -59,8 +59,8 @@ func (a *azure) AuthServerInfo(ctx context.Context) (*AuthServerInfo, error) {
 	return a.OIDCClient.AuthServerInfo(ctx)
 }
 
-func (a *azure) ExchangeAuthCodeForProviderTokens(ctx context.Context, code string) (*IdentityProviderAuth, error) {
-	return a.OIDCClient.ExchangeAuthCodeForProviderTokens(ctx, code)
+func (a *azure) ExchangeAuthCodeForProviderTokens(ctx context.Context, code, codeVerifier string) (*IdentityProviderAuth, error) {
+	return a.OIDCClient.ExchangeAuthCodeForProviderTokens(ctx, code, codeVerifier)
 }
 
 func (a *azure) RefreshAccessToken(ctx context.Context, providerUser *models.ProviderUser) (accessToken string, expiry *time.Time, err error) {
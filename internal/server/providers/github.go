@@ -0,0 +1,351 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// GitHubDefaultURL is the URL used by a GitHub provider when no URL is set.
+const GitHubDefaultURL = "github.com"
+
+// gitHubScopes are the OAuth scopes needed to read the email address of a
+// user, and their organization and team memberships.
+var gitHubScopes = []string{"read:org", "user:email"}
+
+// ErrGitHubOrganizationNotAllowed is returned when a user is not an active
+// member of any of the organizations that are allowed to login.
+var ErrGitHubOrganizationNotAllowed = errors.New("user is not a member of an allowed github organization")
+
+// github implements OIDCClient using the OAuth flow of GitHub, which does not
+// support OpenID Connect. Groups are named after the organizations and teams
+// of the user, as "org" and "org/team".
+type github struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// BaseURL is the URL of github.com or a GitHub Enterprise Server, used
+	// for the OAuth endpoints
+	BaseURL string
+	// APIURL is the URL of the REST API
+	APIURL string
+	// Organizations are the logins of the organizations that are allowed to
+	// login. Only groups from these organizations are synchronized.
+	Organizations []string
+}
+
+func newGitHubClient(provider models.Provider, clientSecret, redirectURL string) *github {
+	baseURL := provider.URL
+	if baseURL == "" {
+		baseURL = GitHubDefaultURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	// github.com uses a separate host for the API, GitHub Enterprise Server
+	// serves the API from a path on the same host
+	apiURL := baseURL + "/api/v3"
+	if u, err := url.Parse(baseURL); err == nil && strings.EqualFold(u.Host, GitHubDefaultURL) {
+		apiURL = "https://api.github.com"
+	}
+
+	return &github{
+		ClientID:      provider.ClientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		BaseURL:       baseURL,
+		APIURL:        apiURL,
+		Organizations: provider.GitHubOrganizations,
+	}
+}
+
+func (g *github) config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     g.ClientID,
+		ClientSecret: g.ClientSecret,
+		RedirectURL:  g.RedirectURL,
+		Scopes:       gitHubScopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   g.BaseURL + "/login/oauth/authorize",
+			TokenURL:  g.BaseURL + "/login/oauth/access_token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// Validate checks that the server can be reached and that the client
+// credentials are valid, by exchanging a code that is not valid.
+func (g *github) Validate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
+	defer cancel()
+
+	_, err := g.config().Exchange(ctx, "test-code")
+	var errRetrieve *oauth2.RetrieveError
+	switch {
+	case err == nil:
+		return nil
+	case !errors.As(err, &errRetrieve):
+		logging.Debugf("error validating github provider: %s", err)
+		return newValidationError("url")
+	case errRetrieve.ErrorCode == "incorrect_client_credentials":
+		logging.Debugf("error validating github provider client: %s", err)
+		return newValidationError("clientSecret")
+	case errRetrieve.Response != nil && errRetrieve.Response.StatusCode == http.StatusNotFound:
+		logging.Debugf("error validating github provider: %s", err)
+		return newValidationError("url")
+	}
+
+	// return nil for all other errors, because the request was made with an
+	// invalid code, which will always fail
+	logging.L.Trace().Err(err).Msg("error validating github provider, this is expected")
+	return nil
+}
+
+func (g *github) AuthServerInfo(ctx context.Context) (*AuthServerInfo, error) {
+	return &AuthServerInfo{
+		AuthURL:         g.config().Endpoint.AuthURL,
+		ScopesSupported: gitHubScopes,
+	}, nil
+}
+
+func (g *github) ExchangeAuthCodeForProviderTokens(ctx context.Context, code, codeVerifier string) (*IdentityProviderAuth, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
+	defer cancel()
+
+	token, err := g.config().Exchange(ctx, code, codeVerifierOptions(codeVerifier)...)
+	if err != nil {
+		return nil, fmt.Errorf("code exchange: %w", err)
+	}
+
+	email, err := g.primaryEmail(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IdentityProviderAuth{
+		AccessToken:       token.AccessToken,
+		RefreshToken:      token.RefreshToken,
+		AccessTokenExpiry: token.Expiry,
+		Email:             email,
+	}, nil
+}
+
+// RefreshAccessToken refreshes the access token if it has expired. Tokens
+// issued to an OAuth app do not expire, and are returned unchanged.
+func (g *github) RefreshAccessToken(ctx context.Context, providerUser *models.ProviderUser) (accessToken string, expiry *time.Time, err error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
+	defer cancel()
+
+	tokenSource := g.config().TokenSource(ctx, &oauth2.Token{
+		AccessToken:  string(providerUser.AccessToken),
+		RefreshToken: string(providerUser.RefreshToken),
+		Expiry:       providerUser.ExpiresAt,
+	})
+
+	newToken, err := tokenSource.Token() // this refreshes token if needed
+	if err != nil {
+		return "", nil, refreshTokenError(err)
+	}
+
+	return newToken.AccessToken, &newToken.Expiry, nil
+}
+
+// GetUserInfo returns the email address and name of the user, and the groups
+// from their memberships in the allowed organizations. An error is returned
+// if the user is not an active member of any of the allowed organizations.
+func (g *github) GetUserInfo(ctx context.Context, providerUser *models.ProviderUser) (*UserInfoClaims, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
+	defer cancel()
+
+	accessToken := string(providerUser.AccessToken)
+
+	var user struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := g.get(ctx, accessToken, "/user", &user); err != nil {
+		return nil, fmt.Errorf("get user info: %w", err)
+	}
+
+	email, err := g.primaryEmail(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := g.groups(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+
+	return &UserInfoClaims{Email: email, Name: name, Groups: groups}, nil
+}
+
+// primaryEmail returns the verified primary email address of the user.
+func (g *github) primaryEmail(ctx context.Context, accessToken string) (string, error) {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := g.get(ctx, accessToken, "/user/emails", &emails); err != nil {
+		return "", fmt.Errorf("get user emails: %w", err)
+	}
+
+	for _, email := range emails {
+		if !email.Primary {
+			continue
+		}
+		if !email.Verified {
+			return "", fmt.Errorf("primary email address of github user is not verified")
+		}
+		if strings.ContainsAny(email.Email, ` '`) {
+			return "", fmt.Errorf("github user has invalid email address")
+		}
+		return email.Email, nil
+	}
+	return "", fmt.Errorf("github user does not have a primary email address")
+}
+
+// groups returns a group for each allowed organization the user is an active
+// member of, and a group for each team of the user in those organizations.
+func (g *github) groups(ctx context.Context, accessToken string) ([]string, error) {
+	var groups []string
+	allowed := map[string]bool{}
+
+	for _, org := range g.Organizations {
+		var membership struct {
+			State        string `json:"state"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}
+		err := g.get(ctx, accessToken, "/user/memberships/orgs/"+url.PathEscape(org), &membership)
+		var apiErr *gitHubAPIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			continue
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+			// the organization restricts access by OAuth apps
+			logging.Warnf("unable to check membership of github organization %q: %s", org, apiErr.Message)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("get organization membership: %w", err)
+		}
+		if membership.State != "active" {
+			continue
+		}
+
+		login := membership.Organization.Login
+		if login == "" {
+			login = org
+		}
+		allowed[strings.ToLower(login)] = true
+		groups = append(groups, login)
+	}
+
+	if len(allowed) == 0 {
+		return nil, ErrGitHubOrganizationNotAllowed
+	}
+
+	for page := 1; ; page++ {
+		var teams []struct {
+			Slug         string `json:"slug"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}
+		path := fmt.Sprintf("/user/teams?per_page=100&page=%d", page)
+		if err := g.get(ctx, accessToken, path, &teams); err != nil {
+			return nil, fmt.Errorf("get user teams: %w", err)
+		}
+
+		for _, team := range teams {
+			if allowed[strings.ToLower(team.Organization.Login)] {
+				groups = append(groups, team.Organization.Login+"/"+team.Slug)
+			}
+		}
+		if len(teams) < 100 {
+			break
+		}
+	}
+
+	sort.Strings(groups)
+	return groups, nil
+}
+
+type gitHubAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *gitHubAPIError) Error() string {
+	return fmt.Sprintf("github api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns ErrAccessTokenRevoked when the API rejected the access token.
+// Tokens issued to an OAuth app do not expire, so they are only rejected once
+// they are revoked.
+func (e *gitHubAPIError) Unwrap() error {
+	if e.StatusCode == http.StatusUnauthorized {
+		return ErrAccessTokenRevoked
+	}
+	return nil
+}
+
+// get calls the REST API and decodes the JSON response into v.
+func (g *github) get(ctx context.Context, accessToken, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		client = c // used in tests for specific transport needs, like skipping TLS verify
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &errResp)
+		return &gitHubAPIError{StatusCode: resp.StatusCode, Message: errResp.Message}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
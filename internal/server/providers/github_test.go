@@ -0,0 +1,192 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// newGitHubTestServer starts a stand-in for a GitHub Enterprise Server, which
+// has a single user with the access token "user-token".
+func newGitHubTestServer(t *testing.T) (*httptest.Server, context.Context) {
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		assert.Check(t, json.NewEncoder(w).Encode(v))
+	}
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Check(t, r.ParseForm())
+		switch {
+		case r.PostForm.Get("client_id") != "client-id" || r.PostForm.Get("client_secret") != "client-secret":
+			writeJSON(w, http.StatusOK, map[string]string{"error": "incorrect_client_credentials"})
+		case r.PostForm.Get("code") != "valid-code":
+			writeJSON(w, http.StatusOK, map[string]string{"error": "bad_verification_code"})
+		default:
+			writeJSON(w, http.StatusOK, map[string]string{"access_token": "user-token", "token_type": "bearer"})
+		}
+	})
+
+	authenticated := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("/api/v3/user", authenticated(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"login": "octocat", "name": "The Octocat"})
+	}))
+	mux.HandleFunc("/api/v3/user/emails", authenticated(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"email": "octocat@users.noreply.github.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	}))
+	mux.HandleFunc("/api/v3/user/memberships/orgs/", authenticated(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/user/memberships/orgs/InfraHQ", "/api/v3/user/memberships/orgs/infrahq":
+			writeJSON(w, http.StatusOK, map[string]any{"state": "active", "organization": map[string]string{"login": "infrahq"}})
+		case "/api/v3/user/memberships/orgs/invited":
+			writeJSON(w, http.StatusOK, map[string]any{"state": "pending", "organization": map[string]string{"login": "invited"}})
+		case "/api/v3/user/memberships/orgs/restricted":
+			writeJSON(w, http.StatusForbidden, map[string]string{"message": "access restricted"})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+	}))
+	mux.HandleFunc("/api/v3/user/teams", authenticated(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"slug": "engineering", "organization": map[string]string{"login": "infrahq"}},
+			{"slug": "design", "organization": map[string]string{"login": "infrahq"}},
+			{"slug": "maintainers", "organization": map[string]string{"login": "other"}},
+		})
+	}))
+
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, srv.Client())
+	return srv, ctx
+}
+
+func gitHubTestProvider(url string, orgs ...string) models.Provider {
+	return models.Provider{
+		Kind:                models.ProviderKindGitHub,
+		URL:                 url,
+		ClientID:            "client-id",
+		GitHubOrganizations: orgs,
+	}
+}
+
+func TestNewGitHubClient(t *testing.T) {
+	t.Run("github.com", func(t *testing.T) {
+		client := newGitHubClient(models.Provider{URL: "github.com"}, "", "")
+		assert.Equal(t, client.BaseURL, "https://github.com")
+		assert.Equal(t, client.APIURL, "https://api.github.com")
+	})
+	t.Run("default url", func(t *testing.T) {
+		client := newGitHubClient(models.Provider{}, "", "")
+		assert.Equal(t, client.BaseURL, "https://github.com")
+		assert.Equal(t, client.APIURL, "https://api.github.com")
+	})
+	t.Run("enterprise server", func(t *testing.T) {
+		client := newGitHubClient(models.Provider{URL: "http://github.example.com/"}, "", "")
+		assert.Equal(t, client.BaseURL, "http://github.example.com")
+		assert.Equal(t, client.APIURL, "http://github.example.com/api/v3")
+	})
+}
+
+func TestGitHub_Validate(t *testing.T) {
+	srv, ctx := newGitHubTestServer(t)
+
+	t.Run("valid", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL, "infrahq"), "client-secret", "")
+		assert.NilError(t, client.Validate(ctx))
+	})
+	t.Run("invalid client credentials", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL, "infrahq"), "wrong", "")
+		assert.ErrorContains(t, client.Validate(ctx), "invalid provider clientSecret")
+	})
+	t.Run("not a github server", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL+"/not-found", "infrahq"), "client-secret", "")
+		assert.ErrorContains(t, client.Validate(ctx), "invalid provider url")
+	})
+}
+
+func TestGitHub_AuthServerInfo(t *testing.T) {
+	client := NewOIDCClient(gitHubTestProvider("github.example.com", "infrahq"), "client-secret", "")
+	info, err := client.AuthServerInfo(context.Background())
+	assert.NilError(t, err)
+	expected := &AuthServerInfo{
+		AuthURL:         "https://github.example.com/login/oauth/authorize",
+		ScopesSupported: []string{"read:org", "user:email"},
+	}
+	assert.DeepEqual(t, info, expected)
+}
+
+func TestGitHub_ExchangeAuthCodeForProviderTokens(t *testing.T) {
+	srv, ctx := newGitHubTestServer(t)
+	client := NewOIDCClient(gitHubTestProvider(srv.URL, "infrahq"), "client-secret", "")
+
+	t.Run("valid code", func(t *testing.T) {
+		auth, err := client.ExchangeAuthCodeForProviderTokens(ctx, "valid-code", "")
+		assert.NilError(t, err)
+		assert.Equal(t, auth.AccessToken, "user-token")
+		assert.Equal(t, auth.Email, "octocat@example.com")
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		_, err := client.ExchangeAuthCodeForProviderTokens(ctx, "invalid-code", "")
+		assert.ErrorContains(t, err, "bad_verification_code")
+	})
+}
+
+func TestGitHub_GetUserInfo(t *testing.T) {
+	srv, ctx := newGitHubTestServer(t)
+	providerUser := &models.ProviderUser{AccessToken: "user-token"}
+
+	t.Run("groups from allowed organizations", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL, "InfraHQ", "invited", "restricted"), "client-secret", "")
+		info, err := client.GetUserInfo(ctx, providerUser)
+		assert.NilError(t, err)
+		expected := &UserInfoClaims{
+			Email:  "octocat@example.com",
+			Name:   "The Octocat",
+			Groups: []string{"infrahq", "infrahq/design", "infrahq/engineering"},
+		}
+		assert.DeepEqual(t, info, expected)
+	})
+	t.Run("not a member of an allowed organization", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL, "other", "invited"), "client-secret", "")
+		_, err := client.GetUserInfo(ctx, providerUser)
+		assert.ErrorIs(t, err, ErrGitHubOrganizationNotAllowed)
+	})
+	t.Run("revoked token", func(t *testing.T) {
+		client := NewOIDCClient(gitHubTestProvider(srv.URL, "infrahq"), "client-secret", "")
+		_, err := client.GetUserInfo(ctx, &models.ProviderUser{AccessToken: "revoked"})
+		assert.ErrorContains(t, err, "github api returned status 401: Bad credentials")
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+	})
+}
+
+func TestGitHub_RefreshAccessToken(t *testing.T) {
+	client := NewOIDCClient(gitHubTestProvider("github.com", "infrahq"), "client-secret", "")
+	// tokens issued to OAuth apps do not expire
+	token, _, err := client.RefreshAccessToken(context.Background(), &models.ProviderUser{AccessToken: "user-token"})
+	assert.NilError(t, err)
+	assert.Equal(t, token, "user-token")
+}
@@ -41,8 +41,8 @@ func (g *google) AuthServerInfo(ctx context.Context) (*AuthServerInfo, error) {
 	return g.OIDCClient.AuthServerInfo(ctx)
 }
 
-func (g *google) ExchangeAuthCodeForProviderTokens(ctx context.Context, code string) (*IdentityProviderAuth, error) {
-	return g.OIDCClient.ExchangeAuthCodeForProviderTokens(ctx, code)
+func (g *google) ExchangeAuthCodeForProviderTokens(ctx context.Context, code, codeVerifier string) (*IdentityProviderAuth, error) {
+	return g.OIDCClient.ExchangeAuthCodeForProviderTokens(ctx, code, codeVerifier)
 }
 
 func (g *google) RefreshAccessToken(ctx context.Context, providerUser *models.ProviderUser) (accessToken string, expiry *time.Time, err error) {
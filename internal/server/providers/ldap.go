@@ -0,0 +1,274 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+const ldapRequestTimeout = time.Second * 30
+
+const (
+	// DefaultLDAPUserFilter finds a user by their Active Directory account
+	// name, user principal name, uid, or email address. {username} is
+	// replaced with the username used to login.
+	DefaultLDAPUserFilter = "(|(sAMAccountName={username})(userPrincipalName={username})(uid={username})(mail={username}))"
+
+	ldapGroupFilter = "(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames))"
+
+	// ldapMaxGroupDepth limits how many levels of nested groups are resolved.
+	ldapMaxGroupDepth = 10
+)
+
+// ErrLDAPInvalidCredentials is returned when the username does not match a
+// user in the directory, or the password is not correct.
+var ErrLDAPInvalidCredentials = errors.New("invalid username or password")
+
+// LDAPClient authenticates users of an LDAP directory, like Active Directory.
+type LDAPClient struct {
+	// URL of the LDAP server, the scheme must be ldap or ldaps
+	URL      string
+	StartTLS bool
+	// CACertificate is a PEM encoded certificate used to verify the TLS
+	// certificate of the server. The system roots are used when it is empty.
+	CACertificate string
+
+	// BindDN and BindPassword are the credentials of the service account used
+	// to search the directory. The search is anonymous when BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	BaseDN      string
+	UserFilter  string
+	GroupBaseDN string
+}
+
+func NewLDAPClient(provider models.Provider) *LDAPClient {
+	c := &LDAPClient{
+		URL:           provider.URL,
+		StartTLS:      provider.LDAPStartTLS,
+		CACertificate: provider.LDAPCACertificate,
+		BindDN:        provider.LDAPBindDN,
+		BindPassword:  string(provider.LDAPBindPassword),
+		BaseDN:        provider.LDAPBaseDN,
+		UserFilter:    provider.LDAPUserFilter,
+		GroupBaseDN:   provider.LDAPGroupBaseDN,
+	}
+	if c.UserFilter == "" {
+		c.UserFilter = DefaultLDAPUserFilter
+	}
+	if c.GroupBaseDN == "" {
+		c.GroupBaseDN = c.BaseDN
+	}
+	return c
+}
+
+// Validate checks that the server can be reached, and that the service
+// account credentials are valid.
+func (c *LDAPClient) Validate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ldapRequestTimeout)
+	defer cancel()
+
+	if _, err := ldap.CompileFilter(strings.ReplaceAll(c.UserFilter, "{username}", "username")); err != nil {
+		return fmt.Errorf("invalid user filter: %w", err)
+	}
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return c.serviceBind(conn)
+}
+
+// Login checks the username and password of a user with a bind, and returns
+// the email address and the groups of the user. Nested groups are included.
+func (c *LDAPClient) Login(ctx context.Context, username, password string) (*UserInfoClaims, error) {
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ldapRequestTimeout)
+	defer cancel()
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := c.serviceBind(conn); err != nil {
+		return nil, err
+	}
+
+	result, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:       c.BaseDN,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    2,
+		Filter:       strings.ReplaceAll(c.UserFilter, "{username}", ldap.EscapeFilter(username)),
+		Attributes:   []string{"mail", "userPrincipalName", "displayName", "cn"},
+	})
+	switch {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded):
+		return nil, fmt.Errorf("more than one user matches username %q", username)
+	case err != nil:
+		return nil, fmt.Errorf("search for user: %w", err)
+	case len(result.Entries) == 0:
+		return nil, ErrLDAPInvalidCredentials
+	case len(result.Entries) > 1:
+		return nil, fmt.Errorf("more than one user matches username %q", username)
+	}
+	user := result.Entries[0]
+
+	if err := conn.Bind(user.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("bind as user: %w", err)
+	}
+
+	// the user may not have permission to read groups, search them using the
+	// service account
+	if err := c.serviceBind(conn); err != nil {
+		return nil, err
+	}
+
+	groups, err := c.groups(conn, user.DN)
+	if err != nil {
+		return nil, err
+	}
+
+	email := user.GetEqualFoldAttributeValue("mail")
+	if upn := user.GetEqualFoldAttributeValue("userPrincipalName"); email == "" && strings.Contains(upn, "@") {
+		email = upn
+	}
+	// the email is the name of the Infra user, so it must come from the
+	// directory, never from the username typed by the user
+	if email == "" {
+		return nil, fmt.Errorf("user %q does not have a mail or userPrincipalName attribute", user.DN)
+	}
+
+	name := user.GetEqualFoldAttributeValue("displayName")
+	if name == "" {
+		name = user.GetEqualFoldAttributeValue("cn")
+	}
+
+	return &UserInfoClaims{Email: email, Name: name, Groups: groups}, nil
+}
+
+// groups returns the names of the groups that the entry is a member of,
+// directly or through other groups. Nested groups are resolved by searching
+// for the groups that list each group as a member, which works with both
+// Active Directory and other LDAP servers.
+func (c *LDAPClient) groups(conn *ldap.Conn, dn string) ([]string, error) {
+	seen := map[string]bool{strings.ToLower(dn): true}
+	members := []string{dn}
+	var names []string
+
+	for depth := 0; len(members) > 0 && depth < ldapMaxGroupDepth; depth++ {
+		var next []string
+		for _, member := range members {
+			escaped := ldap.EscapeFilter(member)
+			result, err := conn.Search(&ldap.SearchRequest{
+				BaseDN:       c.GroupBaseDN,
+				Scope:        ldap.ScopeWholeSubtree,
+				DerefAliases: ldap.NeverDerefAliases,
+				Filter:       fmt.Sprintf("(&%s(|(member=%s)(uniqueMember=%s)))", ldapGroupFilter, escaped, escaped),
+				Attributes:   []string{"cn"},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("search for groups: %w", err)
+			}
+
+			for _, entry := range result.Entries {
+				key := strings.ToLower(entry.DN)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				name := entry.GetEqualFoldAttributeValue("cn")
+				if name == "" {
+					name = entry.DN
+				}
+				names = append(names, name)
+				next = append(next, entry.DN)
+			}
+		}
+		members = next
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *LDAPClient) connect(ctx context.Context) (*ldap.Conn, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url: %w", err)
+	}
+	switch {
+	case u.Scheme != "ldap" && u.Scheme != "ldaps":
+		return nil, fmt.Errorf("unsupported url scheme %q, must be ldap or ldaps", u.Scheme)
+	case c.StartTLS && u.Scheme == "ldaps":
+		return nil, fmt.Errorf("start tls can not be used with an ldaps url")
+	}
+
+	// the certificate of the server is verified against the host name from
+	// the URL, for both ldaps and StartTLS
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: u.Hostname()}
+	if c.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CACertificate)) {
+			return nil, fmt.Errorf("invalid ca certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	dialer := &net.Dialer{}
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		dialer.Deadline = deadline
+	}
+	conn, err := ldap.DialURL(c.URL, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("connect to ldap server: %w", err)
+	}
+	if hasDeadline {
+		conn.SetTimeout(time.Until(deadline))
+	}
+
+	if c.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("start tls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *LDAPClient) serviceBind(conn *ldap.Conn) error {
+	var err error
+	if c.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(c.BindDN, c.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("bind as service account: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/testing/ldapserver"
+)
+
+var ldapTestEntries = []ldapserver.Entry{
+	{
+		DN:       "CN=infra,OU=Service Accounts,DC=example,DC=com",
+		Password: "service-password",
+	},
+	{
+		DN:       "CN=Dana Scully,OU=Users,DC=example,DC=com",
+		Password: "dana-password",
+		Attributes: map[string][]string{
+			"objectClass":    {"user"},
+			"sAMAccountName": {"dscully"},
+			"mail":           {"dana@example.com"},
+			"displayName":    {"Dana Scully"},
+		},
+	},
+	{
+		DN:       "CN=Fox Mulder,OU=Users,DC=example,DC=com",
+		Password: "fox-password",
+		Attributes: map[string][]string{
+			"objectClass":       {"user"},
+			"sAMAccountName":    {"fmulder"},
+			"userPrincipalName": {"fox@example.com"},
+		},
+	},
+	{
+		// no mail or userPrincipalName attribute
+		DN:       "CN=Walter Skinner,OU=Users,DC=example,DC=com",
+		Password: "walter-password",
+		Attributes: map[string][]string{
+			"objectClass":    {"user"},
+			"sAMAccountName": {"skinner@example.com"},
+		},
+	},
+	{
+		DN: "CN=agents,OU=Groups,DC=example,DC=com",
+		Attributes: map[string][]string{
+			"objectClass": {"group"},
+			"cn":          {"agents"},
+			"member":      {"CN=Dana Scully,OU=Users,DC=example,DC=com", "CN=Fox Mulder,OU=Users,DC=example,DC=com"},
+		},
+	},
+	{
+		DN: "CN=x-files,OU=Groups,DC=example,DC=com",
+		Attributes: map[string][]string{
+			"objectClass": {"group"},
+			"cn":          {"x-files"},
+			"member":      {"CN=agents,OU=Groups,DC=example,DC=com"},
+		},
+	},
+	{
+		// a cycle of nested groups
+		DN: "CN=fbi,OU=Groups,DC=example,DC=com",
+		Attributes: map[string][]string{
+			"objectClass": {"group"},
+			"cn":          {"fbi"},
+			"member":      {"CN=x-files,OU=Groups,DC=example,DC=com", "CN=fbi,OU=Groups,DC=example,DC=com"},
+		},
+	},
+	{
+		DN: "CN=skinner-reports,OU=Groups,DC=example,DC=com",
+		Attributes: map[string][]string{
+			"objectClass": {"group"},
+			"cn":          {"skinner-reports"},
+			"member":      {"CN=Fox Mulder,OU=Users,DC=example,DC=com"},
+		},
+	},
+}
+
+func ldapTestProvider(url string) models.Provider {
+	return models.Provider{
+		Kind:             models.ProviderKindLDAP,
+		URL:              url,
+		LDAPBindDN:       "CN=infra,OU=Service Accounts,DC=example,DC=com",
+		LDAPBindPassword: "service-password",
+		LDAPBaseDN:       "OU=Users,DC=example,DC=com",
+		LDAPGroupBaseDN:  "OU=Groups,DC=example,DC=com",
+	}
+}
+
+func TestLDAPClient_Login(t *testing.T) {
+	srv := ldapserver.New(t, ldapTestEntries...)
+	ctx := context.Background()
+
+	t.Run("success with nested groups", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		claims, err := client.Login(ctx, "dscully", "dana-password")
+		assert.NilError(t, err)
+		expected := &UserInfoClaims{
+			Email:  "dana@example.com",
+			Name:   "Dana Scully",
+			Groups: []string{"agents", "fbi", "x-files"},
+		}
+		assert.DeepEqual(t, claims, expected)
+	})
+
+	t.Run("email from user principal name", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		claims, err := client.Login(ctx, "fmulder", "fox-password")
+		assert.NilError(t, err)
+		expected := &UserInfoClaims{
+			Email:  "fox@example.com",
+			Groups: []string{"agents", "fbi", "skinner-reports", "x-files"},
+		}
+		assert.DeepEqual(t, claims, expected)
+	})
+
+	t.Run("login with email address", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		claims, err := client.Login(ctx, "dana@example.com", "dana-password")
+		assert.NilError(t, err)
+		assert.Equal(t, claims.Email, "dana@example.com")
+	})
+
+	t.Run("email is not read from the username", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		_, err := client.Login(ctx, "skinner@example.com", "walter-password")
+		assert.Error(t, err, `user "CN=Walter Skinner,OU=Users,DC=example,DC=com" does not have a mail or userPrincipalName attribute`)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		_, err := client.Login(ctx, "dscully", "fox-password")
+		assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		_, err := client.Login(ctx, "dscully", "")
+		assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		_, err := client.Login(ctx, "cspender", "password")
+		assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+	})
+
+	t.Run("username is escaped in the filter", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		_, err := client.Login(ctx, "*", "dana-password")
+		assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+	})
+
+	t.Run("invalid service account", func(t *testing.T) {
+		provider := ldapTestProvider(srv.URL)
+		provider.LDAPBindPassword = "wrong"
+		client := NewLDAPClient(provider)
+		_, err := client.Login(ctx, "dscully", "dana-password")
+		assert.ErrorContains(t, err, `bind as service account: LDAP Result Code 49 "Invalid Credentials"`)
+	})
+
+	t.Run("start tls", func(t *testing.T) {
+		// the certificate of the server only has a DNS name, which must
+		// match the host name of the url
+		assert.Assert(t, strings.HasPrefix(srv.URL, "ldap://localhost:"))
+		provider := ldapTestProvider(srv.URL)
+		provider.LDAPStartTLS = true
+		provider.LDAPCACertificate = srv.CACertificate
+		client := NewLDAPClient(provider)
+		claims, err := client.Login(ctx, "dscully", "dana-password")
+		assert.NilError(t, err)
+		assert.Equal(t, claims.Email, "dana@example.com")
+	})
+
+	t.Run("start tls with a host name that does not match the certificate", func(t *testing.T) {
+		provider := ldapTestProvider(strings.Replace(srv.URL, "localhost", "127.0.0.1", 1))
+		provider.LDAPStartTLS = true
+		provider.LDAPCACertificate = srv.CACertificate
+		client := NewLDAPClient(provider)
+		_, err := client.Login(ctx, "dscully", "dana-password")
+		assert.ErrorContains(t, err, "cannot validate certificate for 127.0.0.1 because it doesn't contain any IP SANs")
+	})
+
+	t.Run("start tls with untrusted certificate", func(t *testing.T) {
+		provider := ldapTestProvider(srv.URL)
+		provider.LDAPStartTLS = true
+		client := NewLDAPClient(provider)
+		_, err := client.Login(ctx, "dscully", "dana-password")
+		assert.ErrorContains(t, err, "tls: failed to verify certificate: x509: certificate signed by unknown authority")
+	})
+}
+
+func TestLDAPClient_Login_LDAPS(t *testing.T) {
+	srv := ldapserver.NewTLS(t, ldapTestEntries...)
+
+	provider := ldapTestProvider(srv.URL)
+	provider.LDAPCACertificate = srv.CACertificate
+	client := NewLDAPClient(provider)
+	claims, err := client.Login(context.Background(), "dscully", "dana-password")
+	assert.NilError(t, err)
+	assert.Equal(t, claims.Email, "dana@example.com")
+	assert.DeepEqual(t, claims.Groups, []string{"agents", "fbi", "x-files"})
+}
+
+func TestLDAPClient_Validate(t *testing.T) {
+	srv := ldapserver.New(t, ldapTestEntries...)
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		client := NewLDAPClient(ldapTestProvider(srv.URL))
+		assert.NilError(t, client.Validate(ctx))
+	})
+
+	t.Run("invalid user filter", func(t *testing.T) {
+		provider := ldapTestProvider(srv.URL)
+		provider.LDAPUserFilter = "(uid={username}"
+		client := NewLDAPClient(provider)
+		assert.ErrorContains(t, client.Validate(ctx), "invalid user filter")
+	})
+
+	t.Run("start tls with ldaps url", func(t *testing.T) {
+		provider := ldapTestProvider("ldaps://127.0.0.1:1")
+		provider.LDAPStartTLS = true
+		client := NewLDAPClient(provider)
+		assert.ErrorContains(t, client.Validate(ctx), "start tls can not be used with an ldaps url")
+	})
+}
@@ -39,7 +39,10 @@ type IdentityProviderAuth struct {
 type OIDCClient interface {
 	Validate(context.Context) error
 	AuthServerInfo(context.Context) (*AuthServerInfo, error)
-	ExchangeAuthCodeForProviderTokens(ctx context.Context, code string) (*IdentityProviderAuth, error)
+	// ExchangeAuthCodeForProviderTokens exchanges an authorization code for
+	// identity provider tokens. codeVerifier is the PKCE code verifier, and
+	// is empty when the authorization request did not use PKCE.
+	ExchangeAuthCodeForProviderTokens(ctx context.Context, code, codeVerifier string) (*IdentityProviderAuth, error)
 	RefreshAccessToken(ctx context.Context, providerUser *models.ProviderUser) (accessToken string, expiry *time.Time, err error)
 	GetUserInfo(ctx context.Context, providerUser *models.ProviderUser) (*UserInfoClaims, error)
 }
@@ -67,6 +70,10 @@ type oidcClientImplementation struct {
 }
 
 func NewOIDCClient(provider models.Provider, clientSecret, redirectURL string) OIDCClient {
+	if provider.Kind == models.ProviderKindGitHub {
+		return newGitHubClient(provider, clientSecret, redirectURL)
+	}
+
 	oidcClient := &oidcClientImplementation{
 		Domain:       provider.URL,
 		ClientID:     provider.ClientID,
@@ -194,8 +201,17 @@ func (o *oidcClientImplementation) tokenSource(ctx context.Context, conf *oauth2
 	return conf.TokenSource(ctx, userToken), nil
 }
 
+// codeVerifierOptions returns the options that send the PKCE code verifier
+// with the token request, when there is one.
+func codeVerifierOptions(codeVerifier string) []oauth2.AuthCodeOption {
+	if codeVerifier == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("code_verifier", codeVerifier)}
+}
+
 // ExchangeAuthCodeForProviderTokens exchanges the authorization code a user received on login for valid identity provider tokens
-func (o *oidcClientImplementation) ExchangeAuthCodeForProviderTokens(ctx context.Context, code string) (*IdentityProviderAuth, error) {
+func (o *oidcClientImplementation) ExchangeAuthCodeForProviderTokens(ctx context.Context, code, codeVerifier string) (*IdentityProviderAuth, error) {
 	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
 	defer cancel()
 
@@ -204,7 +220,7 @@ func (o *oidcClientImplementation) ExchangeAuthCodeForProviderTokens(ctx context
 		return nil, fmt.Errorf("client exchange code: %w", err)
 	}
 
-	exchanged, err := conf.Exchange(ctx, code)
+	exchanged, err := conf.Exchange(ctx, code, codeVerifierOptions(codeVerifier)...)
 	if err != nil {
 		return nil, fmt.Errorf("code exchange: %w", err)
 	}
@@ -276,12 +292,30 @@ func (o *oidcClientImplementation) RefreshAccessToken(ctx context.Context, provi
 
 	newToken, err := tokenSource.Token() // this refreshes token if needed
 	if err != nil {
-		return "", nil, fmt.Errorf("refresh user token: %w", err)
+		return "", nil, refreshTokenError(err)
 	}
 
 	return newToken.AccessToken, &newToken.Expiry, nil
 }
 
+// ErrRefreshTokenRevoked is returned by RefreshAccessToken when the identity
+// provider rejects the refresh token of the user, because the token was
+// revoked or the user was removed from the identity provider.
+var ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+
+// ErrAccessTokenRevoked is returned by GetUserInfo when the identity provider
+// rejects an access token that does not expire, because the token was revoked
+// or the user was removed from the identity provider.
+var ErrAccessTokenRevoked = errors.New("access token revoked")
+
+func refreshTokenError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		return fmt.Errorf("refresh user token: %w: %s", ErrRefreshTokenRevoked, err)
+	}
+	return fmt.Errorf("refresh user token: %w", err)
+}
+
 // GetUserInfo uses a provider token to call the OpenID Connect UserInfo endpoint,
 // make sure an access token is valid (not expired) before using this
 func (o *oidcClientImplementation) GetUserInfo(ctx context.Context, providerUser *models.ProviderUser) (*UserInfoClaims, error) {
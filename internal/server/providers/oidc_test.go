@@ -35,6 +35,8 @@ type testOIDCServer struct {
 	userInfoResponse string
 	tokenResponse    tokenResponse
 	signingKey       *rsa.PrivateKey
+	// codeVerifier is the code_verifier sent with the last token request
+	codeVerifier string
 }
 
 const (
@@ -96,6 +98,7 @@ func (ts *testOIDCServer) run(t *testing.T, addHandlers func(*testing.T, *http.S
 		assert.Check(t, err, "failed to write keys response")
 	})
 	newMux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		ts.codeVerifier = req.FormValue("code_verifier")
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(ts.tokenResponse.code)
 		_, err := io.WriteString(w, ts.tokenResponse.body)
@@ -466,10 +469,20 @@ func TestExchangeAuthCodeForProviderToken(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			server.tokenResponse = test.tokenResponse(t)
-			auth, err := test.provider.ExchangeAuthCodeForProviderTokens(ctx, "some-auth-code")
+			auth, err := test.provider.ExchangeAuthCodeForProviderTokens(ctx, "some-auth-code", "")
 			test.verifyFunc(t, auth, err)
+			assert.Equal(t, server.codeVerifier, "")
 		})
 	}
+
+	t.Run("with a PKCE code verifier", func(t *testing.T) {
+		server.tokenResponse = tests[len(tests)-1].tokenResponse(t)
+		provider := NewOIDCClient(models.Provider{Kind: models.ProviderKindOIDC, URL: serverURL, ClientID: "client-id"}, "some_client_secret", "http://127.0.0.1:51234/callback")
+		auth, err := provider.ExchangeAuthCodeForProviderTokens(ctx, "some-auth-code", "the-code-verifier")
+		assert.NilError(t, err)
+		assert.Equal(t, auth.Email, "hello@example.com")
+		assert.Equal(t, server.codeVerifier, "the-code-verifier")
+	})
 }
 
 func TestRefreshAccessToken(t *testing.T) {
@@ -511,6 +524,21 @@ func TestRefreshAccessToken(t *testing.T) {
 				assert.ErrorContains(t, err, "cannot fetch token")
 			},
 		},
+		{
+			name: "revoked refresh token fails",
+			providerUser: &models.ProviderUser{
+				AccessToken:  models.EncryptedAtRest("aaa"),
+				RefreshToken: models.EncryptedAtRest("bbb"),
+				ExpiresAt:    time.Now().UTC().Add(-5 * time.Minute),
+			},
+			tokenResponse: tokenResponse{
+				code: 400,
+				body: `{"error": "invalid_grant", "error_description": "The refresh token is invalid or expired."}`,
+			},
+			verifyFunc: func(t *testing.T, accessToken string, expiry *time.Time, err error) {
+				assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+			},
+		},
 		{
 			name: "valid access token is not refreshed",
 			providerUser: &models.ProviderUser{
@@ -0,0 +1,562 @@
+package providers
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"golang.org/x/exp/slices"
+
+	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+const (
+	samlNamespaceProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlNamespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	samlBindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlBindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlNameIDFormatEmail   = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	samlStatusSuccess       = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlConfirmationBearer  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+
+	// samlClockSkew is the difference in time allowed between the clock of the
+	// identity provider and the clock of the server
+	samlClockSkew = 3 * time.Minute
+
+	samlMaxMetadataSize = 1 << 20
+
+	// DefaultSAMLGroupsAttribute is the assertion attribute that contains the
+	// user's groups when the provider does not configure one.
+	DefaultSAMLGroupsAttribute = "groups"
+)
+
+// samlEmailAttributes are the assertion attribute names commonly used by
+// identity providers for the user's email address
+var samlEmailAttributes = []string{
+	"email",
+	"mail",
+	"emailaddress",
+	"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+	"urn:oid:0.9.2342.19200300.100.1.3",
+}
+
+// SAMLIdentityProvider is the configuration of a SAML identity provider, read
+// from its metadata.
+type SAMLIdentityProvider struct {
+	EntityID     string
+	SSOURL       string
+	Certificates []*x509.Certificate
+}
+
+type samlEntityDescriptor struct {
+	XMLName          xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID         string   `xml:"entityID,attr"`
+	IDPSSODescriptor *struct {
+		KeyDescriptors []struct {
+			Use          string   `xml:"use,attr"`
+			Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SingleSignOnServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// FetchSAMLMetadata retrieves the metadata of a SAML identity provider.
+func FetchSAMLMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcProviderRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml metadata url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch saml metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch saml metadata: unexpected status %v", resp.Status)
+	}
+	// metadata documents are small, limit the size to avoid reading an
+	// unexpectedly large response into memory
+	return io.ReadAll(io.LimitReader(resp.Body, samlMaxMetadataSize))
+}
+
+// ParseSAMLMetadata reads the entity ID, single sign-on URL, and signing
+// certificates from the metadata of a SAML identity provider.
+func ParseSAMLMetadata(metadata []byte) (*SAMLIdentityProvider, error) {
+	var descriptor samlEntityDescriptor
+	if err := xml.Unmarshal(metadata, &descriptor); err != nil {
+		return nil, fmt.Errorf("invalid saml metadata: %w", err)
+	}
+
+	switch {
+	case descriptor.EntityID == "":
+		return nil, fmt.Errorf("saml metadata is missing an entityID")
+	case descriptor.IDPSSODescriptor == nil:
+		return nil, fmt.Errorf("saml metadata is missing an IDPSSODescriptor")
+	}
+
+	idp := &SAMLIdentityProvider{EntityID: descriptor.EntityID}
+	for _, sso := range descriptor.IDPSSODescriptor.SingleSignOnServices {
+		if sso.Binding == samlBindingHTTPRedirect {
+			idp.SSOURL = sso.Location
+			break
+		}
+	}
+	if idp.SSOURL == "" {
+		return nil, fmt.Errorf("saml metadata is missing a SingleSignOnService with the HTTP-Redirect binding")
+	}
+
+	for _, key := range descriptor.IDPSSODescriptor.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		for _, encoded := range key.Certificates {
+			raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+			if err != nil {
+				return nil, fmt.Errorf("invalid saml metadata certificate: %w", err)
+			}
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid saml metadata certificate: %w", err)
+			}
+			idp.Certificates = append(idp.Certificates, cert)
+		}
+	}
+	if len(idp.Certificates) == 0 {
+		return nil, fmt.Errorf("saml metadata is missing a signing certificate")
+	}
+
+	return idp, nil
+}
+
+// SAMLServiceProvider is the SAML service provider for a single provider. It
+// creates authentication requests for the identity provider, and validates the
+// responses sent to the assertion consumer service (ACS) URL.
+type SAMLServiceProvider struct {
+	EntityID         string
+	ACSURL           string
+	GroupsAttribute  string
+	IdentityProvider *SAMLIdentityProvider
+}
+
+// NewSAMLServiceProvider returns the service provider for a provider of kind
+// SAML. baseURL is the scheme and host used to reach the server.
+func NewSAMLServiceProvider(provider models.Provider, baseURL string) (*SAMLServiceProvider, error) {
+	idp, err := ParseSAMLMetadata([]byte(provider.SAMLMetadata))
+	if err != nil {
+		return nil, err
+	}
+
+	groupsAttribute := provider.SAMLGroupsAttribute
+	if groupsAttribute == "" {
+		groupsAttribute = DefaultSAMLGroupsAttribute
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &SAMLServiceProvider{
+		EntityID:         fmt.Sprintf("%s/api/providers/%s/saml/metadata", baseURL, provider.ID),
+		ACSURL:           fmt.Sprintf("%s/api/providers/%s/saml/acs", baseURL, provider.ID),
+		GroupsAttribute:  groupsAttribute,
+		IdentityProvider: idp,
+	}, nil
+}
+
+type samlSPMetadata struct {
+	XMLName         xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string   `xml:"entityID,attr"`
+	SPSSODescriptor struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		AssertionConsumerService   struct {
+			Binding   string `xml:"Binding,attr"`
+			Location  string `xml:"Location,attr"`
+			Index     int    `xml:"index,attr"`
+			IsDefault bool   `xml:"isDefault,attr"`
+		} `xml:"AssertionConsumerService"`
+	} `xml:"SPSSODescriptor"`
+}
+
+// Metadata returns the metadata of the service provider, which is used to
+// configure the application in the identity provider.
+func (sp *SAMLServiceProvider) Metadata() ([]byte, error) {
+	metadata := samlSPMetadata{EntityID: sp.EntityID}
+	metadata.SPSSODescriptor.WantAssertionsSigned = true
+	metadata.SPSSODescriptor.ProtocolSupportEnumeration = samlNamespaceProtocol
+	metadata.SPSSODescriptor.NameIDFormat = samlNameIDFormatEmail
+	metadata.SPSSODescriptor.AssertionConsumerService.Binding = samlBindingHTTPPost
+	metadata.SPSSODescriptor.AssertionConsumerService.Location = sp.ACSURL
+	metadata.SPSSODescriptor.AssertionConsumerService.IsDefault = true
+
+	raw, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), raw...), nil
+}
+
+type samlAuthnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameIDPolicy                struct {
+		Format      string `xml:"Format,attr"`
+		AllowCreate bool   `xml:"AllowCreate,attr"`
+	} `xml:"NameIDPolicy"`
+}
+
+// AuthnRequestURL returns the URL used to start a login at the identity
+// provider, using the HTTP-Redirect binding, and the ID of the authentication
+// request. The response from the identity provider must be in response to this
+// ID. relayState is returned unmodified by the identity provider along with
+// its response.
+func (sp *SAMLServiceProvider) AuthnRequestURL(relayState string) (string, string, error) {
+	id, err := generate.CryptoRandom(32, generate.CharsetAlphaNumeric)
+	if err != nil {
+		return "", "", err
+	}
+
+	req := samlAuthnRequest{
+		ID:                          "id-" + id,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 sp.IdentityProvider.SSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             samlBindingHTTPPost,
+		Issuer:                      sp.EntityID,
+	}
+	req.NameIDPolicy.Format = samlNameIDFormatEmail
+	req.NameIDPolicy.AllowCreate = true
+
+	raw, err := xml.Marshal(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return "", "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(sp.IdentityProvider.SSOURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid saml sso url: %w", err)
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), req.ID, nil
+}
+
+// SAMLResponse is the result of a validated SAML response.
+type SAMLResponse struct {
+	// AssertionID is the ID of the assertion. An assertion must only be
+	// used once.
+	AssertionID string
+	Claims      *UserInfoClaims
+}
+
+// ParseResponse validates a base64 encoded SAML response sent to the ACS URL
+// by the identity provider, and returns the user's email and groups from the
+// assertion. Either the response or the assertion must be signed by the
+// identity provider. The response must be in response to the authentication
+// request with requestID, responses that were not requested by the service
+// provider are rejected.
+func (sp *SAMLServiceProvider) ParseResponse(encoded string, requestID string, now time.Time) (*SAMLResponse, error) {
+	if requestID == "" {
+		// logins started by the identity provider are not supported
+		return nil, fmt.Errorf("saml response is not in response to a login request")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml response encoding: %w", err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, fmt.Errorf("invalid saml response: %w", err)
+	}
+	response := doc.Root()
+	if response == nil {
+		return nil, fmt.Errorf("invalid saml response: missing root element")
+	}
+	if response.NamespaceURI() != samlNamespaceProtocol || response.Tag != "Response" {
+		return nil, fmt.Errorf("invalid saml response: unexpected element <%v>", response.Tag)
+	}
+
+	if destination := response.SelectAttrValue("Destination", ""); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("saml response destination %q does not match %q", destination, sp.ACSURL)
+	}
+	if inResponseTo := response.SelectAttrValue("InResponseTo", ""); inResponseTo != "" && inResponseTo != requestID {
+		return nil, fmt.Errorf("saml response is not in response to the login request")
+	}
+
+	if status := samlStatusCode(response); status != samlStatusSuccess {
+		return nil, fmt.Errorf("saml response status is %v", status)
+	}
+
+	if samlChild(response, samlNamespaceAssertion, "EncryptedAssertion") != nil {
+		return nil, fmt.Errorf("encrypted saml assertions are not supported")
+	}
+	assertion, err := samlAssertion(response)
+	if err != nil {
+		return nil, err
+	}
+
+	// all data must be read from the copy of a signed element returned by
+	// the signature validation, the assertion is signed either directly or
+	// as part of the response
+	var signed bool
+	signedResponse, err := sp.validateSignature(response, now)
+	switch {
+	case errors.Is(err, dsig.ErrMissingSignature):
+	case err != nil:
+		return nil, fmt.Errorf("saml response signature: %w", err)
+	default:
+		signed = true
+		if assertion, err = samlAssertion(signedResponse); err != nil {
+			return nil, err
+		}
+	}
+
+	signedAssertion, err := sp.validateSignature(assertion, now)
+	switch {
+	case errors.Is(err, dsig.ErrMissingSignature):
+	case err != nil:
+		return nil, fmt.Errorf("saml assertion signature: %w", err)
+	default:
+		signed = true
+		assertion = signedAssertion
+	}
+	if !signed {
+		return nil, fmt.Errorf("saml response is not signed")
+	}
+
+	if err := sp.validateAssertion(assertion, requestID, now); err != nil {
+		return nil, err
+	}
+
+	claims := &UserInfoClaims{}
+	var nameID string
+	if subject := samlChild(assertion, samlNamespaceAssertion, "Subject"); subject != nil {
+		if el := samlChild(subject, samlNamespaceAssertion, "NameID"); el != nil {
+			nameID = strings.TrimSpace(el.Text())
+		}
+	}
+
+	if statement := samlChild(assertion, samlNamespaceAssertion, "AttributeStatement"); statement != nil {
+		for _, attr := range samlChildren(statement, samlNamespaceAssertion, "Attribute") {
+			name, friendlyName := attr.SelectAttrValue("Name", ""), attr.SelectAttrValue("FriendlyName", "")
+			var values []string
+			for _, value := range samlChildren(attr, samlNamespaceAssertion, "AttributeValue") {
+				if v := strings.TrimSpace(value.Text()); v != "" {
+					values = append(values, v)
+				}
+			}
+
+			switch {
+			case name == sp.GroupsAttribute || friendlyName == sp.GroupsAttribute:
+				claims.Groups = append(claims.Groups, values...)
+			case claims.Email == "" && len(values) > 0 && isSAMLEmailAttribute(name, friendlyName):
+				claims.Email = values[0]
+			}
+		}
+	}
+
+	if claims.Email == "" && strings.Contains(nameID, "@") {
+		claims.Email = nameID
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("saml assertion does not contain an email address")
+	}
+
+	return &SAMLResponse{
+		AssertionID: assertion.SelectAttrValue("ID", ""),
+		Claims:      claims,
+	}, nil
+}
+
+// validateSignature checks that el is signed by one of the certificates of the
+// identity provider. It returns the signed copy of el, which is the only copy
+// that may be trusted. dsig.ErrMissingSignature is returned if el is not signed.
+func (sp *SAMLServiceProvider) validateSignature(el *etree.Element, now time.Time) (*etree.Element, error) {
+	// the signature is validated on a copy of el, so any namespaces declared
+	// by its parents must be declared on the copy
+	nsCtx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(nsCtx, el)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: sp.IdentityProvider.Certificates,
+	})
+	validation.IdAttribute = "ID"
+	validation.Clock = dsig.NewFakeClockAt(now)
+	return validation.Validate(detached)
+}
+
+func (sp *SAMLServiceProvider) validateAssertion(assertion *etree.Element, requestID string, now time.Time) error {
+	if assertion.SelectAttrValue("ID", "") == "" {
+		return fmt.Errorf("saml assertion is missing an ID")
+	}
+
+	issuer := samlChild(assertion, samlNamespaceAssertion, "Issuer")
+	if issuer == nil || strings.TrimSpace(issuer.Text()) != sp.IdentityProvider.EntityID {
+		return fmt.Errorf("saml assertion issuer does not match the identity provider")
+	}
+
+	conditions := samlChild(assertion, samlNamespaceAssertion, "Conditions")
+	if conditions == nil {
+		return fmt.Errorf("saml assertion is missing conditions")
+	}
+	if err := checkSAMLTimeRange(conditions, now); err != nil {
+		return fmt.Errorf("saml assertion conditions: %w", err)
+	}
+	for _, restriction := range samlChildren(conditions, samlNamespaceAssertion, "AudienceRestriction") {
+		var audiences []string
+		for _, audience := range samlChildren(restriction, samlNamespaceAssertion, "Audience") {
+			audiences = append(audiences, strings.TrimSpace(audience.Text()))
+		}
+		if !slices.Contains(audiences, sp.EntityID) {
+			return fmt.Errorf("saml assertion audience does not include %v", sp.EntityID)
+		}
+	}
+
+	subject := samlChild(assertion, samlNamespaceAssertion, "Subject")
+	if subject == nil {
+		return fmt.Errorf("saml assertion is missing a subject")
+	}
+	for _, confirmation := range samlChildren(subject, samlNamespaceAssertion, "SubjectConfirmation") {
+		if confirmation.SelectAttrValue("Method", "") != samlConfirmationBearer {
+			continue
+		}
+		data := samlChild(confirmation, samlNamespaceAssertion, "SubjectConfirmationData")
+		if data == nil || data.SelectAttrValue("Recipient", "") != sp.ACSURL {
+			continue
+		}
+		if data.SelectAttrValue("InResponseTo", "") != requestID {
+			continue
+		}
+		if checkSAMLTimeRange(data, now) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("saml assertion subject has no valid bearer confirmation for %v", sp.ACSURL)
+}
+
+// checkSAMLTimeRange checks the NotBefore and NotOnOrAfter attributes of el,
+// if they are set.
+func checkSAMLTimeRange(el *etree.Element, now time.Time) error {
+	if value := el.SelectAttrValue("NotBefore", ""); value != "" {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid NotBefore: %w", err)
+		}
+		if now.Add(samlClockSkew).Before(notBefore) {
+			return fmt.Errorf("not valid before %v", notBefore)
+		}
+	}
+	if value := el.SelectAttrValue("NotOnOrAfter", ""); value != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid NotOnOrAfter: %w", err)
+		}
+		if !now.Add(-samlClockSkew).Before(notOnOrAfter) {
+			return fmt.Errorf("expired at %v", notOnOrAfter)
+		}
+	}
+	return nil
+}
+
+func samlAssertion(response *etree.Element) (*etree.Element, error) {
+	assertions := samlChildren(response, samlNamespaceAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("saml response must contain exactly one assertion")
+	}
+	return assertions[0], nil
+}
+
+func samlStatusCode(response *etree.Element) string {
+	status := samlChild(response, samlNamespaceProtocol, "Status")
+	if status == nil {
+		return ""
+	}
+	code := samlChild(status, samlNamespaceProtocol, "StatusCode")
+	if code == nil {
+		return ""
+	}
+	return code.SelectAttrValue("Value", "")
+}
+
+// samlChild returns the first child element of el with the namespace and tag,
+// or nil if there is no such element.
+func samlChild(el *etree.Element, space, tag string) *etree.Element {
+	if children := samlChildren(el, space, tag); len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
+// samlChildren returns the child elements of el with the namespace and tag.
+func samlChildren(el *etree.Element, space, tag string) []*etree.Element {
+	var result []*etree.Element
+	for _, child := range el.ChildElements() {
+		if child.Tag == tag && child.NamespaceURI() == space {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+func isSAMLEmailAttribute(names ...string) bool {
+	for _, name := range names {
+		for _, attr := range samlEmailAttributes {
+			if strings.EqualFold(name, attr) {
+				return true
+			}
+		}
+	}
+	return false
+}
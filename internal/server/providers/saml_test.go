@@ -0,0 +1,313 @@
+package providers
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/testing/samlidp"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestParseSAMLMetadata(t *testing.T) {
+	idp := samlidp.New(t)
+
+	t.Run("valid metadata", func(t *testing.T) {
+		actual, err := ParseSAMLMetadata([]byte(idp.Metadata()))
+		assert.NilError(t, err)
+		assert.Equal(t, actual.EntityID, samlidp.EntityID)
+		assert.Equal(t, actual.SSOURL, samlidp.SSOURL)
+		assert.Equal(t, len(actual.Certificates), 1)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		metadata := `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com">
+  <md:IDPSSODescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+		_, err := ParseSAMLMetadata([]byte(metadata))
+		assert.Error(t, err, "saml metadata is missing a signing certificate")
+	})
+
+	t.Run("not metadata", func(t *testing.T) {
+		_, err := ParseSAMLMetadata([]byte(`<html></html>`))
+		assert.ErrorContains(t, err, "invalid saml metadata")
+	})
+}
+
+func newTestSAMLServiceProvider(t *testing.T, idp *samlidp.IdentityProvider) *SAMLServiceProvider {
+	t.Helper()
+	provider := models.Provider{
+		Model:        models.Model{ID: uid.ID(1234)},
+		Kind:         models.ProviderKindSAML,
+		SAMLMetadata: idp.Metadata(),
+	}
+	sp, err := NewSAMLServiceProvider(provider, "https://infra.example.com/")
+	assert.NilError(t, err)
+	return sp
+}
+
+func TestSAMLServiceProvider_Metadata(t *testing.T) {
+	sp := newTestSAMLServiceProvider(t, samlidp.New(t))
+	assert.Equal(t, sp.EntityID, "https://infra.example.com/api/providers/nh/saml/metadata")
+	assert.Equal(t, sp.ACSURL, "https://infra.example.com/api/providers/nh/saml/acs")
+
+	raw, err := sp.Metadata()
+	assert.NilError(t, err)
+
+	var metadata samlSPMetadata
+	assert.NilError(t, xml.Unmarshal(raw, &metadata))
+	assert.Equal(t, metadata.EntityID, sp.EntityID)
+	assert.Equal(t, metadata.SPSSODescriptor.AssertionConsumerService.Location, sp.ACSURL)
+	assert.Equal(t, metadata.SPSSODescriptor.AssertionConsumerService.Binding, samlBindingHTTPPost)
+}
+
+func TestSAMLServiceProvider_AuthnRequestURL(t *testing.T) {
+	sp := newTestSAMLServiceProvider(t, samlidp.New(t))
+
+	raw, requestID, err := sp.AuthnRequestURL("/device?code=ABCD")
+	assert.NilError(t, err)
+
+	u, err := url.Parse(raw)
+	assert.NilError(t, err)
+	assert.Equal(t, u.Scheme+"://"+u.Host+u.Path, samlidp.SSOURL)
+	assert.Equal(t, u.Query().Get("RelayState"), "/device?code=ABCD")
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	assert.NilError(t, err)
+	decoded, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	assert.NilError(t, err)
+
+	var req samlAuthnRequest
+	assert.NilError(t, xml.Unmarshal(decoded, &req))
+	assert.Equal(t, req.Issuer, sp.EntityID)
+	assert.Equal(t, req.AssertionConsumerServiceURL, sp.ACSURL)
+	assert.Equal(t, req.Destination, samlidp.SSOURL)
+	assert.Assert(t, strings.HasPrefix(req.ID, "id-"))
+	assert.Equal(t, req.ID, requestID)
+}
+
+func TestSAMLServiceProvider_ParseResponse(t *testing.T) {
+	idp := samlidp.New(t)
+	sp := newTestSAMLServiceProvider(t, idp)
+
+	validAssertion := func() samlidp.Assertion {
+		return samlidp.Assertion{
+			Recipient:    sp.ACSURL,
+			Audience:     sp.EntityID,
+			InResponseTo: "id-request",
+			NameID:       "alice@example.com",
+			Groups:       []string{"Developers", "Everyone"},
+		}
+	}
+
+	type testCase struct {
+		name     string
+		response func(t *testing.T) string
+		expected func(t *testing.T, claims *UserInfoClaims, err error)
+	}
+
+	testCases := []testCase{
+		{
+			name: "signed assertion",
+			response: func(t *testing.T) string {
+				return idp.Response(t, validAssertion())
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.NilError(t, err)
+				assert.DeepEqual(t, claims, &UserInfoClaims{
+					Email:  "alice@example.com",
+					Groups: []string{"Developers", "Everyone"},
+				})
+			},
+		},
+		{
+			name: "signed response",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.SignResponse = true
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.NilError(t, err)
+				assert.Equal(t, claims.Email, "alice@example.com")
+			},
+		},
+		{
+			name: "email attribute is preferred to the name ID",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.NameID = "00u1234"
+				a.Email = "bob@example.com"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.NilError(t, err)
+				assert.Equal(t, claims.Email, "bob@example.com")
+			},
+		},
+		{
+			name: "no email",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.NameID = "00u1234"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.Error(t, err, "saml assertion does not contain an email address")
+			},
+		},
+		{
+			name: "groups from a different attribute",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.GroupsAttribute = "memberOf"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.NilError(t, err)
+				assert.Equal(t, len(claims.Groups), 0)
+			},
+		},
+		{
+			name: "wrong audience",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.Audience = "https://other.example.com"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.ErrorContains(t, err, "saml assertion audience does not include")
+			},
+		},
+		{
+			name: "wrong recipient",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.Recipient = "https://other.example.com/acs"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.ErrorContains(t, err, "saml response destination")
+			},
+		},
+		{
+			name: "wrong issuer",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.Issuer = "https://other.example.com"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.Error(t, err, "saml assertion issuer does not match the identity provider")
+			},
+		},
+		{
+			name: "expired",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.NotOnOrAfter = time.Now().Add(-5 * time.Minute)
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.ErrorContains(t, err, "saml assertion conditions: expired at")
+			},
+		},
+		{
+			name: "in response to a different request",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.InResponseTo = "id-other"
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.Error(t, err, "saml response is not in response to the login request")
+			},
+		},
+		{
+			name: "not in response to a request",
+			response: func(t *testing.T) string {
+				a := validAssertion()
+				a.InResponseTo = ""
+				return idp.Response(t, a)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.ErrorContains(t, err, "saml assertion subject has no valid bearer confirmation")
+			},
+		},
+		{
+			name: "signed by a different identity provider",
+			response: func(t *testing.T) string {
+				return samlidp.New(t).Response(t, validAssertion())
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.Error(t, err, "saml assertion signature: Could not verify certificate against trusted certs")
+			},
+		},
+		{
+			name: "modified after signing",
+			response: func(t *testing.T) string {
+				raw, err := base64.StdEncoding.DecodeString(idp.Response(t, validAssertion()))
+				assert.NilError(t, err)
+				raw = bytes.Replace(raw, []byte("alice@example.com"), []byte("mallory@example.com"), 1)
+				return base64.StdEncoding.EncodeToString(raw)
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.Error(t, err, "saml assertion signature: Signature could not be verified")
+			},
+		},
+		{
+			name: "not base64",
+			response: func(t *testing.T) string {
+				return "<samlp:Response>"
+			},
+			expected: func(t *testing.T, claims *UserInfoClaims, err error) {
+				assert.ErrorContains(t, err, "invalid saml response encoding")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := sp.ParseResponse(tc.response(t), "id-request", time.Now())
+			var claims *UserInfoClaims
+			if resp != nil {
+				assert.Assert(t, resp.AssertionID != "")
+				claims = resp.Claims
+			}
+			tc.expected(t, claims, err)
+		})
+	}
+}
+
+func TestFetchSAMLMetadata(t *testing.T) {
+	idp := samlidp.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(idp.Metadata()))
+	}))
+	t.Cleanup(srv.Close)
+
+	metadata, err := FetchSAMLMetadata(context.Background(), srv.URL+"/metadata")
+	assert.NilError(t, err)
+	assert.Equal(t, string(metadata), idp.Metadata())
+
+	_, err = FetchSAMLMetadata(context.Background(), srv.URL+"/missing")
+	assert.Error(t, err, "fetch saml metadata: unexpected status 404 Not Found")
+}
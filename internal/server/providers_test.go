@@ -17,6 +17,7 @@ import (
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/uid"
 )
 
 func TestAPI_ListProviders(t *testing.T) {
@@ -28,6 +29,9 @@ func TestAPI_ListProviders(t *testing.T) {
 		Kind:    models.ProviderKindOkta,
 		AuthURL: "https://example.com/v1/auth",
 		Scopes:  []string{"openid", "email"},
+		GroupMapping: models.GroupMapping{
+			Include: "^AAD-",
+		},
 	}
 
 	err := data.CreateProvider(s.DB(), testProvider)
@@ -110,6 +114,7 @@ func TestAPI_GetProvider(t *testing.T) {
 		assert.Equal(t, provider.Name, testProvider.Name)
 		assert.Equal(t, provider.AuthURL, testProvider.AuthURL)
 		assert.Assert(t, slices.Equal(provider.Scopes, testProvider.Scopes))
+		assert.DeepEqual(t, provider.GroupMapping, &api.ProviderGroupMapping{Include: "^AAD-"}, cmpopts.EquateEmpty())
 	})
 	t.Run("get provider with no access key for org returns provider without fields", func(t *testing.T) {
 		// nolint:noctx
@@ -130,6 +135,7 @@ func TestAPI_GetProvider(t *testing.T) {
 		assert.Equal(t, provider.Name, testProvider.Name)
 		assert.Equal(t, provider.AuthURL, testProvider.AuthURL)
 		assert.Assert(t, slices.Equal(provider.Scopes, testProvider.Scopes))
+		assert.Assert(t, provider.GroupMapping == nil)
 	})
 	t.Run("get provider with expired access key for org returns provider without fields", func(t *testing.T) {
 		// nolint:noctx
@@ -364,7 +370,34 @@ func TestAPI_CreateProvider(t *testing.T) {
 				assert.NilError(t, err)
 
 				expected := []api.FieldError{
-					{FieldName: "kind", Errors: []string{"must be one of (oidc, okta, azure, google)"}},
+					{FieldName: "kind", Errors: []string{"must be one of (oidc, okta, azure, google, saml, ldap, github)"}},
+				}
+				assert.DeepEqual(t, respBody.FieldErrors, expected)
+			},
+		},
+		{
+			name: "invalid group mapping",
+			body: api.CreateProviderRequest{
+				Name:         "okta",
+				URL:          "https://example.com",
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				Kind:         string(models.ProviderKindOkta),
+				GroupMapping: &api.ProviderGroupMapping{
+					Include: "AAD-(",
+					Rename:  []api.GroupRenameRule{{Replace: "$1"}},
+				},
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+				respBody := &api.Error{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+
+				expected := []api.FieldError{
+					{FieldName: "groupMapping.include", Errors: []string{"invalid regular expression: error parsing regexp: missing closing ): `AAD-(`"}},
+					{FieldName: "groupMapping.rename[0].match", Errors: []string{"is required"}},
 				}
 				assert.DeepEqual(t, respBody.FieldErrors, expected)
 			},
@@ -446,6 +479,32 @@ func TestAPI_CreateProvider(t *testing.T) {
 				assert.Assert(t, respBody.Name != string(models.ProviderKindGoogle))
 			},
 		},
+		{
+			name: "github provider defaults to github.com",
+			body: api.CreateProviderRequest{
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				Kind:         string(models.ProviderKindGitHub),
+				GitHub:       &api.ProviderGitHub{Organizations: []string{"infrahq"}},
+			},
+			setup: func(t *testing.T, req *http.Request) {
+				ctx := providers.WithOIDCClient(req.Context(), &fakeOIDCImplementation{})
+				*req = *req.WithContext(ctx)
+			},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+				respBody := &api.Provider{}
+				err := json.Unmarshal(resp.Body.Bytes(), respBody)
+				assert.NilError(t, err)
+				assert.Equal(t, respBody.URL, "github.com")
+				assert.Equal(t, respBody.Kind, "github")
+
+				provider, err := data.GetProvider(srv.DB(), data.GetProviderOptions{ByID: respBody.ID})
+				assert.NilError(t, err)
+				assert.DeepEqual(t, provider.GitHubOrganizations, models.CommaSeparatedStrings{"infrahq"})
+			},
+		},
 		{
 			name: "valid provider (name is provided)",
 			body: api.CreateProviderRequest{
@@ -598,7 +657,7 @@ func TestAPI_UpdateProvider(t *testing.T) {
 				assert.NilError(t, err)
 
 				expected := []api.FieldError{
-					{FieldName: "kind", Errors: []string{"must be one of (oidc, okta, azure, google)"}},
+					{FieldName: "kind", Errors: []string{"must be one of (oidc, okta, azure, google, saml, ldap, github)"}},
 				}
 				assert.DeepEqual(t, respBody.FieldErrors, expected)
 			},
@@ -757,11 +816,177 @@ func TestAPI_PatchProvider(t *testing.T) {
 	}
 }
 
+func TestAPI_PreviewGroupMapping(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	provider := &models.Provider{
+		Name:         "okta",
+		Kind:         models.ProviderKindOkta,
+		ClientSecret: "secret",
+		GroupMapping: models.GroupMapping{
+			Include: "^AAD-",
+			Rename:  []models.GroupRenameRule{{Match: "^AAD-(.+)-RW$", Replace: "$1"}},
+		},
+	}
+	assert.NilError(t, data.CreateProvider(srv.DB(), provider))
+
+	saml := &models.Provider{Name: "saml", Kind: models.ProviderKindSAML}
+	assert.NilError(t, data.CreateProvider(srv.DB(), saml))
+
+	user := &models.Identity{Name: "user@example.com"}
+	createIdentities(t, srv.DB(), user)
+
+	providerUser, err := data.CreateProviderUser(srv.DB(), provider, user)
+	assert.NilError(t, err)
+	providerUser.RefreshToken = "ref"
+	providerUser.AccessToken = "acc"
+	providerUser.ExpiresAt = time.Now().Add(time.Hour)
+	assert.NilError(t, data.UpdateProviderUser(srv.DB(), providerUser))
+
+	type testCase struct {
+		name     string
+		id       uid.ID
+		body     api.PreviewGroupMappingRequest
+		expected func(t *testing.T, resp *httptest.ResponseRecorder)
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		id := tc.id
+		if id == 0 {
+			id = provider.ID
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/providers/"+id.String()+"/group-mapping/preview", jsonBody(t, tc.body))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		oidc := &fakeOIDCImplementation{UserGroups: []string{"AAD-Platform-RW", "Everyone"}}
+		*req = *req.WithContext(providers.WithOIDCClient(req.Context(), oidc))
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		tc.expected(t, resp)
+	}
+
+	expectGroups := func(providerGroups, groups []string) func(t *testing.T, resp *httptest.ResponseRecorder) {
+		return func(t *testing.T, resp *httptest.ResponseRecorder) {
+			assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+			var respBody api.PreviewGroupMappingResponse
+			assert.NilError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+			expected := api.PreviewGroupMappingResponse{ProviderGroups: providerGroups, Groups: groups}
+			assert.DeepEqual(t, respBody, expected, cmpopts.EquateEmpty())
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name:     "groups of a user",
+			body:     api.PreviewGroupMappingRequest{UserID: user.ID},
+			expected: expectGroups([]string{"AAD-Platform-RW", "Everyone"}, []string{"Platform"}),
+		},
+		{
+			name: "groups with a new group mapping",
+			body: api.PreviewGroupMappingRequest{
+				Groups:       []string{"AAD-Platform-RW", "Everyone"},
+				GroupMapping: &api.ProviderGroupMapping{Exclude: "^AAD-", PrefixProviderName: true},
+			},
+			expected: expectGroups([]string{"AAD-Platform-RW", "Everyone"}, []string{"okta/Everyone"}),
+		},
+		{
+			name:     "groups of a provider without an OIDC client",
+			id:       saml.ID,
+			body:     api.PreviewGroupMappingRequest{Groups: []string{"Everyone"}},
+			expected: expectGroups([]string{"Everyone"}, []string{"Everyone"}),
+		},
+		{
+			name: "user of a provider without an OIDC client",
+			id:   saml.ID,
+			body: api.PreviewGroupMappingRequest{UserID: user.ID},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+			},
+		},
+		{
+			name: "missing user and groups",
+			body: api.PreviewGroupMappingRequest{},
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
+
+func TestAPI_DiscoverLogin(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	okta := &models.Provider{
+		Name:    "okta",
+		Kind:    models.ProviderKindOkta,
+		AuthURL: "https://example.okta.com/oauth2/v1/authorize",
+		Domains: []string{"example.com", "example.org"},
+	}
+	assert.NilError(t, data.CreateProvider(srv.DB(), okta))
+	ldap := &models.Provider{Name: "directory", Kind: models.ProviderKindLDAP, Domains: []string{"example.org"}}
+	assert.NilError(t, data.CreateProvider(srv.DB(), ldap))
+
+	discover := func(t *testing.T, email string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := jsonBody(t, api.DiscoverLoginRequest{Email: email})
+		req := httptest.NewRequest(http.MethodPost, "/api/login/discover", body)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	names := func(t *testing.T, resp *httptest.ResponseRecorder) []string {
+		t.Helper()
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var respBody api.DiscoverLoginResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+		result := []string{}
+		for _, provider := range respBody.Providers {
+			result = append(result, provider.Name)
+		}
+		return result
+	}
+
+	t.Run("one provider", func(t *testing.T) {
+		resp := discover(t, "dana@Example.com")
+		assert.DeepEqual(t, names(t, resp), []string{"okta"})
+	})
+	t.Run("several providers", func(t *testing.T) {
+		resp := discover(t, "dana@example.org")
+		assert.DeepEqual(t, names(t, resp), []string{"directory", "okta"})
+	})
+	t.Run("no providers", func(t *testing.T) {
+		resp := discover(t, "dana@other.example.com")
+		assert.DeepEqual(t, names(t, resp), []string{})
+	})
+	t.Run("invalid email", func(t *testing.T) {
+		resp := discover(t, "dana")
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+}
+
 // mockOIDC is a fake oidc identity provider
 type fakeOIDCImplementation struct {
-	UserInfoRevoked bool   // when true returns an error fromt the user info endpoint
-	FailExchange    bool   // when true auth code exchange fails
-	UserEmail       string // the email returned from the fake identity provider
+	UserInfoRevoked bool     // when true returns an error fromt the user info endpoint
+	UserInfoErr     error    // the error returned from the user info endpoint, when set
+	RefreshRevoked  bool     // when true the refresh token was revoked by the identity provider
+	FailExchange    bool     // when true auth code exchange fails
+	UserEmail       string   // the email returned from the fake identity provider
+	UserGroups      []string // the groups returned from the fake identity provider
 }
 
 func (m *fakeOIDCImplementation) Validate(_ context.Context) error {
@@ -772,7 +997,7 @@ func (m *fakeOIDCImplementation) AuthServerInfo(_ context.Context) (*providers.A
 	return &providers.AuthServerInfo{AuthURL: "example.com/v1/auth", ScopesSupported: []string{"openid", "email"}}, nil
 }
 
-func (m *fakeOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _ string) (*providers.IdentityProviderAuth, error) {
+func (m *fakeOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Context, _, _ string) (*providers.IdentityProviderAuth, error) {
 	if m.FailExchange {
 		return nil, fmt.Errorf("invalid auth code")
 	}
@@ -785,6 +1010,9 @@ func (m *fakeOIDCImplementation) ExchangeAuthCodeForProviderTokens(_ context.Con
 }
 
 func (m *fakeOIDCImplementation) RefreshAccessToken(_ context.Context, providerUser *models.ProviderUser) (accessToken string, expiry *time.Time, err error) {
+	if m.RefreshRevoked {
+		return "", nil, providers.ErrRefreshTokenRevoked
+	}
 	// never update
 	return string(providerUser.AccessToken), &providerUser.ExpiresAt, nil
 }
@@ -793,5 +1021,8 @@ func (m *fakeOIDCImplementation) GetUserInfo(_ context.Context, _ *models.Provid
 	if m.UserInfoRevoked {
 		return nil, fmt.Errorf("user revoked")
 	}
-	return &providers.UserInfoClaims{}, nil
+	if m.UserInfoErr != nil {
+		return nil, m.UserInfoErr
+	}
+	return &providers.UserInfoClaims{Groups: m.UserGroups}, nil
 }
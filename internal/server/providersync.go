@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/uid"
+)
+
+// directorySyncInterval is how often the users of each provider are synced
+// with the identity provider in the background.
+var directorySyncInterval = time.Hour
+
+func (a *API) GetProviderSync(rCtx access.RequestContext, r *api.Resource) (*api.ProviderSync, error) {
+	sync, err := access.GetProviderSync(rCtx, r.ID)
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		// the provider exists, but it has not been synced yet
+		return &api.ProviderSync{ProviderID: r.ID, Status: "idle"}, nil
+	case err != nil:
+		return nil, err
+	}
+	return sync.ToAPI(), nil
+}
+
+func (a *API) SyncProvider(rCtx access.RequestContext, r *api.Resource) (*api.ProviderSync, error) {
+	provider, err := access.GetProviderToSync(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sync, users, err := a.server.startProviderSync(rCtx.Request.Context(), provider)
+	if err != nil {
+		if errors.Is(err, data.ErrProviderSyncRunning) {
+			return nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+		}
+		return nil, err
+	}
+
+	// the sync can take longer than the request, so it continues in the
+	// background, and its result is returned by GetProviderSync
+	resp := sync.ToAPI()
+	a.server.runInBackground(func(ctx context.Context) {
+		sync, err := a.server.finishProviderSync(ctx, provider, sync, users)
+		logProviderSync(provider, sync, err)
+	})
+	return resp, nil
+}
+
+// runDirectorySync starts a directory sync of every provider that has not
+// been synced in the last directorySyncInterval.
+func (s *Server) runDirectorySync(ctx context.Context) func() error {
+	return func() error {
+		t := time.NewTicker(directorySyncInterval / 6)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				if err := s.syncProviders(ctx); err != nil {
+					logging.Errorf("directory sync: %s", err)
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func (s *Server) syncProviders(ctx context.Context) error {
+	tx, err := s.db.Begin(ctx, nil)
+	if err != nil {
+		return err
+	}
+	toSync, err := data.ListProvidersToSync(tx, directorySyncInterval)
+	_ = tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("list providers: %w", err)
+	}
+
+	for i := range toSync {
+		provider := &toSync[i]
+		sync, users, err := s.startProviderSync(ctx, provider)
+		if errors.Is(err, data.ErrProviderSyncRunning) {
+			// another server started the sync of this provider
+			continue
+		}
+		if err == nil {
+			sync, err = s.finishProviderSync(ctx, provider, sync, users)
+		}
+		logProviderSync(provider, sync, err)
+	}
+	return nil
+}
+
+func logProviderSync(provider *models.Provider, sync *models.ProviderSync, err error) {
+	if err != nil {
+		logging.L.Error().Err(err).Str("provider", provider.ID.String()).Msg("directory sync failed")
+		return
+	}
+	logging.L.Info().
+		Str("provider", provider.ID.String()).
+		Str("status", string(sync.Status)).
+		Int("synced", sync.UsersSynced).
+		Int("deactivated", sync.UsersDeactivated).
+		Int("failed", sync.UsersFailed).
+		Msg("directory sync finished")
+}
+
+// startProviderSync records the start of a sync of the provider, and returns
+// the active users of the provider to sync with finishProviderSync.
+func (s *Server) startProviderSync(ctx context.Context, provider *models.Provider) (*models.ProviderSync, []models.ProviderUser, error) {
+	var sync *models.ProviderSync
+	var users []models.ProviderUser
+	err := s.inOrgTxn(ctx, provider.OrganizationID, func(tx *data.Transaction) error {
+		var err error
+		sync, err = data.StartProviderSync(tx, provider.ID)
+		if err != nil {
+			return err
+		}
+		users, err = data.ListProviderUsers(tx, data.ListProviderUsersOptions{
+			ByProviderID: provider.ID,
+			HideInactive: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return sync, users, nil
+}
+
+// finishProviderSync syncs the groups of every user who has logged in with the
+// provider, and deactivates the users whose tokens were revoked by the
+// provider. Each user is synced in a separate transaction, so that a failure
+// to sync one user does not prevent syncing the others.
+func (s *Server) finishProviderSync(
+	ctx context.Context,
+	provider *models.Provider,
+	sync *models.ProviderSync,
+	users []models.ProviderUser,
+) (*models.ProviderSync, error) {
+	for i := range users {
+		user := &users[i]
+		if !canSyncProviderUser(provider, user) {
+			// users provisioned with SCIM, who have not logged in, have no
+			// session at the provider to sync
+			continue
+		}
+
+		deactivated, err := s.syncProviderUser(ctx, provider, user)
+		switch {
+		case err != nil:
+			logging.L.Warn().Err(err).Str("user", user.IdentityID.String()).Msg("directory sync of user failed")
+			sync.UsersFailed++
+			sync.Error = err.Error()
+		case deactivated:
+			sync.UsersDeactivated++
+		default:
+			sync.UsersSynced++
+		}
+	}
+
+	sync.Status = models.ProviderSyncStatusSucceeded
+	if sync.UsersFailed > 0 {
+		sync.Status = models.ProviderSyncStatusFailed
+	}
+	err := s.inOrgTxn(ctx, provider.OrganizationID, func(tx *data.Transaction) error {
+		return data.FinishProviderSync(tx, sync)
+	})
+	return sync, err
+}
+
+// canSyncProviderUser returns true if the user has a session at the provider.
+// GitHub OAuth apps issue access tokens that do not expire, without a refresh
+// token, so those users are synced with their access token.
+func canSyncProviderUser(provider *models.Provider, user *models.ProviderUser) bool {
+	if user.RefreshToken != "" {
+		return true
+	}
+	return provider.Kind == models.ProviderKindGitHub && user.AccessToken != ""
+}
+
+// isProviderUserRevoked returns true if err shows that the provider no longer
+// allows the user to login.
+func isProviderUserRevoked(err error) bool {
+	return errors.Is(err, providers.ErrRefreshTokenRevoked) ||
+		errors.Is(err, providers.ErrAccessTokenRevoked) ||
+		errors.Is(err, providers.ErrGitHubOrganizationNotAllowed)
+}
+
+// syncProviderUser updates the groups of the user from the provider. If the
+// provider revoked the tokens of the user, or no longer allows them to login,
+// the user is removed from the groups of the provider, their access keys from
+// the provider are deleted, and they are deactivated until they login again.
+func (s *Server) syncProviderUser(ctx context.Context, provider *models.Provider, user *models.ProviderUser) (deactivated bool, err error) {
+	oidc, err := s.providerClient(ctx, provider, user.RedirectURL)
+	if err != nil {
+		return false, fmt.Errorf("provider client: %w", err)
+	}
+
+	err = s.inOrgTxn(ctx, provider.OrganizationID, func(tx *data.Transaction) error {
+		_, err := data.SyncProviderUser(ctx, tx, user, oidc)
+		if !isProviderUserRevoked(err) {
+			return err
+		}
+
+		deactivated = true
+		user.Active = false
+		if _, err := data.AssignIdentityToGroups(tx, user, nil); err != nil {
+			return fmt.Errorf("remove groups: %w", err)
+		}
+		return data.DeleteAccessKeys(tx, data.DeleteAccessKeysOptions{
+			ByIssuedForID: user.IdentityID,
+			ByProviderID:  user.ProviderID,
+		})
+	})
+	return deactivated, err
+}
+
+// inOrgTxn runs fn in a transaction for the organization, and commits the
+// transaction if fn does not return an error.
+func (s *Server) inOrgTxn(ctx context.Context, orgID uid.ID, fn func(tx *data.Transaction) error) error {
+	tx, err := s.db.Begin(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer logError(tx.Rollback, "failed to rollback transaction")
+
+	if err := fn(tx.WithOrgID(orgID)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+)
+
+func TestAPI_ProviderSync(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	provider := &models.Provider{
+		Name:         "okta",
+		Kind:         models.ProviderKindOkta,
+		ClientSecret: "secret",
+	}
+	assert.NilError(t, data.CreateProvider(srv.DB(), provider))
+
+	saml := &models.Provider{Name: "saml", Kind: models.ProviderKindSAML}
+	assert.NilError(t, data.CreateProvider(srv.DB(), saml))
+
+	user := &models.Identity{Name: "user@example.com"}
+	createIdentities(t, srv.DB(), user)
+
+	providerUser, err := data.CreateProviderUser(srv.DB(), provider, user)
+	assert.NilError(t, err)
+	providerUser.RefreshToken = "ref"
+	providerUser.AccessToken = "acc"
+	providerUser.ExpiresAt = time.Now().Add(time.Hour)
+	assert.NilError(t, data.UpdateProviderUser(srv.DB(), providerUser))
+
+	userKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: user.ID,
+		ProviderID:  provider.ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	do := func(t *testing.T, method string, id, key string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/providers/"+id+"/sync", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder) api.ProviderSync {
+		t.Helper()
+		var sync api.ProviderSync
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&sync))
+		return sync
+	}
+
+	// syncNow starts a sync of the provider, waits for the sync to finish in
+	// the background, and returns the result of the sync.
+	syncNow := func(t *testing.T, id string, oidc *fakeOIDCImplementation) api.ProviderSync {
+		t.Helper()
+		srv.backgroundCtx = providers.WithOIDCClient(context.Background(), oidc)
+
+		resp := do(t, http.MethodPost, id, adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Equal(t, decode(t, resp).Status, "running")
+
+		srv.background.Wait()
+		resp = do(t, http.MethodGet, id, adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		return decode(t, resp)
+	}
+
+	t.Run("requires admin", func(t *testing.T) {
+		resp := do(t, http.MethodGet, provider.ID.String(), userKey)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = do(t, http.MethodPost, provider.ID.String(), userKey)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("provider does not support sync", func(t *testing.T) {
+		resp := do(t, http.MethodPost, saml.ID.String(), adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("not synced yet", func(t *testing.T) {
+		resp := do(t, http.MethodGet, provider.ID.String(), adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		sync := decode(t, resp)
+		assert.DeepEqual(t, sync, api.ProviderSync{ProviderID: provider.ID, Status: "idle"})
+	})
+
+	t.Run("sync now", func(t *testing.T) {
+		sync := syncNow(t, provider.ID.String(), &fakeOIDCImplementation{})
+		assert.Equal(t, sync.Status, "succeeded")
+		assert.Equal(t, sync.UsersSynced, 1)
+		assert.Equal(t, sync.UsersDeactivated, 0)
+	})
+
+	t.Run("revoked users are deactivated", func(t *testing.T) {
+		oidc := &fakeOIDCImplementation{RefreshRevoked: true}
+		sync := syncNow(t, provider.ID.String(), oidc)
+		assert.Equal(t, sync.Status, "succeeded")
+		assert.Equal(t, sync.UsersSynced, 0)
+		assert.Equal(t, sync.UsersDeactivated, 1)
+
+		pu, err := data.GetProviderUser(srv.DB(), provider.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, !pu.Active)
+
+		keys, err := data.ListAccessKeys(srv.DB(), data.ListAccessKeyOptions{ByIssuedForID: user.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 0)
+
+		// inactive users are not synced
+		sync = syncNow(t, provider.ID.String(), oidc)
+		assert.Equal(t, sync.UsersDeactivated, 0)
+	})
+
+	t.Run("github users are synced with their access token", func(t *testing.T) {
+		github := &models.Provider{
+			Name:         "github",
+			Kind:         models.ProviderKindGitHub,
+			ClientSecret: "secret",
+		}
+		assert.NilError(t, data.CreateProvider(srv.DB(), github))
+
+		octocat := &models.Identity{Name: "octocat@example.com"}
+		createIdentities(t, srv.DB(), octocat)
+
+		// tokens issued to a GitHub OAuth app do not expire, and have no
+		// refresh token
+		gitHubUser, err := data.CreateProviderUser(srv.DB(), github, octocat)
+		assert.NilError(t, err)
+		gitHubUser.AccessToken = "acc"
+		assert.NilError(t, data.UpdateProviderUser(srv.DB(), gitHubUser))
+
+		_, err = data.CreateAccessKey(srv.DB(), &models.AccessKey{
+			IssuedForID: octocat.ID,
+			ProviderID:  github.ID,
+			ExpiresAt:   time.Now().Add(10 * time.Minute),
+		})
+		assert.NilError(t, err)
+
+		sync := syncNow(t, github.ID.String(), &fakeOIDCImplementation{})
+		assert.Equal(t, sync.Status, "succeeded")
+		assert.Equal(t, sync.UsersSynced, 1)
+
+		oidc := &fakeOIDCImplementation{UserInfoErr: providers.ErrGitHubOrganizationNotAllowed}
+		sync = syncNow(t, github.ID.String(), oidc)
+		assert.Equal(t, sync.Status, "succeeded")
+		assert.Equal(t, sync.UsersSynced, 0)
+		assert.Equal(t, sync.UsersDeactivated, 1)
+
+		pu, err := data.GetProviderUser(srv.DB(), github.ID, octocat.ID)
+		assert.NilError(t, err)
+		assert.Assert(t, !pu.Active)
+
+		keys, err := data.ListAccessKeys(srv.DB(), data.ListAccessKeyOptions{ByIssuedForID: octocat.ID})
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 0)
+	})
+}
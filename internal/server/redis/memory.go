@@ -0,0 +1,196 @@
+package redis
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/infrahq/infra/internal/logging"
+)
+
+// RateLimiter limits the rate of events for a key. It is implemented by
+// Limiter when Redis is configured, and by MemoryLimiter when it is not.
+type RateLimiter interface {
+	// RateOK returns an OverLimitError if the rate per minute of events for
+	// key is over limit.
+	RateOK(key string, limit int) error
+	// LoginOK returns an OverLimitError if logins for key are locked out
+	// because of failed logins.
+	LoginOK(key string) error
+	// LoginGood resets the failed logins for key after a successful login.
+	LoginGood(key string)
+	// LoginBad records a failed login for key. Once there are limit failed
+	// logins in a row, logins are locked out for a time that grows with each
+	// failure.
+	LoginBad(key string, limit int)
+}
+
+// MemoryLimiter is a token bucket rate limiter that stores the buckets in
+// memory. Unlike Limiter, the rate is only limited for the events on this
+// server, so it is used by servers that do not have Redis configured.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	logins    map[string]*loginFailures
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// loginFailuresTTL is how long the failed logins for a key are kept after the
+// last failure, once the key is no longer locked out.
+const loginFailuresTTL = 24 * time.Hour
+
+// loginFailures are the failed logins in a row for a key.
+type loginFailures struct {
+	count   int
+	lockout time.Time
+	updated time.Time
+}
+
+// bucket holds up to limit tokens, and is refilled at a rate of limit tokens
+// per minute. Each event takes one token.
+type bucket struct {
+	tokens  float64
+	limit   int
+	updated time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		logins:  make(map[string]*loginFailures),
+		now:     time.Now,
+	}
+}
+
+// RateOK checks if the rate per minute is acceptable for the specified key
+func (lim *MemoryLimiter) RateOK(key string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	now := lim.now()
+	lim.sweep(now)
+
+	b, ok := lim.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), limit: limit, updated: now}
+		lim.buckets[key] = b
+	}
+	b.refill(now, limit)
+
+	logging.L.Debug().
+		Str("key", key).
+		Int("limit", limit).
+		Float64("remaining", b.tokens).
+		Msg("rate limit check")
+
+	if b.tokens < 1 {
+		return OverLimitError{RetryAfter: b.untilNextToken()}
+	}
+	b.tokens--
+	return nil
+}
+
+func (lim *MemoryLimiter) LoginOK(key string) error {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	failures, ok := lim.logins[key]
+	if !ok {
+		return nil
+	}
+
+	retryAfter := failures.lockout.Sub(lim.now())
+	ok = retryAfter > 0
+
+	logging.L.Debug().
+		Str("key", key).
+		Bool("allowed", !ok).
+		Dur("retry_after", retryAfter).
+		Msg("login limit check")
+
+	if ok {
+		return OverLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (lim *MemoryLimiter) LoginGood(key string) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	delete(lim.logins, key)
+}
+
+func (lim *MemoryLimiter) LoginBad(key string, limit int) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	now := lim.now()
+	lim.sweep(now)
+
+	failures, ok := lim.logins[key]
+	if !ok {
+		failures = &loginFailures{}
+		lim.logins[key] = failures
+	}
+	failures.count++
+	failures.updated = now
+
+	// the same backoff as Limiter.LoginBad. An existing lockout is not
+	// extended until it expires.
+	if failures.count >= limit && !failures.lockout.After(now) {
+		retryAfter := time.Duration(math.Pow(1.5, float64(failures.count)) * float64(time.Second))
+		failures.lockout = now.Add(retryAfter)
+
+		logging.L.Debug().
+			Str("key", key).
+			Int("limit", limit).
+			Dur("retry_after", retryAfter).
+			Msg("login failed")
+	}
+}
+
+// refill adds the tokens for the time since the bucket was last updated.
+func (b *bucket) refill(now time.Time, limit int) {
+	b.limit = limit
+	elapsed := now.Sub(b.updated)
+	if elapsed > 0 {
+		b.tokens += elapsed.Minutes() * float64(limit)
+		b.updated = now
+	}
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+}
+
+func (b *bucket) untilNextToken() time.Duration {
+	return time.Duration((1 - b.tokens) / float64(b.limit) * float64(time.Minute))
+}
+
+// sweep removes the buckets that would be full, so that the keys which are no
+// longer used do not keep using memory. Removing a full bucket does not change
+// the result of RateOK, because a missing bucket is created full. Failed
+// logins are removed once they are older than loginFailuresTTL and no longer
+// lock out the key.
+func (lim *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(lim.lastSweep) < time.Minute {
+		return
+	}
+	lim.lastSweep = now
+
+	for key, b := range lim.buckets {
+		if now.Sub(b.updated) >= time.Minute {
+			delete(lim.buckets, key)
+		}
+	}
+	for key, failures := range lim.logins {
+		if now.Sub(failures.updated) >= loginFailuresTTL && !failures.lockout.After(now) {
+			delete(lim.logins, key)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/opt"
+)
+
+func TestMemoryLimiter_RateOK(t *testing.T) {
+	setup := func(t *testing.T) (*time.Time, *MemoryLimiter) {
+		now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+		lim := NewMemoryLimiter()
+		lim.now = func() time.Time { return now }
+		return &now, lim
+	}
+
+	t.Run("under limit", func(t *testing.T) {
+		_, lim := setup(t)
+		err := lim.RateOK("key1", 1)
+		assert.NilError(t, err)
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		_, lim := setup(t)
+
+		err := lim.RateOK("key1", 1)
+		assert.NilError(t, err)
+
+		err = lim.RateOK("key1", 1)
+		assert.DeepEqual(t, err, OverLimitError{RetryAfter: time.Minute})
+	})
+
+	t.Run("limit reset after 1 minute", func(t *testing.T) {
+		now, lim := setup(t)
+
+		err := lim.RateOK("key1", 1)
+		assert.NilError(t, err)
+
+		err = lim.RateOK("key1", 1)
+		assert.ErrorContains(t, err, "over limit")
+
+		*now = now.Add(time.Minute)
+		err = lim.RateOK("key1", 1)
+		assert.NilError(t, err)
+	})
+
+	t.Run("consistently under limit", func(t *testing.T) {
+		now, lim := setup(t)
+
+		for i := 0; i < 20; i++ {
+			err := lim.RateOK("key1", 10)
+			assert.NilError(t, err)
+			*now = now.Add(6 * time.Second)
+		}
+	})
+
+	t.Run("burst up to the limit", func(t *testing.T) {
+		now, lim := setup(t)
+
+		for i := 0; i < 10; i++ {
+			assert.NilError(t, lim.RateOK("key1", 10))
+		}
+		err := lim.RateOK("key1", 10)
+		assert.DeepEqual(t, err, OverLimitError{RetryAfter: 6 * time.Second})
+
+		*now = now.Add(6 * time.Second)
+		assert.NilError(t, lim.RateOK("key1", 10))
+		assert.ErrorContains(t, lim.RateOK("key1", 10), "over limit")
+	})
+
+	t.Run("keys are counted separately", func(t *testing.T) {
+		_, lim := setup(t)
+
+		keys := []string{"key1", "key2", "key3"}
+		for _, key := range keys {
+			err := lim.RateOK(key, 1)
+			assert.NilError(t, err)
+
+			err = lim.RateOK(key, 1)
+			assert.ErrorContains(t, err, "over limit")
+		}
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		_, lim := setup(t)
+		for i := 0; i < 100; i++ {
+			assert.NilError(t, lim.RateOK("key1", 0))
+		}
+	})
+
+	t.Run("unused keys are removed", func(t *testing.T) {
+		now, lim := setup(t)
+
+		assert.NilError(t, lim.RateOK("key1", 10))
+		assert.NilError(t, lim.RateOK("key2", 10))
+		assert.Equal(t, len(lim.buckets), 2)
+
+		*now = now.Add(30 * time.Second)
+		assert.NilError(t, lim.RateOK("key2", 10))
+
+		*now = now.Add(45 * time.Second)
+		assert.NilError(t, lim.RateOK("key3", 10))
+		assert.Equal(t, len(lim.buckets), 2)
+		_, ok := lim.buckets["key1"]
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestMemoryLimiter_LoginOK(t *testing.T) {
+	setup := func(t *testing.T) (*time.Time, *MemoryLimiter) {
+		now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+		lim := NewMemoryLimiter()
+		lim.now = func() time.Time { return now }
+		return &now, lim
+	}
+	// 1.5^10 seconds
+	const tenFailures = 57665 * time.Millisecond
+
+	t.Run("under limit", func(t *testing.T) {
+		_, lim := setup(t)
+		lim.LoginBad("admin@example.com", 10)
+		assert.NilError(t, lim.LoginOK("admin@example.com"))
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		_, lim := setup(t)
+		for i := 0; i < 10; i++ {
+			lim.LoginBad("admin@example.com", 10)
+		}
+
+		err := lim.LoginOK("admin@example.com")
+		assert.DeepEqual(t, err, OverLimitError{RetryAfter: tenFailures}, opt.DurationWithThreshold(time.Millisecond))
+
+		// other keys are not locked out
+		assert.NilError(t, lim.LoginOK("other@example.com"))
+	})
+
+	t.Run("reset limit", func(t *testing.T) {
+		_, lim := setup(t)
+		for i := 0; i < 10; i++ {
+			lim.LoginBad("admin@example.com", 10)
+		}
+
+		lim.LoginGood("admin@example.com")
+		assert.NilError(t, lim.LoginOK("admin@example.com"))
+	})
+
+	t.Run("lockout is not extended until it expires", func(t *testing.T) {
+		now, lim := setup(t)
+		for i := 0; i < 10; i++ {
+			lim.LoginBad("admin@example.com", 10)
+		}
+
+		*now = now.Add(time.Second)
+		lim.LoginBad("admin@example.com", 10)
+		err := lim.LoginOK("admin@example.com")
+		assert.DeepEqual(t, err, OverLimitError{RetryAfter: tenFailures - time.Second}, opt.DurationWithThreshold(time.Millisecond))
+
+		*now = now.Add(time.Minute)
+		assert.NilError(t, lim.LoginOK("admin@example.com"))
+
+		// the next failure locks out for longer
+		lim.LoginBad("admin@example.com", 10)
+		err = lim.LoginOK("admin@example.com")
+		assert.ErrorContains(t, err, "over limit; retry after 2m9s")
+	})
+
+	t.Run("old failures are removed", func(t *testing.T) {
+		now, lim := setup(t)
+		lim.LoginBad("admin@example.com", 10)
+
+		*now = now.Add(loginFailuresTTL)
+		lim.LoginBad("other@example.com", 10)
+		_, ok := lim.logins["admin@example.com"]
+		assert.Assert(t, !ok)
+	})
+}
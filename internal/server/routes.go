@@ -19,6 +19,7 @@ import (
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/logging"
 	"github.com/infrahq/infra/internal/openapi3"
+	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/validate"
 	"github.com/infrahq/infra/metrics"
 )
@@ -60,6 +61,14 @@ func (s *Server) GenerateRoutes() Routes {
 	add(a, authn, http.MethodGet, "/api/users/:id", getUserRoute)
 	put(a, authn, "/api/users/:id", a.UpdateUser)
 	del(a, authn, "/api/users/:id", a.DeleteUser)
+	get(a, authn, "/api/users/:id/access", a.ListUserAccess)
+	post(a, authn, "/api/users/:id/mfa", a.CreateMFAEnrollment)
+	post(a, authn, "/api/users/:id/mfa/verify", a.VerifyMFAEnrollment)
+	del(a, authn, "/api/users/:id/mfa", a.DeleteMFA)
+	del(a, authn, "/api/users/:id/lockout", a.UnlockUser)
+	get(a, authn, "/api/users/:id/sessions", a.ListSessions)
+	del(a, authn, "/api/users/:id/sessions", a.DeleteSessions)
+	del(a, authn, "/api/users/:id/sessions/:sessionID", a.DeleteSession)
 	put(a, authn, "/api/users/public-key", AddUserPublicKey)
 
 	get(a, authn, "/api/access-keys", a.ListAccessKeys)
@@ -72,6 +81,7 @@ func (s *Server) GenerateRoutes() Routes {
 	get(a, authn, "/api/groups/:id", a.GetGroup)
 	del(a, authn, "/api/groups/:id", a.DeleteGroup)
 	patch(a, authn, "/api/groups/:id/users", a.UpdateUsersInGroup)
+	patch(a, authn, "/api/groups/:id/groups", a.UpdateGroupsInGroup)
 
 	get(a, authn, "/api/organizations", a.ListOrganizations)
 	post(a, authn, "/api/organizations", a.CreateOrganization)
@@ -79,23 +89,43 @@ func (s *Server) GenerateRoutes() Routes {
 	del(a, authn, "/api/organizations/:id", a.DeleteOrganization)
 	put(a, authn, "/api/organizations/:id", a.UpdateOrganization)
 
+	get(a, authn, "/api/signing-keys", a.ListSigningKeys)
+	post(a, authn, "/api/signing-keys/rotate", a.RotateSigningKey)
+
 	get(a, authn, "/api/grants", a.ListGrants)
 	get(a, authn, "/api/grants/:id", a.GetGrant)
 	post(a, authn, "/api/grants", a.CreateGrant)
 	del(a, authn, "/api/grants/:id", a.DeleteGrant)
 	patch(a, authn, "/api/grants", a.UpdateGrants)
 
+	get(a, authn, "/api/access-requests", a.ListAccessRequests)
+	get(a, authn, "/api/access-requests/:id", a.GetAccessRequest)
+	post(a, authn, "/api/access-requests", a.CreateAccessRequest)
+	post(a, authn, "/api/access-requests/:id/approve", a.ApproveAccessRequest)
+	post(a, authn, "/api/access-requests/:id/deny", a.DenyAccessRequest)
+
+	get(a, authn, "/api/audit-events", a.ListAuditEvents)
+
 	post(a, authn, "/api/providers", a.CreateProvider)
 	patch(a, authn, "/api/providers/:id", a.PatchProvider)
 	put(a, authn, "/api/providers/:id", a.UpdateProvider)
 	del(a, authn, "/api/providers/:id", a.DeleteProvider)
+	get(a, authn, "/api/providers/:id/sync", a.GetProviderSync)
+	post(a, authn, "/api/providers/:id/sync", a.SyncProvider)
+	post(a, authn, "/api/providers/:id/group-mapping/preview", a.PreviewGroupMapping)
 
 	get(a, authn, "/api/destinations", a.ListDestinations)
 	get(a, authn, "/api/destinations/:id", a.GetDestination)
+	get(a, authn, "/api/destinations/:id/access", a.ListDestinationAccess)
 	post(a, authn, "/api/destinations", a.CreateDestination)
 	put(a, authn, "/api/destinations/:id", a.UpdateDestination)
 	del(a, authn, "/api/destinations/:id", a.DeleteDestination)
 
+	get(a, authn, "/api/workload-identities", a.ListWorkloadIdentities)
+	get(a, authn, "/api/workload-identities/:id", a.GetWorkloadIdentity)
+	post(a, authn, "/api/workload-identities", a.CreateWorkloadIdentity)
+	del(a, authn, "/api/workload-identities/:id", a.DeleteWorkloadIdentity)
+
 	add(a, authn, http.MethodPost, "/api/tokens", createTokenRoute)
 	post(a, authn, "/api/logout", a.Logout)
 
@@ -106,6 +136,12 @@ func (s *Server) GenerateRoutes() Routes {
 	add(a, authn, http.MethodPut, "/api/scim/v2/Users/:id", updateProviderUserRoute)
 	add(a, authn, http.MethodPatch, "/api/scim/v2/Users/:id", patchProviderUserRoute)
 	add(a, authn, http.MethodDelete, "/api/scim/v2/Users/:id", deleteProviderUserRoute)
+	add(a, authn, http.MethodGet, "/api/scim/v2/Groups/:id", getProviderGroupRoute)
+	add(a, authn, http.MethodGet, "/api/scim/v2/Groups", listProviderGroupsRoute)
+	add(a, authn, http.MethodPost, "/api/scim/v2/Groups", createProviderGroupRoute)
+	add(a, authn, http.MethodPut, "/api/scim/v2/Groups/:id", updateProviderGroupRoute)
+	add(a, authn, http.MethodPatch, "/api/scim/v2/Groups/:id", patchProviderGroupRoute)
+	add(a, authn, http.MethodDelete, "/api/scim/v2/Groups/:id", deleteProviderGroupRoute)
 
 	add(a, authn, http.MethodGet, "/api/debug/pprof/*profile", pprofRoute)
 
@@ -115,16 +151,23 @@ func (s *Server) GenerateRoutes() Routes {
 	get(a, noAuthnNoOrg, "/api/version", a.Version)
 	get(a, noAuthnNoOrg, "/api/server-configuration", a.GetServerConfiguration)
 	post(a, noAuthnNoOrg, "/api/forgot-domain-request", a.RequestForgotDomains)
+	add(a, noAuthnNoOrg, http.MethodGet, "/api/scim/v2/ServiceProviderConfig", getSCIMServiceProviderConfigRoute)
+	add(a, noAuthnNoOrg, http.MethodGet, "/api/scim/v2/ResourceTypes", listSCIMResourceTypesRoute)
+	add(a, noAuthnNoOrg, http.MethodGet, "/api/scim/v2/Schemas", listSCIMSchemasRoute)
 
 	// no auth required, org required
 	noAuthnWithOrg := &routeGroup{RouterGroup: apiGroup.Group("/"), authenticationOptional: true}
 
 	post(a, noAuthnWithOrg, "/api/login", a.Login)
+	post(a, noAuthnWithOrg, "/api/login/discover", a.DiscoverLogin)
 	post(a, noAuthnWithOrg, "/api/password-reset-request", a.RequestPasswordReset)
 	post(a, noAuthnWithOrg, "/api/password-reset", a.VerifiedPasswordReset)
 
 	get(a, noAuthnWithOrg, "/api/providers/:id", a.GetProvider)
 	get(a, noAuthnWithOrg, "/api/providers", a.ListProviders)
+	add(a, noAuthnWithOrg, http.MethodGet, "/api/providers/:id/saml/metadata", samlMetadataRoute)
+	add(a, noAuthnWithOrg, http.MethodGet, "/api/providers/:id/saml/login", samlLoginRoute)
+	add(a, noAuthnWithOrg, http.MethodPost, "/api/providers/:id/saml/acs", a.SAMLACSRoute())
 	add(a, noAuthnWithOrg, http.MethodGet, "/link", verifyAndRedirectRoute)
 
 	add(a, noAuthnWithOrg, http.MethodGet, "/.well-known/jwks.json", wellKnownJWKsRoute)
@@ -217,8 +260,9 @@ func add[Req, Res any](a *API, group *routeGroup, method, urlPath string, route
 // (similar to middleware).
 // The returned function handles validation of the infra version header, manages
 // a request scoped database transaction, authenticates the request, reads the
-// request fields into a request struct, and returns an HTTP response with a
-// status code and response body built from the response type.
+// request fields into a request struct, records an audit event for any route
+// that modifies state, and returns an HTTP response with a status code and
+// response body built from the response type.
 func wrapRoute[Req, Res any](a *API, routeID routeIdentifier, route route[Req, Res]) func(*gin.Context) error {
 	return func(c *gin.Context) error {
 		origRequestContext := c.Request.Context()
@@ -230,6 +274,11 @@ func wrapRoute[Req, Res any](a *API, routeID routeIdentifier, route route[Req, R
 		if err != nil {
 			return err
 		}
+		if !route.authenticationOptional {
+			if err := requireAccessKeyScope(c, routeID, authned); err != nil {
+				return err
+			}
+		}
 
 		req := new(Req)
 		if err := readRequest(c, req); err != nil {
@@ -261,13 +310,33 @@ func wrapRoute[Req, Res any](a *API, routeID routeIdentifier, route route[Req, R
 		}
 		c.Set(access.RequestContextKey, rCtx)
 
+		// every non-GET route that can change data is audited, including
+		// routes without an organization, like signup.
+		readOnly := route.txnOptions != nil && route.txnOptions.ReadOnly
+		audited := routeID.method != http.MethodGet && !readOnly
+
 		resp, err := route.handler(rCtx, req)
 		if err != nil {
+			if audited {
+				// the request transaction is rolled back, so the failure is
+				// recorded in a separate transaction.
+				event := newAuditEvent(rCtx, routeID, req, resp, err)
+				createAuditEventInNewTxn(a.server.db, event)
+			}
 			return err
 		}
 
+		if audited {
+			event := newAuditEvent(rCtx, routeID, req, resp, nil)
+			if event.OrganizationID == 0 {
+				logAuditEvent(event)
+			} else if err := data.CreateAuditEvent(tx, event); err != nil {
+				return fmt.Errorf("create audit event: %w", err)
+			}
+		}
+
 		completeTx := tx.Commit
-		if route.txnOptions != nil && route.txnOptions.ReadOnly {
+		if readOnly {
 			// use rollback to avoid an error when the request handler already completed the txn
 			completeTx = tx.Rollback
 		}
@@ -283,9 +352,16 @@ func wrapRoute[Req, Res any](a *API, routeID routeIdentifier, route route[Req, R
 		if respHeaders, ok := any(resp).(hasResponseHeaders); ok {
 			respHeaders.SetHeaders(rCtx.Response.HTTPWriter.Header())
 		}
-		if r, ok := any(resp).(isRedirect); ok {
-			c.Redirect(http.StatusPermanentRedirect, r.RedirectURL())
-		} else {
+		switch r := any(resp).(type) {
+		case isRedirect:
+			status := http.StatusPermanentRedirect
+			if sc, ok := r.(statusCoder); ok && sc.StatusCode() != 0 {
+				status = sc.StatusCode()
+			}
+			c.Redirect(status, r.RedirectURL())
+		case hasRawBody:
+			c.Data(responseStatusCode(routeID.method, resp), r.ContentType(), r.RawBody())
+		default:
 			c.JSON(responseStatusCode(routeID.method, resp), resp)
 		}
 		return nil
@@ -300,6 +376,11 @@ type isRedirect interface {
 	RedirectURL() string
 }
 
+type hasRawBody interface {
+	ContentType() string
+	RawBody() []byte
+}
+
 type statusCoder interface {
 	StatusCode() int
 }
@@ -308,6 +389,12 @@ type isBlockingRequest interface {
 	IsBlockingRequest() bool
 }
 
+// isFormRequest is implemented by requests that are read from a form encoded
+// body instead of JSON, for example forms posted by an identity provider.
+type isFormRequest interface {
+	IsFormRequest() bool
+}
+
 func requestVersion(req *http.Request) (*semver.Version, error) {
 	headerVer := req.Header.Get("Infra-Version")
 	if headerVer == "" {
@@ -411,7 +498,11 @@ func readRequest(c *gin.Context, req interface{}) error {
 		}
 	}
 
-	if c.Request.Body != nil && c.Request.ContentLength > 0 {
+	if r, ok := req.(isFormRequest); ok && r.IsFormRequest() {
+		if err := binding.FormPost.Bind(c.Request, req); err != nil {
+			return fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+		}
+	} else if c.Request.Body != nil && c.Request.ContentLength > 0 {
 		if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
 			return fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
 		}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/authn"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/server/providers"
+	"github.com/infrahq/infra/uid"
+)
+
+// samlRouteSettings are the settings for routes that are called by a browser,
+// or by a SAML identity provider, instead of an API client.
+var samlRouteSettings = routeSettings{
+	omitFromDocs:               true,
+	omitFromTelemetry:          true,
+	infraVersionHeaderOptional: true,
+	txnOptions:                 &sql.TxOptions{ReadOnly: true},
+}
+
+var samlMetadataRoute = route[api.Resource, *api.SAMLMetadataResponse]{
+	handler:       GetSAMLMetadata,
+	routeSettings: samlRouteSettings,
+}
+
+// samlRequestExpiry is how long the identity provider has to respond to an
+// authentication request.
+const samlRequestExpiry = 10 * time.Minute
+
+var samlLoginRoute = route[api.SAMLLoginRequest, *api.RedirectResponse]{
+	handler:       SAMLLogin,
+	routeSettings: samlWriteRouteSettings(),
+}
+
+func (a *API) SAMLACSRoute() route[api.SAMLACSRequest, *api.RedirectResponse] {
+	return route[api.SAMLACSRequest, *api.RedirectResponse]{
+		handler:       a.SAMLACS,
+		routeSettings: samlWriteRouteSettings(),
+	}
+}
+
+func samlWriteRouteSettings() routeSettings {
+	settings := samlRouteSettings
+	settings.txnOptions = nil
+	return settings
+}
+
+// caution: this endpoint is unauthenticated, do not return sensitive info
+func GetSAMLMetadata(rCtx access.RequestContext, r *api.Resource) (*api.SAMLMetadataResponse, error) {
+	_, sp, err := samlServiceProvider(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := sp.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	return &api.SAMLMetadataResponse{Metadata: metadata}, nil
+}
+
+// SAMLLogin starts a login by redirecting the browser to the SAML identity
+// provider. The ID of the authentication request is stored, and set in a
+// cookie, so that the response is only accepted from the browser that started
+// the login.
+func SAMLLogin(rCtx access.RequestContext, r *api.SAMLLoginRequest) (*api.RedirectResponse, error) {
+	provider, sp, err := samlServiceProvider(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectTo, requestID, err := sp.AuthnRequestURL(safeRedirectPath(r.Next))
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(samlRequestExpiry)
+	if err := data.CreateSAMLRequest(rCtx.DBTxn, provider.ID, requestID, expires); err != nil {
+		return nil, err
+	}
+
+	// the response is posted to the ACS URL by the identity provider, which
+	// is a cross-site request, so the cookie must not be SameSite=Strict
+	setCookie(rCtx.Request, rCtx.Response.HTTPWriter, cookieConfig{
+		Name:     cookieSAMLRequestName,
+		Value:    requestID,
+		Domain:   rCtx.Request.Host,
+		Expires:  expires,
+		SameSite: http.SameSiteNoneMode,
+	})
+	return &api.RedirectResponse{RedirectTo: redirectTo, Status: http.StatusFound}, nil
+}
+
+// SAMLACS is the assertion consumer service. It receives the SAML response from
+// the identity provider, logs in the user, and redirects them to the path they
+// were trying to reach.
+func (a *API) SAMLACS(rCtx access.RequestContext, r *api.SAMLACSRequest) (*api.RedirectResponse, error) {
+	provider, sp, err := samlServiceProvider(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, err := getCookie(rCtx.Request, cookieSAMLRequestName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: login failed: the login was not started from this browser", internal.ErrUnauthorized)
+	}
+	deleteCookie(rCtx.Request, rCtx.Response.HTTPWriter, cookieSAMLRequestName, rCtx.Request.Host)
+
+	loginMethod, err := authn.NewSAMLAuthentication(provider, sp, r.SAMLResponse, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().UTC().Add(a.server.options.SessionDuration)
+	result, err := authn.Login(rCtx.Request.Context(), rCtx.DBTxn, loginMethod, expires, a.server.options.SessionInactivityTimeout, a.server.accessKeyClient(rCtx.Request))
+	if err != nil {
+		return nil, fmt.Errorf("%w: login failed: %v", internal.ErrUnauthorized, err)
+	}
+
+	a.loginSucceeded(rCtx, loginMethod, result)
+
+	// the response is to a POST from the identity provider, use 303 so that
+	// the browser follows the redirect with a GET
+	return &api.RedirectResponse{RedirectTo: safeRedirectPath(r.RelayState), Status: http.StatusSeeOther}, nil
+}
+
+func samlServiceProvider(rCtx access.RequestContext, id uid.ID) (*models.Provider, *providers.SAMLServiceProvider, error) {
+	provider, err := data.GetProvider(rCtx.DBTxn, data.GetProviderOptions{ByID: id})
+	if err != nil {
+		return nil, nil, err
+	}
+	if provider.Kind != models.ProviderKindSAML {
+		return nil, nil, fmt.Errorf("%w: provider %v is not a saml provider", internal.ErrBadRequest, provider.Name)
+	}
+
+	// the URLs of the service provider must not come from the request, they
+	// are used to validate the responses from the identity provider
+	org := rCtx.Authenticated.Organization
+	if org == nil || org.Domain == "" {
+		return nil, nil, fmt.Errorf("%w: saml providers require the organization to have a domain", internal.ErrBadRequest)
+	}
+
+	sp, err := providers.NewSAMLServiceProvider(*provider, "https://"+org.Domain)
+	if err != nil {
+		return nil, nil, err
+	}
+	return provider, sp, nil
+}
+
+// safeRedirectPath returns path if it is a path on this server, otherwise it
+// returns the root path. It is used to prevent redirects to other sites.
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
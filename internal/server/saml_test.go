@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/internal/testing/samlidp"
+)
+
+func TestAPI_SAML(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+	idp := samlidp.New(t)
+
+	var provider api.Provider
+	t.Run("create provider", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/providers", jsonBody(t, api.CreateProviderRequest{
+			Name: "business-unit",
+			Kind: "saml",
+			SAML: &api.ProviderSAML{
+				Metadata:        idp.Metadata(),
+				GroupsAttribute: "memberOf",
+			},
+		}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &provider))
+		assert.Equal(t, provider.Kind, "saml")
+		assert.Equal(t, provider.URL, samlidp.EntityID)
+		assert.Equal(t, provider.AuthURL, samlidp.SSOURL)
+	})
+
+	t.Run("create provider without saml configuration", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/providers", jsonBody(t, api.CreateProviderRequest{
+			Name: "missing-config",
+			Kind: "saml",
+		}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		var apiError api.Error
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &apiError))
+		assert.DeepEqual(t, apiError.FieldErrors, []api.FieldError{
+			{FieldName: "saml", Errors: []string{"is required"}},
+		})
+	})
+
+	basePath := "/api/providers/" + provider.ID.String() + "/saml"
+	entityID := "https://example.com" + basePath + "/metadata"
+	acsURL := "https://example.com" + basePath + "/acs"
+
+	t.Run("metadata", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, basePath+"/metadata", nil)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Equal(t, resp.Header().Get("Content-Type"), "application/samlmetadata+xml")
+		assert.Assert(t, strings.Contains(resp.Body.String(), `entityID="`+entityID+`"`), resp.Body.String())
+		assert.Assert(t, strings.Contains(resp.Body.String(), `Location="`+acsURL+`"`), resp.Body.String())
+	})
+
+	// startLogin starts a login and returns the ID of the authentication
+	// request, which is set in a cookie
+	startLogin := func(t *testing.T) *http.Cookie {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, basePath+"/login", nil)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusFound, resp.Body.String())
+
+		for _, c := range resp.Result().Cookies() {
+			if c.Name == cookieSAMLRequestName {
+				return c
+			}
+		}
+		t.Fatal("missing saml request cookie")
+		return nil
+	}
+
+	t.Run("login redirects to the identity provider", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, basePath+"/login?next=/destinations", nil)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusFound, resp.Body.String())
+
+		location, err := url.Parse(resp.Header().Get("Location"))
+		assert.NilError(t, err)
+		assert.Equal(t, location.Scheme+"://"+location.Host+location.Path, samlidp.SSOURL)
+		assert.Equal(t, location.Query().Get("RelayState"), "/destinations")
+		assert.Assert(t, location.Query().Get("SAMLRequest") != "")
+
+		cookies := resp.Result().Cookies()
+		assert.Equal(t, len(cookies), 1)
+		assert.Equal(t, cookies[0].Name, cookieSAMLRequestName)
+		assert.Equal(t, cookies[0].SameSite, http.SameSiteNoneMode)
+		assert.Assert(t, cookies[0].Secure)
+		assert.Assert(t, strings.HasPrefix(cookies[0].Value, "id-"))
+	})
+
+	postACS := func(t *testing.T, samlResponse, relayState string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		t.Helper()
+		form := url.Values{}
+		form.Set("SAMLResponse", samlResponse)
+		form.Set("RelayState", relayState)
+		req := httptest.NewRequest(http.MethodPost, basePath+"/acs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("assertion consumer service", func(t *testing.T) {
+		cookie := startLogin(t)
+		samlResponse := idp.Response(t, samlidp.Assertion{
+			Recipient:       acsURL,
+			Audience:        entityID,
+			InResponseTo:    cookie.Value,
+			NameID:          "dana@example.com",
+			Groups:          []string{"platform"},
+			GroupsAttribute: "memberOf",
+		})
+
+		resp := postACS(t, samlResponse, "/destinations", cookie)
+		assert.Equal(t, resp.Code, http.StatusSeeOther, resp.Body.String())
+		assert.Equal(t, resp.Header().Get("Location"), "/destinations")
+
+		var authCookie *http.Cookie
+		for _, c := range resp.Result().Cookies() {
+			if c.Name == cookieAuthorizationName {
+				authCookie = c
+			}
+		}
+		assert.Assert(t, authCookie != nil)
+
+		user, err := data.GetIdentity(srv.DB(), data.GetIdentityOptions{ByName: "dana@example.com", LoadGroups: true})
+		assert.NilError(t, err)
+		assert.Equal(t, len(user.Groups), 1)
+		assert.Equal(t, user.Groups[0].Name, "platform")
+
+		t.Run("response is replayed", func(t *testing.T) {
+			resp := postACS(t, samlResponse, "/destinations", cookie)
+			assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		})
+	})
+
+	t.Run("relay state to another site", func(t *testing.T) {
+		cookie := startLogin(t)
+		samlResponse := idp.Response(t, samlidp.Assertion{
+			Recipient:    acsURL,
+			Audience:     entityID,
+			InResponseTo: cookie.Value,
+			NameID:       "dana@example.com",
+		})
+
+		resp := postACS(t, samlResponse, "//evil.example.com/", cookie)
+		assert.Equal(t, resp.Code, http.StatusSeeOther, resp.Body.String())
+		assert.Equal(t, resp.Header().Get("Location"), "/")
+	})
+
+	t.Run("login was not started from this browser", func(t *testing.T) {
+		samlResponse := idp.Response(t, samlidp.Assertion{
+			Recipient:    acsURL,
+			Audience:     entityID,
+			InResponseTo: startLogin(t).Value,
+			NameID:       "mallory@example.com",
+		})
+
+		resp := postACS(t, samlResponse, "/", nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		// the response to a login started by someone else
+		resp = postACS(t, samlResponse, "/", startLogin(t))
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		cookie := startLogin(t)
+		samlResponse := samlidp.New(t).Response(t, samlidp.Assertion{
+			Recipient:    acsURL,
+			Audience:     entityID,
+			InResponseTo: cookie.Value,
+			NameID:       "mallory@example.com",
+		})
+
+		resp := postACS(t, samlResponse, "/", cookie)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("organization without a domain", func(t *testing.T) {
+		rCtx := access.RequestContext{
+			Request:       httptest.NewRequest(http.MethodGet, basePath+"/metadata", nil),
+			DBTxn:         txnForTestCase(t, srv.db, srv.db.DefaultOrg.ID),
+			Authenticated: access.Authenticated{Organization: &models.Organization{}},
+		}
+		_, _, err := samlServiceProvider(rCtx, provider.ID)
+		assert.ErrorIs(t, err, internal.ErrBadRequest)
+	})
+}
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/scim2/filter-parser/v2"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
 )
 
 var getProviderUsersRoute = route[api.Resource, *api.SCIMUser]{
@@ -66,6 +69,87 @@ var deleteProviderUserRoute = route[api.Resource, *api.EmptyResponse]{
 	},
 }
 
+var getProviderGroupRoute = route[api.Resource, *api.SCIMGroup]{
+	handler: GetProviderGroup,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var listProviderGroupsRoute = route[api.SCIMParametersRequest, *api.ListProviderGroupsResponse]{
+	handler: ListProviderGroups,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var createProviderGroupRoute = route[api.SCIMGroupCreateRequest, *api.SCIMGroup]{
+	handler: CreateProviderGroup,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var updateProviderGroupRoute = route[api.SCIMGroupUpdateRequest, *api.SCIMGroup]{
+	handler: UpdateProviderGroup,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var patchProviderGroupRoute = route[api.SCIMGroupPatchRequest, *api.SCIMGroup]{
+	handler: PatchProviderGroup,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var deleteProviderGroupRoute = route[api.Resource, *api.EmptyResponse]{
+	handler: DeleteProviderGroup,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var getSCIMServiceProviderConfigRoute = route[api.EmptyRequest, *api.SCIMServiceProviderConfig]{
+	handler: GetSCIMServiceProviderConfig,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var listSCIMResourceTypesRoute = route[api.EmptyRequest, *api.ListSCIMResourceTypesResponse]{
+	handler: ListSCIMResourceTypes,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
+var listSCIMSchemasRoute = route[api.EmptyRequest, *api.ListSCIMSchemasResponse]{
+	handler: ListSCIMSchemas,
+	routeSettings: routeSettings{
+		omitFromTelemetry:          true,
+		omitFromDocs:               true,
+		infraVersionHeaderOptional: true,
+	},
+}
+
 func GetProviderUser(rCtx access.RequestContext, r *api.Resource) (*api.SCIMUser, error) {
 	user, err := access.GetProviderUser(rCtx, r.ID)
 	if err != nil {
@@ -108,6 +192,7 @@ func CreateProviderUser(rCtx access.RequestContext, r *api.SCIMUserCreateRequest
 		FamilyName: r.Name.FamilyName,
 		Active:     r.Active,
 	}
+	setEnterpriseAttributes(user, r.Enterprise)
 	for _, email := range r.Emails {
 		if email.Primary {
 			user.Email = email.Value
@@ -130,6 +215,7 @@ func UpdateProviderUser(rCtx access.RequestContext, r *api.SCIMUserUpdateRequest
 		FamilyName: r.Name.FamilyName,
 		Active:     r.Active,
 	}
+	setEnterpriseAttributes(user, r.Enterprise)
 	for _, email := range r.Emails {
 		if email.Primary {
 			user.Email = email.Value
@@ -165,3 +251,408 @@ func PatchProviderUser(rCtx access.RequestContext, r *api.SCIMUserPatchRequest)
 func DeleteProviderUser(rCtx access.RequestContext, r *api.Resource) (*api.EmptyResponse, error) {
 	return nil, access.DeleteProviderUser(rCtx, r.ID)
 }
+
+// setEnterpriseAttributes sets the attributes of the SCIM enterprise user
+// extension. A request without the extension removes the attributes.
+func setEnterpriseAttributes(user *models.ProviderUser, ext *api.SCIMEnterpriseUser) {
+	if ext == nil {
+		return
+	}
+	user.Department = ext.Department
+	user.EmployeeNumber = ext.EmployeeNumber
+	if ext.Manager != nil {
+		user.Manager = ext.Manager.Value
+	}
+}
+
+func GetProviderGroup(rCtx access.RequestContext, r *api.Resource) (*api.SCIMGroup, error) {
+	group, members, err := access.GetProviderGroup(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return scimGroup(group, members), nil
+}
+
+func ListProviderGroups(rCtx access.RequestContext, r *api.SCIMParametersRequest) (*api.ListProviderGroupsResponse, error) {
+	p := data.SCIMParameters{
+		StartIndex: r.StartIndex,
+		Count:      r.Count,
+	}
+	if r.Filter != "" {
+		exp, err := filter.ParseFilter([]byte(r.Filter))
+		if err != nil {
+			return nil, fmt.Errorf("parse SCIM filter expression: %w", err)
+		}
+		p.Filter = exp
+	}
+	groups, members, err := access.ListProviderGroups(rCtx, &p)
+	if err != nil {
+		return nil, err
+	}
+	result := &api.ListProviderGroupsResponse{
+		Schemas:      []string{api.ListResponseSchema},
+		TotalResults: p.TotalCount,
+		StartIndex:   p.StartIndex,
+		ItemsPerPage: p.Count,
+	}
+	for i := range groups {
+		result.Resources = append(result.Resources, *scimGroup(&groups[i], members[groups[i].ID]))
+	}
+	return result, nil
+}
+
+func CreateProviderGroup(rCtx access.RequestContext, r *api.SCIMGroupCreateRequest) (*api.SCIMGroup, error) {
+	memberIDs, err := scimGroupMemberIDs(r.Members)
+	if err != nil {
+		return nil, err
+	}
+	group := &models.Group{Name: r.DisplayName}
+	if err := access.CreateProviderGroup(rCtx, group, memberIDs); err != nil {
+		return nil, err
+	}
+	return GetProviderGroup(rCtx, &api.Resource{ID: group.ID})
+}
+
+func UpdateProviderGroup(rCtx access.RequestContext, r *api.SCIMGroupUpdateRequest) (*api.SCIMGroup, error) {
+	memberIDs, err := scimGroupMemberIDs(r.Members)
+	if err != nil {
+		return nil, err
+	}
+	group := &models.Group{Model: models.Model{ID: r.ID}, Name: r.DisplayName}
+	if err := access.UpdateProviderGroup(rCtx, group, memberIDs); err != nil {
+		return nil, err
+	}
+	return GetProviderGroup(rCtx, &api.Resource{ID: group.ID})
+}
+
+func PatchProviderGroup(rCtx access.RequestContext, r *api.SCIMGroupPatchRequest) (*api.SCIMGroup, error) {
+	group, members, err := access.GetProviderGroup(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	patch := &scimGroupPatch{name: group.Name, members: make(map[uid.ID]bool, len(members))}
+	for _, member := range members {
+		patch.members[member.ID] = true
+	}
+	for _, op := range r.Operations {
+		if err := patch.apply(op); err != nil {
+			return nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+		}
+	}
+
+	memberIDs := make([]uid.ID, 0, len(patch.members))
+	for id := range patch.members {
+		memberIDs = append(memberIDs, id)
+	}
+	group.Name = patch.name
+	if err := access.UpdateProviderGroup(rCtx, group, memberIDs); err != nil {
+		return nil, err
+	}
+	return GetProviderGroup(rCtx, &api.Resource{ID: group.ID})
+}
+
+func DeleteProviderGroup(rCtx access.RequestContext, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteProviderGroup(rCtx, r.ID)
+}
+
+func scimGroup(group *models.Group, members []models.Identity) *api.SCIMGroup {
+	result := &api.SCIMGroup{
+		Schemas:     []string{api.GroupSchema},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+		Members:     []api.SCIMGroupMember{},
+		Meta: api.SCIMMetadata{
+			ResourceType: "Group",
+		},
+	}
+	for _, member := range members {
+		result.Members = append(result.Members, api.SCIMGroupMember{
+			Value:   member.ID.String(),
+			Display: member.Name,
+		})
+	}
+	return result
+}
+
+func scimGroupMemberIDs(members []api.SCIMGroupMember) ([]uid.ID, error) {
+	ids := make([]uid.ID, 0, len(members))
+	for _, member := range members {
+		id, err := uid.Parse([]byte(member.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid member %q", internal.ErrBadRequest, member.Value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// scimGroupPatch is the state of a group while the operations of a SCIM PATCH
+// request are applied in order.
+type scimGroupPatch struct {
+	name    string
+	members map[uid.ID]bool
+}
+
+// apply applies a single PATCH operation. The supported operations are the
+// ones sent by identity providers to change the members and the name of a
+// group: https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+func (p *scimGroupPatch) apply(op api.SCIMGroupPatchOperation) error {
+	opName := strings.ToLower(op.Op)
+	if opName != "add" && opName != "remove" && opName != "replace" {
+		return fmt.Errorf("unsupported operation %q", op.Op)
+	}
+
+	if op.Path == "" {
+		// the value is an object with the attributes to change
+		if opName == "remove" {
+			return fmt.Errorf("path is required for remove")
+		}
+		var value struct {
+			DisplayName string                `json:"displayName"`
+			Members     []api.SCIMGroupMember `json:"members"`
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return fmt.Errorf("invalid value: %w", err)
+		}
+		if value.DisplayName != "" {
+			p.name = value.DisplayName
+		}
+		if value.Members != nil {
+			return p.applyMembers(opName, value.Members)
+		}
+		return nil
+	}
+
+	path, err := filter.ParsePath([]byte(op.Path))
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", op.Path, err)
+	}
+	switch strings.ToLower(path.AttributePath.AttributeName) {
+	case "displayname":
+		if opName == "remove" {
+			return fmt.Errorf("displayName can not be removed")
+		}
+		var name string
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return fmt.Errorf("invalid displayName: %w", err)
+		}
+		p.name = name
+		return nil
+	case "members":
+		if path.ValueExpression != nil {
+			// members[value eq "id"]
+			if opName != "remove" {
+				return fmt.Errorf("path %q is only supported for remove", op.Path)
+			}
+			id, err := scimMemberFromExpression(path.ValueExpression)
+			if err != nil {
+				return err
+			}
+			delete(p.members, id)
+			return nil
+		}
+		if opName == "remove" && len(op.Value) == 0 {
+			p.members = map[uid.ID]bool{}
+			return nil
+		}
+		var members []api.SCIMGroupMember
+		if err := json.Unmarshal(op.Value, &members); err != nil {
+			return fmt.Errorf("invalid members: %w", err)
+		}
+		return p.applyMembers(opName, members)
+	default:
+		return fmt.Errorf("unsupported path %q", op.Path)
+	}
+}
+
+func (p *scimGroupPatch) applyMembers(opName string, members []api.SCIMGroupMember) error {
+	ids, err := scimGroupMemberIDs(members)
+	if err != nil {
+		return err
+	}
+	if opName == "replace" {
+		p.members = make(map[uid.ID]bool, len(ids))
+	}
+	for _, id := range ids {
+		if opName == "remove" {
+			delete(p.members, id)
+			continue
+		}
+		p.members[id] = true
+	}
+	return nil
+}
+
+func scimMemberFromExpression(e filter.Expression) (uid.ID, error) {
+	exp, ok := e.(*filter.AttributeExpression)
+	if !ok || exp.AttributePath.AttributeName != "value" || exp.Operator != filter.EQ {
+		return 0, fmt.Errorf("unsupported member filter %q", e)
+	}
+	value, ok := exp.CompareValue.(string)
+	if !ok {
+		return 0, fmt.Errorf("unsupported member filter %q", e)
+	}
+	id, err := uid.Parse([]byte(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid member %q", value)
+	}
+	return id, nil
+}
+
+func GetSCIMServiceProviderConfig(_ access.RequestContext, _ *api.EmptyRequest) (*api.SCIMServiceProviderConfig, error) {
+	return &api.SCIMServiceProviderConfig{
+		Schemas:        []string{api.ServiceProviderConfigSchema},
+		Patch:          api.SCIMSupported{Supported: true},
+		Filter:         api.SCIMFilterSupport{Supported: true},
+		ChangePassword: api.SCIMSupported{Supported: false},
+		Sort:           api.SCIMSupported{Supported: false},
+		ETag:           api.SCIMSupported{Supported: false},
+		AuthenticationSchemes: []api.SCIMAuthenticationScheme{
+			{
+				Type:        "oauthbearertoken",
+				Name:        "OAuth Bearer Token",
+				Description: "Authentication with an Infra access key created for the identity provider",
+				Primary:     true,
+			},
+		},
+		Meta: api.SCIMMetadata{ResourceType: "ServiceProviderConfig"},
+	}, nil
+}
+
+func ListSCIMResourceTypes(_ access.RequestContext, _ *api.EmptyRequest) (*api.ListSCIMResourceTypesResponse, error) {
+	resourceTypes := []api.SCIMResourceType{
+		{
+			Schemas:     []string{api.ResourceTypeSchema},
+			ID:          "User",
+			Name:        "User",
+			Endpoint:    "/Users",
+			Description: "User Account",
+			Schema:      api.UserSchema,
+			SchemaExtensions: []api.SCIMSchemaExtension{
+				{Schema: api.EnterpriseUserSchema, Required: false},
+			},
+			Meta: api.SCIMMetadata{ResourceType: "ResourceType"},
+		},
+		{
+			Schemas:          []string{api.ResourceTypeSchema},
+			ID:               "Group",
+			Name:             "Group",
+			Endpoint:         "/Groups",
+			Description:      "Group",
+			Schema:           api.GroupSchema,
+			SchemaExtensions: []api.SCIMSchemaExtension{},
+			Meta:             api.SCIMMetadata{ResourceType: "ResourceType"},
+		},
+	}
+	return &api.ListSCIMResourceTypesResponse{
+		Schemas:      []string{api.ListResponseSchema},
+		TotalResults: len(resourceTypes),
+		Resources:    resourceTypes,
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+	}, nil
+}
+
+func ListSCIMSchemas(_ access.RequestContext, _ *api.EmptyRequest) (*api.ListSCIMSchemasResponse, error) {
+	schemas := []api.SCIMSchema{scimUserSchema, scimEnterpriseUserSchema, scimGroupSchema}
+	return &api.ListSCIMSchemasResponse{
+		Schemas:      []string{api.ListResponseSchema},
+		TotalResults: len(schemas),
+		Resources:    schemas,
+		StartIndex:   1,
+		ItemsPerPage: len(schemas),
+	}, nil
+}
+
+// scimAttribute returns the definition of a single valued, optional attribute
+// that can be read and written.
+func scimAttribute(name, kind string, subAttributes ...api.SCIMSchemaAttribute) api.SCIMSchemaAttribute {
+	return api.SCIMSchemaAttribute{
+		Name:          name,
+		Type:          kind,
+		Mutability:    "readWrite",
+		Returned:      "default",
+		Uniqueness:    "none",
+		SubAttributes: subAttributes,
+	}
+}
+
+var scimUserSchema = api.SCIMSchema{
+	Schemas:     []string{api.SchemaSchema},
+	ID:          api.UserSchema,
+	Name:        "User",
+	Description: "User Account",
+	Attributes: []api.SCIMSchemaAttribute{
+		{
+			Name:       "userName",
+			Type:       "string",
+			Required:   true,
+			Mutability: "readWrite",
+			Returned:   "default",
+			Uniqueness: "server",
+		},
+		scimAttribute("name", "complex",
+			scimAttribute("givenName", "string"),
+			scimAttribute("familyName", "string"),
+		),
+		{
+			Name:        "emails",
+			Type:        "complex",
+			MultiValued: true,
+			Mutability:  "readWrite",
+			Returned:    "default",
+			Uniqueness:  "none",
+			SubAttributes: []api.SCIMSchemaAttribute{
+				scimAttribute("value", "string"),
+				scimAttribute("primary", "boolean"),
+			},
+		},
+		scimAttribute("active", "boolean"),
+	},
+	Meta: api.SCIMMetadata{ResourceType: "Schema"},
+}
+
+var scimEnterpriseUserSchema = api.SCIMSchema{
+	Schemas:     []string{api.SchemaSchema},
+	ID:          api.EnterpriseUserSchema,
+	Name:        "EnterpriseUser",
+	Description: "Enterprise User",
+	Attributes: []api.SCIMSchemaAttribute{
+		scimAttribute("employeeNumber", "string"),
+		scimAttribute("department", "string"),
+		scimAttribute("manager", "complex",
+			scimAttribute("value", "string"),
+		),
+	},
+	Meta: api.SCIMMetadata{ResourceType: "Schema"},
+}
+
+var scimGroupSchema = api.SCIMSchema{
+	Schemas:     []string{api.SchemaSchema},
+	ID:          api.GroupSchema,
+	Name:        "Group",
+	Description: "Group",
+	Attributes: []api.SCIMSchemaAttribute{
+		{
+			Name:       "displayName",
+			Type:       "string",
+			Required:   true,
+			Mutability: "readWrite",
+			Returned:   "default",
+			Uniqueness: "server",
+		},
+		{
+			Name:        "members",
+			Type:        "complex",
+			MultiValued: true,
+			Mutability:  "readWrite",
+			Returned:    "default",
+			Uniqueness:  "none",
+			SubAttributes: []api.SCIMSchemaAttribute{
+				scimAttribute("value", "string"),
+				scimAttribute("display", "string"),
+			},
+		},
+	},
+	Meta: api.SCIMMetadata{ResourceType: "Schema"},
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -13,6 +14,7 @@ import (
 	"gotest.tools/v3/assert/opt"
 
 	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -898,3 +900,268 @@ func createTestSCIMUserIdentity(t *testing.T, db data.WriteTxn, provider *models
 
 	return testProviderUser
 }
+
+func TestAPI_ProviderGroups(t *testing.T) {
+	s := setupServer(t, withAdminUser)
+	bearer, _, routes := createTestSCIMProvider(t, s)
+
+	provider, err := data.GetProvider(s.DB(), data.GetProviderOptions{ByID: 1234})
+	assert.NilError(t, err)
+	alice := createTestSCIMUserIdentity(t, s.DB(), provider, 3001, "alice@example.com")
+	bob := createTestSCIMUserIdentity(t, s.DB(), provider, 3002, "bob@example.com")
+	other := createTestSCIMUserIdentity(t, s.DB(), data.InfraProvider(s.DB()), 3003, "other@example.com")
+
+	do := func(t *testing.T, method, path, key string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = jsonBody(t, body)
+		}
+		// nolint:noctx
+		req := httptest.NewRequest(method, path, reqBody)
+		req.Header.Add("Authorization", "Bearer "+key)
+		req.Header.Add("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder) api.SCIMGroup {
+		t.Helper()
+		var group api.SCIMGroup
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &group))
+		return group
+	}
+	member := func(user *models.ProviderUser) api.SCIMGroupMember {
+		return api.SCIMGroupMember{Value: user.IdentityID.String(), Display: user.Email}
+	}
+
+	var groupID string
+	t.Run("create", func(t *testing.T) {
+		body := api.SCIMGroupCreateRequest{
+			Schemas:     []string{api.GroupSchema},
+			DisplayName: "Engineering",
+			Members:     []api.SCIMGroupMember{{Value: alice.IdentityID.String()}},
+		}
+		resp := do(t, http.MethodPost, "/api/scim/v2/Groups", bearer, body)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		group := decode(t, resp)
+		groupID = group.ID
+		expected := api.SCIMGroup{
+			Schemas:     []string{api.GroupSchema},
+			ID:          groupID,
+			DisplayName: "Engineering",
+			Members:     []api.SCIMGroupMember{member(alice)},
+			Meta:        api.SCIMMetadata{ResourceType: "Group"},
+		}
+		assert.DeepEqual(t, group, expected)
+
+		created, err := data.GetGroup(s.DB(), data.GetGroupOptions{ByName: "Engineering"})
+		assert.NilError(t, err)
+		assert.Equal(t, created.CreatedByProvider, provider.ID)
+		assert.Assert(t, created.ProvisionedBySCIM)
+	})
+
+	t.Run("create with member from another provider", func(t *testing.T) {
+		body := api.SCIMGroupCreateRequest{
+			Schemas:     []string{api.GroupSchema},
+			DisplayName: "Other",
+			Members:     []api.SCIMGroupMember{{Value: other.IdentityID.String()}},
+		}
+		resp := do(t, http.MethodPost, "/api/scim/v2/Groups", bearer, body)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("list with filter", func(t *testing.T) {
+		resp := do(t, http.MethodGet, `/api/scim/v2/Groups?filter=displayName%20eq%20%22Engineering%22`, bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var list api.ListProviderGroupsResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+		assert.Equal(t, list.TotalResults, 1)
+		assert.Equal(t, list.Resources[0].ID, groupID)
+
+		resp = do(t, http.MethodGet, `/api/scim/v2/Groups?filter=displayName%20eq%20%22Sales%22`, bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+		assert.Equal(t, list.TotalResults, 0)
+	})
+
+	t.Run("patch add and remove members", func(t *testing.T) {
+		body := map[string]any{
+			"schemas": []string{api.PatchOperationSchema},
+			"Operations": []map[string]any{
+				{"op": "add", "path": "members", "value": []map[string]string{{"value": bob.IdentityID.String()}}},
+				{"op": "Remove", "path": fmt.Sprintf(`members[value eq "%v"]`, alice.IdentityID)},
+			},
+		}
+		resp := do(t, http.MethodPatch, "/api/scim/v2/Groups/"+groupID, bearer, body)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.DeepEqual(t, decode(t, resp).Members, []api.SCIMGroupMember{member(bob)})
+	})
+
+	t.Run("patch replace displayName", func(t *testing.T) {
+		body := map[string]any{
+			"schemas": []string{api.PatchOperationSchema},
+			"Operations": []map[string]any{
+				{"op": "replace", "value": map[string]string{"displayName": "Platform"}},
+			},
+		}
+		resp := do(t, http.MethodPatch, "/api/scim/v2/Groups/"+groupID, bearer, body)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Equal(t, decode(t, resp).DisplayName, "Platform")
+	})
+
+	t.Run("put", func(t *testing.T) {
+		body := api.SCIMGroupUpdateRequest{
+			Schemas:     []string{api.GroupSchema},
+			DisplayName: "Platform",
+			Members: []api.SCIMGroupMember{
+				{Value: alice.IdentityID.String()},
+				{Value: bob.IdentityID.String()},
+			},
+		}
+		resp := do(t, http.MethodPut, "/api/scim/v2/Groups/"+groupID, bearer, body)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.DeepEqual(t, decode(t, resp).Members, []api.SCIMGroupMember{member(alice), member(bob)})
+	})
+
+	t.Run("group can not be edited by hand", func(t *testing.T) {
+		body := api.UpdateUsersInGroupRequest{UserIDsToRemove: []uid.ID{alice.IdentityID}}
+		resp := do(t, http.MethodPatch, "/api/groups/"+groupID+"/users", adminAccessKey(s), body)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = do(t, http.MethodDelete, "/api/groups/"+groupID, adminAccessKey(s), nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("groups not created by the provider are not found", func(t *testing.T) {
+		group := &models.Group{Name: "manual"}
+		assert.NilError(t, data.CreateGroup(s.DB(), group))
+
+		resp := do(t, http.MethodGet, "/api/scim/v2/Groups/"+group.ID.String(), bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+
+		resp = do(t, http.MethodDelete, "/api/scim/v2/Groups/"+group.ID.String(), bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+
+	t.Run("groups created by a login can be edited and deleted", func(t *testing.T) {
+		_, err := data.AssignIdentityToGroups(s.DB(), bob, []string{"Login"})
+		assert.NilError(t, err)
+		group, err := data.GetGroup(s.DB(), data.GetGroupOptions{ByName: "Login"})
+		assert.NilError(t, err)
+		assert.Equal(t, group.CreatedByProvider, provider.ID)
+		assert.Assert(t, !group.ProvisionedBySCIM)
+
+		body := api.UpdateUsersInGroupRequest{UserIDsToRemove: []uid.ID{bob.IdentityID}}
+		resp := do(t, http.MethodPatch, "/api/groups/"+group.ID.String()+"/users", adminAccessKey(s), body)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = do(t, http.MethodDelete, "/api/groups/"+group.ID.String(), adminAccessKey(s), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		_, err = data.GetGroup(s.DB(), data.GetGroupOptions{ByID: group.ID})
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := do(t, http.MethodDelete, "/api/scim/v2/Groups/"+groupID, bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = do(t, http.MethodGet, "/api/scim/v2/Groups/"+groupID, bearer, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+}
+
+func TestAPI_CreateProviderUser_EnterpriseExtension(t *testing.T) {
+	s := setupServer(t, withAdminUser)
+	bearer, _, routes := createTestSCIMProvider(t, s)
+
+	body := api.SCIMUserCreateRequest{
+		Schemas:  []string{api.UserSchema, api.EnterpriseUserSchema},
+		UserName: "enterprise@example.com",
+		Emails:   []api.SCIMUserEmail{{Primary: true, Value: "enterprise@example.com"}},
+		Active:   true,
+		Enterprise: &api.SCIMEnterpriseUser{
+			EmployeeNumber: "701984",
+			Department:     "Tour Operations",
+			Manager:        &api.SCIMManager{Value: "26118915-6090-4610-87e4-49d8ca9f808d", DisplayName: "John Smith"},
+		},
+	}
+	// nolint:noctx
+	req := httptest.NewRequest(http.MethodPost, "/api/scim/v2/Users", jsonBody(t, body))
+	req.Header.Add("Authorization", "Bearer "+bearer)
+	resp := httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+	var created map[string]any
+	assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	expected := map[string]any{
+		"employeeNumber": "701984",
+		"department":     "Tour Operations",
+		"manager":        map[string]any{"value": "26118915-6090-4610-87e4-49d8ca9f808d"},
+	}
+	assert.DeepEqual(t, created[api.EnterpriseUserSchema], expected)
+	assert.DeepEqual(t, created["schemas"], []any{api.UserSchema, api.EnterpriseUserSchema})
+
+	// nolint:noctx
+	req = httptest.NewRequest(http.MethodGet, `/api/scim/v2/Users?filter=urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department%20eq%20%22Tour%20Operations%22`, nil)
+	req.Header.Add("Authorization", "Bearer "+bearer)
+	resp = httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+	var list api.ListProviderUsersResponse
+	assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	assert.Equal(t, list.TotalResults, 1)
+	assert.Equal(t, list.Resources[0].UserName, "enterprise@example.com")
+	assert.Equal(t, list.Resources[0].Enterprise.Department, "Tour Operations")
+}
+
+func TestAPI_SCIMDiscovery(t *testing.T) {
+	s := setupServer(t)
+	routes := s.GenerateRoutes()
+
+	get := func(t *testing.T, path string, into any) {
+		t.Helper()
+		// nolint:noctx
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), into))
+	}
+
+	t.Run("service provider config", func(t *testing.T) {
+		var config api.SCIMServiceProviderConfig
+		get(t, "/api/scim/v2/ServiceProviderConfig", &config)
+		assert.DeepEqual(t, config.Schemas, []string{api.ServiceProviderConfigSchema})
+		assert.Assert(t, config.Patch.Supported)
+		assert.Assert(t, config.Filter.Supported)
+		assert.Assert(t, !config.Bulk.Supported)
+		assert.Equal(t, config.AuthenticationSchemes[0].Type, "oauthbearertoken")
+	})
+
+	t.Run("resource types", func(t *testing.T) {
+		var list api.ListSCIMResourceTypesResponse
+		get(t, "/api/scim/v2/ResourceTypes", &list)
+		assert.Equal(t, list.TotalResults, 2)
+		assert.Equal(t, list.Resources[0].Endpoint, "/Users")
+		assert.DeepEqual(t, list.Resources[0].SchemaExtensions,
+			[]api.SCIMSchemaExtension{{Schema: api.EnterpriseUserSchema}})
+		assert.Equal(t, list.Resources[1].Endpoint, "/Groups")
+	})
+
+	t.Run("schemas", func(t *testing.T) {
+		var list api.ListSCIMSchemasResponse
+		get(t, "/api/scim/v2/Schemas", &list)
+		var ids []string
+		for _, schema := range list.Resources {
+			ids = append(ids, schema.ID)
+		}
+		assert.DeepEqual(t, ids, []string{api.UserSchema, api.EnterpriseUserSchema, api.GroupSchema})
+	})
+}
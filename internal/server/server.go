@@ -9,8 +9,10 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/netip"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -57,6 +59,16 @@ type Options struct {
 	// Redis contains configuration options to the cache server.
 	Redis redis.Options
 
+	// Login contains options for the protection of password logins from
+	// brute force attacks. Unlike the rate limits, the failures are stored in
+	// the database, so they are shared by all servers without Redis.
+	Login LoginOptions
+
+	// TrustedProxies are the IP addresses or CIDR ranges of the proxies, like
+	// load balancers, that set X-Forwarded-For. The client IP address is only
+	// read from X-Forwarded-For when the request comes from a trusted proxy.
+	TrustedProxies []string
+
 	GoogleClientID     string
 	GoogleClientSecret string
 
@@ -127,20 +139,51 @@ type TLSOptions struct {
 	ACME bool
 }
 
+type LoginOptions struct {
+	// MaxFailures is the number of failed password logins for a username
+	// after which the username is locked out. Lockout is disabled when it is 0.
+	MaxFailures int
+	// MaxFailuresPerIP is the number of failed password logins from a source
+	// IP address, for any username, after which the address is locked out.
+	MaxFailuresPerIP int
+	// Lockout is how long a username or address is locked out. It doubles
+	// with every failed login after that, up to MaxLockout.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
 type APIOptions struct {
 	RequestTimeout         time.Duration
 	BlockingRequestTimeout time.Duration
+
+	// OrganizationQuota is the number of requests per minute allowed for
+	// each organization. There is no limit when it is 0.
+	OrganizationQuota int
+	// AccessKeyQuota is the number of requests per minute allowed for each
+	// access key. There is no limit when it is 0.
+	AccessKeyQuota int
 }
 
 type Server struct {
-	options         Options
-	db              *data.DB
-	redis           *redis.Redis
+	options Options
+	db      *data.DB
+	redis   *redis.Redis
+	// limiter limits the rate of requests. It is shared by all servers when
+	// Redis is configured, and limits the requests to this server otherwise.
+	limiter         redis.RateLimiter
 	tel             *Telemetry
 	Addrs           Addrs
 	routines        []routine
 	metricsRegistry *prometheus.Registry
 	Google          *models.Provider
+	// trustedProxies are the parsed Options.TrustedProxies
+	trustedProxies []netip.Prefix
+
+	// backgroundCtx is cancelled when the server stops. It is used by work
+	// that is started by a request, and continues after the response is sent.
+	backgroundCtx  context.Context
+	stopBackground context.CancelFunc
+	background     *sync.WaitGroup
 }
 
 type Addrs struct {
@@ -151,7 +194,14 @@ type Addrs struct {
 
 // newServer creates a Server with base dependencies initialized to zero values.
 func newServer(options Options) *Server {
-	return &Server{options: options}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		options:        options,
+		limiter:        redis.NewMemoryLimiter(),
+		backgroundCtx:  ctx,
+		stopBackground: cancel,
+		background:     &sync.WaitGroup{},
+	}
 }
 
 // New creates a Server, and initializes it. The returned Server is ready to run.
@@ -175,6 +225,12 @@ func New(options Options) (*Server, error) {
 
 	server := newServer(options)
 
+	trustedProxies, err := parseTrustedProxies(options.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	server.trustedProxies = trustedProxies
+
 	dsn, err := getPostgresConnectionString(options)
 	if err != nil {
 		return nil, fmt.Errorf("postgres dsn: %w", err)
@@ -199,6 +255,9 @@ func New(options Options) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	if server.redis != nil {
+		server.limiter = redis.NewLimiter(server.redis)
+	}
 
 	if options.EnableTelemetry {
 		server.tel = NewTelemetry(server.db, db.DefaultOrg.InstallID)
@@ -250,6 +309,11 @@ func (s *Server) Run(ctx context.Context) error {
 	group.Go(backgroundJob(ctx, s.db, data.RemoveExpiredAccessKeys, 12*time.Hour))
 	group.Go(backgroundJob(ctx, s.db, data.RemoveExpiredPasswordResetTokens, 15*time.Minute))
 	group.Go(backgroundJob(ctx, s.db, data.DeleteExpiredUserPublicKeys, time.Hour))
+	group.Go(backgroundJob(ctx, s.db, data.DeleteExpiredGrants, time.Minute))
+	group.Go(backgroundJob(ctx, s.db, data.DeleteExpiredLoginFailures, time.Hour))
+	group.Go(backgroundJob(ctx, s.db, data.DeleteExpiredSAMLRequests, 10*time.Minute))
+	group.Go(backgroundJob(ctx, s.db, data.RotateExpiredSigningKeys, 10*time.Minute))
+	group.Go(s.runDirectorySync(ctx))
 
 	if s.tel != nil {
 		group.Go(func() error {
@@ -270,6 +334,8 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 
 	err := group.Wait()
+	s.stopBackground()
+	s.background.Wait()
 	s.tel.Close()
 
 	if err := s.db.Close(); err != nil {
@@ -282,6 +348,18 @@ func (s *Server) Run(ctx context.Context) error {
 	return err
 }
 
+// runInBackground runs fn in a new goroutine, for work that is started by a
+// request and continues after the response is sent. The context passed to fn
+// is cancelled when the server stops, and the server waits for fn to return
+// before it closes the database.
+func (s *Server) runInBackground(fn func(ctx context.Context)) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn(s.backgroundCtx)
+	}()
+}
+
 func runTelemetryHeartbeat(ctx context.Context, tel *Telemetry) error {
 	waiter := repeat.NewWaiter(backoff.NewConstantBackOff(time.Hour))
 	for {
@@ -475,10 +553,19 @@ func (s *Server) syncIdentityInfo(ctx context.Context, tx *data.Transaction, ide
 			return fmt.Errorf("failed to get provider for user info: %w", err)
 		}
 
-		if provider.Kind == models.ProviderKindInfra {
+		switch provider.Kind { // nolint:exhaustive
+		case models.ProviderKindInfra:
 			// no external verification needed
 			logging.L.Trace().Msg("skipped verifying identity within infra provider, not required")
 			return nil
+		case models.ProviderKindSAML:
+			// saml identity providers have no API to query, the user's groups
+			// are updated from the assertion on each login
+			return nil
+		case models.ProviderKindLDAP:
+			// the user's groups are read from the directory on each login,
+			// which requires the user's password
+			return nil
 		}
 	}
 
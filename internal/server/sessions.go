@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// maxUserAgentLength limits the size of the user agent stored for a session.
+const maxUserAgentLength = 256
+
+// accessKeyClient returns the client that made the request, to record on the
+// access key used for the request.
+func (s *Server) accessKeyClient(req *http.Request) data.AccessKeyClient {
+	userAgent := req.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return data.AccessKeyClient{IP: s.clientIP(req), UserAgent: userAgent}
+}
+
+func (a *API) ListSessions(rCtx access.RequestContext, r *api.ListSessionsRequest) (*api.ListResponse[api.Session], error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := PaginationFromRequest(r.PaginationRequest)
+	keys, err := access.ListSessions(rCtx, userID, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	providerNames := map[uid.ID]string{}
+	for _, key := range keys {
+		if _, ok := providerNames[key.ProviderID]; ok {
+			continue
+		}
+		provider, err := data.GetProvider(rCtx.DBTxn, data.GetProviderOptions{ByID: key.ProviderID})
+		if err != nil {
+			// the provider may have been deleted since the login
+			providerNames[key.ProviderID] = ""
+			continue
+		}
+		providerNames[key.ProviderID] = provider.Name
+	}
+
+	var currentID uid.ID
+	if rCtx.Authenticated.AccessKey != nil {
+		currentID = rCtx.Authenticated.AccessKey.ID
+	}
+
+	result := api.NewListResponse(keys, PaginationToResponse(p), func(key models.AccessKey) api.Session {
+		return api.Session{
+			ID:           key.ID,
+			Created:      api.Time(key.CreatedAt),
+			LastUsed:     api.Time(key.UpdatedAt),
+			Expires:      api.Time(key.ExpiresAt),
+			ProviderID:   key.ProviderID,
+			ProviderName: providerNames[key.ProviderID],
+			LoginMethod:  key.LoginMethod,
+			ClientIP:     key.ClientIP,
+			UserAgent:    key.UserAgent,
+			Current:      key.ID == currentID,
+		}
+	})
+	return result, nil
+}
+
+func (a *API) DeleteSession(rCtx access.RequestContext, r *api.DeleteSessionRequest) (*api.EmptyResponse, error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return nil, access.DeleteSession(rCtx, userID, r.SessionID)
+}
+
+func (a *API) DeleteSessions(rCtx access.RequestContext, r *api.DeleteSessionsRequest) (*api.EmptyResponse, error) {
+	userID, err := userIDOrSelf(rCtx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var exceptID uid.ID
+	if r.ExceptCurrent && rCtx.Authenticated.AccessKey != nil {
+		exceptID = rCtx.Authenticated.AccessKey.ID
+	}
+	return nil, access.DeleteSessions(rCtx, userID, exceptID)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_Sessions(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	user := &models.Identity{Name: "sessions@example.com"}
+	assert.NilError(t, data.CreateIdentity(srv.DB(), user))
+	_, err := data.CreateProviderUser(srv.DB(), data.InfraProvider(srv.DB()), user)
+	assert.NilError(t, err)
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	assert.NilError(t, err)
+	assert.NilError(t, data.CreateCredential(srv.DB(), &models.Credential{
+		IdentityID:   user.ID,
+		PasswordHash: hash,
+	}))
+
+	// a key created with the API is not a session
+	_, err = data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		Name:        "not-a-session",
+		IssuedForID: user.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	call := func(t *testing.T, method, path, accessKey string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if accessKey != "" {
+			req.Header.Set("Authorization", "Bearer "+accessKey)
+		}
+		req.Header.Set("Infra-Version", apiVersionLatest)
+		req.Header.Set("User-Agent", "sessions-test/1.0")
+		req.RemoteAddr = "192.0.2.10:52000"
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func(t *testing.T) api.LoginResponse {
+		t.Helper()
+		body := api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: user.Name, Password: "hunter2"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/login", jsonBody(t, body))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+		req.Header.Set("User-Agent", "sessions-test/1.0")
+		req.RemoteAddr = "192.0.2.10:52000"
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+		return loginResp
+	}
+
+	listSessions := func(t *testing.T, path, accessKey string) []api.Session {
+		t.Helper()
+		resp := call(t, http.MethodGet, path, accessKey)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var sessions api.ListResponse[api.Session]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &sessions))
+		return sessions.Items
+	}
+
+	first := login(t)
+	second := login(t)
+
+	t.Run("list own sessions", func(t *testing.T) {
+		sessions := listSessions(t, "/api/users/self/sessions", first.AccessKey)
+		assert.Equal(t, len(sessions), 2)
+
+		var current int
+		for _, session := range sessions {
+			assert.Equal(t, session.LoginMethod, "credentials")
+			assert.Equal(t, session.ProviderName, models.InternalInfraProviderName)
+			assert.Equal(t, session.ClientIP, "192.0.2.10")
+			assert.Equal(t, session.UserAgent, "sessions-test/1.0")
+			if session.Current {
+				current++
+			}
+		}
+		assert.Equal(t, current, 1)
+	})
+
+	t.Run("list requires admin for other users", func(t *testing.T) {
+		resp := call(t, http.MethodGet, "/api/users/"+user.ID.String()+"/sessions", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		other := &models.Identity{Name: "other@example.com"}
+		createIdentities(t, srv.DB(), other)
+		resp = call(t, http.MethodGet, "/api/users/"+other.ID.String()+"/sessions", first.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("revoke one session", func(t *testing.T) {
+		sessions := listSessions(t, "/api/users/self/sessions", first.AccessKey)
+		var secondID string
+		for _, session := range sessions {
+			if !session.Current {
+				secondID = session.ID.String()
+			}
+		}
+
+		resp := call(t, http.MethodDelete, "/api/users/self/sessions/"+secondID, first.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = call(t, http.MethodGet, "/api/users/self/sessions", second.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("revoke a key that is not a session", func(t *testing.T) {
+		key, err := data.GetAccessKey(srv.DB(), data.GetAccessKeysOptions{ByName: "not-a-session", IssuedForID: user.ID})
+		assert.NilError(t, err)
+
+		resp := call(t, http.MethodDelete, "/api/users/self/sessions/"+key.ID.String(), first.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+
+	t.Run("admin revokes all sessions", func(t *testing.T) {
+		third := login(t)
+
+		resp := call(t, http.MethodDelete, "/api/users/"+user.ID.String()+"/sessions", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		for _, key := range []string{first.AccessKey, third.AccessKey} {
+			resp = call(t, http.MethodGet, "/api/users/self/sessions", key)
+			assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		}
+
+		// keys that are not sessions are not revoked
+		_, err := data.GetAccessKey(srv.DB(), data.GetAccessKeysOptions{ByName: "not-a-session", IssuedForID: user.ID})
+		assert.NilError(t, err)
+	})
+
+	t.Run("revoke all except the current session", func(t *testing.T) {
+		current := login(t)
+		other := login(t)
+
+		resp := call(t, http.MethodDelete, "/api/users/self/sessions?exceptCurrent=true", current.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		sessions := listSessions(t, "/api/users/self/sessions", current.AccessKey)
+		assert.Equal(t, len(sessions), 1)
+		assert.Assert(t, sessions[0].Current)
+
+		resp = call(t, http.MethodGet, "/api/users/self/sessions", other.AccessKey)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func (a *API) ListSigningKeys(rCtx access.RequestContext, r *api.ListSigningKeysRequest) (*api.ListResponse[api.SigningKey], error) {
+	p := PaginationFromRequest(r.PaginationRequest)
+	keys, err := access.ListSigningKeys(rCtx, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(keys, PaginationToResponse(p), func(key models.SigningKey) api.SigningKey {
+		return *key.ToAPI()
+	})
+	return result, nil
+}
+
+func (a *API) RotateSigningKey(rCtx access.RequestContext, _ *api.EmptyRequest) (*api.SigningKey, error) {
+	key, err := access.RotateSigningKey(rCtx)
+	if err != nil {
+		return nil, err
+	}
+	return key.ToAPI(), nil
+}
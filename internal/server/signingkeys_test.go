@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_SigningKeys(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	user := &models.Identity{Name: "user@example.com"}
+	createIdentities(t, srv.DB(), user)
+
+	userKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: user.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	do := func(t *testing.T, method, path, key string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	jwks := func(t *testing.T) WellKnownJWKResponse {
+		t.Helper()
+		resp := do(t, http.MethodGet, "/.well-known/jwks.json", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var keys WellKnownJWKResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&keys))
+		return keys
+	}
+
+	var initial api.ListResponse[api.SigningKey]
+	t.Run("list", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/signing-keys", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&initial))
+		assert.Equal(t, len(initial.Items), 1)
+		assert.Equal(t, initial.Items[0].State, "active")
+
+		keys := jwks(t)
+		assert.Equal(t, len(keys.Keys), 1)
+		assert.Equal(t, keys.Keys[0].KeyID, initial.Items[0].KeyID)
+	})
+
+	t.Run("rotate requires admin", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/api/signing-keys/rotate", userKey)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/api/signing-keys/rotate", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.SigningKey
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, created.State, "active")
+		assert.Assert(t, created.KeyID != initial.Items[0].KeyID)
+
+		resp = do(t, http.MethodGet, "/api/signing-keys", adminAccessKey(srv))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var actual api.ListResponse[api.SigningKey]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		assert.Equal(t, len(actual.Items), 2)
+		assert.Equal(t, actual.Items[0].KeyID, created.KeyID)
+		assert.Equal(t, actual.Items[1].KeyID, initial.Items[0].KeyID)
+		assert.Equal(t, actual.Items[1].State, "verifying")
+
+		// both keys are published, so tokens signed by the previous key are
+		// still valid
+		keys := jwks(t)
+		assert.Equal(t, len(keys.Keys), 2)
+		assert.Equal(t, keys.Keys[0].KeyID, created.KeyID)
+		assert.Equal(t, keys.Keys[1].KeyID, initial.Items[0].KeyID)
+	})
+}
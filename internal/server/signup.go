@@ -34,6 +34,12 @@ func (a *API) Signup(rCtx access.RequestContext, r *api.SignupRequest) (*api.Sig
 		return nil, fmt.Errorf("%w: signup is disabled", internal.ErrBadRequest)
 	}
 
+	// signup does not have an organization, so the rate is limited by the
+	// source address instead of by the organization quota
+	if err := a.server.limiter.RateOK("signup:"+a.server.clientIP(rCtx.Request), 10); err != nil {
+		return nil, err
+	}
+
 	keyExpires := time.Now().UTC().Add(a.server.options.SessionDuration)
 
 	var created *NewOrgDetails
@@ -67,7 +73,7 @@ func (a *API) Signup(rCtx access.RequestContext, r *api.SignupRequest) (*api.Sig
 			Org:       &models.Organization{Name: r.OrgName},
 			SubDomain: r.Subdomain,
 		}
-		created, err = createOrgAndUserForSignup(rCtx, keyExpires, a.server.options.BaseDomain, details)
+		created, err = createOrgAndUserForSignup(rCtx, keyExpires, a.server.options.BaseDomain, details, a.server.accessKeyClient(rCtx.Request))
 		if err != nil {
 			return nil, handleSignupError(err)
 		}
@@ -81,7 +87,7 @@ func (a *API) Signup(rCtx access.RequestContext, r *api.SignupRequest) (*api.Sig
 			SubDomain: r.Subdomain,
 		}
 		var err error
-		created, err = createOrgAndUserForSignup(rCtx, keyExpires, a.server.options.BaseDomain, details)
+		created, err = createOrgAndUserForSignup(rCtx, keyExpires, a.server.options.BaseDomain, details, a.server.accessKeyClient(rCtx.Request))
 		if err != nil {
 			return nil, handleSignupError(err)
 		}
@@ -149,7 +155,7 @@ func (a *API) socialSignupUserAuth(rCtx access.RequestContext, provider *models.
 	auth.OIDCProviderClient = providerClient
 
 	// exchange code for tokens from identity provider (these tokens are for the IDP, not Infra)
-	result, err := auth.OIDCProviderClient.ExchangeAuthCodeForProviderTokens(rCtx.Request.Context(), auth.Code)
+	result, err := auth.OIDCProviderClient.ExchangeAuthCodeForProviderTokens(rCtx.Request.Context(), auth.Code, "")
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return nil, fmt.Errorf("%w: %s", internal.ErrBadGateway, err.Error())
@@ -191,7 +197,7 @@ type SignupDetails struct {
 
 // createOrgAndUserForSignup creates a user identity using the supplied name and password and
 // grants the identity "admin" access to Infra.
-func createOrgAndUserForSignup(rCtx access.RequestContext, keyExpiresAt time.Time, baseDomain string, details SignupDetails) (*NewOrgDetails, error) {
+func createOrgAndUserForSignup(rCtx access.RequestContext, keyExpiresAt time.Time, baseDomain string, details SignupDetails, client data.AccessKeyClient) (*NewOrgDetails, error) {
 	if details.Social == nil && details.User == nil {
 		return nil, fmt.Errorf("sign-up requires social login details or user details")
 	}
@@ -241,12 +247,12 @@ func createOrgAndUserForSignup(rCtx access.RequestContext, keyExpiresAt time.Tim
 		}
 
 		var err error
-		identity, bearer, err = signupUser(rCtx, keyExpiresAt, user)
+		identity, bearer, err = signupUser(rCtx, keyExpiresAt, user, client)
 		if err != nil {
 			return nil, err
 		}
 
-		hash, err := access.GenerateFromPassword(details.User.Password)
+		hash, err := access.GenerateFromPassword(details.Org.PasswordPolicy, details.User.Password)
 		if err != nil {
 			return nil, fmt.Errorf("hash password on sign-up: %w", err)
 		}
@@ -273,7 +279,7 @@ func createOrgAndUserForSignup(rCtx access.RequestContext, keyExpiresAt time.Tim
 		}
 
 		var err error
-		identity, bearer, err = signupUser(rCtx, keyExpiresAt, user)
+		identity, bearer, err = signupUser(rCtx, keyExpiresAt, user, client)
 		if err != nil {
 			return nil, err
 		}
@@ -290,7 +296,7 @@ func createOrgAndUserForSignup(rCtx access.RequestContext, keyExpiresAt time.Tim
 }
 
 // signupUser creates the user identity and grants for a new org
-func signupUser(rCtx access.RequestContext, keyExpiresAt time.Time, user *models.ProviderUser) (*models.Identity, string, error) {
+func signupUser(rCtx access.RequestContext, keyExpiresAt time.Time, user *models.ProviderUser, client data.AccessKeyClient) (*models.Identity, string, error) {
 	tx := rCtx.DBTxn
 
 	identity := &models.Identity{
@@ -325,7 +331,9 @@ func signupUser(rCtx access.RequestContext, keyExpiresAt time.Time, user *models
 		ProviderID:    user.ProviderID,
 		ExpiresAt:     keyExpiresAt,
 		Scopes:        []string{models.ScopeAllowCreateAccessKey},
+		LoginMethod:   "signup",
 	}
+	accessKey.ClientIP, accessKey.UserAgent = client.IP, client.UserAgent
 
 	bearer, err := data.CreateAccessKey(tx, accessKey)
 	if err != nil {
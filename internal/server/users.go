@@ -174,6 +174,10 @@ func (a *API) DeleteUser(rCtx access.RequestContext, r *api.Resource) (*api.Empt
 	return nil, access.DeleteIdentity(rCtx, r.ID)
 }
 
+func (a *API) UnlockUser(rCtx access.RequestContext, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.UnlockIdentity(rCtx, r.ID)
+}
+
 func AddUserPublicKey(rCtx access.RequestContext, r *api.AddUserPublicKeyRequest) (*api.UserPublicKey, error) {
 	// no authz required, because the userID comes from authenticated User.ID
 	if rCtx.Authenticated.User == nil {
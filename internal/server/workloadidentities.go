@@ -0,0 +1,53 @@
+package server
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func (a *API) ListWorkloadIdentities(rCtx access.RequestContext, r *api.ListWorkloadIdentitiesRequest) (*api.ListResponse[api.WorkloadIdentity], error) {
+	p := PaginationFromRequest(r.PaginationRequest)
+	opts := data.ListWorkloadIdentitiesOptions{
+		ByIssuer:   r.Issuer,
+		Pagination: &p,
+	}
+	workloads, err := access.ListWorkloadIdentities(rCtx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(workloads, PaginationToResponse(p), func(w models.WorkloadIdentity) api.WorkloadIdentity {
+		return *w.ToAPI()
+	})
+	return result, nil
+}
+
+func (a *API) GetWorkloadIdentity(rCtx access.RequestContext, r *api.Resource) (*api.WorkloadIdentity, error) {
+	w, err := access.GetWorkloadIdentity(rCtx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return w.ToAPI(), nil
+}
+
+func (a *API) CreateWorkloadIdentity(rCtx access.RequestContext, r *api.CreateWorkloadIdentityRequest) (*api.WorkloadIdentity, error) {
+	w := &models.WorkloadIdentity{
+		Name:       r.Name,
+		Issuer:     r.Issuer,
+		JWKSURL:    r.JWKSURL,
+		Audience:   r.Audience,
+		Subject:    r.Subject,
+		IdentityID: r.UserID,
+		Scopes:     r.Scopes,
+	}
+	if err := access.CreateWorkloadIdentity(rCtx, w); err != nil {
+		return nil, err
+	}
+	return w.ToAPI(), nil
+}
+
+func (a *API) DeleteWorkloadIdentity(rCtx access.RequestContext, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteWorkloadIdentity(rCtx, r.ID)
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_WorkloadIdentities(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	deployer := &models.Identity{Name: "deploy@example.com"}
+	user := &models.Identity{Name: "user@example.com"}
+	createIdentities(t, srv.DB(), deployer, user)
+
+	userKey, err := data.CreateAccessKey(srv.DB(), &models.AccessKey{
+		IssuedForID: user.ID,
+		ProviderID:  data.InfraProvider(srv.DB()).ID,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	})
+	assert.NilError(t, err)
+
+	do := func(t *testing.T, method, path, key string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var req *http.Request
+		if body != nil {
+			req = httptest.NewRequest(method, path, jsonBody(t, body))
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	createReq := api.CreateWorkloadIdentityRequest{
+		Name:     "github-deploy",
+		Issuer:   "https://token.actions.githubusercontent.com",
+		JWKSURL:  "https://token.actions.githubusercontent.com/.well-known/jwks",
+		Audience: "https://infra.example.com",
+		Subject:  "repo:infrahq/infra:ref:refs/heads/main",
+		UserID:   deployer.ID,
+		Scopes:   []string{"grants:read"},
+	}
+
+	var created api.WorkloadIdentity
+	t.Run("create", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/api/workload-identities", adminAccessKey(srv), createReq)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, created.Name, "github-deploy")
+		assert.Equal(t, created.UserID, deployer.ID)
+		assert.DeepEqual(t, created.Scopes, []string{"grants:read"})
+	})
+
+	t.Run("create requires admin", func(t *testing.T) {
+		req := createReq
+		req.Name = "by-user"
+		resp := do(t, http.MethodPost, "/api/workload-identities", userKey, req)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("create with invalid fields", func(t *testing.T) {
+		req := createReq
+		req.Name = "invalid"
+		req.JWKSURL = "http://example.com/jwks"
+		req.Scopes = []string{"grants"}
+		resp := do(t, http.MethodPost, "/api/workload-identities", adminAccessKey(srv), req)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		var respErr api.Error
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&respErr))
+		expected := []api.FieldError{
+			{FieldName: "jwksURL", Errors: []string{"must be an https URL"}},
+			{FieldName: "scopes", Errors: []string{`scope "grants" must be in the form resource:action or resource:action:id`}},
+		}
+		assert.DeepEqual(t, respErr.FieldErrors, expected)
+	})
+
+	t.Run("create for a user that does not exist", func(t *testing.T) {
+		req := createReq
+		req.Name = "missing-user"
+		req.UserID = 12345
+		resp := do(t, http.MethodPost, "/api/workload-identities", adminAccessKey(srv), req)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("list", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/api/workload-identities?issuer=https://token.actions.githubusercontent.com", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var actual api.ListResponse[api.WorkloadIdentity]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&actual))
+		assert.Equal(t, len(actual.Items), 1)
+		assert.Equal(t, actual.Items[0].ID, created.ID)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := do(t, http.MethodDelete, "/api/workload-identities/"+created.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = do(t, http.MethodGet, "/api/workload-identities/"+created.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+}
+
+func TestAPI_Login_WorkloadIdentity(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwk := jose.JSONWebKey{Key: key.Public(), KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"}
+		assert.Check(t, json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{jwk}}))
+	}))
+	t.Cleanup(jwks.Close)
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	assert.NilError(t, err)
+
+	deployer := &models.Identity{Name: "deploy@example.com"}
+	createIdentities(t, srv.DB(), deployer)
+
+	// created directly, because the API only accepts https URLs
+	assert.NilError(t, data.CreateWorkloadIdentity(srv.DB(), &models.WorkloadIdentity{
+		Name:       "gitlab-deploy",
+		Issuer:     "https://gitlab.com",
+		JWKSURL:    jwks.URL,
+		Audience:   "https://infra.example.com",
+		Subject:    "project_path:infrahq/infra:ref_type:branch:ref:*",
+		IdentityID: deployer.ID,
+		Scopes:     models.CommaSeparatedStrings{"grants:read"},
+	}))
+	assert.NilError(t, data.CreateWorkloadIdentity(srv.DB(), &models.WorkloadIdentity{
+		Name:       "gitlab-release",
+		Issuer:     "https://gitlab.com",
+		JWKSURL:    jwks.URL,
+		Audience:   "https://infra.example.com",
+		Subject:    "project_path:infrahq/release:*",
+		IdentityID: deployer.ID,
+	}))
+
+	login := func(t *testing.T, claims jwt.Claims) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		assert.NilError(t, err)
+
+		body := api.LoginRequest{WorkloadIdentity: &api.LoginRequestWorkloadIdentity{Token: token}}
+		req := httptest.NewRequest(http.MethodPost, "/api/login", jsonBody(t, body))
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resp := login(t, jwt.Claims{
+			Issuer:   "https://gitlab.com",
+			Subject:  "project_path:infrahq/infra:ref_type:branch:ref:main",
+			Audience: jwt.Audience{"https://infra.example.com"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&loginResp))
+		assert.Equal(t, loginResp.UserID, deployer.ID)
+		assert.Assert(t, time.Time(loginResp.Expires).Before(time.Now().Add(time.Hour+time.Minute)))
+
+		accessKey, err := data.ValidateRequestAccessKey(srv.DB(), loginResp.AccessKey, data.AccessKeyClient{})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []string(accessKey.Scopes), []string{"grants:read"})
+	})
+
+	t.Run("key without scopes can not create access keys", func(t *testing.T) {
+		resp := login(t, jwt.Claims{
+			Issuer:   "https://gitlab.com",
+			Subject:  "project_path:infrahq/release:ref_type:tag:ref:v1.0.0",
+			Audience: jwt.Audience{"https://infra.example.com"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&loginResp))
+
+		body := api.CreateAccessKeyRequest{
+			IssuedForID: deployer.ID,
+			Name:        "long-lived",
+			Expiry:      api.Duration(24 * time.Hour),
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/access-keys", jsonBody(t, body))
+		req.Header.Set("Authorization", "Bearer "+loginResp.AccessKey)
+		req.Header.Set("Infra-Version", apiVersionLatest)
+
+		resp = httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("subject does not match", func(t *testing.T) {
+		resp := login(t, jwt.Claims{
+			Issuer:   "https://gitlab.com",
+			Subject:  "project_path:infrahq/other:ref_type:branch:ref:main",
+			Audience: jwt.Audience{"https://infra.example.com"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+}
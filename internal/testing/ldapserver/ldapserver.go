@@ -0,0 +1,424 @@
+/*
+Package ldapserver provides an in-process LDAP server for tests. It supports
+simple bind, search, and StartTLS, for a fixed set of entries.
+*/
+package ldapserver
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"gotest.tools/v3/assert"
+)
+
+type TestingT interface {
+	assert.TestingT
+	Helper()
+	Cleanup(func())
+}
+
+// Entry is an entry in the directory.
+type Entry struct {
+	DN string
+	// Password is the password used to bind as this entry. Entries without a
+	// password can not bind.
+	Password   string
+	Attributes map[string][]string
+}
+
+type Server struct {
+	// URL is the URL used to connect to the server.
+	URL string
+	// CACertificate is the PEM encoded certificate that signed the TLS
+	// certificate of the server.
+	CACertificate string
+
+	entries   []Entry
+	listener  net.Listener
+	tlsConfig *tls.Config
+	wg        sync.WaitGroup
+}
+
+// New starts an LDAP server that accepts plain connections, which can be
+// upgraded using StartTLS. The server is stopped when the test ends.
+func New(t TestingT, entries ...Entry) *Server {
+	t.Helper()
+	s := newServer(t, entries)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	s.start(t, listener, "ldap://")
+	return s
+}
+
+// NewTLS starts an LDAP server that only accepts TLS connections (LDAPS).
+// The server is stopped when the test ends.
+func NewTLS(t TestingT, entries ...Entry) *Server {
+	t.Helper()
+	s := newServer(t, entries)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", s.tlsConfig)
+	assert.NilError(t, err)
+	s.start(t, listener, "ldaps://")
+	return s
+}
+
+func newServer(t TestingT, entries []Entry) *Server {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	// the certificate only has a DNS name, so that clients must verify it
+	// using the host name from the URL, not the IP address they connected to
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldap.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		DNSNames:              []string{"localhost"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+
+	return &Server{
+		CACertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw})),
+		entries:       entries,
+		tlsConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{{Certificate: [][]byte{raw}, PrivateKey: key}},
+		},
+	}
+}
+
+func (s *Server) start(t TestingT, listener net.Listener, scheme string) {
+	s.listener = listener
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	s.URL = scheme + net.JoinHostPort("localhost", port)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.wg.Wait()
+	})
+}
+
+type session struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// bound is true when the connection has authenticated as an entry
+	bound bool
+}
+
+// oidStartTLS is the name of the StartTLS extended operation.
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// Filter choices from RFC 4511, section 4.5.1.
+const (
+	filterAnd       = 0
+	filterOr        = 1
+	filterNot       = 2
+	filterEqual     = 3
+	filterSubstring = 4
+	filterPresent   = 7
+)
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	// connections are closed by the client, this deadline only prevents a
+	// broken test from blocking the cleanup of the server forever
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+
+	sess := &session{conn: conn, reader: bufio.NewReader(conn)}
+	for {
+		message, err := ber.ReadPacket(sess.reader)
+		if err != nil {
+			return
+		}
+		if len(message.Children) < 2 {
+			return
+		}
+		id, ok := message.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		op := message.Children[1]
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			err = s.bind(sess, id, op)
+		case ldap.ApplicationSearchRequest:
+			err = s.search(sess, id, op)
+		case ldap.ApplicationExtendedRequest:
+			err = s.extended(sess, id, op)
+		default:
+			// unbind, or an operation that is not supported
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) bind(sess *session, id int64, op *ber.Packet) error {
+	if len(op.Children) != 3 {
+		return sess.result(id, ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "invalid bind request")
+	}
+	name, password := text(op.Children[1]), text(op.Children[2])
+
+	sess.bound = false
+	if name == "" && password == "" {
+		return sess.result(id, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+
+	for _, entry := range s.entries {
+		if normalizeDN(entry.DN) == normalizeDN(name) && entry.Password != "" && entry.Password == password {
+			sess.bound = true
+			return sess.result(id, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+		}
+	}
+	return sess.result(id, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *Server) search(sess *session, id int64, op *ber.Packet) error {
+	if !sess.bound {
+		return sess.result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights, "bind required")
+	}
+	if len(op.Children) != 8 {
+		return sess.result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "invalid search request")
+	}
+
+	base := normalizeDN(text(op.Children[0]))
+	scope, _ := op.Children[1].Value.(int64)
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attributes []string
+	for _, attr := range op.Children[7].Children {
+		attributes = append(attributes, text(attr))
+	}
+
+	var count int64
+	for _, entry := range s.entries {
+		if !inScope(normalizeDN(entry.DN), base, scope) || !matchFilter(filter, entry.Attributes) {
+			continue
+		}
+		if sizeLimit > 0 && count == sizeLimit {
+			return sess.result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, "")
+		}
+		count++
+		if err := sess.write(id, searchResultEntry(entry, attributes)); err != nil {
+			return err
+		}
+	}
+	return sess.result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+}
+
+func searchResultEntry(entry Entry, attributes []string) *ber.Packet {
+	attrs := ber.NewSequence("attributes")
+	for name, values := range entry.Attributes {
+		if len(attributes) > 0 && !containsFold(attributes, name) {
+			continue
+		}
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attr := ber.NewSequence("attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "search result entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "dn"))
+	op.AppendChild(attrs)
+	return op
+}
+
+func (s *Server) extended(sess *session, id int64, op *ber.Packet) error {
+	if len(op.Children) == 0 || text(op.Children[0]) != oidStartTLS {
+		return sess.result(id, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")
+	}
+	if _, ok := sess.conn.(*tls.Conn); ok {
+		return sess.result(id, ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform, "tls is already started")
+	}
+
+	if err := sess.result(id, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, ""); err != nil {
+		return err
+	}
+	tlsConn := tls.Server(sess.conn, s.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	sess.conn = tlsConn
+	sess.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+func (sess *session) result(id int64, tag ber.Tag, code uint16, message string) error {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "diagnosticMessage"))
+	return sess.write(id, op)
+}
+
+func (sess *session) write(id int64, op *ber.Packet) error {
+	message := ber.NewSequence("message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "messageID"))
+	message.AppendChild(op)
+	_, err := sess.conn.Write(message.Bytes())
+	return err
+}
+
+// text returns the value of a primitive packet as a string.
+func text(p *ber.Packet) string {
+	return p.Data.String()
+}
+
+// matchFilter returns true if the attributes match the search filter. Only
+// the filters used by the LDAP provider are supported: and, or, not,
+// equality, substrings, and present. Matching is case insensitive.
+func matchFilter(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case filterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range filter.Children {
+			if matchFilter(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return len(filter.Children) == 1 && !matchFilter(filter.Children[0], attributes)
+	case filterPresent:
+		return len(attributeValues(attributes, text(filter))) > 0
+	case filterEqual:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		expected := text(filter.Children[1])
+		for _, v := range attributeValues(attributes, text(filter.Children[0])) {
+			if strings.EqualFold(v, expected) {
+				return true
+			}
+		}
+		return false
+	case filterSubstring:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range attributeValues(attributes, text(filter.Children[0])) {
+			if matchSubstrings(strings.ToLower(v), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// matchSubstrings matches v against the initial (0), any (1), and final (2)
+// substrings of a substrings filter.
+func matchSubstrings(v string, substrings []*ber.Packet) bool {
+	for _, sub := range substrings {
+		s := strings.ToLower(text(sub))
+		switch sub.Tag {
+		case 0:
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case 1:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case 2:
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attributeValues(attributes map[string][]string, name string) []string {
+	for k, values := range attributes {
+		if strings.EqualFold(k, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(parts[i]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+		return parent == base
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+/*
+Package samlidp provides a SAML identity provider for tests. It creates
+metadata and signed responses for a key pair generated by the test.
+*/
+package samlidp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/generate"
+)
+
+type TestingT interface {
+	assert.TestingT
+	Helper()
+}
+
+const (
+	// EntityID is the entity ID of the identity provider
+	EntityID = "https://idp.example.com/saml"
+	// SSOURL is the single sign-on URL of the identity provider
+	SSOURL = "https://idp.example.com/saml/sso"
+)
+
+type IdentityProvider struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+// New returns an identity provider with a new signing key.
+func New(t TestingT) *IdentityProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	assert.NilError(t, err)
+	return &IdentityProvider{key: key, cert: cert}
+}
+
+// Metadata returns the metadata document of the identity provider.
+func (p *IdentityProvider) Metadata() string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%[1]s">
+  <md:IDPSSODescriptor WantAuthnRequestsSigned="false" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>%[2]s</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</md:NameIDFormat>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="%[3]s"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="%[3]s"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`,
+		EntityID, base64.StdEncoding.EncodeToString(p.cert.Raw), SSOURL)
+}
+
+// Assertion describes the SAML response created by Response.
+type Assertion struct {
+	// Recipient is the ACS URL of the service provider
+	Recipient string
+	// Audience is the entity ID of the service provider
+	Audience string
+	// InResponseTo is the ID of the authentication request from the service
+	// provider
+	InResponseTo string
+
+	NameID          string
+	Email           string
+	Groups          []string
+	GroupsAttribute string // defaults to groups
+
+	// Issuer defaults to EntityID
+	Issuer string
+	// NotOnOrAfter defaults to 5 minutes from now
+	NotOnOrAfter time.Time
+	// SignResponse signs the response instead of the assertion
+	SignResponse bool
+}
+
+// Response returns a base64 encoded SAML response, as it would be posted to
+// the ACS URL of the service provider.
+func (p *IdentityProvider) Response(t TestingT, a Assertion) string {
+	t.Helper()
+
+	if a.Issuer == "" {
+		a.Issuer = EntityID
+	}
+	if a.NotOnOrAfter.IsZero() {
+		a.NotOnOrAfter = time.Now().Add(5 * time.Minute)
+	}
+	if a.GroupsAttribute == "" {
+		a.GroupsAttribute = "groups"
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	assertionID, err := generate.CryptoRandom(20, generate.CharsetAlphaNumeric)
+	assert.NilError(t, err)
+	notOnOrAfter := a.NotOnOrAfter.UTC().Format(time.RFC3339)
+
+	var attributes strings.Builder
+	if a.Email != "" {
+		fmt.Fprintf(&attributes, `<saml:Attribute Name="email"><saml:AttributeValue>%s</saml:AttributeValue></saml:Attribute>`, escape(a.Email))
+	}
+	if len(a.Groups) > 0 {
+		fmt.Fprintf(&attributes, `<saml:Attribute Name="%s">`, escape(a.GroupsAttribute))
+		for _, group := range a.Groups {
+			fmt.Fprintf(&attributes, `<saml:AttributeValue>%s</saml:AttributeValue>`, escape(group))
+		}
+		attributes.WriteString(`</saml:Attribute>`)
+	}
+
+	raw := fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response" Version="2.0" IssueInstant="%[1]s" Destination="%[2]s" InResponseTo="%[8]s">`+
+		`<saml:Issuer>%[3]s</saml:Issuer>`+
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>`+
+		`<saml:Assertion ID="_%[9]s" Version="2.0" IssueInstant="%[1]s">`+
+		`<saml:Issuer>%[3]s</saml:Issuer>`+
+		`<saml:Subject>`+
+		`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">%[4]s</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">`+
+		`<saml:SubjectConfirmationData NotOnOrAfter="%[5]s" Recipient="%[2]s" InResponseTo="%[8]s"/>`+
+		`</saml:SubjectConfirmation>`+
+		`</saml:Subject>`+
+		`<saml:Conditions NotBefore="%[1]s" NotOnOrAfter="%[5]s">`+
+		`<saml:AudienceRestriction><saml:Audience>%[6]s</saml:Audience></saml:AudienceRestriction>`+
+		`</saml:Conditions>`+
+		`<saml:AuthnStatement AuthnInstant="%[1]s"/>`+
+		`<saml:AttributeStatement>%[7]s</saml:AttributeStatement>`+
+		`</saml:Assertion>`+
+		`</samlp:Response>`,
+		now, escape(a.Recipient), escape(a.Issuer), escape(a.NameID), notOnOrAfter, escape(a.Audience), attributes.String(),
+		escape(a.InResponseTo), assertionID)
+
+	doc := etree.NewDocument()
+	assert.NilError(t, doc.ReadFromString(raw))
+	response := doc.Root()
+
+	signed := response.SelectElement("Assertion")
+	if a.SignResponse {
+		signed = response
+	}
+	// sign a copy that declares the namespaces of its parents, the same way
+	// the copy is created when the signature is validated
+	nsCtx, err := etreeutils.NSBuildParentContext(signed)
+	assert.NilError(t, err)
+	detached, err := etreeutils.NSDetatch(nsCtx, signed)
+	assert.NilError(t, err)
+
+	signing := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{p.cert.Raw},
+		PrivateKey:  p.key,
+	}))
+	signing.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	sig, err := signing.ConstructSignature(detached, true)
+	assert.NilError(t, err)
+	// the signature must follow the issuer
+	detached.InsertChildAt(detached.SelectElement("Issuer").Index()+1, sig)
+
+	if signed == response {
+		doc.SetRoot(detached)
+	} else {
+		response.InsertChildAt(signed.Index(), detached)
+		response.RemoveChild(signed)
+	}
+
+	out, err := doc.WriteToBytes()
+	assert.NilError(t, err)
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// secret from the test vectors in RFC 6238 appendix B, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	type testCase struct {
+		unix     int64
+		expected string
+	}
+
+	// the RFC uses 8 digit codes, these are the last 6 digits
+	testCases := []testCase{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tc := range testCases {
+		actual, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+		assert.NilError(t, err)
+		assert.Equal(t, actual, tc.expected, "unix=%v", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	t.Run("current period", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, "005924", now)
+		assert.Assert(t, ok)
+		assert.Equal(t, step, Step(now))
+	})
+
+	t.Run("previous period within skew", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, "005924", now.Add(Period*time.Second))
+		assert.Assert(t, ok)
+		assert.Equal(t, step, Step(now))
+	})
+
+	t.Run("outside skew", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "005924", now.Add(2*Period*time.Second))
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("spaces are ignored", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "005 924", now)
+		assert.Assert(t, ok)
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "123456", now)
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, ok := Validate("not base32!", "005924", now)
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NilError(t, err)
+	assert.Equal(t, len(secret), 32)
+
+	code, err := Code(secret, Step(time.Now()))
+	assert.NilError(t, err)
+	_, ok := Validate(secret, code, time.Now())
+	assert.Assert(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Infra", "alice@example.com", rfcSecret)
+
+	u, err := url.Parse(uri)
+	assert.NilError(t, err)
+	assert.Equal(t, u.Scheme, "otpauth")
+	assert.Equal(t, u.Host, "totp")
+	assert.Equal(t, u.Path, "/Infra:alice@example.com")
+	assert.Check(t, is.Equal(u.Query().Get("secret"), rfcSecret))
+	assert.Check(t, is.Equal(u.Query().Get("issuer"), "Infra"))
+}
@@ -10,6 +10,19 @@ export function oidcLogin(
   { baseDomain, loginDomain, id, clientID, authURL, scopes, kind },
   next
 ) {
+  if (kind === 'saml') {
+    // saml logins are started by the server, which redirects to the identity provider
+    const sendTo = new URL(
+      `/api/providers/${id}/saml/login`,
+      window.location.origin
+    )
+    if (next) {
+      sendTo.searchParams.append('next', decodeURIComponent(next))
+    }
+    document.location.href = sendTo.href
+    return
+  }
+
   if (baseDomain === '') {
     // this is possible if not configured on the server
     // fallback to the browser domain
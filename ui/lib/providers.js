@@ -22,6 +22,7 @@ export const providers = [
   {
     name: 'GitHub',
     kind: 'github',
+    available: true,
   },
   {
     name: 'GitLab',
@@ -27,6 +27,7 @@ export default function Login() {
   const [error, setError] = useState('')
   const [errors, setErrors] = useState({})
   const [updatePasswordForUser, setUpdatePasswordForUser] = useState('')
+  const [ldapProvider, setLDAPProvider] = useState(null)
   const { isEmailConfigured, baseDomain, loginDomain } = useServerConfig()
   const { login } = useUser()
 
@@ -34,12 +35,22 @@ export default function Login() {
     e.preventDefault()
 
     try {
-      const data = await login({
-        passwordCredentials: {
-          name,
-          password,
-        },
-      })
+      const data = await login(
+        ldapProvider
+          ? {
+              ldap: {
+                providerID: ldapProvider.id,
+                name,
+                password,
+              },
+            }
+          : {
+              passwordCredentials: {
+                name,
+                password,
+              },
+            }
+      )
 
       if (data.passwordUpdateRequired) {
         setUpdatePasswordForUser(data.userID)
@@ -69,6 +80,17 @@ export default function Login() {
     return false
   }
 
+  function providerLogin(provider, next) {
+    if (provider.kind === 'ldap') {
+      // ldap providers check the username and password from the form
+      setLDAPProvider(provider)
+      setErrors({})
+      setError('')
+      return
+    }
+    oidcLogin(provider, next)
+  }
+
   return (
     <div className='flex w-full flex-col items-center px-10 pt-4 pb-6'>
       <h1 className='mt-4 font-display text-2xl font-semibold leading-snug'>
@@ -87,7 +109,7 @@ export default function Login() {
                 providers={providers || []}
                 baseDomain={baseDomain}
                 loginDomain={loginDomain}
-                authnFunc={oidcLogin}
+                authnFunc={providerLogin}
                 buttonPrompt={'Log in with'}
               />
               <div className='relative mt-6 mb-2 w-full'>
@@ -112,13 +134,13 @@ export default function Login() {
                   htmlFor='name'
                   className='text-2xs font-medium text-gray-700'
                 >
-                  Email
+                  {ldapProvider ? `${ldapProvider.name} username` : 'Email'}
                 </label>
                 <input
                   required
                   autoFocus
                   id='name'
-                  type='email'
+                  type={ldapProvider ? 'text' : 'email'}
                   onChange={e => {
                     setName(e.target.value)
                     setErrors({})
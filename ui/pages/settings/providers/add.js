@@ -50,6 +50,7 @@ export default function ProvidersAddDetails() {
   const [privateKey, setPrivateKey] = useState('')
   const [clientEmail, setClientEmail] = useState('')
   const [domainAdminEmail, setDomainAdminEmail] = useState('')
+  const [githubOrgs, setGitHubOrgs] = useState('')
   const [error, setError] = useState('')
   const [errors, setErrors] = useState({})
   const [name, setName] = useState('')
@@ -57,7 +58,13 @@ export default function ProvidersAddDetails() {
   const [keyDialogOpen, setKeyDialogOpen] = useState(false)
 
   useEffect(() => {
-    setURL(type === 'google' ? 'accounts.google.com' : '')
+    if (type === 'google') {
+      setURL('accounts.google.com')
+    } else if (type === 'github') {
+      setURL('github.com')
+    } else {
+      setURL('')
+    }
   }, [type])
 
   function docLink() {
@@ -92,6 +99,15 @@ export default function ProvidersAddDetails() {
               clientSecret,
               kind,
               api,
+              github:
+                kind === 'github'
+                  ? {
+                      organizations: githubOrgs
+                        .split(',')
+                        .map(org => org.trim())
+                        .filter(org => org),
+                    }
+                  : undefined,
             }),
           })
 
@@ -372,6 +388,34 @@ export default function ProvidersAddDetails() {
                   </p>
                 )}
               </div>
+              {kind === 'github' && (
+                <div>
+                  <label className='text-2xs font-medium text-gray-700'>
+                    Organizations
+                  </label>
+                  <input
+                    required
+                    type='text'
+                    placeholder='infrahq, example'
+                    value={githubOrgs}
+                    onChange={e => {
+                      setGitHubOrgs(e.target.value)
+                      setErrors({})
+                      setError('')
+                    }}
+                    className={`mt-1 block w-full rounded-md shadow-sm focus:border-blue-500 focus:ring-blue-500 sm:text-sm ${
+                      errors['github.organizations']
+                        ? 'border-red-500'
+                        : 'border-gray-300'
+                    }`}
+                  />
+                  {errors['github.organizations'] && (
+                    <p className='my-1 text-xs text-red-500'>
+                      {errors['github.organizations']}
+                    </p>
+                  )}
+                </div>
+              )}
               <div className='group'>
                 <input
                   name='scim-checkbox'